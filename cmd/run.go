@@ -8,11 +8,13 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"syscall"
 	"time"
 
 	"github.com/docker/docker/client"
 	"github.com/go-playground/validator/v10"
+	"github.com/hide-org/hide/pkg/dap"
 	"github.com/hide-org/hide/pkg/devcontainer"
 	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/gitignore"
@@ -23,6 +25,7 @@ import (
 	"github.com/hide-org/hide/pkg/project"
 	"github.com/hide-org/hide/pkg/random"
 	"github.com/hide-org/hide/pkg/util"
+	"github.com/hide-org/hide/pkg/watcher"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -35,9 +38,12 @@ const (
 )
 
 var (
-	envPath string
-	debug   bool
-	port    int
+	envPath            string
+	debug              bool
+	port               int
+	diagnosticsTimeout time.Duration
+	languageServers    string
+	languageThreshold  float64
 )
 
 func init() {
@@ -45,6 +51,9 @@ func init() {
 	pf.StringVar(&envPath, "env", DefaultDotEnvPath, "path to the .env file")
 	pf.BoolVar(&debug, "debug", false, "run service in a debug mode")
 	pf.IntVar(&port, "port", 8080, "service port")
+	pf.StringVar(&languageServers, "language-servers", "", "path to a JSON file with language servers in addition to the default ones")
+	pf.Float64Var(&languageThreshold, "language-threshold", project.DefaultLanguageThreshold, "share of the code of a project a language needs for its language server to be started")
+	pf.DurationVar(&diagnosticsTimeout, "diagnostics-timeout", project.DefaultDiagnosticsTimeout, "how long to wait for the diagnostics of a changed file")
 }
 
 var runCmd = &cobra.Command{
@@ -87,7 +96,8 @@ var runCmd = &cobra.Command{
 			log.Fatal().Err(err).Msg("Cannot initialize docker client")
 		}
 
-		containerRunner := devcontainer.NewDockerRunner(devcontainer.NewExecutorImpl(), devcontainer.NewImageManager(dockerClient, random.String, devcontainer.NewDockerHubRegistryCredentials(dockerUser, dockerToken)), devcontainer.NewDockerContainerManager(dockerClient))
+		containerManager := devcontainer.NewDockerContainerManager(dockerClient)
+		containerRunner := devcontainer.NewDockerRunner(devcontainer.NewExecutorImpl(), devcontainer.NewImageManager(dockerClient, random.String, devcontainer.NewDockerHubRegistryCredentials(dockerUser, dockerToken)), containerManager)
 		projectStore := project.NewInMemoryStore(make(map[string]*model.Project))
 		home, err := os.UserHomeDir()
 		if err != nil {
@@ -96,12 +106,44 @@ var runCmd = &cobra.Command{
 
 		projectsDir := filepath.Join(home, HidePath, ProjectsDir)
 
-		fileManager := files.NewFileManager(gitignore.NewMatcherFactory())
+		fileWatcher := watcher.NewService(gitignore.NewMatcherFactory(), watcher.DefaultDebounce)
+		fileManager := files.NewFileManager(gitignore.NewMatcherFactory(), files.NewTreeCache(gitignore.NewMatcherFactory(), fileWatcher))
 		languageDetector := lsp.NewLanguageDetector()
 		diagnosticsStore := lsp.NewDiagnosticsStore()
 		clientPool := lsp.NewClientPool()
-		lspService := lsp.NewService(languageDetector, lsp.LspServerExecutables, diagnosticsStore, clientPool)
-		projectManager := project.NewProjectManager(containerRunner, projectStore, projectsDir, fileManager, lspService, languageDetector, random.String)
+		servers := lsp.DefaultServers
+		if languageServers != "" {
+			content, err := os.ReadFile(languageServers)
+			if err != nil {
+				log.Fatal().Err(err).Msgf("Failed to read language servers from %s", languageServers)
+			}
+
+			configured, err := lsp.ParseServers(content)
+			if err != nil {
+				log.Fatal().Err(err).Msgf("Failed to read language servers from %s", languageServers)
+			}
+
+			servers = append(slices.Clone(servers), configured...)
+		}
+
+		registry := lsp.NewRegistry(servers...)
+		lspService := lsp.NewService(languageDetector, registry, diagnosticsStore, clientPool, lsp.NewContainerProcessFactory(containerManager))
+		// symbols of languages without a running language server are found in an index of the project files
+		lspService = lsp.NewSymbolIndexService(lspService, registry, languageDetector, fileManager)
+		debugService := dap.NewService(dap.NewRegistry(dap.DefaultAdapters...), lsp.NewContainerProcessFactory(containerManager), random.String)
+		projectManager := project.NewProjectManager(project.ManagerOptions{
+			DevContainerRunner: containerRunner,
+			Store:              projectStore,
+			ProjectsRoot:       projectsDir,
+			FileManager:        fileManager,
+			LspService:         lspService,
+			DebugService:       debugService,
+			LanguageDetector:   languageDetector,
+			FileWatcher:        fileWatcher,
+			RandomString:       random.String,
+			DiagnosticsTimeout: diagnosticsTimeout,
+			LanguageThreshold:  languageThreshold,
+		})
 		validator := validator.New(validator.WithRequiredStructEnabled())
 
 		router := handlers.
@@ -112,11 +154,41 @@ var runCmd = &cobra.Command{
 			WithListTasksHandler(handlers.ListTasksHandler{Manager: projectManager}).
 			WithCreateFileHandler(handlers.CreateFileHandler{ProjectManager: projectManager}).
 			WithListFilesHandler(handlers.ListFilesHandler{ProjectManager: projectManager}).
+			WithFileOutlineHandler(middleware.PathValidator(handlers.FileOutlineHandler{ProjectManager: projectManager})).
 			WithReadFileHandler(middleware.PathValidator(handlers.ReadFileHandler{ProjectManager: projectManager})).
 			WithUpdateFileHandler(middleware.PathValidator(handlers.UpdateFileHandler{ProjectManager: projectManager})).
 			WithDeleteFileHandler(middleware.PathValidator(handlers.DeleteFileHandler{ProjectManager: projectManager})).
 			WithSearchFileHandler(handlers.SearchFilesHandler{ProjectManager: projectManager}).
 			WithSearchSymbolsHandler(handlers.NewSearchSymbolsHandler(projectManager)).
+			WithFileEventsHandler(handlers.FileEventsHandler{ProjectManager: projectManager}).
+			WithDownloadArchiveHandler(handlers.DownloadArchiveHandler{ProjectManager: projectManager}).
+			WithUploadArchiveHandler(handlers.UploadArchiveHandler{ProjectManager: projectManager}).
+			WithProjectDiagnosticsHandler(handlers.ProjectDiagnosticsHandler{ProjectManager: projectManager}).
+			WithDiagnosticsStreamHandler(handlers.DiagnosticsStreamHandler{ProjectManager: projectManager}).
+			WithLanguageServersHandler(handlers.LanguageServersHandler{ProjectManager: projectManager}).
+			WithStartLanguageServerHandler(handlers.StartLanguageServerHandler{ProjectManager: projectManager}).
+			WithStopLanguageServerHandler(handlers.StopLanguageServerHandler{ProjectManager: projectManager}).
+			WithLanguageServerWebSocketHandler(handlers.LanguageServerWebSocketHandler{ProjectManager: projectManager}).
+			WithFindDefinitionHandler(handlers.FindDefinitionHandler{ProjectManager: projectManager}).
+			WithFindReferencesHandler(handlers.FindReferencesHandler{ProjectManager: projectManager}).
+			WithHoverHandler(handlers.HoverHandler{ProjectManager: projectManager}).
+			WithCompletionHandler(handlers.CompletionHandler{ProjectManager: projectManager}).
+			WithCallHierarchyHandler(handlers.CallHierarchyHandler{ProjectManager: projectManager}).
+			WithTypeHierarchyHandler(handlers.TypeHierarchyHandler{ProjectManager: projectManager}).
+			WithFormatFileHandler(middleware.PathValidator(handlers.FormatFileHandler{ProjectManager: projectManager})).
+			WithRenameHandler(handlers.RenameHandler{ProjectManager: projectManager}).
+			WithListCodeActionsHandler(handlers.ListCodeActionsHandler{ProjectManager: projectManager}).
+			WithApplyCodeActionHandler(handlers.ApplyCodeActionHandler{ProjectManager: projectManager}).
+			WithLaunchDebugSessionHandler(handlers.LaunchDebugSessionHandler{ProjectManager: projectManager}).
+			WithListDebugSessionsHandler(handlers.ListDebugSessionsHandler{ProjectManager: projectManager}).
+			WithGetDebugSessionHandler(handlers.GetDebugSessionHandler{ProjectManager: projectManager}).
+			WithTerminateDebugSessionHandler(handlers.TerminateDebugSessionHandler{ProjectManager: projectManager}).
+			WithSetBreakpointsHandler(handlers.SetBreakpointsHandler{ProjectManager: projectManager}).
+			WithStepDebugSessionHandler(handlers.StepDebugSessionHandler{ProjectManager: projectManager}).
+			WithDebugThreadsHandler(handlers.DebugThreadsHandler{ProjectManager: projectManager}).
+			WithDebugStackTraceHandler(handlers.DebugStackTraceHandler{ProjectManager: projectManager}).
+			WithDebugScopesHandler(handlers.DebugScopesHandler{ProjectManager: projectManager}).
+			WithDebugVariablesHandler(handlers.DebugVariablesHandler{ProjectManager: projectManager}).
 			Build()
 
 		addr := fmt.Sprintf("127.0.0.1:%d", port)
@@ -0,0 +1,220 @@
+package dap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/rs/zerolog/log"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// Connection sends requests to a debug adapter. Unlike JSON-RPC, DAP has its own message format, only the framing is the same as the one of LSP.
+type Connection interface {
+	// Call sends a request and decodes the body of the response into result, if it's not nil. Failed responses are returned as *RequestError.
+	Call(ctx context.Context, command string, arguments any, result any) error
+	// Go sends a request like Call but doesn't wait for the response, the error of the call is sent to the returned channel.
+	// The request is sent before Go returns, so that the requests after it are received after it.
+	Go(ctx context.Context, command string, arguments any, result any) <-chan error
+	// DisconnectNotify returns a channel that is closed when the connection is closed, e.g. when the adapter exited
+	DisconnectNotify() <-chan struct{}
+	Close() error
+}
+
+// Handler handles the events of the adapter and the requests it sends to the client, e.g. startDebugging.
+// Events are handled in the order they are received, the connection doesn't read other messages until they are handled.
+type Handler interface {
+	HandleEvent(event string, body json.RawMessage)
+	HandleRequest(ctx context.Context, command string, arguments json.RawMessage) (any, error)
+}
+
+type request struct {
+	Seq       int    `json:"seq"`
+	Type      string `json:"type"`
+	Command   string `json:"command"`
+	Arguments any    `json:"arguments,omitempty"`
+}
+
+type response struct {
+	Seq        int    `json:"seq"`
+	Type       string `json:"type"`
+	RequestSeq int    `json:"request_seq"`
+	Command    string `json:"command"`
+	Success    bool   `json:"success"`
+	Message    string `json:"message,omitempty"`
+	Body       any    `json:"body,omitempty"`
+}
+
+type ConnectionImpl struct {
+	stream  jsonrpc2.ObjectStream
+	handler Handler
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	seq     int
+	pending map[int]chan *message
+
+	disconnect chan struct{}
+	closeOnce  sync.Once
+}
+
+func NewConnection(rwc io.ReadWriteCloser, handler Handler, mapping lsp.PathMapping) Connection {
+	stream := newPathMappingStream(jsonrpc2.NewBufferedStream(rwc, jsonrpc2.VSCodeObjectCodec{}), mapping)
+
+	c := &ConnectionImpl{
+		stream:     stream,
+		handler:    handler,
+		pending:    make(map[int]chan *message),
+		disconnect: make(chan struct{}),
+	}
+	go c.read()
+	return c
+}
+
+// Call implements Connection.
+func (c *ConnectionImpl) Call(ctx context.Context, command string, arguments any, result any) error {
+	seq, done, err := c.send(command, arguments)
+	if err != nil {
+		return err
+	}
+
+	return c.wait(ctx, command, seq, done, result)
+}
+
+// Go implements Connection.
+func (c *ConnectionImpl) Go(ctx context.Context, command string, arguments any, result any) <-chan error {
+	errs := make(chan error, 1)
+
+	seq, done, err := c.send(command, arguments)
+	if err != nil {
+		errs <- err
+		return errs
+	}
+
+	go func() {
+		errs <- c.wait(ctx, command, seq, done, result)
+	}()
+	return errs
+}
+
+func (c *ConnectionImpl) send(command string, arguments any) (int, chan *message, error) {
+	c.mu.Lock()
+	c.seq++
+	seq := c.seq
+	done := make(chan *message, 1)
+	c.pending[seq] = done
+	c.mu.Unlock()
+
+	if err := c.write(request{Seq: seq, Type: messageTypeRequest, Command: command, Arguments: arguments}); err != nil {
+		c.forget(seq)
+		return 0, nil, fmt.Errorf("Failed to send %s request: %w", command, err)
+	}
+
+	return seq, done, nil
+}
+
+func (c *ConnectionImpl) wait(ctx context.Context, command string, seq int, done chan *message, result any) error {
+	defer c.forget(seq)
+
+	select {
+	case resp := <-done:
+		if !resp.Success {
+			return newRequestError(command, resp)
+		}
+
+		if result != nil && len(resp.Body) > 0 {
+			if err := json.Unmarshal(resp.Body, result); err != nil {
+				return fmt.Errorf("Failed to decode %s response: %w", command, err)
+			}
+		}
+		return nil
+	case <-c.disconnect:
+		return fmt.Errorf("Debug adapter disconnected during %s request", command)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *ConnectionImpl) forget(seq int) {
+	c.mu.Lock()
+	delete(c.pending, seq)
+	c.mu.Unlock()
+}
+
+func (c *ConnectionImpl) DisconnectNotify() <-chan struct{} {
+	return c.disconnect
+}
+
+// Close implements Connection.
+func (c *ConnectionImpl) Close() error {
+	err := c.stream.Close()
+	c.closed()
+	return err
+}
+
+func (c *ConnectionImpl) closed() {
+	c.closeOnce.Do(func() {
+		close(c.disconnect)
+	})
+}
+
+func (c *ConnectionImpl) write(msg any) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.stream.WriteObject(msg)
+}
+
+func (c *ConnectionImpl) read() {
+	defer c.closed()
+
+	for {
+		var msg message
+		if err := c.stream.ReadObject(&msg); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrClosedPipe) {
+				log.Debug().Err(err).Msg("Debug adapter connection closed")
+			}
+			return
+		}
+
+		switch msg.Type {
+		case messageTypeResponse:
+			c.mu.Lock()
+			done, ok := c.pending[msg.RequestSeq]
+			c.mu.Unlock()
+
+			if ok {
+				done <- &msg
+			}
+		case messageTypeEvent:
+			c.handler.HandleEvent(msg.Event, msg.Body)
+		case messageTypeRequest:
+			// requests of the adapter may call the adapter again, so they must not block reading its responses
+			go c.reply(msg)
+		default:
+			log.Warn().Str("type", msg.Type).Msg("Unknown debug adapter message")
+		}
+	}
+}
+
+func (c *ConnectionImpl) reply(req message) {
+	body, err := c.handler.HandleRequest(context.Background(), req.Command, req.Arguments)
+
+	c.mu.Lock()
+	c.seq++
+	seq := c.seq
+	c.mu.Unlock()
+
+	resp := response{Seq: seq, Type: messageTypeResponse, RequestSeq: req.Seq, Command: req.Command, Success: err == nil, Body: body}
+	if err != nil {
+		resp.Message = err.Error()
+	}
+
+	if err := c.write(resp); err != nil {
+		log.Warn().Err(err).Str("command", req.Command).Msg("Failed to answer debug adapter request")
+	}
+}
@@ -0,0 +1,67 @@
+package dap
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type AdapterNotFoundError struct {
+	Language string
+}
+
+func (e AdapterNotFoundError) Error() string {
+	return fmt.Sprintf("No debug adapter for language %s", e.Language)
+}
+
+func NewAdapterNotFoundError(language string) *AdapterNotFoundError {
+	return &AdapterNotFoundError{Language: language}
+}
+
+type SessionNotFoundError struct {
+	ProjectId ProjectId
+	SessionId SessionId
+}
+
+func (e SessionNotFoundError) Error() string {
+	return fmt.Sprintf("Debug session %s not found in project %s", e.SessionId, e.ProjectId)
+}
+
+func NewSessionNotFoundError(projectId ProjectId, sessionId SessionId) *SessionNotFoundError {
+	return &SessionNotFoundError{ProjectId: projectId, SessionId: sessionId}
+}
+
+// SessionStateError is returned for requests that need another state of the session, e.g. stack traces of a running program.
+type SessionStateError struct {
+	SessionId SessionId
+	Status    SessionStatus
+	Request   string
+}
+
+func (e SessionStateError) Error() string {
+	return fmt.Sprintf("Cannot %s, debug session %s is %s", e.Request, e.SessionId, e.Status)
+}
+
+func NewSessionStateError(sessionId SessionId, status SessionStatus, request string) *SessionStateError {
+	return &SessionStateError{SessionId: sessionId, Status: status, Request: request}
+}
+
+// RequestError is a request the debug adapter answered with a failure, e.g. a launch of a program that doesn't exist.
+type RequestError struct {
+	Command string
+	Message string
+}
+
+func (e RequestError) Error() string {
+	return fmt.Sprintf("Debug adapter failed %s request: %s", e.Command, e.Message)
+}
+
+func newRequestError(command string, resp *message) *RequestError {
+	msg := resp.Message
+
+	var body errorResponseBody
+	if err := json.Unmarshal(resp.Body, &body); err == nil && body.Error != nil && body.Error.Format != "" {
+		msg = body.Error.Format
+	}
+
+	return &RequestError{Command: command, Message: msg}
+}
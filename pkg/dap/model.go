@@ -0,0 +1,106 @@
+package dap
+
+import (
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+)
+
+type ProjectId = model.ProjectId
+
+type SessionId = string
+
+type LaunchOptions struct {
+	Language lsp.LanguageId
+	// Program is the file or package to debug, relative to the project root
+	Program string
+	Args    []string
+	// Test debugs the tests of the program instead of running it
+	Test bool
+	// Cwd is relative to the project root, the program runs in the project root if it's empty
+	Cwd         string
+	Env         map[string]string
+	StopOnEntry bool
+	// Breakpoints are set before the program runs, by file path relative to the project root
+	Breakpoints map[string][]SourceBreakpoint
+	// Configuration is merged into the launch arguments of the adapter, e.g. to set justMyCode of debugpy
+	Configuration map[string]any
+}
+
+type SessionStatus string
+
+const (
+	SessionRunning    SessionStatus = "running"
+	SessionStopped    SessionStatus = "stopped"
+	SessionTerminated SessionStatus = "terminated"
+)
+
+type Session struct {
+	Id       SessionId      `json:"id"`
+	Language lsp.LanguageId `json:"language"`
+	Program  string         `json:"program"`
+	Status   SessionStatus  `json:"status"`
+	// Stopped tells why and where the program stopped, it is only set while the session is stopped
+	Stopped  *StoppedInfo `json:"stopped,omitempty"`
+	ExitCode *int         `json:"exitCode,omitempty"`
+	// Output is the output of the program since the session was launched or last resumed, only its end is kept if it is long
+	Output string `json:"output"`
+}
+
+type StoppedInfo struct {
+	// Reason is e.g. breakpoint, step, exception or pause
+	Reason      string `json:"reason"`
+	Description string `json:"description,omitempty"`
+	// ThreadId is the thread that stopped, it is the default thread of the stack trace and the steps
+	ThreadId int `json:"threadId"`
+}
+
+// StepAction continues or steps a stopped thread, or pauses a running one. The values are the commands of the protocol.
+type StepAction string
+
+const (
+	StepContinue StepAction = "continue"
+	StepNext     StepAction = "next"
+	StepIn       StepAction = "stepIn"
+	StepOut      StepAction = "stepOut"
+	StepPause    StepAction = "pause"
+)
+
+func (a StepAction) Valid() bool {
+	switch a {
+	case StepContinue, StepNext, StepIn, StepOut, StepPause:
+		return true
+	}
+	return false
+}
+
+type Breakpoint struct {
+	Line int `json:"line"`
+	// Verified is false if the adapter couldn't set the breakpoint, e.g. on a line without code
+	Verified bool   `json:"verified"`
+	Message  string `json:"message,omitempty"`
+}
+
+type StackFrame struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+	// Path is relative to the project root, or absolute for frames outside of the project, e.g. in the standard library
+	Path string `json:"path,omitempty"`
+	// Line is 1-based and Column 0-based, like positions in the rest of Hide
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type Scope struct {
+	Name string `json:"name"`
+	// VariablesReference is passed to GetVariables to get the variables of the scope
+	VariablesReference int  `json:"variablesReference"`
+	Expensive          bool `json:"expensive"`
+}
+
+type Variable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+	// VariablesReference is not 0 for structured values, whose fields or elements are variables of their own
+	VariablesReference int `json:"variablesReference"`
+}
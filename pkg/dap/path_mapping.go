@@ -0,0 +1,64 @@
+package dap
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// pathMappingStream rewrites the paths of messages sent to the debug adapter from host to adapter paths, and back for the messages it sends.
+// Unlike LSP, DAP has plain paths, so any string that starts with the project path is rewritten, e.g. the program and the cwd of a launch.
+type pathMappingStream struct {
+	jsonrpc2.ObjectStream
+	toAdapter *pathRewriter
+	toHost    *pathRewriter
+}
+
+func newPathMappingStream(stream jsonrpc2.ObjectStream, mapping lsp.PathMapping) jsonrpc2.ObjectStream {
+	if mapping.Host == mapping.Server {
+		return stream
+	}
+
+	return &pathMappingStream{
+		ObjectStream: stream,
+		toAdapter:    newPathRewriter(mapping.Host, mapping.Server),
+		toHost:       newPathRewriter(mapping.Server, mapping.Host),
+	}
+}
+
+func (s *pathMappingStream) WriteObject(obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	return s.ObjectStream.WriteObject(json.RawMessage(s.toAdapter.rewrite(data)))
+}
+
+func (s *pathMappingStream) ReadObject(v interface{}) error {
+	var data json.RawMessage
+	if err := s.ObjectStream.ReadObject(&data); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(s.toHost.rewrite(data), v)
+}
+
+type pathRewriter struct {
+	pattern     *regexp.Regexp
+	replacement []byte
+}
+
+func newPathRewriter(from, to string) *pathRewriter {
+	// only strings that start with the path are rewritten, and only whole path segments, /project must not match /project2
+	return &pathRewriter{
+		pattern:     regexp.MustCompile(`"` + regexp.QuoteMeta(from) + `([/"])`),
+		replacement: []byte(`"` + to + `$1`),
+	}
+}
+
+func (r *pathRewriter) rewrite(data []byte) []byte {
+	return r.pattern.ReplaceAll(data, r.replacement)
+}
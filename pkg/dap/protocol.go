@@ -0,0 +1,178 @@
+package dap
+
+import "encoding/json"
+
+// The Debug Adapter Protocol, only the parts Hide uses. See https://microsoft.github.io/debug-adapter-protocol/specification
+
+const (
+	messageTypeRequest  = "request"
+	messageTypeResponse = "response"
+	messageTypeEvent    = "event"
+)
+
+// message is any message of the protocol, its type tells which of the fields are set.
+type message struct {
+	Seq  int    `json:"seq"`
+	Type string `json:"type"`
+
+	// requests
+	Command   string          `json:"command,omitempty"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+
+	// responses, they also have the command of the request
+	RequestSeq int    `json:"request_seq,omitempty"`
+	Success    bool   `json:"success,omitempty"`
+	Message    string `json:"message,omitempty"`
+
+	// events, responses also have a body
+	Event string          `json:"event,omitempty"`
+	Body  json.RawMessage `json:"body,omitempty"`
+}
+
+// errorResponseBody is the body of a failed response, the message of the response is only a short error code for some adapters.
+type errorResponseBody struct {
+	Error *struct {
+		Format string `json:"format"`
+	} `json:"error,omitempty"`
+}
+
+type InitializeRequestArguments struct {
+	ClientID                      string `json:"clientID,omitempty"`
+	ClientName                    string `json:"clientName,omitempty"`
+	AdapterID                     string `json:"adapterID"`
+	PathFormat                    string `json:"pathFormat,omitempty"`
+	LinesStartAt1                 bool   `json:"linesStartAt1"`
+	ColumnsStartAt1               bool   `json:"columnsStartAt1"`
+	SupportsVariableType          bool   `json:"supportsVariableType,omitempty"`
+	SupportsRunInTerminalRequest  bool   `json:"supportsRunInTerminalRequest,omitempty"`
+	SupportsStartDebuggingRequest bool   `json:"supportsStartDebuggingRequest,omitempty"`
+}
+
+type Capabilities struct {
+	SupportsConfigurationDoneRequest  bool `json:"supportsConfigurationDoneRequest,omitempty"`
+	SupportsConditionalBreakpoints    bool `json:"supportsConditionalBreakpoints,omitempty"`
+	SupportsHitConditionalBreakpoints bool `json:"supportsHitConditionalBreakpoints,omitempty"`
+	SupportsLogPoints                 bool `json:"supportsLogPoints,omitempty"`
+	SupportsTerminateRequest          bool `json:"supportsTerminateRequest,omitempty"`
+}
+
+type Source struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+type SourceBreakpoint struct {
+	Line         int    `json:"line"`
+	Condition    string `json:"condition,omitempty"`
+	HitCondition string `json:"hitCondition,omitempty"`
+	LogMessage   string `json:"logMessage,omitempty"`
+}
+
+type SetBreakpointsArguments struct {
+	Source      Source             `json:"source"`
+	Breakpoints []SourceBreakpoint `json:"breakpoints"`
+}
+
+type SetBreakpointsResponseBody struct {
+	Breakpoints []ProtocolBreakpoint `json:"breakpoints"`
+}
+
+type ProtocolBreakpoint struct {
+	Id       int     `json:"id,omitempty"`
+	Verified bool    `json:"verified"`
+	Message  string  `json:"message,omitempty"`
+	Source   *Source `json:"source,omitempty"`
+	Line     int     `json:"line,omitempty"`
+}
+
+// ThreadArguments are the arguments of continue, next, stepIn, stepOut and pause.
+type ThreadArguments struct {
+	ThreadId int `json:"threadId"`
+}
+
+type Thread struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ThreadsResponseBody struct {
+	Threads []Thread `json:"threads"`
+}
+
+type StackTraceArguments struct {
+	ThreadId   int `json:"threadId"`
+	StartFrame int `json:"startFrame,omitempty"`
+	Levels     int `json:"levels,omitempty"`
+}
+
+type ProtocolStackFrame struct {
+	Id     int     `json:"id"`
+	Name   string  `json:"name"`
+	Source *Source `json:"source,omitempty"`
+	Line   int     `json:"line"`
+	Column int     `json:"column"`
+}
+
+type StackTraceResponseBody struct {
+	StackFrames []ProtocolStackFrame `json:"stackFrames"`
+	TotalFrames int                  `json:"totalFrames,omitempty"`
+}
+
+type ScopesArguments struct {
+	FrameId int `json:"frameId"`
+}
+
+type ProtocolScope struct {
+	Name               string `json:"name"`
+	VariablesReference int    `json:"variablesReference"`
+	Expensive          bool   `json:"expensive"`
+}
+
+type ScopesResponseBody struct {
+	Scopes []ProtocolScope `json:"scopes"`
+}
+
+type VariablesArguments struct {
+	VariablesReference int `json:"variablesReference"`
+	Start              int `json:"start,omitempty"`
+	Count              int `json:"count,omitempty"`
+}
+
+type ProtocolVariable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	Type               string `json:"type,omitempty"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+type VariablesResponseBody struct {
+	Variables []ProtocolVariable `json:"variables"`
+}
+
+type DisconnectArguments struct {
+	TerminateDebuggee bool `json:"terminateDebuggee"`
+}
+
+type StoppedEventBody struct {
+	Reason            string `json:"reason"`
+	Description       string `json:"description,omitempty"`
+	ThreadId          int    `json:"threadId,omitempty"`
+	Text              string `json:"text,omitempty"`
+	AllThreadsStopped bool   `json:"allThreadsStopped,omitempty"`
+}
+
+type OutputEventBody struct {
+	Category string `json:"category,omitempty"`
+	Output   string `json:"output"`
+}
+
+type ExitedEventBody struct {
+	ExitCode int `json:"exitCode"`
+}
+
+// StartDebuggingRequestArguments are sent by adapters that debug each process in a child session, e.g. js-debug.
+type StartDebuggingRequestArguments struct {
+	Configuration map[string]any `json:"configuration"`
+	// Request is launch or attach
+	Request string `json:"request"`
+}
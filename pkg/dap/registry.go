@@ -0,0 +1,122 @@
+package dap
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hide-org/hide/pkg/lsp"
+)
+
+// portPlaceholder in the arguments of an adapter is replaced with the port it listens on.
+const portPlaceholder = "{port}"
+
+// AdapterConfig describes how to start a debug adapter and how to launch programs with it.
+type AdapterConfig struct {
+	Command string
+	Args    []string
+	// Type is the adapter ID that is sent with the initialize request
+	Type string
+	// Languages are the languages the adapter debugs, the IDs of the LanguageDetector
+	Languages []lsp.LanguageId
+	// Listens is true for adapters that only talk DAP over a socket, e.g. delve. They are reached through a bridge in the project container.
+	Listens bool
+
+	// launchArguments returns the adapter specific arguments of the launch request, program and cwd are absolute
+	launchArguments func(opts LaunchOptions, program, cwd string) map[string]any
+}
+
+func (c AdapterConfig) command(port int) lsp.Command {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = strings.ReplaceAll(arg, portPlaceholder, strconv.Itoa(port))
+	}
+	return lsp.NewCommand(c.Command, args)
+}
+
+// DefaultAdapters are the debug adapters Hide knows, they must be installed in the project container.
+var DefaultAdapters = []AdapterConfig{
+	{
+		Command:   "dlv",
+		Args:      []string{"dap", "--listen=127.0.0.1:" + portPlaceholder},
+		Type:      "go",
+		Languages: []lsp.LanguageId{lsp.Go},
+		Listens:   true,
+		launchArguments: func(opts LaunchOptions, program, cwd string) map[string]any {
+			mode := "debug"
+			if opts.Test {
+				mode = "test"
+			}
+			return commonLaunchArguments(opts, program, cwd, map[string]any{"mode": mode})
+		},
+	},
+	{
+		Command:   "python3",
+		Args:      []string{"-m", "debugpy.adapter"},
+		Type:      "debugpy",
+		Languages: []lsp.LanguageId{lsp.Python},
+		launchArguments: func(opts LaunchOptions, program, cwd string) map[string]any {
+			arguments := commonLaunchArguments(opts, program, cwd, map[string]any{"console": "internalConsole", "justMyCode": true})
+			// tests are run with pytest, the program is the file or directory of the tests
+			if opts.Test {
+				delete(arguments, "program")
+				arguments["module"] = "pytest"
+				arguments["args"] = append([]string{program}, opts.Args...)
+			}
+			return arguments
+		},
+	},
+	{
+		Command:   "js-debug-adapter",
+		Args:      []string{portPlaceholder, "127.0.0.1"},
+		Type:      "pwa-node",
+		Languages: []lsp.LanguageId{lsp.JavaScript, lsp.TypeScript},
+		Listens:   true,
+		launchArguments: func(opts LaunchOptions, program, cwd string) map[string]any {
+			arguments := commonLaunchArguments(opts, program, cwd, map[string]any{"type": "pwa-node", "console": "internalConsole"})
+			// tests are run with the test runner of node
+			if opts.Test {
+				arguments["runtimeArgs"] = []string{"--test"}
+			}
+			return arguments
+		},
+	},
+}
+
+func commonLaunchArguments(opts LaunchOptions, program, cwd string, arguments map[string]any) map[string]any {
+	arguments["program"] = program
+	arguments["cwd"] = cwd
+	arguments["stopOnEntry"] = opts.StopOnEntry
+	if len(opts.Args) > 0 {
+		arguments["args"] = opts.Args
+	}
+	if len(opts.Env) > 0 {
+		arguments["env"] = opts.Env
+	}
+	return arguments
+}
+
+// Registry knows the debug adapters of the languages.
+type Registry interface {
+	Get(languageId lsp.LanguageId) (AdapterConfig, bool)
+}
+
+type RegistryImpl struct {
+	adapters []AdapterConfig
+}
+
+// NewRegistry returns a registry of the adapters, later adapters replace earlier ones for the same languages.
+func NewRegistry(adapters ...AdapterConfig) Registry {
+	return &RegistryImpl{adapters: adapters}
+}
+
+// Get implements Registry.
+func (r *RegistryImpl) Get(languageId lsp.LanguageId) (AdapterConfig, bool) {
+	for i := len(r.adapters) - 1; i >= 0; i-- {
+		if slices.Contains(r.adapters[i].Languages, languageId) {
+			return r.adapters[i], true
+		}
+	}
+
+	return AdapterConfig{}, false
+}
@@ -0,0 +1,477 @@
+package dap
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultTimeout is how long launches and steps wait for the program to stop
+	DefaultTimeout = 30 * time.Second
+
+	// adapters that listen on a socket get a port of this range in the project container
+	minAdapterPort = 40000
+	maxAdapterPort = 50000
+
+	// idleSessionTimeout is how long a session is kept without any request for it before it is ended and its adapter stopped
+	idleSessionTimeout = 30 * time.Minute
+	// terminatedSessionTimeout is how long a terminated session is kept, so that its exit code and output can still be read
+	terminatedSessionTimeout = 10 * time.Minute
+	// reapInterval is how often sessions are checked for these timeouts
+	reapInterval = time.Minute
+
+	// bridgeScript connects to an adapter in the project container and copies its socket to stdout and stdin. It only needs bash,
+	// the adapter is retried until it listens.
+	bridgeScript = `for i in $(seq 100); do { exec 3<>/dev/tcp/127.0.0.1/%d; } 2>/dev/null && break; sleep 0.1; done; cat >&3 & exec cat <&3`
+)
+
+// Service debugs programs of the projects with the debug adapters of their languages. Like the language servers, the adapters
+// run in the project container, so they use its toolchain.
+type Service interface {
+	// Launch starts the debug adapter of the language in the project of the context and launches the program with the breakpoints of the options.
+	// It waits until the program stops or terminates, at most for timeout, the session may still be running then. If the context is done
+	// while waiting, the session is ended.
+	Launch(ctx context.Context, opts LaunchOptions, timeout time.Duration) (*Session, error)
+	GetSession(ctx context.Context, sessionId SessionId) (*Session, error)
+	// ListSessions returns the sessions of the project in the order they were launched. Terminated sessions are kept until they are deleted
+	// or for a while after they terminated, sessions without requests for a long time are ended.
+	ListSessions(ctx context.Context) []Session
+	// SetBreakpoints replaces the breakpoints of the file, which is relative to the project root. Without breakpoints the ones of the file are removed.
+	SetBreakpoints(ctx context.Context, sessionId SessionId, path string, breakpoints []SourceBreakpoint) ([]Breakpoint, error)
+	// Step continues or steps the thread that stopped, or pauses the running program, and waits like Launch. Another thread can be given by its id.
+	Step(ctx context.Context, sessionId SessionId, action StepAction, threadId int, timeout time.Duration) (*Session, error)
+	GetThreads(ctx context.Context, sessionId SessionId) ([]Thread, error)
+	// GetStackTrace returns the frames of the thread that stopped, or of threadId if it's not 0, at most levels of them unless levels is 0
+	GetStackTrace(ctx context.Context, sessionId SessionId, threadId int, levels int) ([]StackFrame, error)
+	GetScopes(ctx context.Context, sessionId SessionId, frameId int) ([]Scope, error)
+	GetVariables(ctx context.Context, sessionId SessionId, variablesReference int) ([]Variable, error)
+	// Terminate ends the session and deletes it, the program is terminated and the adapter stopped
+	Terminate(ctx context.Context, sessionId SessionId) error
+	CleanupProject(ctx context.Context, projectId ProjectId) error
+}
+
+type ServiceImpl struct {
+	registry       Registry
+	processFactory lsp.ProcessFactory
+	randomString   func(int) string
+
+	idleTimeout       time.Duration
+	terminatedTimeout time.Duration
+
+	mu       sync.Mutex
+	sessions map[ProjectId][]*session
+	// ports are the ports of the adapters in the containers of the projects
+	ports map[ProjectId]map[int]struct{}
+}
+
+func NewService(registry Registry, processFactory lsp.ProcessFactory, randomString func(int) string) Service {
+	s := &ServiceImpl{
+		registry:          registry,
+		processFactory:    processFactory,
+		randomString:      randomString,
+		idleTimeout:       idleSessionTimeout,
+		terminatedTimeout: terminatedSessionTimeout,
+		sessions:          make(map[ProjectId][]*session),
+		ports:             make(map[ProjectId]map[int]struct{}),
+	}
+
+	go s.reapSessions(reapInterval)
+	return s
+}
+
+// Launch implements Service.
+func (s *ServiceImpl) Launch(ctx context.Context, opts LaunchOptions, timeout time.Duration) (*Session, error) {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		log.Error().Msg("Project not found in context")
+		return nil, fmt.Errorf("Project not found in context")
+	}
+
+	adapter, ok := s.registry.Get(opts.Language)
+	if !ok {
+		return nil, NewAdapterNotFoundError(opts.Language)
+	}
+
+	sess := newSession(s.randomString(8), *project, opts, adapter)
+	parent := sess.newTarget()
+	conn, err := s.startAdapter(sess, parent)
+	if err != nil {
+		sess.end()
+		log.Error().Err(err).Str("projectId", project.Id).Str("command", adapter.Command).Msg("Failed to start debug adapter")
+		return nil, fmt.Errorf("Failed to start debug adapter %s: %w", adapter.Command, err)
+	}
+
+	parent.conn = conn
+	sess.mu.Lock()
+	sess.parent = parent
+	sess.active = parent
+	sess.mu.Unlock()
+
+	go func() {
+		<-conn.DisconnectNotify()
+		sess.disconnected()
+	}()
+
+	deadline := time.Now().Add(timeout)
+	startCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	if err := sess.start(startCtx, parent, "launch", sess.launchArguments(opts)); err != nil {
+		sess.end()
+		log.Error().Err(err).Str("projectId", project.Id).Str("program", opts.Program).Msg("Failed to launch program")
+		return nil, fmt.Errorf("Failed to launch %s: %w", opts.Program, err)
+	}
+
+	s.mu.Lock()
+	s.sessions[project.Id] = append(s.sessions[project.Id], sess)
+	s.mu.Unlock()
+
+	log.Debug().Str("projectId", project.Id).Str("sessionId", sess.id).Str("program", opts.Program).Msg("Launched program")
+
+	state, err := sess.wait(ctx, time.Until(deadline))
+	if err != nil {
+		// the caller doesn't get the id of the session, so it couldn't be terminated otherwise
+		s.remove(sess)
+		sess.end()
+		return nil, err
+	}
+	return &state, nil
+}
+
+// startAdapter starts the adapter process of the session and connects the target to it.
+func (s *ServiceImpl) startAdapter(sess *session, t *target) (Connection, error) {
+	port, err := s.allocatePort(sess.project.Id)
+	if err != nil {
+		return nil, err
+	}
+	sess.onEnd(func() { s.releasePort(sess.project.Id, port) })
+
+	process, mapping, err := s.processFactory.NewProcess(sess.project, sess.adapter.command(port), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := process.Start(); err != nil {
+		return nil, err
+	}
+	sess.onEnd(func() { stopProcess(process) })
+
+	if !sess.adapter.Listens {
+		return NewConnection(process.ReadWriteCloser(), t, mapping), nil
+	}
+
+	// child sessions connect to the same adapter
+	sess.connect = func(handler Handler) (Connection, error) {
+		return s.bridge(sess, port, handler)
+	}
+	return s.bridge(sess, port, t)
+}
+
+// allocatePort picks a port of the adapter range that no other session of the project uses.
+func (s *ServiceImpl) allocatePort(projectId ProjectId) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.ports[projectId]; !ok {
+		s.ports[projectId] = make(map[int]struct{})
+	}
+
+	// the search starts at a random port, so that ports of ended sessions aren't reused right away
+	start := rand.Intn(maxAdapterPort - minAdapterPort)
+	for i := 0; i < maxAdapterPort-minAdapterPort; i++ {
+		port := minAdapterPort + (start+i)%(maxAdapterPort-minAdapterPort)
+		if _, used := s.ports[projectId][port]; !used {
+			s.ports[projectId][port] = struct{}{}
+			return port, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no free port for the debug adapter in project %s", projectId)
+}
+
+func (s *ServiceImpl) releasePort(projectId ProjectId, port int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.ports[projectId], port)
+	if len(s.ports[projectId]) == 0 {
+		delete(s.ports, projectId)
+	}
+}
+
+// bridge connects to an adapter that listens on the port in the project container, through a process that copies its socket.
+func (s *ServiceImpl) bridge(sess *session, port int, handler Handler) (Connection, error) {
+	process, mapping, err := s.processFactory.NewProcess(sess.project, lsp.NewCommand("bash", []string{"-c", fmt.Sprintf(bridgeScript, port)}), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := process.Start(); err != nil {
+		return nil, err
+	}
+	sess.onEnd(func() { stopProcess(process) })
+
+	return NewConnection(process.ReadWriteCloser(), handler, mapping), nil
+}
+
+func stopProcess(process lsp.Process) {
+	if err := process.Stop(); err != nil {
+		log.Warn().Err(err).Msg("Failed to stop debug adapter process")
+		return
+	}
+
+	// the exit of a stopped process is expected
+	_ = process.Wait()
+}
+
+// GetSession implements Service.
+func (s *ServiceImpl) GetSession(ctx context.Context, sessionId SessionId) (*Session, error) {
+	sess, err := s.getSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	state := sess.state()
+	return &state, nil
+}
+
+// ListSessions implements Service.
+func (s *ServiceImpl) ListSessions(ctx context.Context) []Session {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		log.Error().Msg("Project not found in context")
+		return nil
+	}
+
+	s.mu.Lock()
+	sessions := append([]*session{}, s.sessions[project.Id]...)
+	s.mu.Unlock()
+
+	states := make([]Session, 0, len(sessions))
+	for _, sess := range sessions {
+		states = append(states, sess.state())
+	}
+	return states
+}
+
+// SetBreakpoints implements Service.
+func (s *ServiceImpl) SetBreakpoints(ctx context.Context, sessionId SessionId, path string, breakpoints []SourceBreakpoint) ([]Breakpoint, error) {
+	sess, err := s.getSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return sess.setBreakpoints(ctx, path, breakpoints)
+}
+
+// Step implements Service.
+func (s *ServiceImpl) Step(ctx context.Context, sessionId SessionId, action StepAction, threadId int, timeout time.Duration) (*Session, error) {
+	sess, err := s.getSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := sess.step(ctx, action, threadId, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// GetThreads implements Service.
+func (s *ServiceImpl) GetThreads(ctx context.Context, sessionId SessionId) ([]Thread, error) {
+	sess, err := s.getSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	t, _, err := sess.stoppedTarget("get threads")
+	if err != nil {
+		return nil, err
+	}
+
+	return t.threads(ctx)
+}
+
+// GetStackTrace implements Service.
+func (s *ServiceImpl) GetStackTrace(ctx context.Context, sessionId SessionId, threadId int, levels int) ([]StackFrame, error) {
+	sess, err := s.getSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	t, stopped, err := sess.stoppedTarget("get stack trace")
+	if err != nil {
+		return nil, err
+	}
+
+	if threadId == 0 {
+		threadId = stopped.ThreadId
+	}
+
+	var body StackTraceResponseBody
+	if err := t.conn.Call(ctx, "stackTrace", StackTraceArguments{ThreadId: threadId, Levels: levels}, &body); err != nil {
+		return nil, err
+	}
+
+	frames := make([]StackFrame, 0, len(body.StackFrames))
+	for _, frame := range body.StackFrames {
+		stackFrame := StackFrame{Id: frame.Id, Name: frame.Name, Line: frame.Line, Column: frame.Column}
+		if frame.Source != nil && frame.Source.Path != "" {
+			stackFrame.Path = sess.projectPath(frame.Source.Path)
+		}
+		frames = append(frames, stackFrame)
+	}
+
+	return frames, nil
+}
+
+// GetScopes implements Service.
+func (s *ServiceImpl) GetScopes(ctx context.Context, sessionId SessionId, frameId int) ([]Scope, error) {
+	sess, err := s.getSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	t, _, err := sess.stoppedTarget("get scopes")
+	if err != nil {
+		return nil, err
+	}
+
+	var body ScopesResponseBody
+	if err := t.conn.Call(ctx, "scopes", ScopesArguments{FrameId: frameId}, &body); err != nil {
+		return nil, err
+	}
+
+	scopes := make([]Scope, 0, len(body.Scopes))
+	for _, scope := range body.Scopes {
+		scopes = append(scopes, Scope{Name: scope.Name, VariablesReference: scope.VariablesReference, Expensive: scope.Expensive})
+	}
+
+	return scopes, nil
+}
+
+// GetVariables implements Service.
+func (s *ServiceImpl) GetVariables(ctx context.Context, sessionId SessionId, variablesReference int) ([]Variable, error) {
+	sess, err := s.getSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	t, _, err := sess.stoppedTarget("get variables")
+	if err != nil {
+		return nil, err
+	}
+
+	var body VariablesResponseBody
+	if err := t.conn.Call(ctx, "variables", VariablesArguments{VariablesReference: variablesReference}, &body); err != nil {
+		return nil, err
+	}
+
+	variables := make([]Variable, 0, len(body.Variables))
+	for _, variable := range body.Variables {
+		variables = append(variables, Variable{Name: variable.Name, Value: variable.Value, Type: variable.Type, VariablesReference: variable.VariablesReference})
+	}
+
+	return variables, nil
+}
+
+// Terminate implements Service.
+func (s *ServiceImpl) Terminate(ctx context.Context, sessionId SessionId) error {
+	sess, err := s.getSession(ctx, sessionId)
+	if err != nil {
+		return err
+	}
+
+	s.remove(sess)
+	sess.end()
+	return nil
+}
+
+// remove deletes the session from the sessions of its project.
+func (s *ServiceImpl) remove(sess *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := s.sessions[sess.project.Id]
+	for i, other := range sessions {
+		if other == sess {
+			s.sessions[sess.project.Id] = append(sessions[:i:i], sessions[i+1:]...)
+			break
+		}
+	}
+}
+
+// reapSessions ends idle sessions and deletes terminated ones periodically.
+func (s *ServiceImpl) reapSessions(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		s.reap(now)
+	}
+}
+
+// reap ends the sessions that had no requests for the idle timeout and deletes the ones that terminated before the terminated timeout.
+func (s *ServiceImpl) reap(now time.Time) {
+	var expired []*session
+
+	s.mu.Lock()
+	for projectId, sessions := range s.sessions {
+		kept := sessions[:0:0]
+		for _, sess := range sessions {
+			if sess.expired(now, s.idleTimeout, s.terminatedTimeout) {
+				expired = append(expired, sess)
+			} else {
+				kept = append(kept, sess)
+			}
+		}
+		s.sessions[projectId] = kept
+	}
+	s.mu.Unlock()
+
+	for _, sess := range expired {
+		log.Debug().Str("projectId", sess.project.Id).Str("sessionId", sess.id).Msg("Deleting expired debug session")
+		sess.end()
+	}
+}
+
+// CleanupProject implements Service.
+func (s *ServiceImpl) CleanupProject(ctx context.Context, projectId ProjectId) error {
+	s.mu.Lock()
+	sessions := s.sessions[projectId]
+	delete(s.sessions, projectId)
+	s.mu.Unlock()
+
+	for _, sess := range sessions {
+		sess.end()
+	}
+	return nil
+}
+
+func (s *ServiceImpl) getSession(ctx context.Context, sessionId SessionId) (*session, error) {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		log.Error().Msg("Project not found in context")
+		return nil, fmt.Errorf("Project not found in context")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sess := range s.sessions[project.Id] {
+		if sess.id == sessionId {
+			sess.touch()
+			return sess, nil
+		}
+	}
+
+	return nil, NewSessionNotFoundError(project.Id, sessionId)
+}
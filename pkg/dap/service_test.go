@@ -0,0 +1,525 @@
+package dap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/random"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Debug(t *testing.T) {
+	project := model.Project{Id: "project-id", Path: "/test/project"}
+	ctx := model.NewContextWithProject(context.Background(), &project)
+
+	var launchArguments map[string]any
+	var pendingLaunch *message
+	adapter := func(a *fakeAdapter, req message) {
+		switch req.Command {
+		case "initialize":
+			a.respond(req, Capabilities{SupportsConfigurationDoneRequest: true})
+			a.event("initialized", nil)
+		case "launch":
+			// like debugpy, the launch is answered once the configuration is done
+			json.Unmarshal(req.Arguments, &launchArguments)
+			pendingLaunch = &req
+		case "setBreakpoints":
+			var args SetBreakpointsArguments
+			json.Unmarshal(req.Arguments, &args)
+			assert.Equal(t, "/workspaces/app/main.py", args.Source.Path)
+
+			breakpoints := []ProtocolBreakpoint{}
+			for _, breakpoint := range args.Breakpoints {
+				if breakpoint.Line > 10 {
+					breakpoints = append(breakpoints, ProtocolBreakpoint{Verified: false, Message: "no code"})
+					continue
+				}
+				breakpoints = append(breakpoints, ProtocolBreakpoint{Verified: true, Line: breakpoint.Line})
+			}
+			a.respond(req, SetBreakpointsResponseBody{Breakpoints: breakpoints})
+		case "configurationDone":
+			a.respond(req, nil)
+			a.respond(*pendingLaunch, nil)
+			a.event("output", OutputEventBody{Category: "telemetry", Output: "ptvsd"})
+			a.event("output", OutputEventBody{Category: "stdout", Output: "starting\n"})
+			a.event("stopped", StoppedEventBody{Reason: "breakpoint", ThreadId: 1})
+		case "stackTrace":
+			a.respond(req, StackTraceResponseBody{StackFrames: []ProtocolStackFrame{
+				{Id: 1, Name: "main", Source: &Source{Path: "/workspaces/app/main.py"}, Line: 3},
+				{Id: 2, Name: "_run_code", Source: &Source{Path: "/usr/lib/python3.12/runpy.py"}, Line: 88, Column: 4},
+			}})
+		case "scopes":
+			a.respond(req, ScopesResponseBody{Scopes: []ProtocolScope{{Name: "Locals", VariablesReference: 10}}})
+		case "variables":
+			a.respond(req, VariablesResponseBody{Variables: []ProtocolVariable{{Name: "x", Value: "42", Type: "int"}}})
+		case "next":
+			a.respond(req, nil)
+			a.event("continued", map[string]any{"threadId": 1})
+			a.event("stopped", StoppedEventBody{Reason: "step", ThreadId: 1})
+		case "continue":
+			a.respond(req, nil)
+			a.event("output", OutputEventBody{Category: "stdout", Output: "done\n"})
+			a.event("exited", ExitedEventBody{ExitCode: 0})
+			a.event("terminated", nil)
+		case "disconnect":
+			a.respond(req, nil)
+		default:
+			a.fail(req, "unknown command")
+		}
+	}
+
+	factory := &fakeProcessFactory{t: t, mapping: lsp.PathMapping{Host: project.Path, Server: "/workspaces/app"}, adapters: []adapterHandler{adapter}}
+	service := NewService(NewRegistry(DefaultAdapters...), factory, random.FixedString)
+
+	got, err := service.Launch(ctx, LaunchOptions{
+		Language:      lsp.Python,
+		Program:       "main.py",
+		Breakpoints:   map[string][]SourceBreakpoint{"main.py": {{Line: 3}}},
+		Configuration: map[string]any{"justMyCode": false},
+	}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "python3 -m debugpy.adapter", factory.commands[0])
+	assert.Equal(t, map[string]any{
+		"program":     "/workspaces/app/main.py",
+		"cwd":         "/workspaces/app",
+		"stopOnEntry": false,
+		"console":     "internalConsole",
+		"justMyCode":  false,
+	}, launchArguments)
+	assert.Equal(t, &Session{Id: "test", Language: lsp.Python, Program: "main.py", Status: SessionStopped, Stopped: &StoppedInfo{Reason: "breakpoint", ThreadId: 1}, Output: "starting\n"}, got)
+
+	breakpoints, err := service.SetBreakpoints(ctx, "test", "main.py", []SourceBreakpoint{{Line: 3}, {Line: 40}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Breakpoint{{Line: 3, Verified: true}, {Line: 40, Message: "no code"}}, breakpoints)
+
+	// paths of the project are relative, others stay absolute
+	frames, err := service.GetStackTrace(ctx, "test", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []StackFrame{
+		{Id: 1, Name: "main", Path: "main.py", Line: 3},
+		{Id: 2, Name: "_run_code", Path: "/usr/lib/python3.12/runpy.py", Line: 88, Column: 4},
+	}, frames)
+
+	scopes, err := service.GetScopes(ctx, "test", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Scope{{Name: "Locals", VariablesReference: 10}}, scopes)
+
+	variables, err := service.GetVariables(ctx, "test", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Variable{{Name: "x", Value: "42", Type: "int"}}, variables)
+
+	got, err = service.Step(ctx, "test", StepNext, 0, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, SessionStopped, got.Status)
+	assert.Equal(t, &StoppedInfo{Reason: "step", ThreadId: 1}, got.Stopped)
+	assert.Empty(t, got.Output, "the output is of the last step only")
+
+	got, err = service.Step(ctx, "test", StepContinue, 0, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exitCode := 0
+	assert.Equal(t, &Session{Id: "test", Language: lsp.Python, Program: "main.py", Status: SessionTerminated, ExitCode: &exitCode, Output: "done\n"}, got)
+
+	_, err = service.GetStackTrace(ctx, "test", 0, 0)
+	assert.Equal(t, NewSessionStateError("test", SessionTerminated, "get stack trace"), err)
+
+	// terminated sessions are kept until they are deleted
+	assert.Len(t, service.ListSessions(ctx), 1)
+	if err := service.Terminate(ctx, "test"); err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, service.ListSessions(ctx))
+
+	_, err = service.GetSession(ctx, "test")
+	assert.Equal(t, NewSessionNotFoundError("project-id", "test"), err)
+}
+
+func TestService_ChildSession(t *testing.T) {
+	project := model.Project{Id: "project-id", Path: "/test/project"}
+	ctx := model.NewContextWithProject(context.Background(), &project)
+
+	started := make(chan bool, 1)
+	parent := func(a *fakeAdapter, req message) {
+		switch req.Command {
+		case "initialize":
+			a.respond(req, Capabilities{SupportsConfigurationDoneRequest: true})
+			a.event("initialized", nil)
+		case "launch", "setBreakpoints", "disconnect":
+			a.respond(req, nil)
+		case "configurationDone":
+			a.respond(req, nil)
+			// like js-debug, the program is debugged in a child session
+			go func() {
+				resp := a.request("startDebugging", StartDebuggingRequestArguments{Request: "attach", Configuration: map[string]any{"__pendingTargetId": "target-1"}})
+				started <- resp.Success
+			}()
+		default:
+			a.fail(req, "unknown command")
+		}
+	}
+
+	var attachArguments map[string]any
+	var childBreakpoints SetBreakpointsArguments
+	child := func(a *fakeAdapter, req message) {
+		switch req.Command {
+		case "initialize":
+			a.respond(req, Capabilities{SupportsConfigurationDoneRequest: true})
+			a.event("initialized", nil)
+		case "attach":
+			json.Unmarshal(req.Arguments, &attachArguments)
+			a.respond(req, nil)
+		case "setBreakpoints":
+			json.Unmarshal(req.Arguments, &childBreakpoints)
+			a.respond(req, SetBreakpointsResponseBody{Breakpoints: []ProtocolBreakpoint{{Verified: true, Line: 2}}})
+		case "configurationDone":
+			a.respond(req, nil)
+			a.event("stopped", StoppedEventBody{Reason: "breakpoint", ThreadId: 7})
+		case "stackTrace":
+			var args StackTraceArguments
+			json.Unmarshal(req.Arguments, &args)
+			assert.Equal(t, 7, args.ThreadId)
+			a.respond(req, StackTraceResponseBody{StackFrames: []ProtocolStackFrame{{Id: 1, Name: "main", Source: &Source{Path: "/test/project/index.js"}, Line: 2}}})
+		case "disconnect":
+			a.respond(req, nil)
+		default:
+			a.fail(req, "unknown command")
+		}
+	}
+
+	factory := &fakeProcessFactory{t: t, adapters: []adapterHandler{parent, child}}
+	service := NewService(NewRegistry(DefaultAdapters...), factory, random.FixedString)
+
+	got, err := service.Launch(ctx, LaunchOptions{
+		Language:    lsp.JavaScript,
+		Program:     "index.js",
+		Breakpoints: map[string][]SourceBreakpoint{"index.js": {{Line: 2}}},
+	}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, <-started)
+	assert.True(t, strings.HasPrefix(factory.commands[0], "js-debug-adapter "))
+	assert.True(t, strings.HasPrefix(factory.commands[1], "bash -c "), "the adapter is reached through a bridge")
+	assert.Equal(t, SessionStopped, got.Status)
+	assert.Equal(t, &StoppedInfo{Reason: "breakpoint", ThreadId: 7}, got.Stopped)
+	assert.Equal(t, map[string]any{"__pendingTargetId": "target-1"}, attachArguments)
+	assert.Equal(t, SetBreakpointsArguments{Source: Source{Name: "index.js", Path: "/test/project/index.js"}, Breakpoints: []SourceBreakpoint{{Line: 2}}}, childBreakpoints)
+
+	// requests go to the child session that stopped
+	frames, err := service.GetStackTrace(ctx, "test", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []StackFrame{{Id: 1, Name: "main", Path: "index.js", Line: 2}}, frames)
+
+	if err := service.CleanupProject(ctx, project.Id); err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, service.ListSessions(ctx))
+}
+
+func TestService_Launch_Errors(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+
+	adapter := func(a *fakeAdapter, req message) {
+		switch req.Command {
+		case "initialize":
+			a.respond(req, Capabilities{})
+		case "launch":
+			a.failWithBody(req, "launch failed", map[string]any{"error": map[string]any{"format": "program /test/project/missing.py does not exist"}})
+		case "disconnect":
+			a.respond(req, nil)
+		}
+	}
+
+	factory := &fakeProcessFactory{t: t, adapters: []adapterHandler{adapter}}
+	service := NewService(NewRegistry(DefaultAdapters...), factory, random.FixedString)
+
+	_, err := service.Launch(ctx, LaunchOptions{Language: "Rust", Program: "main.rs"}, time.Second)
+	assert.Equal(t, NewAdapterNotFoundError("Rust"), err)
+
+	_, err = service.Launch(ctx, LaunchOptions{Language: lsp.Python, Program: "missing.py"}, time.Second)
+	var requestError *RequestError
+	if assert.True(t, errors.As(err, &requestError)) {
+		assert.Equal(t, "program /test/project/missing.py does not exist", requestError.Message)
+	}
+	assert.Empty(t, service.ListSessions(ctx), "failed launches are not kept")
+}
+
+func TestService_Launch_Canceled(t *testing.T) {
+	project := model.Project{Id: "project-id", Path: "/test/project"}
+	ctx, cancel := context.WithCancel(model.NewContextWithProject(context.Background(), &project))
+	defer cancel()
+
+	disconnected := make(chan struct{})
+	adapter := func(a *fakeAdapter, req message) {
+		switch req.Command {
+		case "initialize":
+			a.respond(req, Capabilities{})
+			a.event("initialized", nil)
+		case "launch", "setBreakpoints":
+			a.respond(req, nil)
+		case "disconnect":
+			a.respond(req, nil)
+			close(disconnected)
+		}
+	}
+
+	factory := &fakeProcessFactory{t: t, adapters: []adapterHandler{adapter}}
+	service := NewService(NewRegistry(DefaultAdapters...), factory, random.FixedString)
+
+	// the program keeps running, the client goes away while Launch waits
+	go func() {
+		for len(service.ListSessions(ctx)) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+
+	_, err := service.Launch(ctx, LaunchOptions{Language: lsp.Python, Program: "main.py"}, time.Minute)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, service.ListSessions(ctx), "the session is ended, its id was never returned")
+
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("adapter not disconnected")
+	}
+}
+
+func TestService_Reap(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+
+	newAdapter := func(event string) adapterHandler {
+		return func(a *fakeAdapter, req message) {
+			switch req.Command {
+			case "initialize":
+				a.respond(req, Capabilities{SupportsConfigurationDoneRequest: true})
+				a.event("initialized", nil)
+			case "launch", "setBreakpoints", "disconnect":
+				a.respond(req, nil)
+			case "configurationDone":
+				a.respond(req, nil)
+				a.event(event, StoppedEventBody{Reason: "breakpoint", ThreadId: 1})
+			}
+		}
+	}
+
+	factory := &fakeProcessFactory{t: t, adapters: []adapterHandler{newAdapter("stopped"), newAdapter("terminated")}}
+	service := NewService(NewRegistry(DefaultAdapters...), factory, random.FixedString).(*ServiceImpl)
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.Launch(ctx, LaunchOptions{Language: lsp.Python, Program: "main.py"}, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	statuses := func() []SessionStatus {
+		var statuses []SessionStatus
+		for _, sess := range service.ListSessions(ctx) {
+			statuses = append(statuses, sess.Status)
+		}
+		return statuses
+	}
+
+	service.reap(time.Now())
+	assert.Equal(t, []SessionStatus{SessionStopped, SessionTerminated}, statuses(), "recent sessions are kept")
+
+	service.reap(time.Now().Add(terminatedSessionTimeout))
+	assert.Equal(t, []SessionStatus{SessionStopped}, statuses(), "terminated sessions are deleted after a while")
+
+	service.reap(time.Now().Add(idleSessionTimeout))
+	assert.Empty(t, statuses(), "idle sessions are ended")
+
+	// terminated sessions end in the background
+	assert.Eventually(t, func() bool {
+		service.mu.Lock()
+		defer service.mu.Unlock()
+		return len(service.ports) == 0
+	}, time.Second, time.Millisecond, "the ports of ended sessions are released")
+}
+
+func TestService_AllocatePort(t *testing.T) {
+	service := NewService(NewRegistry(), nil, random.FixedString).(*ServiceImpl)
+
+	ports := make(map[int]bool)
+	for i := minAdapterPort; i < maxAdapterPort; i++ {
+		port, err := service.allocatePort("project-id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.False(t, ports[port], "port %d is allocated twice", port)
+		assert.True(t, port >= minAdapterPort && port < maxAdapterPort)
+		ports[port] = true
+	}
+
+	_, err := service.allocatePort("project-id")
+	assert.Error(t, err, "all ports are in use")
+
+	// other projects have their own containers
+	_, err = service.allocatePort("other-project-id")
+	assert.NoError(t, err)
+
+	service.releasePort("project-id", minAdapterPort)
+	port, err := service.allocatePort("project-id")
+	assert.NoError(t, err)
+	assert.Equal(t, minAdapterPort, port)
+}
+
+type adapterHandler func(a *fakeAdapter, req message)
+
+// fakeProcessFactory connects each process to the next adapter handler, except debug adapters that listen on a socket, only their bridges are connected.
+type fakeProcessFactory struct {
+	t        *testing.T
+	mapping  lsp.PathMapping
+	adapters []adapterHandler
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func (f *fakeProcessFactory) NewProcess(project model.Project, command lsp.Command, stderr io.Writer) (lsp.Process, lsp.PathMapping, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.commands = append(f.commands, command.String())
+	if strings.HasPrefix(command.String(), "js-debug-adapter") {
+		return newFakeProcess(nil), f.mapping, nil
+	}
+
+	handler := f.adapters[0]
+	f.adapters = f.adapters[1:]
+	return newFakeProcess(handler), f.mapping, nil
+}
+
+type fakeProcess struct {
+	handler adapterHandler
+	client  net.Conn
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newFakeProcess(handler adapterHandler) *fakeProcess {
+	return &fakeProcess{handler: handler, done: make(chan struct{})}
+}
+
+func (p *fakeProcess) Start() error {
+	client, server := net.Pipe()
+	p.client = client
+
+	if p.handler != nil {
+		adapter := &fakeAdapter{stream: jsonrpc2.NewBufferedStream(server, jsonrpc2.VSCodeObjectCodec{}), handler: p.handler, responses: make(map[int]chan message)}
+		go adapter.serve()
+	}
+	return nil
+}
+
+func (p *fakeProcess) Stop() error {
+	p.once.Do(func() {
+		p.client.Close()
+		close(p.done)
+	})
+	return nil
+}
+
+func (p *fakeProcess) ReadWriteCloser() io.ReadWriteCloser {
+	return p.client
+}
+
+func (p *fakeProcess) Wait() error {
+	<-p.done
+	return nil
+}
+
+// fakeAdapter answers the requests of the client with its handler, in the order they are received.
+type fakeAdapter struct {
+	stream  jsonrpc2.ObjectStream
+	handler adapterHandler
+
+	mu        sync.Mutex
+	seq       int
+	responses map[int]chan message
+}
+
+func (a *fakeAdapter) serve() {
+	for {
+		var msg message
+		if err := a.stream.ReadObject(&msg); err != nil {
+			return
+		}
+
+		if msg.Type == messageTypeResponse {
+			a.mu.Lock()
+			a.responses[msg.RequestSeq] <- msg
+			a.mu.Unlock()
+			continue
+		}
+
+		a.handler(a, msg)
+	}
+}
+
+func (a *fakeAdapter) write(msg map[string]any) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.seq++
+	msg["seq"] = a.seq
+	a.stream.WriteObject(msg)
+	return a.seq
+}
+
+func (a *fakeAdapter) respond(req message, body any) {
+	a.write(map[string]any{"type": "response", "request_seq": req.Seq, "command": req.Command, "success": true, "body": body})
+}
+
+func (a *fakeAdapter) fail(req message, msg string) {
+	a.failWithBody(req, msg, nil)
+}
+
+func (a *fakeAdapter) failWithBody(req message, msg string, body any) {
+	a.write(map[string]any{"type": "response", "request_seq": req.Seq, "command": req.Command, "success": false, "message": msg, "body": body})
+}
+
+func (a *fakeAdapter) event(event string, body any) {
+	a.write(map[string]any{"type": "event", "event": event, "body": body})
+}
+
+// request sends a request to the client and waits for its response.
+func (a *fakeAdapter) request(command string, arguments any) message {
+	response := make(chan message, 1)
+
+	a.mu.Lock()
+	a.seq++
+	seq := a.seq
+	a.responses[seq] = response
+	a.stream.WriteObject(map[string]any{"seq": seq, "type": "request", "command": command, "arguments": arguments})
+	a.mu.Unlock()
+
+	return <-response
+}
@@ -0,0 +1,579 @@
+package dap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxOutput is how much of the output of the program a session keeps between steps
+	maxOutput = 64 * 1024
+	// disconnectTimeout bounds the disconnect request at the end of a session, the adapter may not answer anymore
+	disconnectTimeout = 5 * time.Second
+	// childStartTimeout bounds the start of a child session, which the adapter asks for while the program runs
+	childStartTimeout = 30 * time.Second
+)
+
+// session is a program that is debugged with a debug adapter in the project container.
+type session struct {
+	id       SessionId
+	project  model.Project
+	language lsp.LanguageId
+	program  string
+	adapter  AdapterConfig
+	// connect opens another connection to the adapter for a child session, it is nil for adapters that don't listen on a socket
+	connect func(handler Handler) (Connection, error)
+
+	mu       sync.Mutex
+	parent   *target
+	children []*target
+	// active is the target of the debugged program, a child session for adapters like js-debug, or the target that stopped last
+	active   *target
+	status   SessionStatus
+	stopped  *StoppedInfo
+	exitCode *int
+	output   []byte
+	// breakpoints are kept by absolute path, so that they are also set in child sessions
+	breakpoints map[string][]SourceBreakpoint
+	// changed is closed and replaced when the status changes
+	changed chan struct{}
+	// closers stop the processes of the session when it ends
+	closers []func()
+	ended   bool
+	// used is when the session was last requested, terminated when it terminated, they are compared with the timeouts of the service
+	used       time.Time
+	terminated time.Time
+}
+
+func newSession(id SessionId, project model.Project, opts LaunchOptions, adapter AdapterConfig) *session {
+	s := &session{
+		id:          id,
+		project:     project,
+		language:    opts.Language,
+		program:     opts.Program,
+		adapter:     adapter,
+		status:      SessionRunning,
+		breakpoints: make(map[string][]SourceBreakpoint),
+		changed:     make(chan struct{}),
+		used:        time.Now(),
+	}
+
+	for path, breakpoints := range opts.Breakpoints {
+		s.breakpoints[s.absolutePath(path)] = breakpoints
+	}
+
+	return s
+}
+
+// target is a connection to the debug adapter, a session has one of its own and one for each child session.
+type target struct {
+	session      *session
+	conn         Connection
+	capabilities Capabilities
+	initialized  chan struct{}
+	initOnce     sync.Once
+}
+
+func (s *session) newTarget() *target {
+	return &target{session: s, initialized: make(chan struct{})}
+}
+
+// HandleEvent implements Handler.
+func (t *target) HandleEvent(event string, body json.RawMessage) {
+	t.session.handleEvent(t, event, body)
+}
+
+// HandleRequest implements Handler.
+func (t *target) HandleRequest(ctx context.Context, command string, arguments json.RawMessage) (any, error) {
+	return t.session.handleRequest(command, arguments)
+}
+
+// launchArguments returns the arguments of the launch request, the configuration of the options replaces the ones of the adapter.
+func (s *session) launchArguments(opts LaunchOptions) map[string]any {
+	program, cwd := s.absolutePath(opts.Program), s.absolutePath(opts.Cwd)
+
+	var arguments map[string]any
+	if s.adapter.launchArguments != nil {
+		arguments = s.adapter.launchArguments(opts, program, cwd)
+	} else {
+		arguments = commonLaunchArguments(opts, program, cwd, map[string]any{})
+	}
+
+	for key, value := range opts.Configuration {
+		arguments[key] = value
+	}
+
+	return arguments
+}
+
+// start initializes the target and sends the launch or attach request with the breakpoints of the session.
+func (s *session) start(ctx context.Context, t *target, request string, arguments any) error {
+	if err := t.conn.Call(ctx, "initialize", InitializeRequestArguments{
+		ClientID:                      "hide",
+		ClientName:                    "Hide",
+		AdapterID:                     s.adapter.Type,
+		PathFormat:                    "path",
+		LinesStartAt1:                 true,
+		ColumnsStartAt1:               false,
+		SupportsVariableType:          true,
+		SupportsStartDebuggingRequest: s.connect != nil,
+	}, &t.capabilities); err != nil {
+		return err
+	}
+
+	// adapters ask for the configuration with the initialized event, some answer the launch request only after it is done
+	launched := t.conn.Go(ctx, request, arguments, nil)
+	launchDone := false
+	select {
+	case <-t.initialized:
+	case err := <-launched:
+		if err != nil {
+			return err
+		}
+
+		launchDone = true
+		select {
+		case <-t.initialized:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
+	breakpoints := make(map[string][]SourceBreakpoint, len(s.breakpoints))
+	for path, fileBreakpoints := range s.breakpoints {
+		breakpoints[path] = fileBreakpoints
+	}
+	s.mu.Unlock()
+
+	for path, fileBreakpoints := range breakpoints {
+		if _, err := t.setBreakpoints(ctx, path, fileBreakpoints); err != nil {
+			return err
+		}
+	}
+
+	if t.capabilities.SupportsConfigurationDoneRequest {
+		if err := t.conn.Call(ctx, "configurationDone", nil, nil); err != nil {
+			return err
+		}
+	}
+
+	if !launchDone {
+		select {
+		case err := <-launched:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// startChild starts a child session the adapter asked for, it becomes the active target of the session.
+func (s *session) startChild(args StartDebuggingRequestArguments) {
+	child := s.newTarget()
+	conn, err := s.connect(child)
+	if err != nil {
+		log.Warn().Err(err).Str("projectId", s.project.Id).Str("sessionId", s.id).Msg("Failed to connect child debug session")
+		return
+	}
+	child.conn = conn
+
+	s.mu.Lock()
+	s.children = append(s.children, child)
+	s.active = child
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), childStartTimeout)
+	defer cancel()
+
+	if err := s.start(ctx, child, args.Request, args.Configuration); err != nil {
+		log.Warn().Err(err).Str("projectId", s.project.Id).Str("sessionId", s.id).Msg("Failed to start child debug session")
+	}
+}
+
+func (s *session) handleEvent(t *target, event string, body json.RawMessage) {
+	switch event {
+	case "initialized":
+		t.initOnce.Do(func() { close(t.initialized) })
+	case "stopped":
+		var stopped StoppedEventBody
+		if err := json.Unmarshal(body, &stopped); err != nil {
+			log.Warn().Err(err).Str("sessionId", s.id).Msg("Failed to parse stopped event")
+			return
+		}
+
+		s.mu.Lock()
+		s.active = t
+		s.stopped = &StoppedInfo{Reason: stopped.Reason, Description: stopped.Description, ThreadId: stopped.ThreadId}
+		s.setStatus(SessionStopped)
+		s.mu.Unlock()
+	case "continued":
+		s.mu.Lock()
+		if s.status == SessionStopped {
+			s.stopped = nil
+			s.setStatus(SessionRunning)
+		}
+		s.mu.Unlock()
+	case "output":
+		var output OutputEventBody
+		if err := json.Unmarshal(body, &output); err != nil || output.Category == "telemetry" {
+			return
+		}
+
+		s.mu.Lock()
+		s.appendOutput(output.Output)
+		s.mu.Unlock()
+	case "exited":
+		var exited ExitedEventBody
+		if err := json.Unmarshal(body, &exited); err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.exitCode = &exited.ExitCode
+		s.mu.Unlock()
+	case "terminated":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// a child session ends with its process, the session goes on until the adapter is done
+		if t != s.parent {
+			if s.active == t {
+				s.active = s.parent
+			}
+			return
+		}
+
+		s.stopped = nil
+		s.setStatus(SessionTerminated)
+		go s.end()
+	}
+}
+
+func (s *session) handleRequest(command string, arguments json.RawMessage) (any, error) {
+	if command != "startDebugging" || s.connect == nil {
+		return nil, fmt.Errorf("%s is not supported", command)
+	}
+
+	var args StartDebuggingRequestArguments
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid startDebugging arguments: %w", err)
+	}
+
+	// the adapter waits for the child to connect, so the request is answered before the child starts
+	go s.startChild(args)
+	return nil, nil
+}
+
+// disconnected ends the session when the connection to the adapter is closed, e.g. because the adapter crashed.
+func (s *session) disconnected() {
+	s.mu.Lock()
+	if s.status != SessionTerminated {
+		s.stopped = nil
+		s.setStatus(SessionTerminated)
+	}
+	s.mu.Unlock()
+
+	s.end()
+}
+
+// end disconnects from the adapter, which terminates the program, and stops the processes of the session.
+func (s *session) end() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+
+	s.ended = true
+	if s.status != SessionTerminated {
+		s.stopped = nil
+		s.setStatus(SessionTerminated)
+	}
+
+	targets := append([]*target{}, s.children...)
+	if s.parent != nil {
+		targets = append(targets, s.parent)
+	}
+	closers := s.closers
+	s.closers = nil
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), disconnectTimeout)
+	defer cancel()
+
+	for _, t := range targets {
+		if err := t.conn.Call(ctx, "disconnect", DisconnectArguments{TerminateDebuggee: true}, nil); err != nil {
+			log.Debug().Err(err).Str("sessionId", s.id).Msg("Failed to disconnect from debug adapter")
+		}
+		t.conn.Close()
+	}
+
+	for _, closer := range closers {
+		closer()
+	}
+}
+
+// onEnd registers a function that is called when the session ends, it is called right away if the session already ended.
+func (s *session) onEnd(closer func()) {
+	s.mu.Lock()
+	if !s.ended {
+		s.closers = append(s.closers, closer)
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+
+	closer()
+}
+
+// touch marks the session as used.
+func (s *session) touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.used = time.Now()
+}
+
+// expired returns true if the session wasn't used for the idle timeout or terminated more than the terminated timeout ago.
+func (s *session) expired(now time.Time, idleTimeout, terminatedTimeout time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status == SessionTerminated {
+		return now.Sub(s.terminated) >= terminatedTimeout
+	}
+	return now.Sub(s.used) >= idleTimeout
+}
+
+// setStatus must be called with the lock held.
+func (s *session) setStatus(status SessionStatus) {
+	if status == SessionTerminated && s.status != SessionTerminated {
+		s.terminated = time.Now()
+	}
+	s.status = status
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// appendOutput must be called with the lock held.
+func (s *session) appendOutput(output string) {
+	s.output = append(s.output, output...)
+	if len(s.output) <= maxOutput {
+		return
+	}
+
+	// only whole characters are kept
+	cut := len(s.output) - maxOutput
+	for cut < len(s.output) && !utf8.RuneStart(s.output[cut]) {
+		cut++
+	}
+	s.output = append([]byte{}, s.output[cut:]...)
+}
+
+func (s *session) state() Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stateLocked()
+}
+
+func (s *session) stateLocked() Session {
+	state := Session{
+		Id:       s.id,
+		Language: s.language,
+		Program:  s.program,
+		Status:   s.status,
+		ExitCode: s.exitCode,
+		Output:   string(s.output),
+	}
+
+	if s.stopped != nil {
+		stopped := *s.stopped
+		state.Stopped = &stopped
+	}
+
+	return state
+}
+
+// wait waits while the program runs, at most for timeout. The session may still be running when it returns.
+func (s *session) wait(ctx context.Context, timeout time.Duration) (Session, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		if s.status != SessionRunning {
+			state := s.stateLocked()
+			s.mu.Unlock()
+			return state, nil
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return s.state(), nil
+		case <-ctx.Done():
+			return Session{}, ctx.Err()
+		}
+	}
+}
+
+// step continues, steps or pauses the thread and waits until the program stops again.
+func (s *session) step(ctx context.Context, action StepAction, threadId int, timeout time.Duration) (Session, error) {
+	s.mu.Lock()
+	wanted := SessionStopped
+	if action == StepPause {
+		wanted = SessionRunning
+	}
+	if s.status != wanted {
+		status := s.status
+		s.mu.Unlock()
+		return Session{}, NewSessionStateError(s.id, status, string(action))
+	}
+
+	t := s.active
+	stopped := s.stopped
+	if threadId == 0 && stopped != nil {
+		threadId = stopped.ThreadId
+	}
+	s.mu.Unlock()
+
+	if threadId == 0 {
+		threads, err := t.threads(ctx)
+		if err != nil {
+			return Session{}, err
+		}
+		if len(threads) == 0 {
+			return Session{}, errors.New("Debugged program has no threads")
+		}
+		threadId = threads[0].Id
+	}
+
+	if action != StepPause {
+		s.mu.Lock()
+		s.stopped = nil
+		s.output = nil
+		s.setStatus(SessionRunning)
+		s.mu.Unlock()
+	}
+
+	if err := t.conn.Call(ctx, string(action), ThreadArguments{ThreadId: threadId}, nil); err != nil {
+		// the program didn't move, unless it stopped again in the meantime
+		s.mu.Lock()
+		if action != StepPause && s.status == SessionRunning && stopped != nil {
+			s.stopped = stopped
+			s.setStatus(SessionStopped)
+		}
+		s.mu.Unlock()
+		return Session{}, err
+	}
+
+	return s.wait(ctx, timeout)
+}
+
+// stoppedTarget returns the target of the stopped program, requests for its state fail while it runs.
+func (s *session) stoppedTarget(request string) (*target, *StoppedInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status != SessionStopped {
+		return nil, nil, NewSessionStateError(s.id, s.status, request)
+	}
+
+	return s.active, s.stopped, nil
+}
+
+// setBreakpoints replaces the breakpoints of the file, they are also set in the child sessions started later.
+func (s *session) setBreakpoints(ctx context.Context, path string, breakpoints []SourceBreakpoint) ([]Breakpoint, error) {
+	path = s.absolutePath(path)
+
+	s.mu.Lock()
+	if s.status == SessionTerminated {
+		status := s.status
+		s.mu.Unlock()
+		return nil, NewSessionStateError(s.id, status, "set breakpoints")
+	}
+
+	if len(breakpoints) == 0 {
+		delete(s.breakpoints, path)
+	} else {
+		s.breakpoints[path] = breakpoints
+	}
+	t := s.active
+	s.mu.Unlock()
+
+	return t.setBreakpoints(ctx, path, breakpoints)
+}
+
+func (t *target) setBreakpoints(ctx context.Context, path string, breakpoints []SourceBreakpoint) ([]Breakpoint, error) {
+	if breakpoints == nil {
+		breakpoints = []SourceBreakpoint{}
+	}
+
+	var body SetBreakpointsResponseBody
+	if err := t.conn.Call(ctx, "setBreakpoints", SetBreakpointsArguments{
+		Source:      Source{Name: filepath.Base(path), Path: path},
+		Breakpoints: breakpoints,
+	}, &body); err != nil {
+		return nil, err
+	}
+
+	result := make([]Breakpoint, 0, len(body.Breakpoints))
+	for i, breakpoint := range body.Breakpoints {
+		line := breakpoint.Line
+		// adapters may leave out the line of breakpoints they couldn't set
+		if line == 0 && i < len(breakpoints) {
+			line = breakpoints[i].Line
+		}
+		result = append(result, Breakpoint{Line: line, Verified: breakpoint.Verified, Message: breakpoint.Message})
+	}
+
+	return result, nil
+}
+
+func (t *target) threads(ctx context.Context) ([]Thread, error) {
+	var body ThreadsResponseBody
+	if err := t.conn.Call(ctx, "threads", nil, &body); err != nil {
+		return nil, err
+	}
+
+	if body.Threads == nil {
+		return []Thread{}, nil
+	}
+	return body.Threads, nil
+}
+
+// absolutePath returns the host path of a path relative to the project root.
+func (s *session) absolutePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.project.Path, path)
+}
+
+// projectPath returns the path relative to the project root, paths outside of the project stay absolute.
+func (s *session) projectPath(path string) string {
+	relativePath, err := filepath.Rel(s.project.Path, path)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return relativePath
+}
@@ -454,6 +454,12 @@ func (c Customizations) Equals(other Customizations) bool {
 
 type HideCustomization struct {
 	Tasks []Task `json:"tasks,omitempty"`
+	// Ignore lists patterns in the gitignore format for files that are hidden from listing and search, in addition to .hideignore.
+	Ignore []string `json:"ignore,omitempty"`
+	// ProtectedPaths lists patterns in the gitignore format for files that cannot be created, updated or deleted.
+	ProtectedPaths []string `json:"protectedPaths,omitempty"`
+	// LanguageServers are started for the project in addition to the language servers Hide knows, they replace them for the same languages.
+	LanguageServers []LanguageServer `json:"languageServers,omitempty"`
 }
 
 func (h *HideCustomization) Equals(other *HideCustomization) bool {
@@ -465,7 +471,32 @@ func (h *HideCustomization) Equals(other *HideCustomization) bool {
 		return false
 	}
 
-	return slices.Equal(h.Tasks, other.Tasks)
+	return slices.Equal(h.Tasks, other.Tasks) &&
+		slices.Equal(h.Ignore, other.Ignore) &&
+		slices.Equal(h.ProtectedPaths, other.ProtectedPaths) &&
+		slices.EqualFunc(h.LanguageServers, other.LanguageServers, LanguageServer.Equals)
+}
+
+type LanguageServer struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	// LanguageIds are the languages the server is started for, named like in GitHub Linguist, e.g. Rust or C++
+	LanguageIds []string `json:"languageIds"`
+	// FilePatterns are glob patterns of paths in the project or of file names, matching files belong to the first language of the server
+	FilePatterns []string `json:"filePatterns,omitempty"`
+	// InitializationOptions are sent to the server with the initialize request
+	InitializationOptions any `json:"initializationOptions,omitempty"`
+	// Settings are the workspace settings the server asks for with workspace/configuration
+	Settings any `json:"settings,omitempty"`
+}
+
+func (l LanguageServer) Equals(other LanguageServer) bool {
+	return l.Command == other.Command &&
+		slices.Equal(l.Args, other.Args) &&
+		slices.Equal(l.LanguageIds, other.LanguageIds) &&
+		slices.Equal(l.FilePatterns, other.FilePatterns) &&
+		reflect.DeepEqual(l.InitializationOptions, other.InitializationOptions) &&
+		reflect.DeepEqual(l.Settings, other.Settings)
 }
 
 type Task struct {
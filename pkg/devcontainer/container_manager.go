@@ -12,6 +12,7 @@ import (
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
+	"github.com/rs/zerolog/log"
 )
 
 const DefaultShell = "/bin/sh"
@@ -24,6 +25,8 @@ type ContainerManager interface {
 	StartContainer(ctx context.Context, containerId string) error
 	StopContainer(ctx context.Context, containerId string) error
 	Exec(ctx context.Context, containerId string, command []string) (ExecResult, error)
+	// ExecStream runs the command attached to stdin and stdout until it exits or ctx is cancelled, stderr is logged and also written to stderr if it's not nil. Returns the exit code of the command.
+	ExecStream(ctx context.Context, containerId string, command []string, workingDir string, stdin io.Reader, stdout, stderr io.Writer) (int, error)
 }
 
 type DockerContainerManager struct {
@@ -76,13 +79,11 @@ func (cm *DockerContainerManager) CreateContainer(ctx context.Context, image str
 	}
 
 	mounts := []mount.Mount{}
-	workspaceSource := projectPath
-	workspaceTarget := DefaultWorkingDir
+	workspaceSource := WorkspaceMountSource(config, projectPath)
+	workspaceTarget := WorkspaceMountTarget(config)
 	containerConfig.WorkingDir = DefaultWorkingDir
 
 	if config.WorkspaceMount != nil && config.WorkspaceFolder != "" {
-		workspaceSource = config.WorkspaceMount.Source
-		workspaceTarget = config.WorkspaceMount.Destination
 		containerConfig.WorkingDir = config.WorkspaceFolder
 	}
 
@@ -160,6 +161,78 @@ func (cm *DockerContainerManager) Exec(ctx context.Context, containerId string,
 	return ExecResult{StdOut: stdOut.String(), StdErr: stdErr.String(), ExitCode: inspectResp.ExitCode}, nil
 }
 
+func (cm *DockerContainerManager) ExecStream(ctx context.Context, containerId string, command []string, workingDir string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	execConfig := types.ExecConfig{
+		Cmd:          command,
+		WorkingDir:   workingDir,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	execIDResp, err := cm.ContainerExecCreate(ctx, containerId, execConfig)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to create exec configuration for command %s in container %s: %w", command, containerId, err)
+	}
+
+	execID := execIDResp.ID
+	resp, err := cm.ContainerExecAttach(ctx, execID, types.ExecStartCheck{})
+	if err != nil {
+		return 0, fmt.Errorf("Failed to attach to exec process %s in container %s: %w", execID, containerId, err)
+	}
+
+	defer resp.Close()
+
+	go func() {
+		if _, err := io.Copy(resp.Conn, stdin); err != nil {
+			log.Debug().Err(err).Str("execId", execID).Msg("Stopped writing to exec process")
+		}
+		resp.CloseWrite()
+	}()
+
+	// closing the connection unblocks reading the output
+	stop := context.AfterFunc(ctx, func() { resp.Close() })
+	defer stop()
+
+	var errorOutput io.Writer = &logPipe{}
+	if stderr != nil {
+		errorOutput = io.MultiWriter(errorOutput, stderr)
+	}
+
+	if err := readOutputFromContainer(resp.Reader, stdout, errorOutput); err != nil && ctx.Err() == nil {
+		return 0, fmt.Errorf("Failed reading output from container %s: %w", containerId, err)
+	}
+
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	inspectResp, err := cm.ContainerExecInspect(ctx, execID)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to inspect exec process %s in container %s: %w", execID, containerId, err)
+	}
+
+	return inspectResp.ExitCode, nil
+}
+
+// WorkspaceMountSource returns the path on the host that is mounted as the workspace of the container.
+func WorkspaceMountSource(config Config, projectPath string) string {
+	if config.WorkspaceMount != nil && config.WorkspaceFolder != "" {
+		return config.WorkspaceMount.Source
+	}
+
+	return projectPath
+}
+
+// WorkspaceMountTarget returns the path in the container where the project is mounted.
+func WorkspaceMountTarget(config Config) string {
+	if config.WorkspaceMount != nil && config.WorkspaceFolder != "" {
+		return config.WorkspaceMount.Destination
+	}
+
+	return DefaultWorkingDir
+}
+
 func stringToType(s string) (mount.Type, error) {
 	switch s {
 	case string(mount.TypeBind):
@@ -1,8 +1,10 @@
 package devcontainer_test
 
 import (
+	"bytes"
 	"context"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/docker/docker/api/types"
@@ -331,3 +333,37 @@ func TestDockerContainerManager_Exec(t *testing.T) {
 		})
 	}
 }
+
+func TestDockerContainerManager_ExecStream(t *testing.T) {
+	mockClient := &mocks.MockDockerContainerClient{}
+	mockClient.On("ContainerExecCreate", mock.Anything, "test-container-id", mock.MatchedBy(func(config types.ExecConfig) bool {
+		return slices.Equal(config.Cmd, []string{"gopls"}) &&
+			config.WorkingDir == "/workspace" &&
+			config.AttachStdin &&
+			config.AttachStdout &&
+			config.AttachStderr
+	})).Return(types.IDResponse{ID: "test-exec-id"}, nil)
+	mockClient.On("ContainerExecAttach", mock.Anything, "test-exec-id", mock.AnythingOfType("types.ExecStartCheck")).Return(mocks.CreateMockHijackedResponse("test-stdout\n", "test-stderr\n"), nil)
+	mockClient.On("ContainerExecInspect", mock.Anything, "test-exec-id").Return(types.ContainerExecInspect{ExitCode: 2}, nil)
+
+	containerManager := devcontainer.NewDockerContainerManager(mockClient)
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := containerManager.ExecStream(context.Background(), "test-container-id", []string{"gopls"}, "/workspace", strings.NewReader("test-stdin"), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, exitCode)
+	assert.Equal(t, "test-stdout\n", stdout.String(), "stderr is not written to stdout")
+	assert.Equal(t, "test-stderr\n", stderr.String())
+	mockClient.AssertExpectations(t)
+}
+
+func TestWorkspaceMountTarget(t *testing.T) {
+	assert.Equal(t, devcontainer.DefaultWorkingDir, devcontainer.WorkspaceMountTarget(devcontainer.Config{}))
+	assert.Equal(t, "/workspaces/app", devcontainer.WorkspaceMountTarget(devcontainer.Config{DockerImageProps: devcontainer.DockerImageProps{
+		WorkspaceMount:  &devcontainer.Mount{Source: "/project", Destination: "/workspaces/app"},
+		WorkspaceFolder: "/workspaces/app",
+	}}))
+}
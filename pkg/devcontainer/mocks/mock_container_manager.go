@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"io"
 
 	"github.com/stretchr/testify/mock"
 
@@ -33,3 +34,8 @@ func (m *MockContainerManager) Exec(ctx context.Context, containerId string, com
 	args := m.Called(ctx, containerId, command)
 	return args.Get(0).(devcontainer.ExecResult), args.Error(1)
 }
+
+func (m *MockContainerManager) ExecStream(ctx context.Context, containerId string, command []string, workingDir string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	args := m.Called(ctx, containerId, command, workingDir, stdin, stdout, stderr)
+	return args.Int(0), args.Error(1)
+}
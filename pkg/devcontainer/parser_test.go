@@ -62,6 +62,27 @@ func TestParseConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "hide with ignore rules and protected paths",
+			content: devcontainer.File{Path: "config.json", Content: []byte(`{
+	"customizations": {
+		"hide": {
+			"ignore": ["fixtures/", "*.min.js"],
+			"protectedPaths": [".github/workflows/**", "package-lock.json"]
+		}
+	}
+}`)},
+			expected: &devcontainer.Config{
+				GeneralProperties: devcontainer.GeneralProperties{
+					Customizations: devcontainer.Customizations{
+						Hide: &devcontainer.HideCustomization{
+							Ignore:         []string{"fixtures/", "*.min.js"},
+							ProtectedPaths: []string{".github/workflows/**", "package-lock.json"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -236,6 +257,51 @@ func TestConfigWithCustomizations(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "hide with language servers",
+			content: devcontainer.File{Path: "config.json", Content: []byte(`{
+	"customizations": {
+		"hide": {
+			"languageServers": [
+				{
+					"command": "rust-analyzer",
+					"languageIds": ["Rust"],
+					"initializationOptions": {"cargo": {"allFeatures": true}},
+					"settings": {"rust-analyzer": {"checkOnSave": false}}
+				},
+				{
+					"command": "ruby-lsp",
+					"args": ["--stdio"],
+					"languageIds": ["Ruby"],
+					"filePatterns": ["*.rbi", "Gemfile"]
+				}
+			]
+		}
+	}
+}`)},
+			expected: &devcontainer.Config{
+				GeneralProperties: devcontainer.GeneralProperties{
+					Customizations: devcontainer.Customizations{
+						Hide: &devcontainer.HideCustomization{
+							LanguageServers: []devcontainer.LanguageServer{
+								{
+									Command:               "rust-analyzer",
+									LanguageIds:           []string{"Rust"},
+									InitializationOptions: map[string]any{"cargo": map[string]any{"allFeatures": true}},
+									Settings:              map[string]any{"rust-analyzer": map[string]any{"checkOnSave": false}},
+								},
+								{
+									Command:      "ruby-lsp",
+									Args:         []string{"--stdio"},
+									LanguageIds:  []string{"Ruby"},
+									FilePatterns: []string{"*.rbi", "Gemfile"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,448 @@
+package files
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/afero"
+)
+
+const (
+	// MaxArchiveSize is the largest archive that can be extracted, as uploaded
+	MaxArchiveSize int64 = 512 << 20
+	// MaxExtractedFileSize is the largest file an archive may contain
+	MaxExtractedFileSize int64 = 512 << 20
+	// MaxExtractedSize is the largest total size of the files of an archive
+	MaxExtractedSize int64 = 2 << 30
+	// MaxArchiveEntries is the largest number of entries of an archive
+	MaxArchiveEntries = 100000
+)
+
+type ArchiveFormat string
+
+const (
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+	ArchiveZip   ArchiveFormat = "zip"
+)
+
+func (f ArchiveFormat) Valid() bool {
+	return f == ArchiveTarGz || f == ArchiveZip
+}
+
+// OverwritePolicy defines what happens when an extracted file already exists.
+type OverwritePolicy string
+
+const (
+	// OverwriteError fails the extraction before any file is written
+	OverwriteError   OverwritePolicy = "error"
+	OverwriteSkip    OverwritePolicy = "skip"
+	OverwriteReplace OverwritePolicy = "replace"
+)
+
+func (p OverwritePolicy) Valid() bool {
+	return p == OverwriteError || p == OverwriteSkip || p == OverwriteReplace
+}
+
+type ExtractResult struct {
+	// Files are the extracted files, relative to the project root
+	Files []string `json:"files"`
+	// Skipped are the archive entries that were not extracted, because they already exist or are not regular files
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// archiveEntry is a file or directory in an archive. The mode contains the type bits and permissions.
+type archiveEntry struct {
+	name string
+	mode os.FileMode
+	// size is the uncompressed size the archive declares, the readers of both formats fail if the content differs from it
+	size int64
+}
+
+func (fm *FileManagerImpl) WriteArchive(ctx context.Context, fs afero.Fs, w io.Writer, path string, format ArchiveFormat, opts ...ListFileOption) error {
+	opt := &ListFilesOptions{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	root := filepath.Join("/", path)
+
+	info, err := lstat(fs, root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewFileNotFoundError(path)
+		}
+		return fmt.Errorf("Failed to check if file %s exists: %w", path, err)
+	}
+
+	aw, err := newArchiveWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	err = fm.walkFiles(ctx, fs, opt, func(p string, mode os.FileMode) error {
+		if !isWithin(p, root) {
+			if mode.IsDir() && !isWithin(root, p) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if mode.IsDir() {
+			return nil
+		}
+
+		// entries are relative to the archived directory, a single file is archived by its name
+		name := filepath.Base(p)
+		if info.IsDir() {
+			if name, err = filepath.Rel(root, p); err != nil {
+				return err
+			}
+		}
+
+		return aw.add(fs, p, filepath.ToSlash(name))
+	})
+	if err != nil {
+		aw.Close()
+		return err
+	}
+
+	return aw.Close()
+}
+
+func (fm *FileManagerImpl) ExtractArchive(ctx context.Context, fs afero.Fs, r io.Reader, path string, format ArchiveFormat, overwrite OverwritePolicy) (*ExtractResult, error) {
+	// the archive is read twice, to validate all entries before anything is written, and zip needs random access anyway
+	tmp, err := os.CreateTemp("", "hide-archive-*")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, io.LimitReader(r, MaxArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read archive: %w", err)
+	}
+	if size > MaxArchiveSize {
+		return nil, NewArchiveTooLargeError(fmt.Sprintf("archive is larger than %d bytes", MaxArchiveSize))
+	}
+
+	root := filepath.Join("/", path)
+	skip := make(map[string]bool)
+	seen := make(map[string]os.FileMode) // cleaned entry name -> mode
+	result := &ExtractResult{Files: []string{}}
+	entries := 0
+	var extractedSize int64
+
+	err = forEachArchiveEntry(tmp, size, format, func(entry archiveEntry, _ io.Reader) error {
+		if err := checkArchiveEntryName(entry.name); err != nil {
+			return err
+		}
+
+		// the second pass could not tell which of two entries with the same name was checked
+		name := filepath.Clean(filepath.FromSlash(entry.name))
+		if mode, ok := seen[name]; ok && !(mode.IsDir() && entry.mode.IsDir()) {
+			return NewInvalidArchiveError(fmt.Errorf("duplicate entry %s", entry.name))
+		}
+		seen[name] = entry.mode
+
+		// the limits are checked with the declared sizes before anything is written
+		entries++
+		if entries > MaxArchiveEntries {
+			return NewArchiveTooLargeError(fmt.Sprintf("archive has more than %d entries", MaxArchiveEntries))
+		}
+
+		if entry.mode.IsRegular() {
+			if entry.size < 0 || entry.size > MaxExtractedFileSize {
+				return NewArchiveTooLargeError(fmt.Sprintf("%s is larger than %d bytes", entry.name, MaxExtractedFileSize))
+			}
+
+			extractedSize += entry.size
+			if extractedSize > MaxExtractedSize {
+				return NewArchiveTooLargeError(fmt.Sprintf("files are larger than %d bytes", MaxExtractedSize))
+			}
+		}
+
+		if !entry.mode.IsRegular() {
+			if !entry.mode.IsDir() {
+				log.Warn().Str("entry", entry.name).Str("mode", entry.mode.String()).Msg("Skipping archive entry that is not a regular file or directory")
+				skip[entry.name] = true
+				result.Skipped = append(result.Skipped, entry.name)
+			}
+			return nil
+		}
+
+		target := filepath.Join(root, entry.name)
+		if err := checkProtected(ctx, fs, target); err != nil {
+			return err
+		}
+
+		exists, err := fileExists(fs, target)
+		if err != nil {
+			return fmt.Errorf("Failed to check if file %s exists: %w", target, err)
+		}
+
+		if exists {
+			switch overwrite {
+			case OverwriteSkip:
+				skip[entry.name] = true
+				result.Skipped = append(result.Skipped, entry.name)
+			case OverwriteReplace:
+			default:
+				return NewFileAlreadyExistsError(strings.TrimPrefix(target, "/"))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("Failed to read archive: %w", err)
+	}
+
+	err = forEachArchiveEntry(tmp, size, format, func(entry archiveEntry, content io.Reader) error {
+		if skip[entry.name] {
+			return nil
+		}
+
+		target := filepath.Join(root, entry.name)
+
+		if entry.mode.IsDir() {
+			return fs.MkdirAll(target, 0o755)
+		}
+
+		exists, err := fileExists(fs, target)
+		if err != nil {
+			return fmt.Errorf("Failed to check if file %s exists: %w", target, err)
+		}
+
+		// never more than the declared size, which was checked against the limits
+		if err := extractFile(fs, target, entry.mode.Perm(), io.LimitReader(content, entry.size)); err != nil {
+			return fmt.Errorf("Failed to extract %s: %w", entry.name, err)
+		}
+
+		if exists {
+			fm.updateTree(ctx, model.FileModified, target)
+		} else {
+			fm.updateTree(ctx, model.FileCreated, target)
+		}
+
+		result.Files = append(result.Files, strings.TrimPrefix(target, "/"))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func extractFile(fs afero.Fs, path string, perm os.FileMode, content io.Reader) error {
+	if err := fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// owner must be able to update the file later on
+	f, err := fs.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm|0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// checkArchiveEntryName rejects entries that would be extracted outside of the target directory.
+func checkArchiveEntryName(name string) error {
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return NewPathOutsideProjectError(name)
+	}
+
+	return nil
+}
+
+func forEachArchiveEntry(r io.ReaderAt, size int64, format ArchiveFormat, fn func(entry archiveEntry, content io.Reader) error) error {
+	switch format {
+	case ArchiveTarGz:
+		gr, err := gzip.NewReader(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return NewInvalidArchiveError(err)
+		}
+		defer gr.Close()
+
+		tr := tar.NewReader(gr)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return NewInvalidArchiveError(err)
+			}
+
+			if err := fn(archiveEntry{name: hdr.Name, mode: hdr.FileInfo().Mode(), size: hdr.Size}, tr); err != nil {
+				return err
+			}
+		}
+	case ArchiveZip:
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return NewInvalidArchiveError(err)
+		}
+
+		for _, f := range zr.File {
+			if err := forZipEntry(f, fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported archive format %s", format)
+	}
+}
+
+func forZipEntry(f *zip.File, fn func(entry archiveEntry, content io.Reader) error) error {
+	entry := archiveEntry{name: f.Name, mode: f.Mode(), size: int64(f.UncompressedSize64)}
+	if !entry.mode.IsRegular() {
+		return fn(entry, nil)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return NewInvalidArchiveError(err)
+	}
+	defer rc.Close()
+
+	return fn(entry, rc)
+}
+
+type archiveWriter interface {
+	add(fs afero.Fs, path, name string) error
+	Close() error
+}
+
+func newArchiveWriter(w io.Writer, format ArchiveFormat) (archiveWriter, error) {
+	switch format {
+	case ArchiveTarGz:
+		gw := gzip.NewWriter(w)
+		return &tarGzWriter{gw: gw, tw: tar.NewWriter(gw)}, nil
+	case ArchiveZip:
+		return &zipWriter{zw: zip.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format %s", format)
+	}
+}
+
+type tarGzWriter struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+func (t *tarGzWriter) add(fs afero.Fs, path, name string) error {
+	info, err := lstat(fs, path)
+	if err != nil {
+		return err
+	}
+
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		if link, err = readlink(fs, path); err != nil {
+			return err
+		}
+	}
+
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	return copyFile(t.tw, fs, path)
+}
+
+func (t *tarGzWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.gw.Close()
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipWriter) add(fs afero.Fs, path, name string) error {
+	info, err := lstat(fs, path)
+	if err != nil {
+		return err
+	}
+
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	hdr.Method = zip.Deflate
+
+	w, err := z.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+
+	// zip stores the target of a symlink as its content
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := readlink(fs, path)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, link)
+		return err
+	}
+
+	return copyFile(w, fs, path)
+}
+
+func (z *zipWriter) Close() error {
+	return z.zw.Close()
+}
+
+func copyFile(w io.Writer, fs afero.Fs, path string) error {
+	f, err := fs.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func isWithin(path, dir string) bool {
+	return dir == "/" || path == dir || strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,255 @@
+package files_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/gitignore"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileManager_Archive_RoundTrip(t *testing.T) {
+	for _, format := range []files.ArchiveFormat{files.ArchiveTarGz, files.ArchiveZip} {
+		t.Run(string(format), func(t *testing.T) {
+			src := newTestFs(t, map[string]string{
+				"/.gitignore":        "*.log\n",
+				"/main.go":           "package main",
+				"/src/lib.go":        "package src",
+				"/src/lib_test.go":   "package src",
+				"/src/debug.log":     "log",
+				"/src/.env":          "KEY=value",
+				"/src/nested/doc.md": "# Doc",
+			})
+
+			fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+			var archive bytes.Buffer
+			filter := files.PatternFilter{Exclude: []string{"*_test.go"}}
+			if err := fm.WriteArchive(context.Background(), src, &archive, "src", format, files.ListFilesWithFilter(filter)); err != nil {
+				t.Fatal(err)
+			}
+
+			dst := newTestFs(t, map[string]string{"/existing.txt": "content"})
+
+			result, err := fm.ExtractArchive(context.Background(), dst, &archive, "vendor/lib", format, files.OverwriteError)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.ElementsMatch(t, []string{"vendor/lib/lib.go", "vendor/lib/nested/doc.md"}, result.Files)
+			assertFileContent(t, dst, "/vendor/lib/lib.go", "package src")
+			assertFileContent(t, dst, "/vendor/lib/nested/doc.md", "# Doc")
+		})
+	}
+}
+
+func TestFileManager_WriteArchive_SingleFile(t *testing.T) {
+	fs := newTestFs(t, map[string]string{"/src/lib.go": "package src"})
+	fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+	var archive bytes.Buffer
+	if err := fm.WriteArchive(context.Background(), fs, &archive, "src/lib.go", files.ArchiveZip); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Len(t, zr.File, 1) {
+		assert.Equal(t, "lib.go", zr.File[0].Name)
+	}
+}
+
+func TestFileManager_WriteArchive_NotFound(t *testing.T) {
+	fs := newTestFs(t, map[string]string{"/main.go": "package main"})
+	fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+	err := fm.WriteArchive(context.Background(), fs, &bytes.Buffer{}, "missing", files.ArchiveTarGz)
+
+	var notFound *files.FileNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestFileManager_ExtractArchive(t *testing.T) {
+	tests := []struct {
+		name        string
+		entries     []tar.Header
+		overwrite   files.OverwritePolicy
+		wantFiles   []string
+		wantSkipped []string
+		wantErr     any
+		wantContent map[string]string
+	}{
+		{
+			name:        "overwrite error",
+			entries:     []tar.Header{{Name: "new.txt"}, {Name: "existing.txt"}},
+			overwrite:   files.OverwriteError,
+			wantErr:     new(*files.FileAlreadyExistsError),
+			wantContent: map[string]string{"/existing.txt": "old"},
+		},
+		{
+			name:        "overwrite skip",
+			entries:     []tar.Header{{Name: "new.txt"}, {Name: "existing.txt"}},
+			overwrite:   files.OverwriteSkip,
+			wantFiles:   []string{"new.txt"},
+			wantSkipped: []string{"existing.txt"},
+			wantContent: map[string]string{"/new.txt": "new.txt", "/existing.txt": "old"},
+		},
+		{
+			name:        "overwrite replace",
+			entries:     []tar.Header{{Name: "existing.txt"}},
+			overwrite:   files.OverwriteReplace,
+			wantFiles:   []string{"existing.txt"},
+			wantContent: map[string]string{"/existing.txt": "existing.txt"},
+		},
+		{
+			name:      "path traversal",
+			entries:   []tar.Header{{Name: "new.txt"}, {Name: "../../etc/passwd"}},
+			overwrite: files.OverwriteReplace,
+			wantErr:   new(*files.PathOutsideProjectError),
+		},
+		{
+			name:      "absolute path",
+			entries:   []tar.Header{{Name: "/etc/passwd"}},
+			overwrite: files.OverwriteReplace,
+			wantErr:   new(*files.PathOutsideProjectError),
+		},
+		{
+			name:      "duplicate entries",
+			entries:   []tar.Header{{Name: "new.txt"}, {Name: "dir/file.txt"}, {Name: "./dir//file.txt"}},
+			overwrite: files.OverwriteReplace,
+			wantErr:   new(*files.InvalidArchiveError),
+		},
+		{
+			name:        "symlinks are skipped",
+			entries:     []tar.Header{{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}, {Name: "dir/new.txt"}},
+			overwrite:   files.OverwriteError,
+			wantFiles:   []string{"dir/new.txt"},
+			wantSkipped: []string{"link"},
+			wantContent: map[string]string{"/dir/new.txt": "dir/new.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newTestFs(t, map[string]string{"/existing.txt": "old"})
+			fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+			result, err := fm.ExtractArchive(context.Background(), fs, newTarGz(t, tt.entries), "", files.ArchiveTarGz, tt.overwrite)
+
+			if tt.wantErr != nil {
+				assert.ErrorAs(t, err, tt.wantErr)
+				// nothing is extracted if the archive is rejected
+				exists, _ := afero.Exists(fs, "/new.txt")
+				assert.False(t, exists)
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, tt.wantFiles, result.Files)
+				assert.Equal(t, tt.wantSkipped, result.Skipped)
+			}
+
+			for path, content := range tt.wantContent {
+				assertFileContent(t, fs, path, content)
+			}
+		})
+	}
+}
+
+func TestFileManager_ExtractArchive_Invalid(t *testing.T) {
+	fs := newTestFs(t, map[string]string{})
+	fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+	_, err := fm.ExtractArchive(context.Background(), fs, bytes.NewBufferString("not an archive"), "", files.ArchiveZip, files.OverwriteError)
+
+	var invalid *files.InvalidArchiveError
+	assert.ErrorAs(t, err, &invalid)
+}
+
+func TestFileManager_ExtractArchive_TooLarge(t *testing.T) {
+	fs := newTestFs(t, map[string]string{})
+	fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+	// a small entry that declares to decompress to more than the limit
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.CreateRaw(&zip.FileHeader{Name: "bomb.txt", Method: zip.Store, CompressedSize64: 4, UncompressedSize64: uint64(files.MaxExtractedFileSize) + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("bomb")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fm.ExtractArchive(context.Background(), fs, &buf, "", files.ArchiveZip, files.OverwriteError)
+
+	var tooLarge *files.ArchiveTooLargeError
+	assert.ErrorAs(t, err, &tooLarge)
+	exists, _ := afero.Exists(fs, "/bomb.txt")
+	assert.False(t, exists)
+}
+
+// newTarGz creates a tar.gz archive, regular files contain their name
+func newTarGz(t *testing.T, entries []tar.Header) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for _, hdr := range entries {
+		hdr.Mode = 0o644
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = int64(len(hdr.Name))
+		}
+
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(hdr.Name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf
+}
+
+func assertFileContent(t *testing.T, fs afero.Fs, path, want string) {
+	t.Helper()
+
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			t.Errorf("file %s does not exist", filepath.Clean(path))
+			return
+		}
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, want, string(content))
+}
@@ -25,3 +25,56 @@ func (e FileAlreadyExistsError) Error() string {
 func NewFileAlreadyExistsError(path string) *FileAlreadyExistsError {
 	return &FileAlreadyExistsError{path: path}
 }
+
+type PathOutsideProjectError struct {
+	path string
+}
+
+func (e PathOutsideProjectError) Error() string {
+	return fmt.Sprintf("path %s is outside of the project", e.path)
+}
+
+func NewPathOutsideProjectError(path string) *PathOutsideProjectError {
+	return &PathOutsideProjectError{path: path}
+}
+
+type PathProtectedError struct {
+	path string
+}
+
+func (e PathProtectedError) Error() string {
+	return fmt.Sprintf("path %s is protected", e.path)
+}
+
+func NewPathProtectedError(path string) *PathProtectedError {
+	return &PathProtectedError{path: path}
+}
+
+type InvalidArchiveError struct {
+	err error
+}
+
+func (e InvalidArchiveError) Error() string {
+	return fmt.Sprintf("invalid archive: %s", e.err)
+}
+
+func (e InvalidArchiveError) Unwrap() error {
+	return e.err
+}
+
+func NewInvalidArchiveError(err error) *InvalidArchiveError {
+	return &InvalidArchiveError{err: err}
+}
+
+// ArchiveTooLargeError is returned for archives that exceed one of the limits of extraction, e.g. a zip bomb.
+type ArchiveTooLargeError struct {
+	reason string
+}
+
+func (e ArchiveTooLargeError) Error() string {
+	return fmt.Sprintf("archive too large: %s", e.reason)
+}
+
+func NewArchiveTooLargeError(reason string) *ArchiveTooLargeError {
+	return &ArchiveTooLargeError{reason: reason}
+}
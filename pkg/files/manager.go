@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,17 +24,28 @@ type FileManager interface {
 	ListFiles(ctx context.Context, fs afero.Fs, opts ...ListFileOption) ([]*model.File, error)
 	ApplyPatch(ctx context.Context, fs afero.Fs, path, patch string) (*model.File, error)
 	UpdateLines(ctx context.Context, fs afero.Fs, path string, lineDiff LineDiffChunk) (*model.File, error)
+	// WriteArchive writes the files under path to w, honoring the same ignore rules and filters as ListFiles.
+	WriteArchive(ctx context.Context, fs afero.Fs, w io.Writer, path string, format ArchiveFormat, opts ...ListFileOption) error
+	// ExtractArchive extracts the archive into the directory at path.
+	ExtractArchive(ctx context.Context, fs afero.Fs, r io.Reader, path string, format ArchiveFormat, overwrite OverwritePolicy) (*ExtractResult, error)
+	// ApplyWorkspaceEdit applies all changes or none of them and describes the changed files. With dryRun nothing is written.
+	ApplyWorkspaceEdit(ctx context.Context, fs afero.Fs, changes []FileChange, dryRun bool) ([]FileDiff, error)
 }
 
 type FileManagerImpl struct {
 	gitignoreFactory gitignore.MatcherFactory
+	treeCache        TreeCache
 }
 
-func NewFileManager(factory gitignore.MatcherFactory) FileManager {
-	return &FileManagerImpl{gitignoreFactory: factory}
+func NewFileManager(factory gitignore.MatcherFactory, treeCache TreeCache) FileManager {
+	return &FileManagerImpl{gitignoreFactory: factory, treeCache: treeCache}
 }
 
 func (fm *FileManagerImpl) CreateFile(ctx context.Context, fs afero.Fs, path, content string) (*model.File, error) {
+	if err := checkProtected(ctx, fs, path); err != nil {
+		return nil, err
+	}
+
 	exists, err := fileExists(fs, path)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to check if file %s exists: %w", path, err)
@@ -52,6 +64,8 @@ func (fm *FileManagerImpl) CreateFile(ctx context.Context, fs afero.Fs, path, co
 		return nil, fmt.Errorf("Failed to write file %s: %w", path, err)
 	}
 
+	fm.updateTree(ctx, model.FileCreated, path)
+
 	return model.NewFile(path, content), nil
 }
 
@@ -69,6 +83,10 @@ func (fm *FileManagerImpl) ReadFile(ctx context.Context, fs afero.Fs, path strin
 }
 
 func (fm *FileManagerImpl) UpdateFile(ctx context.Context, fs afero.Fs, path, content string) (*model.File, error) {
+	if err := checkProtected(ctx, fs, path); err != nil {
+		return nil, err
+	}
+
 	exists, err := fileExists(fs, path)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to check if file %s exists: %w", path, err)
@@ -84,10 +102,16 @@ func (fm *FileManagerImpl) UpdateFile(ctx context.Context, fs afero.Fs, path, co
 		return nil, fmt.Errorf("Failed to write file %s: %w", path, err)
 	}
 
+	fm.updateTree(ctx, model.FileModified, path)
+
 	return readFile(fs, path)
 }
 
 func (fm *FileManagerImpl) DeleteFile(ctx context.Context, fs afero.Fs, path string) error {
+	if err := checkProtected(ctx, fs, path); err != nil {
+		return err
+	}
+
 	exists, err := fileExists(fs, path)
 	if err != nil {
 		return fmt.Errorf("Failed to check if file %s exists: %w", path, err)
@@ -96,7 +120,14 @@ func (fm *FileManagerImpl) DeleteFile(ctx context.Context, fs afero.Fs, path str
 	if !exists {
 		return NewFileNotFoundError(path)
 	}
-	return fs.Remove(path)
+
+	if err := fs.Remove(path); err != nil {
+		return err
+	}
+
+	fm.updateTree(ctx, model.FileDeleted, path)
+
+	return nil
 }
 
 func (fm *FileManagerImpl) ListFiles(ctx context.Context, fs afero.Fs, opts ...ListFileOption) ([]*model.File, error) {
@@ -107,52 +138,26 @@ func (fm *FileManagerImpl) ListFiles(ctx context.Context, fs afero.Fs, opts ...L
 		o(opt)
 	}
 
-	m, err := fm.gitignoreFactory.NewMatcher(fs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create gitignore matcher: %w", err)
-	}
-
-	err = afero.Walk(fs, "/", func(path string, info os.FileInfo, err error) error {
-		select {
-		case <-ctx.Done():
-			return errors.New("context cancelled")
-		default:
-		}
-
-		if err != nil {
-			return fmt.Errorf("Error walking file tree on path %s: %w", path, err)
-		}
-
-		// check gitignore
-		match, err := m.Match(path, info.IsDir())
-		if err != nil {
-			return fmt.Errorf("failed to match path %s: %w", path, err)
-		}
-		if match {
-			if info.IsDir() {
-				return filepath.SkipDir
+	err := fm.walkFiles(ctx, fs, opt, func(path string, mode os.FileMode) error {
+		if mode&os.ModeSymlink != 0 {
+			// links are reported with their target instead of being followed, the target can be outside of the project
+			target, err := readlink(fs, path)
+			if err != nil {
+				return fmt.Errorf("Error reading link %s: %w", path, err)
 			}
-			return nil
-		}
 
-		if !opt.ShowHidden && isHidden(path) {
-			if info.IsDir() {
-				return filepath.SkipDir
+			path, err = filepath.Rel("/", path)
+			if err != nil {
+				return err
 			}
-			return nil
-		}
 
-		ok, err := opt.Filter.keep(path, info)
-		if err != nil {
-			return err
-		}
-		if !ok {
+			files = append(files, model.SymlinkFile(path, target))
 			return nil
 		}
 
-		if !info.IsDir() {
+		if !mode.IsDir() {
 			if !opt.WithContent {
-				path, err = filepath.Rel("/", path)
+				path, err := filepath.Rel("/", path)
 				if err != nil {
 					return err
 				}
@@ -182,6 +187,10 @@ func (fm *FileManagerImpl) ListFiles(ctx context.Context, fs afero.Fs, opts ...L
 }
 
 func (fm *FileManagerImpl) ApplyPatch(ctx context.Context, fs afero.Fs, path, patch string) (*model.File, error) {
+	if err := checkProtected(ctx, fs, path); err != nil {
+		return nil, err
+	}
+
 	exists, err := fileExists(fs, path)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to check if file %s exists: %w", path, err)
@@ -219,10 +228,16 @@ func (fm *FileManagerImpl) ApplyPatch(ctx context.Context, fs afero.Fs, path, pa
 		return nil, fmt.Errorf("Failed to write file %s after applying patch: %w", path, err)
 	}
 
+	fm.updateTree(ctx, model.FileModified, path)
+
 	return readFile(fs, path)
 }
 
 func (fm *FileManagerImpl) UpdateLines(ctx context.Context, fs afero.Fs, path string, lineDiff LineDiffChunk) (*model.File, error) {
+	if err := checkProtected(ctx, fs, path); err != nil {
+		return nil, err
+	}
+
 	exists, err := fileExists(fs, path)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to check if file %s exists: %w", path, err)
@@ -260,9 +275,104 @@ func (fm *FileManagerImpl) UpdateLines(ctx context.Context, fs afero.Fs, path st
 		return nil, fmt.Errorf("Failed to write file %s: %w", path, err)
 	}
 
+	fm.updateTree(ctx, model.FileModified, path)
+
 	return readFile(fs, path)
 }
 
+// walkFiles calls fn for every file and directory that is not ignored by gitignore or the project ignore rules,
+// and that passes the hidden and pattern filters of opt.
+func (fm *FileManagerImpl) walkFiles(ctx context.Context, fs afero.Fs, opt *ListFilesOptions, fn func(path string, mode os.FileMode) error) error {
+	filter, err := opt.Filter.compile()
+	if err != nil {
+		return err
+	}
+
+	ignored, err := ignoreMatcher(ctx, fs)
+	if err != nil {
+		return err
+	}
+
+	return fm.walk(ctx, fs, func(path string, mode os.FileMode) error {
+		select {
+		case <-ctx.Done():
+			return errors.New("context cancelled")
+		default:
+		}
+
+		isDir := mode.IsDir()
+
+		match, err := ignored.Match(path, isDir)
+		if err != nil {
+			return fmt.Errorf("failed to match path %s: %w", path, err)
+		}
+		if match {
+			if isDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !opt.ShowHidden && isHidden(path) {
+			if isDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ok, err := filter.keep(path, isDir)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		return fn(path, mode)
+	})
+}
+
+// walk calls fn for every file and directory that is not ignored by gitignore. The cached file tree is used if available.
+func (fm *FileManagerImpl) walk(ctx context.Context, fs afero.Fs, fn func(path string, mode os.FileMode) error) error {
+	if fm.treeCache != nil {
+		err := fm.treeCache.Walk(ctx, fs, fn)
+		if !errors.Is(err, errTreeNotCached) {
+			return err
+		}
+	}
+
+	m, err := fm.gitignoreFactory.NewMatcher(fs)
+	if err != nil {
+		return fmt.Errorf("failed to create gitignore matcher: %w", err)
+	}
+
+	return afero.Walk(fs, "/", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("Error walking file tree on path %s: %w", path, err)
+		}
+
+		match, err := m.Match(path, info.IsDir())
+		if err != nil {
+			return fmt.Errorf("failed to match path %s: %w", path, err)
+		}
+		if match {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		return fn(path, info.Mode().Type())
+	})
+}
+
+// updateTree keeps the cached file tree in sync with writes, without waiting for file events.
+func (fm *FileManagerImpl) updateTree(ctx context.Context, eventType model.FileEventType, path string) {
+	if fm.treeCache != nil {
+		fm.treeCache.Update(ctx, model.FileEvent{Type: eventType, Path: path})
+	}
+}
+
 func readFile(fs afero.Fs, path string) (*model.File, error) {
 	content, err := afero.ReadFile(fs, path)
 	if err != nil {
@@ -276,8 +386,31 @@ func writeFile(fs afero.Fs, file *model.File) error {
 	return afero.WriteFile(fs, file.Path, []byte(file.GetContent()), 0o644)
 }
 
+// fileExists checks if the file exists without following symlinks, so that links can be deleted even if their target is not accessible.
 func fileExists(fs afero.Fs, path string) (bool, error) {
-	return afero.Exists(fs, path)
+	_, err := lstat(fs, path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func lstat(fs afero.Fs, path string) (os.FileInfo, error) {
+	if l, ok := fs.(afero.Lstater); ok {
+		info, _, err := l.LstatIfPossible(path)
+		return info, err
+	}
+	return fs.Stat(path)
+}
+
+func readlink(fs afero.Fs, path string) (string, error) {
+	if l, ok := fs.(afero.LinkReader); ok {
+		return l.ReadlinkIfPossible(path)
+	}
+	return "", fmt.Errorf("file system %s does not support links", fs.Name())
 }
 
 func isHidden(path string) bool {
@@ -19,7 +19,7 @@ func TestReadFile(t *testing.T) {
 	content := "line1\nline2\nline3\n"
 	afero.WriteFile(fs, path, []byte(content), 0o644)
 
-	fm := files.NewFileManager(nil)
+	fm := files.NewFileManager(nil, nil)
 	actual, err := fm.ReadFile(context.Background(), fs, path)
 	expected := model.NewFile(path, content)
 
@@ -36,7 +36,7 @@ func TestReadNonExistentFile(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	afero.WriteFile(fs, "test.txt", []byte("line1\nline2\nline3\n"), 0o644)
 
-	fm := files.NewFileManager(nil)
+	fm := files.NewFileManager(nil, nil)
 	_, err := fm.ReadFile(context.Background(), fs, "non-existent.txt")
 	if err == nil {
 		t.Fatalf("Expected error, got nil")
@@ -92,7 +92,7 @@ func TestFileManagerImpl_ApplyPatch_Success(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			filesystem := afero.NewMemMapFs()
 			afero.WriteFile(filesystem, "test.txt", []byte("line1\nline2\nline3\nline4\nline5\nline6\nline7\nline8\nline9\nline10\n"), 0o644)
-			fm := files.NewFileManager(nil)
+			fm := files.NewFileManager(nil, nil)
 			actual, err := fm.ApplyPatch(context.Background(), filesystem, "test.txt", tt.patch)
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
@@ -158,7 +158,7 @@ func TestFileManagerImpl_ApplyPatch_Failure(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			fileSystem := afero.NewMemMapFs()
 			afero.WriteFile(fileSystem, "test.txt", []byte("line1\nline2\nline3\n"), 0o644)
-			fm := files.NewFileManager(nil)
+			fm := files.NewFileManager(nil, nil)
 			_, err := fm.ApplyPatch(context.Background(), fileSystem, tt.file, tt.patch)
 			if err == nil {
 				t.Fatalf("Expected error, got nil")
@@ -208,7 +208,7 @@ func TestFileManagerImpl_UpdateLines_Success(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fm := files.NewFileManager(nil)
+			fm := files.NewFileManager(nil, nil)
 			filesystem := afero.NewMemMapFs()
 			afero.WriteFile(filesystem, "test.txt", []byte("line1\nline2\nline3\n"), 0o644)
 			actual, err := fm.UpdateLines(context.Background(), filesystem, "test.txt", tt.lineDiff)
@@ -253,7 +253,7 @@ func TestFileManagerImpl_UpdateLines_Failure(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			filesystem := afero.NewMemMapFs()
 			afero.WriteFile(filesystem, "test.txt", []byte("line1\nline2\nline3\n"), 0o644)
-			fm := files.NewFileManager(nil)
+			fm := files.NewFileManager(nil, nil)
 			_, err := fm.UpdateLines(context.Background(), filesystem, "test.txt", tt.lineDiff)
 			if err == nil {
 				t.Fatalf("Expected error, got nil")
@@ -283,7 +283,7 @@ func TestUpdateFile_Success(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			filesystem := afero.NewMemMapFs()
 			afero.WriteFile(filesystem, "test.txt", []byte("line11\nline12\n"), 0o644)
-			fm := files.NewFileManager(nil)
+			fm := files.NewFileManager(nil, nil)
 			actual, err := fm.UpdateFile(context.Background(), filesystem, "test.txt", tt.content)
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
@@ -312,7 +312,7 @@ func TestUpdateFile_Failure(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			filesystem := afero.NewMemMapFs()
-			fm := files.NewFileManager(nil)
+			fm := files.NewFileManager(nil, nil)
 			_, err := fm.UpdateFile(context.Background(), filesystem, "test.txt", tt.content)
 			if err == nil {
 				t.Fatalf("Expected error, got nil")
@@ -1249,7 +1249,7 @@ func TestListFile(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockGitignoreFactory := mocks.NewMockMatcherFactory()
 			tt.mockSetup(mockGitignoreFactory)
-			fm := files.NewFileManager(mockGitignoreFactory)
+			fm := files.NewFileManager(mockGitignoreFactory, nil)
 
 			files, err := fm.ListFiles(context.Background(), tt.fs, tt.opts...)
 			if err != nil {
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"io"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hide-org/hide/pkg/files"
@@ -11,13 +12,16 @@ import (
 
 // MockFileManager is a mock of the filemanager.FileManager interface for testing
 type MockFileManager struct {
-	CreateFileFunc  func(ctx context.Context, fs afero.Fs, path, content string) (*model.File, error)
-	ReadFileFunc    func(ctx context.Context, fs afero.Fs, path string) (*model.File, error)
-	UpdateFileFunc  func(ctx context.Context, fs afero.Fs, path, content string) (*model.File, error)
-	DeleteFileFunc  func(ctx context.Context, fs afero.Fs, path string) error
-	ListFilesFunc   func(ctx context.Context, fs afero.Fs) ([]*model.File, error)
-	ApplyPatchFunc  func(ctx context.Context, fs afero.Fs, path, patch string) (*model.File, error)
-	UpdateLinesFunc func(ctx context.Context, fs afero.Fs, path string, lineDiff files.LineDiffChunk) (*model.File, error)
+	CreateFileFunc         func(ctx context.Context, fs afero.Fs, path, content string) (*model.File, error)
+	ReadFileFunc           func(ctx context.Context, fs afero.Fs, path string) (*model.File, error)
+	UpdateFileFunc         func(ctx context.Context, fs afero.Fs, path, content string) (*model.File, error)
+	DeleteFileFunc         func(ctx context.Context, fs afero.Fs, path string) error
+	ListFilesFunc          func(ctx context.Context, fs afero.Fs) ([]*model.File, error)
+	ApplyPatchFunc         func(ctx context.Context, fs afero.Fs, path, patch string) (*model.File, error)
+	UpdateLinesFunc        func(ctx context.Context, fs afero.Fs, path string, lineDiff files.LineDiffChunk) (*model.File, error)
+	WriteArchiveFunc       func(ctx context.Context, fs afero.Fs, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error
+	ExtractArchiveFunc     func(ctx context.Context, fs afero.Fs, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error)
+	ApplyWorkspaceEditFunc func(ctx context.Context, fs afero.Fs, changes []files.FileChange, dryRun bool) ([]files.FileDiff, error)
 }
 
 func (m *MockFileManager) CreateFile(ctx context.Context, fs afero.Fs, path, content string) (*model.File, error) {
@@ -56,3 +60,15 @@ func DiffListFilesOpts(want files.ListFilesOptions, got ...files.ListFileOption)
 
 	return cmp.Diff(*gotO, want)
 }
+
+func (m *MockFileManager) WriteArchive(ctx context.Context, fs afero.Fs, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error {
+	return m.WriteArchiveFunc(ctx, fs, w, path, format, opts...)
+}
+
+func (m *MockFileManager) ExtractArchive(ctx context.Context, fs afero.Fs, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error) {
+	return m.ExtractArchiveFunc(ctx, fs, r, path, format, overwrite)
+}
+
+func (m *MockFileManager) ApplyWorkspaceEdit(ctx context.Context, fs afero.Fs, changes []files.FileChange, dryRun bool) ([]files.FileDiff, error) {
+	return m.ApplyWorkspaceEditFunc(ctx, fs, changes, dryRun)
+}
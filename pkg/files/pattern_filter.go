@@ -2,7 +2,6 @@ package files
 
 import (
 	"fmt"
-	"io/fs"
 	"path/filepath"
 
 	"github.com/gobwas/glob"
@@ -13,8 +12,50 @@ type PatternFilter struct {
 	Exclude []string
 }
 
-func (p PatternFilter) keep(path string, info fs.FileInfo) (ok bool, err error) {
-	exclude, err := p.shouldExclude(path, info)
+// compile compiles the include and exclude patterns once, so that they are not recompiled for every path.
+func (p PatternFilter) compile() (compiledPatternFilter, error) {
+	var c compiledPatternFilter
+
+	for _, pattern := range p.Include {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return compiledPatternFilter{}, fmt.Errorf("Error include matching pattern %s: %w", pattern, err)
+		}
+		c.include = append(c.include, g)
+	}
+
+	for _, pattern := range p.Exclude {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return compiledPatternFilter{}, fmt.Errorf("Error exclude matching pattern %s: %w", pattern, err)
+		}
+		c.exclude = append(c.exclude, g)
+	}
+
+	return c, nil
+}
+
+// Matcher returns a function that reports if a file is kept by the filter, the patterns are compiled once.
+func (p PatternFilter) Matcher() (func(path string) bool, error) {
+	c, err := p.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(path string) bool {
+		// errors are only returned for directories
+		ok, _ := c.keep(path, false)
+		return ok
+	}, nil
+}
+
+type compiledPatternFilter struct {
+	include []glob.Glob
+	exclude []glob.Glob
+}
+
+func (p compiledPatternFilter) keep(path string, isDir bool) (ok bool, err error) {
+	exclude, err := p.shouldExclude(path, isDir)
 	if err != nil {
 		return false, err
 	}
@@ -22,49 +63,32 @@ func (p PatternFilter) keep(path string, info fs.FileInfo) (ok bool, err error)
 		return false, nil
 	}
 
-	include, err := p.shouldInclude(path, info)
-	if err != nil {
-		return false, err
-	}
-	if !include {
+	if !p.shouldInclude(path, isDir) {
 		return false, nil
 	}
 
 	return true, nil
 }
 
-func (p PatternFilter) shouldInclude(path string, info fs.FileInfo) (ok bool, err error) {
+func (p compiledPatternFilter) shouldInclude(path string, isDir bool) bool {
 	// always include directories
-	if len(p.Include) == 0 || info.IsDir() {
-		return true, nil
+	if len(p.include) == 0 || isDir {
+		return true
 	}
 
-	for _, pattern := range p.Include {
-		g, err := glob.Compile(pattern)
-		if err != nil {
-			return false, fmt.Errorf("Error include matching pattern %s: %w", pattern, err)
-		}
+	for _, g := range p.include {
 		if g.Match(path) {
-			return true, nil
+			return true
 		}
 	}
 
-	return false, nil
+	return false
 }
 
-func (p PatternFilter) shouldExclude(path string, info fs.FileInfo) (ok bool, err error) {
-	if len(p.Exclude) == 0 {
-		return false, nil
-	}
-
-	for _, pattern := range p.Exclude {
-		g, err := glob.Compile(pattern)
-		if err != nil {
-			return false, fmt.Errorf("Error exclude matching pattern %s: %w", pattern, err)
-		}
-
+func (p compiledPatternFilter) shouldExclude(path string, isDir bool) (ok bool, err error) {
+	for _, g := range p.exclude {
 		if g.Match(path) {
-			if info.IsDir() {
+			if isDir {
 				// exclude whole directory
 				return false, filepath.SkipDir
 			}
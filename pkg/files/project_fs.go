@@ -0,0 +1,274 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// maxSymlinks limits the number of symlinks followed while resolving a path, same as Linux.
+const maxSymlinks = 40
+
+var (
+	_ afero.Lstater    = (*ProjectFs)(nil)
+	_ afero.LinkReader = (*ProjectFs)(nil)
+)
+
+// ProjectFs is a file system rooted at the project directory. Unlike afero.BasePathFs, it resolves symlinks
+// and refuses paths that point outside of the project, so a link in the repository cannot be used to touch host files.
+// Operations that act on a link itself (Remove, RemoveAll, Rename, LstatIfPossible, ReadlinkIfPossible) do not follow the last path element.
+//
+// Paths are resolved before they are used, so a link that is swapped in between could still be followed. Files that are opened
+// are checked again after the open, on Linux by the path of their descriptor. The other operations are not, they rely on the
+// project directory not being changed concurrently by someone who wants to escape it.
+type ProjectFs struct {
+	root string
+	base afero.Fs
+}
+
+func NewProjectFs(root string) afero.Fs {
+	return &ProjectFs{root: root, base: afero.NewBasePathFs(afero.NewOsFs(), root)}
+}
+
+func (p *ProjectFs) Name() string {
+	return "ProjectFs"
+}
+
+func (p *ProjectFs) Create(name string) (afero.File, error) {
+	return p.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (p *ProjectFs) Mkdir(name string, perm os.FileMode) error {
+	path, err := p.resolve(name, true)
+	if err != nil {
+		return err
+	}
+	return p.base.Mkdir(path, perm)
+}
+
+func (p *ProjectFs) MkdirAll(name string, perm os.FileMode) error {
+	path, err := p.resolve(name, true)
+	if err != nil {
+		return err
+	}
+	return p.base.MkdirAll(path, perm)
+}
+
+func (p *ProjectFs) Open(name string) (afero.File, error) {
+	return p.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (p *ProjectFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	path, err := p.resolve(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// the last element is resolved already, if it is a link now it was swapped in after the resolve
+	file, err := p.base.OpenFile(path, flag|syscall.O_NOFOLLOW, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.checkOpened(name, file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (p *ProjectFs) Remove(name string) error {
+	path, err := p.resolve(name, false)
+	if err != nil {
+		return err
+	}
+	return p.base.Remove(path)
+}
+
+func (p *ProjectFs) RemoveAll(name string) error {
+	path, err := p.resolve(name, false)
+	if err != nil {
+		return err
+	}
+	return p.base.RemoveAll(path)
+}
+
+func (p *ProjectFs) Rename(oldname, newname string) error {
+	oldPath, err := p.resolve(oldname, false)
+	if err != nil {
+		return err
+	}
+	newPath, err := p.resolve(newname, false)
+	if err != nil {
+		return err
+	}
+	return p.base.Rename(oldPath, newPath)
+}
+
+func (p *ProjectFs) Stat(name string) (os.FileInfo, error) {
+	path, err := p.resolve(name, true)
+	if err != nil {
+		return nil, err
+	}
+	return p.base.Stat(path)
+}
+
+func (p *ProjectFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	path, err := p.resolve(name, false)
+	if err != nil {
+		return nil, false, err
+	}
+	return p.base.(afero.Lstater).LstatIfPossible(path)
+}
+
+// ReadlinkIfPossible returns the target of the link as it is stored, without resolving it.
+func (p *ProjectFs) ReadlinkIfPossible(name string) (string, error) {
+	path, err := p.resolve(name, false)
+	if err != nil {
+		return "", err
+	}
+	return p.base.(afero.LinkReader).ReadlinkIfPossible(path)
+}
+
+func (p *ProjectFs) Chmod(name string, mode os.FileMode) error {
+	path, err := p.resolve(name, true)
+	if err != nil {
+		return err
+	}
+	return p.base.Chmod(path, mode)
+}
+
+func (p *ProjectFs) Chown(name string, uid, gid int) error {
+	path, err := p.resolve(name, true)
+	if err != nil {
+		return err
+	}
+	return p.base.Chown(path, uid, gid)
+}
+
+func (p *ProjectFs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	path, err := p.resolve(name, true)
+	if err != nil {
+		return err
+	}
+	return p.base.Chtimes(path, atime, mtime)
+}
+
+// checkOpened makes sure that an opened file is within the project, in case a directory of its path was replaced by a link
+// after the path was resolved. Without /proc the file can't be checked and is accepted.
+func (p *ProjectFs) checkOpened(name string, file afero.File) error {
+	if baseFile, ok := file.(*afero.BasePathFile); ok {
+		file = baseFile.File
+	}
+
+	osFile, ok := file.(*os.File)
+	if !ok {
+		return nil
+	}
+
+	opened, err := os.Readlink(fmt.Sprintf("/proc/self/fd/%d", osFile.Fd()))
+	if err != nil {
+		return nil
+	}
+
+	root, err := filepath.EvalSymlinks(p.root)
+	if err != nil {
+		return err
+	}
+
+	if rel, err := filepath.Rel(root, opened); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return NewPathOutsideProjectError(name)
+	}
+
+	return nil
+}
+
+// resolve returns the path relative to the project root with all symlinks resolved. If followLast is false, the last
+// path element is not resolved. Paths that do not exist yet are resolved as far as they exist.
+func (p *ProjectFs) resolve(name string, followLast bool) (string, error) {
+	cleaned := filepath.Clean(strings.TrimPrefix(name, string(filepath.Separator)))
+	if cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", NewPathOutsideProjectError(name)
+	}
+
+	root, err := filepath.EvalSymlinks(p.root)
+	if err != nil {
+		return "", err
+	}
+
+	current := root
+	remaining := splitPath(cleaned)
+	links := 0
+	missing := false
+
+	for len(remaining) > 0 {
+		part := remaining[0]
+		remaining = remaining[1:]
+
+		if part == ".." {
+			current = filepath.Dir(current)
+			continue
+		}
+
+		next := filepath.Join(current, part)
+
+		if missing || (len(remaining) == 0 && !followLast) {
+			current = next
+			continue
+		}
+
+		info, err := os.Lstat(next)
+		if os.IsNotExist(err) {
+			// the rest of the path does not exist, so it cannot contain symlinks
+			missing = true
+			current = next
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		links++
+		if links > maxSymlinks {
+			return "", &os.PathError{Op: "resolve", Path: name, Err: syscall.ELOOP}
+		}
+
+		target, err := os.Readlink(next)
+		if err != nil {
+			return "", err
+		}
+
+		if filepath.IsAbs(target) {
+			current = string(filepath.Separator)
+		}
+		remaining = append(splitPath(target), remaining...)
+	}
+
+	rel, err := filepath.Rel(root, current)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", NewPathOutsideProjectError(name)
+	}
+
+	return filepath.Join(string(filepath.Separator), rel), nil
+}
+
+func splitPath(path string) []string {
+	var parts []string
+	for _, part := range strings.Split(path, string(filepath.Separator)) {
+		if part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
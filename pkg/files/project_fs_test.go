@@ -0,0 +1,154 @@
+package files_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/gitignore"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectFs(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		"main.go":       "package main",
+		"src/lib.go":    "package src",
+		"src/nested.go": "package src",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for link, target := range map[string]string{
+		"link.go":          "main.go",
+		"src/up.go":        "../main.go",
+		"lib":              "src",
+		"secret.txt":       filepath.Join(outside, "secret.txt"),
+		"outside":          outside,
+		"relative-outside": "../" + filepath.Base(outside),
+		"dangling":         filepath.Join(outside, "new.txt"),
+		"loop":             "loop",
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fs := files.NewProjectFs(root)
+
+	tests := []struct {
+		name        string
+		op          func() error
+		wantOutside bool
+		wantErr     bool
+	}{
+		{name: "read file", op: readOp(fs, "main.go")},
+		{name: "read file with absolute path", op: readOp(fs, "/src/lib.go")},
+		{name: "read file with dot segments", op: readOp(fs, "src/../main.go")},
+		{name: "read link within project", op: readOp(fs, "link.go")},
+		{name: "read relative link within project", op: readOp(fs, "src/up.go")},
+		{name: "read through directory link", op: readOp(fs, "lib/lib.go")},
+		{name: "read path escaping with dot segments", op: readOp(fs, "../secret.txt"), wantOutside: true},
+		{name: "read path escaping with nested dot segments", op: readOp(fs, "src/../../secret.txt"), wantOutside: true},
+		{name: "read link to file outside", op: readOp(fs, "secret.txt"), wantOutside: true},
+		{name: "read through directory link outside", op: readOp(fs, "outside/secret.txt"), wantOutside: true},
+		{name: "read through relative link outside", op: readOp(fs, "relative-outside/secret.txt"), wantOutside: true},
+		{name: "write through directory link outside", op: writeOp(fs, "outside/new.txt"), wantOutside: true},
+		{name: "write to dangling link outside", op: writeOp(fs, "dangling"), wantOutside: true},
+		{name: "create directory through link outside", op: func() error { return fs.MkdirAll("outside/dir", 0o755) }, wantOutside: true},
+		{name: "write new file", op: writeOp(fs, "new/file.go")},
+		{name: "read link loop", op: readOp(fs, "loop"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op()
+
+			var outsideErr *files.PathOutsideProjectError
+			switch {
+			case tt.wantOutside:
+				assert.ErrorAs(t, err, &outsideErr)
+			case tt.wantErr:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, entries, 1, "files outside of the project must not be created")
+
+	// links themselves can be removed, their targets are not touched
+	if err := fs.Remove("secret.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.RemoveAll("outside"); err != nil {
+		t.Fatal(err)
+	}
+	assert.FileExists(t, filepath.Join(outside, "secret.txt"))
+}
+
+func TestFileManager_ListFiles_Symlinks(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("main.go", filepath.Join(root, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "outside")); err != nil {
+		t.Fatal(err)
+	}
+
+	fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+	got, err := fm.ListFiles(context.Background(), files.NewProjectFs(root), files.ListFilesWithContent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []*model.File{
+		model.SymlinkFile("link.go", "main.go"),
+		model.NewFile("main.go", "package main"),
+		model.SymlinkFile("outside", outside),
+	}, got)
+}
+
+func readOp(fs afero.Fs, path string) func() error {
+	return func() error {
+		_, err := afero.ReadFile(fs, path)
+		return err
+	}
+}
+
+func writeOp(fs afero.Fs, path string) func() error {
+	return func() error {
+		if err := fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return afero.WriteFile(fs, path, []byte("content"), 0o644)
+	}
+}
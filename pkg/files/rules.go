@@ -0,0 +1,64 @@
+package files
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/gitignore"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/spf13/afero"
+)
+
+// hideignoreFile lists files that are hidden from listing and search, in the gitignore format
+const hideignoreFile = ".hideignore"
+
+// ignoreMatcher returns a matcher for the .hideignore file and the ignore list of the project in ctx.
+func ignoreMatcher(ctx context.Context, fs afero.Fs) (gitignore.Matcher, error) {
+	var patterns []string
+	if hide := hideCustomization(ctx); hide != nil {
+		patterns = hide.Ignore
+	}
+
+	return gitignore.NewFileMatcher(fs, hideignoreFile, patterns)
+}
+
+// checkProtected returns PathProtectedError if the path matches protected paths of the project in ctx.
+// Symlinks are resolved, so that a link cannot be used to modify a protected file.
+func checkProtected(ctx context.Context, fs afero.Fs, path string) error {
+	hide := hideCustomization(ctx)
+	if hide == nil || len(hide.ProtectedPaths) == 0 {
+		return nil
+	}
+
+	matcher := gitignore.NewPatternMatcher(hide.ProtectedPaths)
+	paths := []string{filepath.Join("/", path)}
+	if projectFs, ok := fs.(*ProjectFs); ok {
+		if resolved, err := projectFs.resolve(path, true); err == nil {
+			paths = append(paths, resolved)
+		}
+	}
+
+	for _, p := range paths {
+		match, err := matcher.Match(p, false)
+		if err != nil {
+			return fmt.Errorf("failed to match protected paths: %w", err)
+		}
+
+		if match {
+			return NewPathProtectedError(path)
+		}
+	}
+
+	return nil
+}
+
+func hideCustomization(ctx context.Context) *devcontainer.HideCustomization {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	return project.Config.DevContainerConfig.Customizations.Hide
+}
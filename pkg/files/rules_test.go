@@ -0,0 +1,152 @@
+package files_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/gitignore"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileManager_ListFiles_IgnoreRules(t *testing.T) {
+	fs := newTestFs(t, map[string]string{
+		"/.hideignore":          "secrets/\n# comment\n*.env\n",
+		"/main.go":              "package main",
+		"/prod.env":             "KEY=value",
+		"/secrets/key.pem":      "key",
+		"/fixtures/large.json":  "{}",
+		"/fixtures/small.json":  "{}",
+		"/src/generated/api.go": "package generated",
+	})
+
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{
+		Id: "project-id",
+		Config: model.Config{DevContainerConfig: devcontainer.Config{GeneralProperties: devcontainer.GeneralProperties{
+			Customizations: devcontainer.Customizations{Hide: &devcontainer.HideCustomization{
+				Ignore: []string{"fixtures/large.json", "generated"},
+			}},
+		}}},
+	})
+
+	fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+	got, err := fm.ListFiles(ctx, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []*model.File{
+		model.EmptyFile("fixtures/small.json"),
+		model.EmptyFile("main.go"),
+	}, got)
+}
+
+func TestFileManager_ProtectedPaths(t *testing.T) {
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		".github/workflows/ci.yml": "on: push",
+		"package-lock.json":        "{}",
+		"web/package-lock.json":    "{}",
+		"main.go":                  "package main",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(".github/workflows/ci.yml", filepath.Join(root, "ci.yml")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{
+		Id: "project-id",
+		Config: model.Config{DevContainerConfig: devcontainer.Config{GeneralProperties: devcontainer.GeneralProperties{
+			Customizations: devcontainer.Customizations{Hide: &devcontainer.HideCustomization{
+				ProtectedPaths: []string{".github/workflows/**", "package-lock.json"},
+			}},
+		}}},
+	})
+
+	fs := files.NewProjectFs(root)
+	fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+	tests := []struct {
+		name          string
+		op            func() error
+		wantProtected bool
+	}{
+		{
+			name: "update protected file",
+			op: func() error {
+				_, err := fm.UpdateFile(ctx, fs, ".github/workflows/ci.yml", "on: pull_request")
+				return err
+			},
+			wantProtected: true,
+		},
+		{
+			name: "create file in protected directory",
+			op: func() error {
+				_, err := fm.CreateFile(ctx, fs, ".github/workflows/release.yml", "on: push")
+				return err
+			},
+			wantProtected: true,
+		},
+		{
+			name: "delete nested protected file",
+			op: func() error {
+				return fm.DeleteFile(ctx, fs, "web/package-lock.json")
+			},
+			wantProtected: true,
+		},
+		{
+			name: "patch protected file",
+			op: func() error {
+				_, err := fm.ApplyPatch(ctx, fs, "package-lock.json", "--- package-lock.json\n+++ package-lock.json\n@@ -1 +1 @@\n-{}\n+[]\n")
+				return err
+			},
+			wantProtected: true,
+		},
+		{
+			name: "update lines of protected file through link",
+			op: func() error {
+				_, err := fm.UpdateLines(ctx, fs, "ci.yml", files.LineDiffChunk{StartLine: 1, EndLine: 2, Content: "on: pull_request"})
+				return err
+			},
+			wantProtected: true,
+		},
+		{
+			name: "update not protected file",
+			op: func() error {
+				_, err := fm.UpdateFile(ctx, fs, "main.go", "package app")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op()
+
+			if tt.wantProtected {
+				var protectedErr *files.PathProtectedError
+				assert.ErrorAs(t, err, &protectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	// protected files can still be read
+	file, err := fm.ReadFile(ctx, fs, ".github/workflows/ci.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "on: push", file.GetContent())
+}
@@ -0,0 +1,358 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hide-org/hide/pkg/gitignore"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/watcher"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/afero"
+)
+
+const (
+	gitDir          = ".git"
+	gitignoreFile   = ".gitignore"
+	infoExcludeFile = ".git/info/exclude"
+)
+
+var errTreeNotCached = errors.New("file tree is not cached")
+
+// TreeCache keeps an in-memory file tree per project with compiled gitignore patterns.
+// Trees are built on first use and updated incrementally on writes and on file events of the project.
+type TreeCache interface {
+	// Walk calls fn for every file and directory of the project in ctx that is not ignored, in lexical order.
+	// The mode only contains the type bits, symlinks are not followed. If fn returns filepath.SkipDir for a directory, its content is skipped.
+	Walk(ctx context.Context, fs afero.Fs, fn func(path string, mode os.FileMode) error) error
+	// Update applies file events to the tree of the project in ctx, if it is cached.
+	Update(ctx context.Context, events ...model.FileEvent)
+}
+
+type TreeCacheImpl struct {
+	matcherFactory gitignore.MatcherFactory
+	fileWatcher    watcher.Service
+	trees          map[model.ProjectId]*tree
+	mu             sync.Mutex
+}
+
+func NewTreeCache(matcherFactory gitignore.MatcherFactory, fileWatcher watcher.Service) TreeCache {
+	return &TreeCacheImpl{
+		matcherFactory: matcherFactory,
+		fileWatcher:    fileWatcher,
+		trees:          make(map[model.ProjectId]*tree),
+	}
+}
+
+func (c *TreeCacheImpl) Walk(ctx context.Context, fs afero.Fs, fn func(path string, mode os.FileMode) error) error {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		return errTreeNotCached
+	}
+
+	t, err := c.getOrBuild(project.Id, fs)
+	if err != nil {
+		return err
+	}
+
+	return t.walk(fn)
+}
+
+func (c *TreeCacheImpl) Update(ctx context.Context, events ...model.FileEvent) {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	t, ok := c.trees[project.Id]
+	c.mu.Unlock()
+
+	if ok {
+		t.update(events)
+	}
+}
+
+func (c *TreeCacheImpl) getOrBuild(projectId model.ProjectId, fs afero.Fs) (*tree, error) {
+	c.mu.Lock()
+	t, ok := c.trees[projectId]
+	if !ok {
+		t = &tree{fs: fs, matcherFactory: c.matcherFactory}
+		c.trees[projectId] = t
+	}
+	c.mu.Unlock()
+
+	t.once.Do(func() {
+		// without file events the tree would become stale, so it is only cached while the project is watched
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := c.fileWatcher.Subscribe(ctx, projectId)
+		if err != nil {
+			cancel()
+			log.Debug().Err(err).Str("projectId", projectId).Msg("Project is not watched, file tree will not be cached")
+			t.err = errTreeNotCached
+			return
+		}
+		t.cancel = cancel
+
+		if err := t.build(); err != nil {
+			t.err = fmt.Errorf("failed to build file tree: %w", err)
+			return
+		}
+
+		log.Debug().Str("projectId", projectId).Int("entries", len(t.entries)).Msg("Cached file tree")
+
+		go func() {
+			for batch := range events {
+				t.update(batch)
+			}
+
+			// project is not watched anymore
+			c.evict(projectId, t)
+		}()
+	})
+
+	if t.err != nil {
+		c.evict(projectId, t)
+		return nil, t.err
+	}
+
+	return t, nil
+}
+
+// evict drops the tree of the project, if it is still cached, and ends its subscription to file events.
+func (c *TreeCacheImpl) evict(projectId model.ProjectId, t *tree) {
+	c.mu.Lock()
+	if c.trees[projectId] == t {
+		delete(c.trees, projectId)
+	}
+	c.mu.Unlock()
+
+	if t.cancel != nil {
+		t.cancel()
+	}
+}
+
+// tree is a snapshot of the project files that are not ignored. Paths are absolute within the project file system, e.g. /src/main.go.
+type tree struct {
+	fs             afero.Fs
+	matcherFactory gitignore.MatcherFactory
+	matcher        gitignore.Matcher
+	entries        map[string]os.FileMode // path -> type bits of the file mode
+	sorted         []string               // entries in walk order, nil if it has to be recomputed
+	stale          bool                   // ignore rules changed, the tree has to be rebuilt
+	once           sync.Once
+	cancel         context.CancelFunc // ends the subscription to file events
+	err            error
+	mu             sync.Mutex
+}
+
+func (t *tree) build() error {
+	matcher, err := t.matcherFactory.NewMatcher(t.fs)
+	if err != nil {
+		return fmt.Errorf("failed to create gitignore matcher: %w", err)
+	}
+
+	entries := make(map[string]os.FileMode)
+	if err := walkNotIgnored(t.fs, "/", matcher, func(path string, mode os.FileMode) {
+		entries[path] = mode
+	}); err != nil {
+		return err
+	}
+
+	t.matcher = matcher
+	t.entries = entries
+	t.sorted = nil
+	t.stale = false
+
+	return nil
+}
+
+func (t *tree) walk(fn func(path string, mode os.FileMode) error) error {
+	t.mu.Lock()
+	if t.stale {
+		if err := t.build(); err != nil {
+			t.mu.Unlock()
+			return fmt.Errorf("failed to rebuild file tree: %w", err)
+		}
+	}
+
+	if t.sorted == nil {
+		t.sorted = make([]string, 0, len(t.entries))
+		for path := range t.entries {
+			t.sorted = append(t.sorted, path)
+		}
+		slices.SortFunc(t.sorted, comparePaths)
+	}
+
+	// fn is called without holding the lock, so that it can read files while the tree is updated
+	paths := t.sorted
+	modes := make([]os.FileMode, len(paths))
+	for i, path := range paths {
+		modes[i] = t.entries[path]
+	}
+	t.mu.Unlock()
+
+	skipPrefix := ""
+	for i, path := range paths {
+		if skipPrefix != "" && strings.HasPrefix(path, skipPrefix) {
+			continue
+		}
+		skipPrefix = ""
+
+		if err := fn(path, modes[i]); err != nil {
+			if errors.Is(err, filepath.SkipDir) && modes[i].IsDir() {
+				skipPrefix = strings.TrimSuffix(path, "/") + "/"
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *tree) update(events []model.FileEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.entries == nil {
+		return
+	}
+
+	for _, event := range events {
+		path := filepath.Join("/", event.Path)
+
+		if filepath.Base(path) == gitignoreFile || path == filepath.Join("/", infoExcludeFile) {
+			// rebuilding lazily is simpler than re-evaluating every path against the new patterns
+			t.stale = true
+		}
+
+		switch event.Type {
+		case model.FileCreated, model.FileModified:
+			t.add(path)
+		case model.FileDeleted:
+			t.remove(path)
+		case model.FileRenamed:
+			t.remove(filepath.Join("/", event.OldPath))
+			t.add(path)
+		}
+	}
+}
+
+// add adds the path with its missing parent directories. The content of added directories is read from the file system.
+func (t *tree) add(path string) {
+	if _, ok := t.entries[path]; ok {
+		return
+	}
+
+	parent := filepath.Dir(path)
+	if _, ok := t.entries[parent]; !ok && parent != path {
+		t.add(parent)
+		if _, ok := t.entries[parent]; !ok {
+			// parent is ignored
+			return
+		}
+	}
+
+	info, err := lstat(t.fs, path)
+	if err != nil {
+		// removed in the meantime, a delete event follows
+		return
+	}
+
+	if t.ignored(path, info.IsDir()) {
+		return
+	}
+
+	t.sorted = nil
+
+	if !info.IsDir() {
+		t.entries[path] = info.Mode().Type()
+		return
+	}
+
+	if err := walkNotIgnored(t.fs, path, t.matcher, func(p string, mode os.FileMode) {
+		t.entries[p] = mode
+	}); err != nil {
+		log.Warn().Err(err).Str("path", path).Msg("Failed to read directory, rebuilding file tree")
+		t.stale = true
+	}
+}
+
+func (t *tree) remove(path string) {
+	mode, ok := t.entries[path]
+	if !ok {
+		return
+	}
+
+	t.sorted = nil
+	delete(t.entries, path)
+
+	if !mode.IsDir() {
+		return
+	}
+
+	prefix := path + "/"
+	for p := range t.entries {
+		if strings.HasPrefix(p, prefix) {
+			delete(t.entries, p)
+		}
+	}
+}
+
+func (t *tree) ignored(path string, isDir bool) bool {
+	if path == "/"+gitDir || strings.HasPrefix(path, "/"+gitDir+"/") {
+		return true
+	}
+
+	match, err := t.matcher.Match(path, isDir)
+	if err != nil {
+		log.Warn().Err(err).Str("path", path).Msg("Failed to match path against gitignore")
+		return false
+	}
+
+	return match
+}
+
+// walkNotIgnored walks the directory and calls fn for every file and directory that is not ignored.
+// The .git directory is skipped, because its content is not part of the project and changes all the time.
+func walkNotIgnored(fs afero.Fs, root string, matcher gitignore.Matcher, fn func(path string, mode os.FileMode)) error {
+	return afero.Walk(fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path != root {
+				// removed while walking
+				return nil
+			}
+			return fmt.Errorf("Error walking file tree on path %s: %w", path, err)
+		}
+
+		if path == "/"+gitDir {
+			return filepath.SkipDir
+		}
+
+		match, err := matcher.Match(path, info.IsDir())
+		if err != nil {
+			return fmt.Errorf("failed to match path %s: %w", path, err)
+		}
+		if match {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		fn(path, info.Mode().Type())
+		return nil
+	})
+}
+
+// comparePaths orders paths the way afero.Walk visits them: directories are followed by their content.
+func comparePaths(a, b string) int {
+	return slices.Compare(strings.Split(a, "/"), strings.Split(b, "/"))
+}
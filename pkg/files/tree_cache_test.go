@@ -0,0 +1,284 @@
+package files_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/gitignore"
+	gitignore_mocks "github.com/hide-org/hide/pkg/gitignore/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	watcher_mocks "github.com/hide-org/hide/pkg/watcher/mocks"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTreeCache_Walk(t *testing.T) {
+	fs := newTestFs(t, map[string]string{
+		"/.gitignore":            "node_modules\n*.log\n",
+		"/.git/HEAD":             "ref: refs/heads/main",
+		"/main.go":               "package main",
+		"/debug.log":             "log",
+		"/node_modules/index.js": "",
+		"/pkg/util/util.go":      "package util",
+		"/pkg/util.go":           "package pkg",
+		"/pkg-extra/extra.go":    "package extra",
+	})
+
+	events := make(chan []model.FileEvent)
+	defer close(events)
+
+	fileWatcher := &watcher_mocks.MockWatcherService{}
+	fileWatcher.On("Subscribe", mock.Anything, "project-id").Return((<-chan []model.FileEvent)(events), nil)
+
+	cache := files.NewTreeCache(gitignore.NewMatcherFactory(), fileWatcher)
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id"})
+
+	assert.Equal(t, []string{
+		"/",
+		"/.gitignore",
+		"/main.go",
+		"/pkg",
+		"/pkg/util",
+		"/pkg/util/util.go",
+		"/pkg/util.go",
+		"/pkg-extra",
+		"/pkg-extra/extra.go",
+	}, walkPaths(t, cache, ctx, fs, nil))
+
+	skipPkg := func(path string) bool { return path == "/pkg" }
+	assert.Equal(t, []string{
+		"/",
+		"/.gitignore",
+		"/main.go",
+		"/pkg",
+		"/pkg-extra",
+		"/pkg-extra/extra.go",
+	}, walkPaths(t, cache, ctx, fs, skipPkg))
+
+	// tree is built once
+	fileWatcher.AssertNumberOfCalls(t, "Subscribe", 1)
+}
+
+func TestTreeCache_FileEvents(t *testing.T) {
+	fs := newTestFs(t, map[string]string{
+		"/.gitignore": "*.log\n",
+		"/main.go":    "package main",
+		"/old/a.go":   "package old",
+	})
+
+	events := make(chan []model.FileEvent)
+	defer close(events)
+
+	fileWatcher := &watcher_mocks.MockWatcherService{}
+	fileWatcher.On("Subscribe", mock.Anything, "project-id").Return((<-chan []model.FileEvent)(events), nil)
+
+	cache := files.NewTreeCache(gitignore.NewMatcherFactory(), fileWatcher)
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id"})
+
+	// build the tree
+	walkPaths(t, cache, ctx, fs, nil)
+
+	writeTestFile(t, fs, "/src/lib/lib.go", "package lib")
+	writeTestFile(t, fs, "/debug.log", "log")
+	if err := fs.Rename("/old", "/new"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Remove("/main.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	events <- []model.FileEvent{
+		{Type: model.FileCreated, Path: "src", IsDir: true},
+		{Type: model.FileCreated, Path: "debug.log"},
+		{Type: model.FileRenamed, Path: "new", OldPath: "old", IsDir: true},
+		{Type: model.FileDeleted, Path: "main.go"},
+	}
+
+	want := []string{
+		"/",
+		"/.gitignore",
+		"/new",
+		"/new/a.go",
+		"/src",
+		"/src/lib",
+		"/src/lib/lib.go",
+	}
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(want, walkPaths(t, cache, ctx, fs, nil))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTreeCache_GitignoreChanged(t *testing.T) {
+	fs := newTestFs(t, map[string]string{
+		"/.gitignore": "",
+		"/main.go":    "package main",
+		"/debug.log":  "log",
+	})
+
+	events := make(chan []model.FileEvent)
+	defer close(events)
+
+	fileWatcher := &watcher_mocks.MockWatcherService{}
+	fileWatcher.On("Subscribe", mock.Anything, "project-id").Return((<-chan []model.FileEvent)(events), nil)
+
+	cache := files.NewTreeCache(gitignore.NewMatcherFactory(), fileWatcher)
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id"})
+
+	assert.Equal(t, []string{"/", "/.gitignore", "/debug.log", "/main.go"}, walkPaths(t, cache, ctx, fs, nil))
+
+	writeTestFile(t, fs, "/.gitignore", "*.log\n")
+	cache.Update(ctx, model.FileEvent{Type: model.FileModified, Path: ".gitignore"})
+
+	assert.Equal(t, []string{"/", "/.gitignore", "/main.go"}, walkPaths(t, cache, ctx, fs, nil))
+}
+
+func TestTreeCache_SubscriptionClosed(t *testing.T) {
+	fs := newTestFs(t, map[string]string{"/main.go": "package main"})
+
+	events := make(chan []model.FileEvent)
+
+	fileWatcher := &watcher_mocks.MockWatcherService{}
+	fileWatcher.On("Subscribe", mock.Anything, "project-id").Return((<-chan []model.FileEvent)(events), nil).Once()
+	fileWatcher.On("Subscribe", mock.Anything, "project-id").Return(nil, errors.New("not watched"))
+
+	cache := files.NewTreeCache(gitignore.NewMatcherFactory(), fileWatcher)
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id"})
+
+	walkPaths(t, cache, ctx, fs, nil)
+	close(events)
+
+	// once the project is not watched anymore, the tree is dropped and not cached again
+	assert.Eventually(t, func() bool {
+		return cache.Walk(ctx, fs, func(string, os.FileMode) error { return nil }) != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTreeCache_BuildFailed(t *testing.T) {
+	fs := newTestFs(t, map[string]string{"/main.go": "package main"})
+
+	var subscription context.Context
+	fileWatcher := &watcher_mocks.MockWatcherService{}
+	fileWatcher.On("Subscribe", mock.Anything, "project-id").Run(func(args mock.Arguments) {
+		subscription = args.Get(0).(context.Context)
+	}).Return((<-chan []model.FileEvent)(make(chan []model.FileEvent)), nil)
+
+	matcherFactory := gitignore_mocks.NewMockMatcherFactory()
+	matcherFactory.On("NewMatcher", fs).Return(gitignore_mocks.NewMockMatcher(), errors.New("invalid pattern"))
+
+	cache := files.NewTreeCache(matcherFactory, fileWatcher)
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id"})
+
+	err := cache.Walk(ctx, fs, func(string, os.FileMode) error { return nil })
+	assert.Error(t, err)
+
+	// the subscription of the tree that could not be built is ended
+	select {
+	case <-subscription.Done():
+	case <-time.After(time.Second):
+		t.Fatal("subscription not canceled")
+	}
+}
+
+func TestFileManager_ListFiles_TreeCache(t *testing.T) {
+	fs := newTestFs(t, map[string]string{
+		"/.gitignore": "*.log\n",
+		"/main.go":    "package main",
+		"/debug.log":  "log",
+	})
+
+	events := make(chan []model.FileEvent)
+	defer close(events)
+
+	fileWatcher := &watcher_mocks.MockWatcherService{}
+	fileWatcher.On("Subscribe", mock.Anything, "project-id").Return((<-chan []model.FileEvent)(events), nil)
+
+	fm := files.NewFileManager(gitignore.NewMatcherFactory(), files.NewTreeCache(gitignore.NewMatcherFactory(), fileWatcher))
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id"})
+
+	got, err := fm.ListFiles(ctx, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*model.File{model.EmptyFile("main.go")}, got)
+
+	// writes are applied to the tree without waiting for file events
+	if _, err := fm.CreateFile(ctx, fs, "/src/lib.go", "package src"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fm.DeleteFile(ctx, fs, "/main.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = fm.ListFiles(ctx, fs, files.ListFilesWithShowHidden())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*model.File{model.EmptyFile(".gitignore"), model.EmptyFile("src/lib.go")}, got)
+}
+
+func TestFileManager_ListFiles_NotWatched(t *testing.T) {
+	fs := newTestFs(t, map[string]string{
+		"/.gitignore": "*.log\n",
+		"/main.go":    "package main",
+		"/debug.log":  "log",
+	})
+
+	fileWatcher := &watcher_mocks.MockWatcherService{}
+	fileWatcher.On("Subscribe", mock.Anything, "project-id").Return(nil, errors.New("not watched"))
+
+	fm := files.NewFileManager(gitignore.NewMatcherFactory(), files.NewTreeCache(gitignore.NewMatcherFactory(), fileWatcher))
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id"})
+
+	got, err := fm.ListFiles(ctx, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*model.File{model.EmptyFile("main.go")}, got)
+}
+
+func newTestFs(t *testing.T, content map[string]string) afero.Fs {
+	t.Helper()
+
+	// same as the project file system, gitignore patterns are read with relative paths
+	fs := afero.NewBasePathFs(afero.NewOsFs(), t.TempDir())
+	for path, c := range content {
+		writeTestFile(t, fs, path, c)
+	}
+
+	return fs
+}
+
+func writeTestFile(t *testing.T, fs afero.Fs, path, content string) {
+	t.Helper()
+
+	if err := fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func walkPaths(t *testing.T, cache files.TreeCache, ctx context.Context, fs afero.Fs, skip func(path string) bool) []string {
+	t.Helper()
+
+	var paths []string
+	err := cache.Walk(ctx, fs, func(path string, mode os.FileMode) error {
+		paths = append(paths, path)
+		if skip != nil && skip(path) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return paths
+}
@@ -0,0 +1,429 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/afero"
+)
+
+// TextEdit replaces a range of a file with new text. Lines are 1-based, characters are 0-based and counted in UTF-16 code units, like in LSP.
+type TextEdit struct {
+	StartLine      int    `json:"startLine"`
+	StartCharacter int    `json:"startCharacter"`
+	EndLine        int    `json:"endLine"`
+	EndCharacter   int    `json:"endCharacter"`
+	NewText        string `json:"newText"`
+}
+
+// FileChange is a change of a single file. Exactly one of Create, Delete, NewPath or Edits is expected.
+type FileChange struct {
+	Path string `json:"path"`
+	// Create creates an empty file, it fails if the file already exists
+	Create bool `json:"create,omitempty"`
+	// Delete deletes the file
+	Delete bool `json:"delete,omitempty"`
+	// NewPath renames the file, it fails if a file already exists at the new path
+	NewPath string `json:"newPath,omitempty"`
+	// Edits are applied to the content of the file, the ranges refer to the content before any of the edits
+	Edits []TextEdit `json:"edits,omitempty"`
+}
+
+// FileDiff describes how a file is changed by a workspace edit.
+type FileDiff struct {
+	// Path is the path of the file after the edit, for deleted files it is the path before the edit
+	Path string `json:"path"`
+	// OldPath is set if the file was renamed
+	OldPath string `json:"oldPath,omitempty"`
+	Created bool   `json:"created,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+	// Diff is a unified diff of the content
+	Diff string `json:"diff,omitempty"`
+}
+
+// stagedFile is the state of a file while a workspace edit is staged
+type stagedFile struct {
+	// origin is the path the file had before the edit, empty for created files
+	origin  string
+	content string
+	exists  bool
+	// mode are the permissions the file is written with, the ones of the origin or defaultFileMode for created files
+	mode os.FileMode
+}
+
+// defaultFileMode are the permissions of files created by workspace edits
+const defaultFileMode os.FileMode = 0o644
+
+// ApplyWorkspaceEdit applies all changes or none of them. Changes are applied in order, so a change can refer to a file created or renamed by a previous change.
+// With dryRun the files are not changed, the result describes what would be changed.
+func (fm *FileManagerImpl) ApplyWorkspaceEdit(ctx context.Context, fs afero.Fs, changes []FileChange, dryRun bool) ([]FileDiff, error) {
+	staged := make(map[string]*stagedFile)
+	// paths in the order they were touched, for a stable result
+	var touched []string
+
+	load := func(path string) (*stagedFile, error) {
+		if file, ok := staged[path]; ok {
+			return file, nil
+		}
+
+		if err := checkProtected(ctx, fs, path); err != nil {
+			return nil, err
+		}
+
+		file := &stagedFile{origin: path, mode: defaultFileMode}
+		content, mode, err := readFileWithMode(fs, path)
+		switch {
+		case err == nil:
+			file.content = string(content)
+			file.exists = true
+			file.mode = mode
+		case os.IsNotExist(err):
+		default:
+			return nil, fmt.Errorf("Failed to read file %s: %w", path, err)
+		}
+
+		staged[path] = file
+		touched = append(touched, path)
+		return file, nil
+	}
+
+	for _, change := range changes {
+		path := cleanPath(change.Path)
+
+		file, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case change.Create:
+			if file.exists {
+				return nil, NewFileAlreadyExistsError(path)
+			}
+			file.exists = true
+			file.content = ""
+			file.mode = defaultFileMode
+		case change.Delete:
+			if !file.exists {
+				return nil, NewFileNotFoundError(path)
+			}
+			file.exists = false
+		case change.NewPath != "":
+			if !file.exists {
+				return nil, NewFileNotFoundError(path)
+			}
+
+			newPath := cleanPath(change.NewPath)
+			target, err := load(newPath)
+			if err != nil {
+				return nil, err
+			}
+			if target.exists {
+				return nil, NewFileAlreadyExistsError(newPath)
+			}
+
+			*target = stagedFile{origin: file.origin, content: file.content, exists: true, mode: file.mode}
+			*file = stagedFile{exists: false}
+		default:
+			if !file.exists {
+				return nil, NewFileNotFoundError(path)
+			}
+
+			content, err := ApplyTextEdits(file.content, change.Edits)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to apply edits to %s: %w", path, err)
+			}
+			file.content = content
+		}
+	}
+
+	diffs, err := diffStaged(fs, staged, touched)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return diffs, nil
+	}
+
+	if err := fm.writeStaged(ctx, fs, staged, touched); err != nil {
+		return nil, err
+	}
+
+	return diffs, nil
+}
+
+// writeStaged writes the staged files, and restores the original files if any write fails.
+func (fm *FileManagerImpl) writeStaged(ctx context.Context, fs afero.Fs, staged map[string]*stagedFile, touched []string) error {
+	type original struct {
+		content []byte
+		exists  bool
+		mode    os.FileMode
+	}
+
+	originals := make(map[string]original, len(touched))
+	for _, path := range touched {
+		content, mode, err := readFileWithMode(fs, path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed to read file %s: %w", path, err)
+		}
+		originals[path] = original{content: content, exists: err == nil, mode: mode}
+	}
+
+	var written []string
+	restore := func() {
+		for _, path := range written {
+			o := originals[path]
+			var err error
+			if o.exists {
+				err = writeFileWithMode(fs, path, o.content, o.mode)
+			} else {
+				err = fs.Remove(path)
+			}
+			if err != nil && !os.IsNotExist(err) {
+				log.Error().Err(err).Str("path", path).Msg("Failed to restore file after failed workspace edit")
+			}
+		}
+	}
+
+	for _, path := range touched {
+		file := staged[path]
+		o := originals[path]
+
+		var err error
+		switch {
+		case file.exists:
+			if err = fs.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+				err = writeFileWithMode(fs, path, []byte(file.content), file.mode)
+			}
+		case o.exists:
+			err = fs.Remove(path)
+		default:
+			continue
+		}
+
+		written = append(written, path)
+
+		if err != nil {
+			restore()
+			return fmt.Errorf("Failed to write file %s: %w", path, err)
+		}
+	}
+
+	for _, path := range touched {
+		file := staged[path]
+		o := originals[path]
+
+		switch {
+		case file.exists && o.exists:
+			fm.updateTree(ctx, model.FileModified, path)
+		case file.exists:
+			fm.updateTree(ctx, model.FileCreated, path)
+		case o.exists:
+			fm.updateTree(ctx, model.FileDeleted, path)
+		}
+	}
+
+	return nil
+}
+
+// readFileWithMode returns the content of the file with its permissions.
+func readFileWithMode(fs afero.Fs, path string) ([]byte, os.FileMode, error) {
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return content, info.Mode().Perm(), nil
+}
+
+// writeFileWithMode writes the file with the permissions, also if it already exists with other ones.
+func writeFileWithMode(fs afero.Fs, path string, content []byte, mode os.FileMode) error {
+	if err := afero.WriteFile(fs, path, content, mode); err != nil {
+		return err
+	}
+
+	// the permissions of existing files are not changed by the write
+	return fs.Chmod(path, mode)
+}
+
+func diffStaged(fs afero.Fs, staged map[string]*stagedFile, touched []string) ([]FileDiff, error) {
+	originals := make(map[string]string)
+	for _, path := range touched {
+		content, err := afero.ReadFile(fs, path)
+		if err == nil {
+			originals[path] = string(content)
+		}
+	}
+
+	// files that still exist under another path are renamed, not deleted
+	moved := make(map[string]bool)
+	for _, path := range touched {
+		if file := staged[path]; file.exists && file.origin != "" && file.origin != path {
+			moved[file.origin] = true
+		}
+	}
+
+	diffs := []FileDiff{}
+	for _, path := range touched {
+		file := staged[path]
+		original, existed := originals[path]
+
+		switch {
+		case !file.exists && existed && !moved[path]:
+			diff, err := unifiedDiff(original, "", path, "/dev/null")
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, FileDiff{Path: path, Deleted: true, Diff: diff})
+		case file.exists && file.origin != "" && file.origin != path:
+			diff, err := unifiedDiff(originals[file.origin], file.content, file.origin, path)
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, FileDiff{Path: path, OldPath: file.origin, Diff: diff})
+		case file.exists && !existed:
+			diff, err := unifiedDiff("", file.content, "/dev/null", path)
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, FileDiff{Path: path, Created: true, Diff: diff})
+		case file.exists && original != file.content:
+			diff, err := unifiedDiff(original, file.content, path, path)
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, FileDiff{Path: path, Diff: diff})
+		}
+	}
+
+	return diffs, nil
+}
+
+func unifiedDiff(a, b, fromFile, toFile string) (string, error) {
+	if a == b {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// splitLines splits the content into lines that all end with a newline, which the diff output expects.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// ApplyTextEdits applies non-overlapping edits, all ranges refer to the original content.
+func ApplyTextEdits(content string, edits []TextEdit) (string, error) {
+	type span struct {
+		start, end int
+		text       string
+	}
+
+	lineStarts := lineOffsets(content)
+	spans := make([]span, 0, len(edits))
+	for _, edit := range edits {
+		start := offsetAt(content, lineStarts, edit.StartLine, edit.StartCharacter)
+		end := offsetAt(content, lineStarts, edit.EndLine, edit.EndCharacter)
+		if end < start {
+			return "", fmt.Errorf("invalid range %d:%d-%d:%d", edit.StartLine, edit.StartCharacter, edit.EndLine, edit.EndCharacter)
+		}
+		spans = append(spans, span{start: start, end: end, text: edit.NewText})
+	}
+
+	// edits at the same position are applied in the order they are given
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			return "", errors.New("edits overlap")
+		}
+		b.WriteString(content[last:s.start])
+		b.WriteString(s.text)
+		last = s.end
+	}
+	b.WriteString(content[last:])
+
+	return b.String(), nil
+}
+
+// lineOffsets returns the byte offsets of the starts of the lines
+func lineOffsets(content string) []int {
+	offsets := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// offsetAt converts a 1-based line and a 0-based UTF-16 character to a byte offset, positions past the end of a line or the content are clamped.
+func offsetAt(content string, lineStarts []int, line, character int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(lineStarts) {
+		return len(content)
+	}
+
+	lineStart, lineEnd := lineStarts[line-1], len(content)
+	if line < len(lineStarts) {
+		lineEnd = lineStarts[line] - 1
+	}
+
+	return lineStart + ByteOffset(content[lineStart:lineEnd], character)
+}
+
+// ByteOffset converts a 0-based UTF-16 character of the line, as used by language servers, to a byte offset in the line.
+// Characters past the end of the line are clamped.
+func ByteOffset(line string, character int) int {
+	offset := 0
+	for units := 0; offset < len(line) && units < character; {
+		r, size := utf8.DecodeRuneInString(line[offset:])
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+		offset += size
+	}
+
+	return offset
+}
+
+func cleanPath(path string) string {
+	return filepath.Clean(strings.TrimPrefix(filepath.ToSlash(path), "/"))
+}
@@ -0,0 +1,217 @@
+package files_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/gitignore"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileManager_ApplyWorkspaceEdit(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   map[string]string
+		changes   []files.FileChange
+		wantDiffs []files.FileDiff
+		wantFiles map[string]string
+		wantGone  []string
+	}{
+		{
+			name:    "text edits in multiple files",
+			content: map[string]string{"/a.go": "func foo() {}\nfoo()\n", "/b.go": "x := foo()\n"},
+			changes: []files.FileChange{
+				{Path: "a.go", Edits: []files.TextEdit{
+					{StartLine: 2, StartCharacter: 0, EndLine: 2, EndCharacter: 3, NewText: "bar"},
+					{StartLine: 1, StartCharacter: 5, EndLine: 1, EndCharacter: 8, NewText: "bar"},
+				}},
+				{Path: "b.go", Edits: []files.TextEdit{{StartLine: 1, StartCharacter: 5, EndLine: 1, EndCharacter: 8, NewText: "bar"}}},
+			},
+			wantDiffs: []files.FileDiff{
+				{Path: "a.go", Diff: "--- a.go\n+++ a.go\n@@ -1,2 +1,2 @@\n-func foo() {}\n-foo()\n+func bar() {}\n+bar()\n"},
+				{Path: "b.go", Diff: "--- b.go\n+++ b.go\n@@ -1 +1 @@\n-x := foo()\n+x := bar()\n"},
+			},
+			wantFiles: map[string]string{"/a.go": "func bar() {}\nbar()\n", "/b.go": "x := bar()\n"},
+		},
+		{
+			name:    "characters are counted in UTF-16 code units",
+			content: map[string]string{"/a.txt": "😀 héllo\n"},
+			changes: []files.FileChange{
+				{Path: "a.txt", Edits: []files.TextEdit{{StartLine: 1, StartCharacter: 3, EndLine: 1, EndCharacter: 8, NewText: "world"}}},
+			},
+			wantDiffs: []files.FileDiff{
+				{Path: "a.txt", Diff: "--- a.txt\n+++ a.txt\n@@ -1 +1 @@\n-😀 héllo\n+😀 world\n"},
+			},
+			wantFiles: map[string]string{"/a.txt": "😀 world\n"},
+		},
+		{
+			name:    "rename and edit the renamed file",
+			content: map[string]string{"/foo.py": "class Foo:\n    pass\n"},
+			changes: []files.FileChange{
+				{Path: "foo.py", NewPath: "pkg/bar.py"},
+				{Path: "pkg/bar.py", Edits: []files.TextEdit{{StartLine: 1, StartCharacter: 6, EndLine: 1, EndCharacter: 9, NewText: "Bar"}}},
+			},
+			wantDiffs: []files.FileDiff{
+				{Path: "pkg/bar.py", OldPath: "foo.py", Diff: "--- foo.py\n+++ pkg/bar.py\n@@ -1,2 +1,2 @@\n-class Foo:\n+class Bar:\n     pass\n"},
+			},
+			wantFiles: map[string]string{"/pkg/bar.py": "class Bar:\n    pass\n"},
+			wantGone:  []string{"/foo.py"},
+		},
+		{
+			name:    "create and delete",
+			content: map[string]string{"/old.txt": "old\n"},
+			changes: []files.FileChange{
+				{Path: "new.txt", Create: true},
+				{Path: "new.txt", Edits: []files.TextEdit{{StartLine: 1, StartCharacter: 0, EndLine: 1, EndCharacter: 0, NewText: "new\n"}}},
+				{Path: "old.txt", Delete: true},
+			},
+			wantDiffs: []files.FileDiff{
+				{Path: "new.txt", Created: true, Diff: "--- /dev/null\n+++ new.txt\n@@ -0,0 +1 @@\n+new\n"},
+				{Path: "old.txt", Deleted: true, Diff: "--- old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-old\n"},
+			},
+			wantFiles: map[string]string{"/new.txt": "new\n"},
+			wantGone:  []string{"/old.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+			t.Run("dry run", func(t *testing.T) {
+				fs := newTestFs(t, tt.content)
+
+				diffs, err := fm.ApplyWorkspaceEdit(context.Background(), fs, tt.changes, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tt.wantDiffs, diffs)
+				for path, content := range tt.content {
+					assertFileContent(t, fs, path, content)
+				}
+			})
+
+			t.Run("apply", func(t *testing.T) {
+				fs := newTestFs(t, tt.content)
+
+				diffs, err := fm.ApplyWorkspaceEdit(context.Background(), fs, tt.changes, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tt.wantDiffs, diffs)
+				for path, content := range tt.wantFiles {
+					assertFileContent(t, fs, path, content)
+				}
+				for _, path := range tt.wantGone {
+					exists, err := afero.Exists(fs, path)
+					if err != nil {
+						t.Fatal(err)
+					}
+					assert.False(t, exists, "file %s should not exist", path)
+				}
+			})
+		})
+	}
+}
+
+func TestFileManager_ApplyWorkspaceEdit_Errors(t *testing.T) {
+	content := map[string]string{"/a.txt": "a\n", "/b.txt": "b\n"}
+
+	tests := []struct {
+		name    string
+		changes []files.FileChange
+		check   func(t *testing.T, err error)
+	}{
+		{
+			name: "edit of a missing file",
+			changes: []files.FileChange{
+				{Path: "a.txt", Edits: []files.TextEdit{{StartLine: 1, EndLine: 1, EndCharacter: 1, NewText: "x"}}},
+				{Path: "missing.txt", Edits: []files.TextEdit{{StartLine: 1, NewText: "x"}}},
+			},
+			check: func(t *testing.T, err error) {
+				var notFound *files.FileNotFoundError
+				assert.True(t, errors.As(err, &notFound), "expected FileNotFoundError, got %v", err)
+			},
+		},
+		{
+			name: "rename onto an existing file",
+			changes: []files.FileChange{
+				{Path: "a.txt", Edits: []files.TextEdit{{StartLine: 1, EndLine: 1, EndCharacter: 1, NewText: "x"}}},
+				{Path: "a.txt", NewPath: "b.txt"},
+			},
+			check: func(t *testing.T, err error) {
+				var exists *files.FileAlreadyExistsError
+				assert.True(t, errors.As(err, &exists), "expected FileAlreadyExistsError, got %v", err)
+			},
+		},
+		{
+			name: "overlapping edits",
+			changes: []files.FileChange{
+				{Path: "a.txt", Edits: []files.TextEdit{
+					{StartLine: 1, StartCharacter: 0, EndLine: 2, EndCharacter: 0, NewText: "x"},
+					{StartLine: 1, StartCharacter: 1, EndLine: 1, EndCharacter: 1, NewText: "y"},
+				}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "edits overlap")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newTestFs(t, content)
+			fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+
+			_, err := fm.ApplyWorkspaceEdit(context.Background(), fs, tt.changes, false)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			tt.check(t, err)
+			for path, c := range content {
+				assertFileContent(t, fs, path, c)
+			}
+		})
+	}
+}
+
+func TestFileManager_ApplyWorkspaceEdit_FileMode(t *testing.T) {
+	fs := newTestFs(t, map[string]string{"/run.sh": "echo run\n", "/build.sh": "echo build\n", "/secret.txt": "secret\n"})
+	if err := fs.Chmod("/run.sh", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Chmod("/build.sh", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Chmod("/secret.txt", 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fm := files.NewFileManager(gitignore.NewMatcherFactory(), nil)
+	_, err := fm.ApplyWorkspaceEdit(context.Background(), fs, []files.FileChange{
+		{Path: "run.sh", Edits: []files.TextEdit{{StartLine: 1, StartCharacter: 5, EndLine: 1, EndCharacter: 8, NewText: "start"}}},
+		{Path: "run.sh", NewPath: "bin/run.sh"},
+		{Path: "secret.txt", Delete: true},
+		{Path: "build.sh", NewPath: "secret.txt"},
+		{Path: "new.txt", Create: true},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// renamed files keep the permissions of their source, also if they replace a deleted file
+	for path, want := range map[string]os.FileMode{"/bin/run.sh": 0o755, "/secret.txt": 0o755, "/new.txt": 0o644} {
+		info, err := fs.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, want, info.Mode().Perm(), path)
+	}
+}
@@ -85,13 +85,19 @@ func readIgnoreFile(fs afero.Fs, path []string, ignoreFile string) (ps []gitigno
 		return nil, err
 	}
 
-	for _, s := range strings.Split(string(data), eol) {
+	return ParsePatterns(strings.Split(string(data), eol), path), nil
+}
+
+// ParsePatterns parses lines in the gitignore format, skipping comments and empty lines. The domain is the directory the patterns are relative to.
+func ParsePatterns(lines []string, domain []string) []gitignore.Pattern {
+	var ps []gitignore.Pattern
+	for _, s := range lines {
 		if !strings.HasPrefix(s, commentPrefix) && len(strings.TrimSpace(s)) > 0 {
-			ps = append(ps, gitignore.ParsePattern(s, path))
+			ps = append(ps, gitignore.ParsePattern(s, domain))
 		}
 	}
 
-	return ps, nil
+	return ps
 }
 
 func readDir(fs afero.Fs, dir string) ([]os.FileInfo, error) {
@@ -38,6 +38,23 @@ func (m MatcherImpl) Match(path string, isDir bool) (bool, error) {
 	return m.matcher.Match(strings.Split(path, string(os.PathSeparator)), isDir), nil
 }
 
+// NewPatternMatcher creates a matcher from patterns in the gitignore format. Patterns are relative to the root.
+func NewPatternMatcher(patterns []string) Matcher {
+	return NewMatcher(gitignore.NewMatcher(ParsePatterns(patterns, nil)))
+}
+
+// NewFileMatcher creates a matcher from an ignore file in the root of fs and additional patterns. Both use the gitignore format.
+// A missing ignore file is not an error.
+func NewFileMatcher(fs afero.Fs, ignoreFile string, patterns []string) (Matcher, error) {
+	ps, err := readIgnoreFile(fs, nil, ignoreFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ignoreFile, err)
+	}
+
+	ps = append(ps, ParsePatterns(patterns, nil)...)
+	return NewMatcher(gitignore.NewMatcher(ps)), nil
+}
+
 type MatcherFactory interface {
 	NewMatcher(fs afero.Fs) (Matcher, error)
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type ApplyCodeActionRequest struct {
+	Path           string `json:"path"`
+	StartLine      int    `json:"startLine"`
+	StartCharacter int    `json:"startCharacter"`
+	// EndLine defaults to StartLine, together with EndCharacter
+	EndLine      int    `json:"endLine"`
+	EndCharacter int    `json:"endCharacter"`
+	Title        string `json:"title"`
+	DryRun       bool   `json:"dryRun"`
+}
+
+func (r *ApplyCodeActionRequest) Range() lsp.Range {
+	return documentRange(r.StartLine, r.StartCharacter, r.EndLine, r.EndCharacter)
+}
+
+func (r *ApplyCodeActionRequest) Validate() error {
+	if r.Path == "" {
+		return errors.New("path must be provided")
+	}
+
+	if r.Title == "" {
+		return errors.New("title must be provided")
+	}
+
+	return validateRange(r.Range())
+}
+
+type ApplyCodeActionHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h ApplyCodeActionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var request ApplyCodeActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("Failed parsing request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.ProjectManager.ApplyCodeAction(r.Context(), projectID, request.Path, request.Range(), request.Title, request.DryRun)
+	if err != nil {
+		var codeActionNotFoundError *lsp.CodeActionNotFoundError
+		if errors.As(err, &codeActionNotFoundError) {
+			http.Error(w, codeActionNotFoundError.Error(), http.StatusNotFound)
+			return
+		}
+
+		var codeActionDisabledError *lsp.CodeActionDisabledError
+		if errors.As(err, &codeActionDisabledError) {
+			http.Error(w, codeActionDisabledError.Error(), http.StatusBadRequest)
+			return
+		}
+
+		handleLanguageFeatureError(w, err, "Failed to apply code action")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyCodeActionHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		applyCodeAction func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range, title string, dryRun bool) (*project.WorkspaceEditResult, error)
+		wantStatusCode  int
+		wantBody        string
+	}{
+		{
+			name: "success",
+			body: `{"path": "main.go", "startLine": 5, "startCharacter": 1, "title": "Remove variable x"}`,
+			applyCodeAction: func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range, title string, dryRun bool) (*project.WorkspaceEditResult, error) {
+				position := lsp.Position{Line: 5, Character: 1}
+				if path != "main.go" || rng != (lsp.Range{Start: position, End: position}) || title != "Remove variable x" || dryRun {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &project.WorkspaceEditResult{Files: []files.FileDiff{{Path: "main.go", Diff: "-\tx := 1\n"}}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"files":[{"path":"main.go","diff":"-\tx := 1\n"}]}`,
+		},
+		{
+			name:           "missing title",
+			body:           `{"path": "main.go", "startLine": 5}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "title must be provided",
+		},
+		{
+			name:           "invalid range",
+			body:           `{"path": "main.go", "startLine": 5, "endLine": 3, "title": "Remove variable x"}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "end must not be before start",
+		},
+		{
+			name: "not found",
+			body: `{"path": "main.go", "startLine": 5, "title": "Inline call"}`,
+			applyCodeAction: func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range, title string, dryRun bool) (*project.WorkspaceEditResult, error) {
+				return nil, lsp.NewCodeActionNotFoundError(path, title)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       `Code action "Inline call" not found in main.go`,
+		},
+		{
+			name: "disabled",
+			body: `{"path": "main.go", "startLine": 5, "title": "Extract function"}`,
+			applyCodeAction: func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range, title string, dryRun bool) (*project.WorkspaceEditResult, error) {
+				return nil, lsp.NewCodeActionDisabledError(title, "no statements selected")
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "no statements selected",
+		},
+		{
+			name: "protected file",
+			body: `{"path": "main.go", "startLine": 5, "title": "Tidy"}`,
+			applyCodeAction: func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range, title string, dryRun bool) (*project.WorkspaceEditResult, error) {
+				return nil, files.NewPathProtectedError("go.sum")
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantBody:       "go.sum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{ApplyCodeActionFunc: tt.applyCodeAction}
+
+			router := handlers.NewRouter().WithApplyCodeActionHandler(handlers.ApplyCodeActionHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodPost, "/projects/123/code-actions/apply", strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+const (
+	defaultHierarchyDepth = 1
+	maxHierarchyDepth     = 5
+)
+
+type CallHierarchyHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h CallHierarchyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	path, position, err := getDocumentPosition(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid position: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	direction := lsp.CallHierarchyIncoming
+	if r.URL.Query().Has("direction") {
+		direction = lsp.CallHierarchyDirection(r.URL.Query().Get("direction"))
+		if !direction.Valid() {
+			http.Error(w, fmt.Sprintf("Invalid direction: %s, must be %s or %s", direction, lsp.CallHierarchyIncoming, lsp.CallHierarchyOutgoing), http.StatusBadRequest)
+			return
+		}
+	}
+
+	depth, err := getHierarchyDepth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.ProjectManager.GetCallHierarchy(r.Context(), projectID, path, position, direction, depth)
+	if err != nil {
+		handleLanguageFeatureError(w, err, "Failed to get call hierarchy")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
+
+func getHierarchyDepth(r *http.Request) (int, error) {
+	depth, ok, err := parseIntQueryParam(r.URL.Query(), "depth")
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		return defaultHierarchyDepth, nil
+	}
+
+	if depth < 1 || depth > maxHierarchyDepth {
+		return 0, fmt.Errorf("depth must be between 1 and %d", maxHierarchyDepth)
+	}
+
+	return depth, nil
+}
@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallHierarchyHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name             string
+		target           string
+		getCallHierarchy func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.CallHierarchyDirection, depth int) ([]lsp.HierarchyItem, error)
+		wantStatusCode   int
+		wantBody         string
+	}{
+		{
+			name:   "defaults",
+			target: "/projects/123/call-hierarchy?path=main.go&line=3&character=6",
+			getCallHierarchy: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.CallHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+				if path != "main.go" || position != (lsp.Position{Line: 3, Character: 6}) || direction != lsp.CallHierarchyIncoming || depth != 1 {
+					return nil, errors.New("unexpected arguments")
+				}
+				return []lsp.HierarchyItem{{Name: "foo", Kind: "Function", Location: lsp.Location{Path: "main.go"}}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"name":"foo","kind":"Function","location":{"path":"main.go"`,
+		},
+		{
+			name:   "outgoing",
+			target: "/projects/123/call-hierarchy?path=main.go&line=3&direction=outgoing&depth=3",
+			getCallHierarchy: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.CallHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+				if direction != lsp.CallHierarchyOutgoing || depth != 3 {
+					return nil, errors.New("unexpected arguments")
+				}
+				return []lsp.HierarchyItem{}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[]`,
+		},
+		{
+			name:           "invalid direction",
+			target:         "/projects/123/call-hierarchy?path=main.go&line=3&direction=up",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Invalid direction: up",
+		},
+		{
+			name:           "invalid depth",
+			target:         "/projects/123/call-hierarchy?path=main.go&line=3&depth=6",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "depth must be between 1 and 5",
+		},
+		{
+			name:   "not supported",
+			target: "/projects/123/call-hierarchy?path=main.go&line=3",
+			getCallHierarchy: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.CallHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+				return nil, lsp.NewFeatureNotSupportedError("call hierarchy")
+			},
+			wantStatusCode: http.StatusNotImplemented,
+			wantBody:       "Language server does not support call hierarchy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{GetCallHierarchyFunc: tt.getCallHierarchy}
+
+			router := handlers.NewRouter().WithCallHierarchyHandler(handlers.CallHierarchyHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
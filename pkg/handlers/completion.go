@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/project"
+)
+
+const (
+	defaultCompletionLimit = 50
+	maxCompletionLimit     = 500
+)
+
+type CompletionHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h CompletionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	path, position, err := getDocumentPosition(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid position: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	limit, ok, err := parseIntQueryParam(r.URL.Query(), "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !ok {
+		limit = defaultCompletionLimit
+	}
+
+	if limit < 1 || limit > maxCompletionLimit {
+		http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxCompletionLimit), http.StatusBadRequest)
+		return
+	}
+
+	completions, err := h.ProjectManager.Complete(r.Context(), projectID, path, position, limit)
+	if err != nil {
+		handleLanguageFeatureError(w, err, "Failed to get completions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(completions)
+}
@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		complete       func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, limit int) (*lsp.CompletionList, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:   "success",
+			target: "/projects/123/completion?path=main.go&line=3&character=6&limit=10",
+			complete: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, limit int) (*lsp.CompletionList, error) {
+				if path != "main.go" || position != (lsp.Position{Line: 3, Character: 6}) || limit != 10 {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &lsp.CompletionList{Items: []lsp.CompletionItem{{Label: "Println", Kind: "Function", Detail: "func(a ...any)"}}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"isIncomplete":false,"items":[{"label":"Println","kind":"Function","detail":"func(a ...any)"}]}`,
+		},
+		{
+			name:   "default limit",
+			target: "/projects/123/completion?path=main.go&line=3&character=6",
+			complete: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, limit int) (*lsp.CompletionList, error) {
+				if limit != 50 {
+					return nil, errors.New("unexpected limit")
+				}
+				return &lsp.CompletionList{Items: []lsp.CompletionItem{}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"isIncomplete":false,"items":[]}`,
+		},
+		{
+			name:           "invalid limit",
+			target:         "/projects/123/completion?path=main.go&line=3&limit=0",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "limit must be between 1 and 500",
+		},
+		{
+			name:           "missing line",
+			target:         "/projects/123/completion?path=main.go",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "line must be a positive number",
+		},
+		{
+			name:   "language server not found",
+			target: "/projects/123/completion?path=README.md&line=1",
+			complete: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, limit int) (*lsp.CompletionList, error) {
+				return nil, lsp.NewLanguageServerNotFoundError(projectId, "Markdown")
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "Language server not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{CompleteFunc: tt.complete}
+
+			router := handlers.NewRouter().WithCompletionHandler(handlers.CompletionHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
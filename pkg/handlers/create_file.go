@@ -2,11 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 
-	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/project"
 )
 
@@ -26,6 +24,12 @@ func (h CreateFileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := getWriteFileOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var request CreateFileRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -33,21 +37,9 @@ func (h CreateFileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, err := h.ProjectManager.CreateFile(r.Context(), projectID, request.Path, request.Content)
+	file, err := h.ProjectManager.CreateFile(r.Context(), projectID, request.Path, request.Content, opts...)
 	if err != nil {
-		var projectNotFoundError *project.ProjectNotFoundError
-		if errors.As(err, &projectNotFoundError) {
-			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
-			return
-		}
-
-		var fileAlreadyExistsError *files.FileAlreadyExistsError
-		if errors.As(err, &fileAlreadyExistsError) {
-			http.Error(w, fileAlreadyExistsError.Error(), http.StatusConflict)
-			return
-		}
-
-		http.Error(w, fmt.Sprintf("Failed to create file: %s", err), http.StatusInternalServerError)
+		handleFileError(w, err, "Failed to create file")
 		return
 	}
 
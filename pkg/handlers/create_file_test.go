@@ -19,14 +19,14 @@ import (
 func TestCreateFileHandler(t *testing.T) {
 	testCases := []struct {
 		name           string
-		createFileFunc func(ctx context.Context, projectId, path, content string) (*model.File, error)
+		createFileFunc func(ctx context.Context, projectId, path, content string, opts ...project.WriteFileOption) (*model.File, error)
 		requestBody    handlers.CreateFileRequest
 		wantStatus     int
 		wantFile       *model.File
 	}{
 		{
 			name: "Success",
-			createFileFunc: func(ctx context.Context, projectId, path, content string) (*model.File, error) {
+			createFileFunc: func(ctx context.Context, projectId, path, content string, opts ...project.WriteFileOption) (*model.File, error) {
 				return model.NewFile("/test/path", "test content"), nil
 			},
 			requestBody: handlers.CreateFileRequest{Path: "/test/path", Content: "test content"},
@@ -35,7 +35,7 @@ func TestCreateFileHandler(t *testing.T) {
 		},
 		{
 			name: "ProjectNotFound",
-			createFileFunc: func(ctx context.Context, projectId, path, content string) (*model.File, error) {
+			createFileFunc: func(ctx context.Context, projectId, path, content string, opts ...project.WriteFileOption) (*model.File, error) {
 				return nil, project.NewProjectNotFoundError(projectId)
 			},
 			requestBody: handlers.CreateFileRequest{Path: "/test/path", Content: "test content"},
@@ -43,7 +43,7 @@ func TestCreateFileHandler(t *testing.T) {
 		},
 		{
 			name: "FileAlreadyExists",
-			createFileFunc: func(ctx context.Context, projectId, path, content string) (*model.File, error) {
+			createFileFunc: func(ctx context.Context, projectId, path, content string, opts ...project.WriteFileOption) (*model.File, error) {
 				return nil, files.NewFileAlreadyExistsError(path)
 			},
 			requestBody: handlers.CreateFileRequest{Path: "/test/path", Content: "test content"},
@@ -51,7 +51,7 @@ func TestCreateFileHandler(t *testing.T) {
 		},
 		{
 			name: "InternalServerError",
-			createFileFunc: func(ctx context.Context, projectId, path, content string) (*model.File, error) {
+			createFileFunc: func(ctx context.Context, projectId, path, content string, opts ...project.WriteFileOption) (*model.File, error) {
 				return nil, errors.New("Test error")
 			},
 			requestBody: handlers.CreateFileRequest{Path: "/test/path", Content: "test content"},
@@ -92,6 +92,50 @@ func TestCreateFileHandler(t *testing.T) {
 	}
 }
 
+func TestCreateFileHandler_Format(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantFormat bool
+	}{
+		{name: "format", query: "?format=true", wantStatus: http.StatusCreated, wantFormat: true},
+		{name: "no format", query: "?format=false", wantStatus: http.StatusCreated},
+		{name: "default", query: "", wantStatus: http.StatusCreated},
+		{name: "invalid", query: "?format=maybe", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var options project.WriteFileOptions
+			mockManager := &mocks.MockProjectManager{
+				CreateFileFunc: func(ctx context.Context, projectId, path, content string, opts ...project.WriteFileOption) (*model.File, error) {
+					for _, opt := range opts {
+						opt(&options)
+					}
+					return model.NewFile(path, content), nil
+				},
+			}
+
+			router := handlers.NewRouter().WithCreateFileHandler(handlers.CreateFileHandler{ProjectManager: mockManager}).Build()
+
+			body, _ := json.Marshal(handlers.CreateFileRequest{Path: "main.go", Content: "package main"})
+			request, _ := http.NewRequest(http.MethodPost, "/projects/123/files"+tt.query, bytes.NewBuffer(body))
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			if response.Code != tt.wantStatus {
+				t.Errorf("want status %d, got %d", tt.wantStatus, response.Code)
+			}
+
+			if options.Format != tt.wantFormat {
+				t.Errorf("want format %t, got %t", tt.wantFormat, options.Format)
+			}
+		})
+	}
+}
+
 func TestCreateFileHandler_InvalidPayload(t *testing.T) {
 	mockManager := &mocks.MockProjectManager{}
 
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/hide-org/hide/pkg/lsp"
 	"github.com/hide-org/hide/pkg/project"
 )
 
@@ -42,6 +44,12 @@ func (h CreateProjectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	result := <-h.Manager.CreateProject(r.Context(), request)
 
 	if result.IsFailure() {
+		var languageNotSupportedError *lsp.LanguageNotSupportedError
+		if errors.As(result.Error, &languageNotSupportedError) {
+			http.Error(w, fmt.Sprintf("Failed to create project: %s", languageNotSupportedError), http.StatusBadRequest)
+			return
+		}
+
 		http.Error(w, fmt.Sprintf("Failed to create project: %s", result.Error), http.StatusInternalServerError)
 		return
 	}
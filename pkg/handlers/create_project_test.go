@@ -12,6 +12,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
 	"github.com/hide-org/hide/pkg/model"
 	"github.com/hide-org/hide/pkg/project"
 	"github.com/hide-org/hide/pkg/project/mocks"
@@ -58,6 +59,22 @@ func TestCreateProjectHandler(t *testing.T) {
 			wantStatusCode: http.StatusInternalServerError,
 			wantError:      "Failed to create project: Test error",
 		},
+		{
+			name: "language not supported",
+			createProjectFunc: func(ctx context.Context, req project.CreateProjectRequest) <-chan result.Result[model.Project] {
+				ch := make(chan result.Result[model.Project], 1)
+				ch <- result.Failure[model.Project](lsp.NewLanguageNotSupportedError("COBOL"))
+				return ch
+			},
+			request: project.CreateProjectRequest{
+				Repository: project.Repository{
+					Url: "https://github.com/example/repo.git",
+				},
+				Languages: []lsp.LanguageId{"COBOL"},
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantError:      "Failed to create project: Language COBOL is not supported",
+		},
 		{
 			name: "validation error",
 			request: project.CreateProjectRequest{
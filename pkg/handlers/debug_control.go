@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/dap"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type SetBreakpointsRequest struct {
+	// Path is relative to the project root, the breakpoints replace the ones of the file
+	Path        string                 `json:"path"`
+	Breakpoints []dap.SourceBreakpoint `json:"breakpoints"`
+}
+
+func (r *SetBreakpointsRequest) Validate() error {
+	return validateBreakpoints(r.Path, r.Breakpoints)
+}
+
+type SetBreakpointsHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h SetBreakpointsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, sessionID, ok := getDebugSessionID(w, r)
+	if !ok {
+		return
+	}
+
+	var request SetBreakpointsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("Failed parsing request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	breakpoints, err := h.ProjectManager.SetBreakpoints(r.Context(), projectID, sessionID, request.Path, request.Breakpoints)
+	if err != nil {
+		handleDebugError(w, err, "Failed to set breakpoints")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(breakpoints)
+}
+
+type StepDebugSessionRequest struct {
+	// Action is one of continue, next, stepIn, stepOut and pause, continue is the default
+	Action dap.StepAction `json:"action"`
+	// ThreadId is the thread to step, the one that stopped if it's 0
+	ThreadId int `json:"threadId,omitempty"`
+	// Timeout in seconds to wait for the program to stop again or terminate
+	Timeout int `json:"timeout,omitempty"`
+}
+
+func (r *StepDebugSessionRequest) Validate() error {
+	if r.Action == "" {
+		r.Action = dap.StepContinue
+	}
+
+	if !r.Action.Valid() {
+		return fmt.Errorf("unknown action %s", r.Action)
+	}
+
+	if r.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+
+	return nil
+}
+
+type StepDebugSessionHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h StepDebugSessionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, sessionID, ok := getDebugSessionID(w, r)
+	if !ok {
+		return
+	}
+
+	// the body is optional, without it the program continues
+	var request StepDebugSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, fmt.Sprintf("Failed parsing request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.ProjectManager.StepDebugSession(r.Context(), projectID, sessionID, request.Action, request.ThreadId, debugTimeout(request.Timeout))
+	if err != nil {
+		handleDebugError(w, err, fmt.Sprintf("Failed to %s", request.Action))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(session)
+}
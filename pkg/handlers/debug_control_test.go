@@ -0,0 +1,164 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/dap"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/model"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetBreakpointsHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setBreakpoints func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, path string, breakpoints []dap.SourceBreakpoint) ([]dap.Breakpoint, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name: "ok",
+			body: `{"path": "main.go", "breakpoints": [{"line": 3, "condition": "i > 2"}, {"line": 40}]}`,
+			setBreakpoints: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, path string, breakpoints []dap.SourceBreakpoint) ([]dap.Breakpoint, error) {
+				if projectId != "123" || sessionId != "abc" || path != "main.go" || len(breakpoints) != 2 || breakpoints[0].Condition != "i > 2" {
+					return nil, errors.New("unexpected arguments")
+				}
+				return []dap.Breakpoint{{Line: 3, Verified: true}, {Line: 40, Message: "no code"}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"line":3,"verified":true},{"line":40,"verified":false,"message":"no code"}]`,
+		},
+		{
+			name: "remove breakpoints",
+			body: `{"path": "main.go"}`,
+			setBreakpoints: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, path string, breakpoints []dap.SourceBreakpoint) ([]dap.Breakpoint, error) {
+				if len(breakpoints) != 0 {
+					return nil, errors.New("unexpected breakpoints")
+				}
+				return []dap.Breakpoint{}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[]`,
+		},
+		{
+			name:           "missing path",
+			body:           `{"breakpoints": [{"line": 3}]}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "path of breakpoints must be provided",
+		},
+		{
+			name:           "absolute path",
+			body:           `{"path": "/etc/passwd", "breakpoints": [{"line": 3}]}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "path /etc/passwd starts with '/'",
+		},
+		{
+			name: "session terminated",
+			body: `{"path": "main.go", "breakpoints": [{"line": 3}]}`,
+			setBreakpoints: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, path string, breakpoints []dap.SourceBreakpoint) ([]dap.Breakpoint, error) {
+				return nil, dap.NewSessionStateError(sessionId, dap.SessionTerminated, "set breakpoints")
+			},
+			wantStatusCode: http.StatusConflict,
+			wantBody:       "Cannot set breakpoints, debug session abc is terminated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{SetBreakpointsFunc: tt.setBreakpoints}
+
+			router := handlers.NewRouter().WithSetBreakpointsHandler(handlers.SetBreakpointsHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodPut, "/projects/123/debug/sessions/abc/breakpoints", strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestStepDebugSessionHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		step           func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, action dap.StepAction, threadId int, timeout time.Duration) (*dap.Session, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name: "ok",
+			body: `{"action": "stepIn", "threadId": 2, "timeout": 10}`,
+			step: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, action dap.StepAction, threadId int, timeout time.Duration) (*dap.Session, error) {
+				if projectId != "123" || sessionId != "abc" || action != dap.StepIn || threadId != 2 || timeout != 10*time.Second {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &dap.Session{Id: "abc", Status: dap.SessionStopped, Stopped: &dap.StoppedInfo{Reason: "step", ThreadId: 2}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `"stopped":{"reason":"step","threadId":2}`,
+		},
+		{
+			name: "continue without body",
+			step: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, action dap.StepAction, threadId int, timeout time.Duration) (*dap.Session, error) {
+				if action != dap.StepContinue || threadId != 0 || timeout != dap.DefaultTimeout {
+					return nil, errors.New("unexpected arguments")
+				}
+				exitCode := 0
+				return &dap.Session{Id: "abc", Status: dap.SessionTerminated, ExitCode: &exitCode, Output: "done\n"}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `"status":"terminated","exitCode":0,"output":"done\n"`,
+		},
+		{
+			name:           "unknown action",
+			body:           `{"action": "jump"}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "unknown action jump",
+		},
+		{
+			name: "session running",
+			body: `{"action": "next"}`,
+			step: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, action dap.StepAction, threadId int, timeout time.Duration) (*dap.Session, error) {
+				return nil, dap.NewSessionStateError(sessionId, dap.SessionRunning, string(action))
+			},
+			wantStatusCode: http.StatusConflict,
+			wantBody:       "Cannot next, debug session abc is running",
+		},
+		{
+			name: "internal error",
+			body: `{"action": "next"}`,
+			step: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, action dap.StepAction, threadId int, timeout time.Duration) (*dap.Session, error) {
+				return nil, errors.New("connection closed")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to next: connection closed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{StepDebugSessionFunc: tt.step}
+
+			router := handlers.NewRouter().WithStepDebugSessionHandler(handlers.StepDebugSessionHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodPost, "/projects/123/debug/sessions/abc/step", strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type DebugThreadsHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h DebugThreadsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, sessionID, ok := getDebugSessionID(w, r)
+	if !ok {
+		return
+	}
+
+	threads, err := h.ProjectManager.GetDebugThreads(r.Context(), projectID, sessionID)
+	if err != nil {
+		handleDebugError(w, err, "Failed to get threads")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(threads)
+}
+
+type DebugStackTraceHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h DebugStackTraceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, sessionID, ok := getDebugSessionID(w, r)
+	if !ok {
+		return
+	}
+
+	// both are optional, by default all frames of the thread that stopped are returned
+	threadID, _, err := parseIntQueryParam(r.URL.Query(), "threadId")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	levels, _, err := parseIntQueryParam(r.URL.Query(), "levels")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if levels < 0 {
+		http.Error(w, "levels must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	frames, err := h.ProjectManager.GetDebugStackTrace(r.Context(), projectID, sessionID, threadID, levels)
+	if err != nil {
+		handleDebugError(w, err, "Failed to get stack trace")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(frames)
+}
+
+type DebugScopesHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h DebugScopesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, sessionID, ok := getDebugSessionID(w, r)
+	if !ok {
+		return
+	}
+
+	frameID, err := requiredIntQueryParam(r, "frameId")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	scopes, err := h.ProjectManager.GetDebugScopes(r.Context(), projectID, sessionID, frameID)
+	if err != nil {
+		handleDebugError(w, err, "Failed to get scopes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(scopes)
+}
+
+type DebugVariablesHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h DebugVariablesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, sessionID, ok := getDebugSessionID(w, r)
+	if !ok {
+		return
+	}
+
+	// the reference is of a scope or of a structured variable
+	ref, err := requiredIntQueryParam(r, "ref")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if ref < 1 {
+		http.Error(w, "ref must be a positive number", http.StatusBadRequest)
+		return
+	}
+
+	variables, err := h.ProjectManager.GetDebugVariables(r.Context(), projectID, sessionID, ref)
+	if err != nil {
+		handleDebugError(w, err, "Failed to get variables")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(variables)
+}
+
+// requiredIntQueryParam reads a reference of the debug adapter from the query, e.g. the id of a frame.
+func requiredIntQueryParam(r *http.Request, name string) (int, error) {
+	value, ok, err := parseIntQueryParam(r.URL.Query(), name)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		return 0, fmt.Errorf("%s not specified", name)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,131 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/dap"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/model"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugInspectHandlers_ServeHTTP(t *testing.T) {
+	mockManager := &project_mocks.MockProjectManager{
+		GetDebugThreadsFunc: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) ([]dap.Thread, error) {
+			return []dap.Thread{{Id: 1, Name: "main"}}, nil
+		},
+		GetDebugStackTraceFunc: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, threadId int, levels int) ([]dap.StackFrame, error) {
+			if sessionId == "running" {
+				return nil, dap.NewSessionStateError(sessionId, dap.SessionRunning, "get stack trace")
+			}
+			if threadId != 0 && threadId != 2 || levels != 0 && levels != 1 {
+				return nil, errors.New("unexpected arguments")
+			}
+			return []dap.StackFrame{{Id: 1000, Name: "main.main", Path: "main.go", Line: 3}}, nil
+		},
+		GetDebugScopesFunc: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, frameId int) ([]dap.Scope, error) {
+			if frameId != 1000 {
+				return nil, &dap.RequestError{Command: "scopes", Message: "unknown frame"}
+			}
+			return []dap.Scope{{Name: "Locals", VariablesReference: 1001}}, nil
+		},
+		GetDebugVariablesFunc: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, variablesReference int) ([]dap.Variable, error) {
+			if variablesReference != 1001 {
+				return nil, errors.New("unexpected reference")
+			}
+			return []dap.Variable{{Name: "i", Value: "3", Type: "int"}}, nil
+		},
+	}
+
+	router := handlers.NewRouter().
+		WithDebugThreadsHandler(handlers.DebugThreadsHandler{ProjectManager: mockManager}).
+		WithDebugStackTraceHandler(handlers.DebugStackTraceHandler{ProjectManager: mockManager}).
+		WithDebugScopesHandler(handlers.DebugScopesHandler{ProjectManager: mockManager}).
+		WithDebugVariablesHandler(handlers.DebugVariablesHandler{ProjectManager: mockManager}).
+		Build()
+
+	tests := []struct {
+		name           string
+		target         string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:           "threads",
+			target:         "/projects/123/debug/sessions/abc/threads",
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"id":1,"name":"main"}]`,
+		},
+		{
+			name:           "stack trace",
+			target:         "/projects/123/debug/sessions/abc/stack",
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"id":1000,"name":"main.main","path":"main.go","line":3,"column":0}]`,
+		},
+		{
+			name:           "stack trace of thread",
+			target:         "/projects/123/debug/sessions/abc/stack?threadId=2&levels=1",
+			wantStatusCode: http.StatusOK,
+			wantBody:       `"name":"main.main"`,
+		},
+		{
+			name:           "stack trace with invalid levels",
+			target:         "/projects/123/debug/sessions/abc/stack?levels=-1",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "levels must not be negative",
+		},
+		{
+			name:           "stack trace of running session",
+			target:         "/projects/123/debug/sessions/running/stack",
+			wantStatusCode: http.StatusConflict,
+			wantBody:       "Cannot get stack trace, debug session running is running",
+		},
+		{
+			name:           "scopes",
+			target:         "/projects/123/debug/sessions/abc/scopes?frameId=1000",
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"name":"Locals","variablesReference":1001,"expensive":false}]`,
+		},
+		{
+			name:           "scopes without frame",
+			target:         "/projects/123/debug/sessions/abc/scopes",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "frameId not specified",
+		},
+		{
+			name:           "scopes of unknown frame",
+			target:         "/projects/123/debug/sessions/abc/scopes?frameId=1",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Debug adapter failed scopes request: unknown frame",
+		},
+		{
+			name:           "variables",
+			target:         "/projects/123/debug/sessions/abc/variables?ref=1001",
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"name":"i","value":"3","type":"int","variablesReference":0}]`,
+		},
+		{
+			name:           "variables with invalid reference",
+			target:         "/projects/123/debug/sessions/abc/variables?ref=0",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "ref must be a positive number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hide-org/hide/pkg/dap"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type LaunchDebugSessionRequest struct {
+	Language lsp.LanguageId `json:"language"`
+	// Program is the file or package to debug, relative to the project root
+	Program       string                            `json:"program"`
+	Args          []string                          `json:"args,omitempty"`
+	Test          bool                              `json:"test"`
+	Cwd           string                            `json:"cwd,omitempty"`
+	Env           map[string]string                 `json:"env,omitempty"`
+	StopOnEntry   bool                              `json:"stopOnEntry"`
+	Breakpoints   map[string][]dap.SourceBreakpoint `json:"breakpoints,omitempty"`
+	Configuration map[string]any                    `json:"configuration,omitempty"`
+	// Timeout in seconds to wait for the program to stop or terminate, the session keeps running after it
+	Timeout int `json:"timeout,omitempty"`
+}
+
+func (r *LaunchDebugSessionRequest) Options() dap.LaunchOptions {
+	return dap.LaunchOptions{
+		Language:      r.Language,
+		Program:       r.Program,
+		Args:          r.Args,
+		Test:          r.Test,
+		Cwd:           r.Cwd,
+		Env:           r.Env,
+		StopOnEntry:   r.StopOnEntry,
+		Breakpoints:   r.Breakpoints,
+		Configuration: r.Configuration,
+	}
+}
+
+func (r *LaunchDebugSessionRequest) Validate() error {
+	if r.Language == "" {
+		return errors.New("language must be provided")
+	}
+
+	if r.Program == "" {
+		return errors.New("program must be provided")
+	}
+
+	for _, path := range []string{r.Program, r.Cwd} {
+		if err := validateProjectPath(path); err != nil {
+			return err
+		}
+	}
+
+	for path, breakpoints := range r.Breakpoints {
+		if err := validateBreakpoints(path, breakpoints); err != nil {
+			return err
+		}
+	}
+
+	if r.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+
+	return nil
+}
+
+type LaunchDebugSessionHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h LaunchDebugSessionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var request LaunchDebugSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("Failed parsing request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.ProjectManager.LaunchDebugSession(r.Context(), projectID, request.Options(), debugTimeout(request.Timeout))
+	if err != nil {
+		handleDebugError(w, err, "Failed to launch debug session")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+}
+
+type ListDebugSessionsHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h ListDebugSessionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	sessions, err := h.ProjectManager.ListDebugSessions(r.Context(), projectID)
+	if err != nil {
+		handleDebugError(w, err, "Failed to list debug sessions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sessions)
+}
+
+type GetDebugSessionHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h GetDebugSessionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, sessionID, ok := getDebugSessionID(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := h.ProjectManager.GetDebugSession(r.Context(), projectID, sessionID)
+	if err != nil {
+		handleDebugError(w, err, "Failed to get debug session")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(session)
+}
+
+type TerminateDebugSessionHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h TerminateDebugSessionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, sessionID, ok := getDebugSessionID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ProjectManager.TerminateDebugSession(r.Context(), projectID, sessionID); err != nil {
+		handleDebugError(w, err, "Failed to terminate debug session")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getDebugSessionID reads the project and session IDs from the path, it writes the error response if one of them is missing.
+func getDebugSessionID(w http.ResponseWriter, r *http.Request) (string, dap.SessionId, bool) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return "", "", false
+	}
+
+	sessionID, err := getPathValue(r, "session")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid session ID: %s", err), http.StatusBadRequest)
+		return "", "", false
+	}
+
+	return projectID, sessionID, true
+}
+
+// debugTimeout converts the timeout in seconds of a request, without one the default is used.
+func debugTimeout(seconds int) time.Duration {
+	if seconds == 0 {
+		return dap.DefaultTimeout
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// validateProjectPath rejects paths of request bodies that are outside of the project, like the PathValidator middleware does for paths of URLs.
+func validateProjectPath(path string) error {
+	if strings.HasPrefix(path, "/") {
+		return fmt.Errorf("path %s starts with '/'", path)
+	}
+
+	if cleaned := filepath.Clean(path); cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("path %s is outside of the project", path)
+	}
+
+	return nil
+}
+
+func validateBreakpoints(path string, breakpoints []dap.SourceBreakpoint) error {
+	if path == "" {
+		return errors.New("path of breakpoints must be provided")
+	}
+
+	if err := validateProjectPath(path); err != nil {
+		return err
+	}
+
+	for _, breakpoint := range breakpoints {
+		if breakpoint.Line < 1 {
+			return fmt.Errorf("line of breakpoint in %s must be a positive number", path)
+		}
+	}
+
+	return nil
+}
+
+func handleDebugError(w http.ResponseWriter, err error, message string) {
+	var projectNotFoundError *project.ProjectNotFoundError
+	if errors.As(err, &projectNotFoundError) {
+		http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
+		return
+	}
+
+	var sessionNotFoundError *dap.SessionNotFoundError
+	if errors.As(err, &sessionNotFoundError) {
+		http.Error(w, sessionNotFoundError.Error(), http.StatusNotFound)
+		return
+	}
+
+	var adapterNotFoundError *dap.AdapterNotFoundError
+	if errors.As(err, &adapterNotFoundError) {
+		http.Error(w, adapterNotFoundError.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var sessionStateError *dap.SessionStateError
+	if errors.As(err, &sessionStateError) {
+		http.Error(w, sessionStateError.Error(), http.StatusConflict)
+		return
+	}
+
+	// the adapter refused the request, e.g. a variables reference that is no longer valid
+	var requestError *dap.RequestError
+	if errors.As(err, &requestError) {
+		http.Error(w, requestError.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Error(w, fmt.Sprintf("%s: %s", message, err), http.StatusInternalServerError)
+}
@@ -0,0 +1,221 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/dap"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLaunchDebugSessionHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		launch         func(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name: "ok",
+			body: `{"language": "Python", "program": "main.py", "breakpoints": {"main.py": [{"line": 3}]}, "timeout": 5}`,
+			launch: func(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error) {
+				if projectId != "123" || opts.Language != lsp.Python || opts.Program != "main.py" || opts.Breakpoints["main.py"][0].Line != 3 || timeout != 5*time.Second {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &dap.Session{Id: "abc", Language: lsp.Python, Program: "main.py", Status: dap.SessionStopped, Stopped: &dap.StoppedInfo{Reason: "breakpoint", ThreadId: 1}}, nil
+			},
+			wantStatusCode: http.StatusCreated,
+			wantBody:       `{"id":"abc","language":"Python","program":"main.py","status":"stopped","stopped":{"reason":"breakpoint","threadId":1},"output":""}`,
+		},
+		{
+			name: "default timeout",
+			body: `{"language": "Go", "program": ".", "test": true}`,
+			launch: func(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error) {
+				if !opts.Test || timeout != dap.DefaultTimeout {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &dap.Session{Id: "abc", Status: dap.SessionRunning}, nil
+			},
+			wantStatusCode: http.StatusCreated,
+			wantBody:       `"status":"running"`,
+		},
+		{
+			name:           "invalid body",
+			body:           `{"language": "Go"`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Failed parsing request body",
+		},
+		{
+			name:           "missing program",
+			body:           `{"language": "Go"}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "program must be provided",
+		},
+		{
+			name:           "program outside of project",
+			body:           `{"language": "Go", "program": "../other/main.go"}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "path ../other/main.go is outside of the project",
+		},
+		{
+			name:           "invalid breakpoint",
+			body:           `{"language": "Go", "program": "main.go", "breakpoints": {"main.go": [{"line": 0}]}}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "line of breakpoint in main.go must be a positive number",
+		},
+		{
+			name: "project not found",
+			body: `{"language": "Go", "program": "main.go"}`,
+			launch: func(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error) {
+				return nil, project.NewProjectNotFoundError(projectId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 123 not found",
+		},
+		{
+			name: "no adapter",
+			body: `{"language": "COBOL", "program": "main.cbl"}`,
+			launch: func(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error) {
+				return nil, dap.NewAdapterNotFoundError(opts.Language)
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "No debug adapter for language COBOL",
+		},
+		{
+			name: "launch failed",
+			body: `{"language": "Python", "program": "missing.py"}`,
+			launch: func(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error) {
+				return nil, &dap.RequestError{Command: "launch", Message: "missing.py does not exist"}
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Debug adapter failed launch request: missing.py does not exist",
+		},
+		{
+			name: "internal error",
+			body: `{"language": "Go", "program": "main.go"}`,
+			launch: func(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error) {
+				return nil, errors.New("dlv not found")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to launch debug session: dlv not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{LaunchDebugSessionFunc: tt.launch}
+
+			router := handlers.NewRouter().WithLaunchDebugSessionHandler(handlers.LaunchDebugSessionHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodPost, "/projects/123/debug/sessions", strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestDebugSessionHandlers_ServeHTTP(t *testing.T) {
+	mockManager := &project_mocks.MockProjectManager{
+		ListDebugSessionsFunc: func(ctx context.Context, projectId model.ProjectId) ([]dap.Session, error) {
+			if projectId != "123" {
+				return nil, project.NewProjectNotFoundError(projectId)
+			}
+			return []dap.Session{{Id: "abc", Status: dap.SessionRunning}}, nil
+		},
+		GetDebugSessionFunc: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) (*dap.Session, error) {
+			if sessionId != "abc" {
+				return nil, dap.NewSessionNotFoundError(projectId, sessionId)
+			}
+			return &dap.Session{Id: "abc", Status: dap.SessionRunning}, nil
+		},
+		TerminateDebugSessionFunc: func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) error {
+			if sessionId != "abc" {
+				return dap.NewSessionNotFoundError(projectId, sessionId)
+			}
+			return nil
+		},
+	}
+
+	router := handlers.NewRouter().
+		WithListDebugSessionsHandler(handlers.ListDebugSessionsHandler{ProjectManager: mockManager}).
+		WithGetDebugSessionHandler(handlers.GetDebugSessionHandler{ProjectManager: mockManager}).
+		WithTerminateDebugSessionHandler(handlers.TerminateDebugSessionHandler{ProjectManager: mockManager}).
+		Build()
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:           "list",
+			method:         http.MethodGet,
+			target:         "/projects/123/debug/sessions",
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"id":"abc","language":"","program":"","status":"running","output":""}]`,
+		},
+		{
+			name:           "list of missing project",
+			method:         http.MethodGet,
+			target:         "/projects/456/debug/sessions",
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 456 not found",
+		},
+		{
+			name:           "get",
+			method:         http.MethodGet,
+			target:         "/projects/123/debug/sessions/abc",
+			wantStatusCode: http.StatusOK,
+			wantBody:       `"id":"abc"`,
+		},
+		{
+			name:           "get missing session",
+			method:         http.MethodGet,
+			target:         "/projects/123/debug/sessions/def",
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "Debug session def not found in project 123",
+		},
+		{
+			name:           "terminate",
+			method:         http.MethodDelete,
+			target:         "/projects/123/debug/sessions/abc",
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name:           "terminate missing session",
+			method:         http.MethodDelete,
+			target:         "/projects/123/debug/sessions/def",
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "Debug session def not found in project 123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -1,10 +1,8 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 
-	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/project"
 )
 
@@ -26,19 +24,7 @@ func (h DeleteFileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.ProjectManager.DeleteFile(r.Context(), projectID, filePath); err != nil {
-		var projectNotFoundError *project.ProjectNotFoundError
-		if errors.As(err, &projectNotFoundError) {
-			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
-			return
-		}
-
-		var fileNotFoundError *files.FileNotFoundError
-		if errors.As(err, &fileNotFoundError) {
-			http.Error(w, fileNotFoundError.Error(), http.StatusNotFound)
-			return
-		}
-
-		http.Error(w, "Failed to delete file", http.StatusInternalServerError)
+		handleFileError(w, err, "Failed to delete file")
 		return
 	}
 
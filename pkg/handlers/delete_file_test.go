@@ -3,6 +3,7 @@ package handlers_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -59,6 +60,24 @@ func TestDeleteFileHandler_ServeHTTP(t *testing.T) {
 			wantStatusCode: http.StatusNotFound,
 			wantBody:       "file test.txt not found",
 		},
+		{
+			name:   "path outside of project",
+			target: "/projects/123/files/link/passwd",
+			mockDeleteFileFunc: func(ctx context.Context, projectId, path string) error {
+				return fmt.Errorf("Failed to check if file %s exists: %w", path, files.NewPathOutsideProjectError(path))
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantBody:       "path link/passwd is outside of the project",
+		},
+		{
+			name:   "protected path",
+			target: "/projects/123/files/package-lock.json",
+			mockDeleteFileFunc: func(ctx context.Context, projectId, path string) error {
+				return files.NewPathProtectedError(path)
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantBody:       "path package-lock.json is protected",
+		},
 		{
 			name:   "internal server error",
 			target: "/projects/123/files/test.txt",
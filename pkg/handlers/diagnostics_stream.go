@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/project"
+	"github.com/rs/zerolog/log"
+)
+
+type DiagnosticsStreamHandler struct {
+	ProjectManager project.Manager
+}
+
+// ServeHTTP streams the diagnostics of the project as server-sent events until the client disconnects. The current diagnostics
+// come first, then an event for every file whose diagnostics change.
+func (h DiagnosticsStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := getDiagnosticsFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	diagnostics, err := h.ProjectManager.SubscribeDiagnostics(r.Context(), projectID, filter)
+	if err != nil {
+		var projectNotFoundError *project.ProjectNotFoundError
+		if errors.As(err, &projectNotFoundError) {
+			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("Failed to subscribe to diagnostics: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case file, ok := <-diagnostics:
+			if !ok {
+				return
+			}
+
+			if err := sse.send("diagnostics", file); err != nil {
+				log.Debug().Err(err).Str("projectId", projectID).Msg("Failed to send diagnostics")
+				return
+			}
+		}
+	}
+}
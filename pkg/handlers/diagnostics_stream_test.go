@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestDiagnosticsStreamHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		subscribe      func(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter) (<-chan lsp.FileDiagnostics, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:  "success",
+			query: "?severity=error",
+			subscribe: func(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter) (<-chan lsp.FileDiagnostics, error) {
+				if len(filter.Severities) != 1 || filter.Severities[0] != protocol.DiagnosticSeverityError {
+					return nil, errors.New("unexpected filter")
+				}
+
+				events := make(chan lsp.FileDiagnostics, 2)
+				events <- lsp.FileDiagnostics{Path: "main.go", Counts: lsp.DiagnosticsCount{Errors: 1}, Diagnostics: []protocol.Diagnostic{{Message: "undefined: foo"}}}
+				events <- lsp.FileDiagnostics{Path: "main.go", Diagnostics: []protocol.Diagnostic{}}
+				close(events)
+				return events, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody: "event: diagnostics\ndata: {\"path\":\"main.go\",\"counts\":{\"errors\":1,\"warnings\":0,\"information\":0,\"hints\":0},\"diagnostics\":[{\"range\":{\"start\":{\"line\":0,\"character\":0},\"end\":{\"line\":0,\"character\":0}},\"message\":\"undefined: foo\"}]}\n\n" +
+				"event: diagnostics\ndata: {\"path\":\"main.go\",\"counts\":{\"errors\":0,\"warnings\":0,\"information\":0,\"hints\":0},\"diagnostics\":[]}\n\n",
+		},
+		{
+			name:           "invalid severity",
+			query:          "?severity=fatal",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Invalid severity: fatal",
+		},
+		{
+			name: "project not found",
+			subscribe: func(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter) (<-chan lsp.FileDiagnostics, error) {
+				return nil, project.NewProjectNotFoundError(projectId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 123 not found",
+		},
+		{
+			name: "subscription fails",
+			subscribe: func(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter) (<-chan lsp.FileDiagnostics, error) {
+				return nil, errors.New("boom")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to subscribe to diagnostics: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{
+				SubscribeDiagnosticsFunc: tt.subscribe,
+			}
+
+			router := handlers.NewRouter().
+				WithProjectDiagnosticsHandler(handlers.ProjectDiagnosticsHandler{ProjectManager: mockManager}).
+				WithDiagnosticsStreamHandler(handlers.DiagnosticsStreamHandler{ProjectManager: mockManager}).
+				Build()
+
+			request := httptest.NewRequest(http.MethodGet, "/projects/123/diagnostics/stream"+tt.query, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+var archiveContentTypes = map[files.ArchiveFormat]string{
+	files.ArchiveTarGz: "application/gzip",
+	files.ArchiveZip:   "application/zip",
+}
+
+type DownloadArchiveHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h DownloadArchiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	format, err := getArchiveFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	path := r.URL.Query().Get("path")
+
+	// the archive is built before anything is written, so that errors are not sent as a truncated archive
+	tmp, err := os.CreateTemp("", "hide-download-*")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to download archive: %s", err), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := h.ProjectManager.DownloadArchive(r.Context(), projectID, tmp, path, format, getListFilesOptions(r)...); err != nil {
+		handleFileError(w, err, "Failed to download archive")
+		return
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to download archive: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	name := fmt.Sprintf("%s.%s", projectID, format)
+	w.Header().Set("Content-Type", archiveContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeContent(w, r, name, time.Time{}, tmp)
+}
+
+func getArchiveFormat(r *http.Request) (files.ArchiveFormat, error) {
+	format := files.ArchiveFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		return files.ArchiveTarGz, nil
+	}
+
+	if !format.Valid() {
+		return "", fmt.Errorf("Invalid archive format: %s, must be %s or %s", format, files.ArchiveTarGz, files.ArchiveZip)
+	}
+
+	return format, nil
+}
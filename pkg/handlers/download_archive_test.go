@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadArchiveHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name            string
+		target          string
+		downloadArchive func(ctx context.Context, projectId model.ProjectId, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error
+		wantStatusCode  int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:   "default format",
+			target: "/projects/123/archive?path=src",
+			downloadArchive: func(ctx context.Context, projectId model.ProjectId, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error {
+				_, err := io.WriteString(w, string(format)+":"+path)
+				return err
+			},
+			wantStatusCode:  http.StatusOK,
+			wantContentType: "application/gzip",
+			wantBody:        "tar.gz:src",
+		},
+		{
+			name:   "zip",
+			target: "/projects/123/archive?format=zip",
+			downloadArchive: func(ctx context.Context, projectId model.ProjectId, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error {
+				_, err := io.WriteString(w, string(format))
+				return err
+			},
+			wantStatusCode:  http.StatusOK,
+			wantContentType: "application/zip",
+			wantBody:        "zip",
+		},
+		{
+			name:           "invalid format",
+			target:         "/projects/123/archive?format=rar",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Invalid archive format: rar",
+		},
+		{
+			name:   "project not found",
+			target: "/projects/123/archive",
+			downloadArchive: func(ctx context.Context, projectId model.ProjectId, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error {
+				return project.NewProjectNotFoundError(projectId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 123 not found",
+		},
+		{
+			name:   "path not found",
+			target: "/projects/123/archive?path=missing",
+			downloadArchive: func(ctx context.Context, projectId model.ProjectId, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error {
+				return files.NewFileNotFoundError(path)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "file missing not found",
+		},
+		{
+			name:   "internal error",
+			target: "/projects/123/archive",
+			downloadArchive: func(ctx context.Context, projectId model.ProjectId, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error {
+				return errors.New("disk failure")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to download archive: disk failure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{DownloadArchiveFunc: tt.downloadArchive}
+
+			handler := handlers.DownloadArchiveHandler{ProjectManager: mockManager}
+			router := handlers.NewRouter().WithDownloadArchiveHandler(handler).Build()
+
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+			if tt.wantContentType != "" {
+				assert.Equal(t, tt.wantContentType, response.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hide-org/hide/pkg/project"
+	"github.com/rs/zerolog/log"
+)
+
+type FileEventsHandler struct {
+	ProjectManager project.Manager
+}
+
+// ServeHTTP streams file events of the project as server-sent events until the client disconnects.
+func (h FileEventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.ProjectManager.SubscribeFileEvents(r.Context(), projectID)
+	if err != nil {
+		var projectNotFoundError *project.ProjectNotFoundError
+		if errors.As(err, &projectNotFoundError) {
+			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("Failed to subscribe to file events: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case batch, ok := <-events:
+			if !ok {
+				return
+			}
+
+			for _, event := range batch {
+				if err := sse.send("change", event); err != nil {
+					log.Debug().Err(err).Str("projectId", projectID).Msg("Failed to send file event")
+					return
+				}
+			}
+		}
+	}
+}
+
+type sseWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// newSSEWriter writes the event stream headers. Write deadline is disabled because the stream is long-lived.
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, error) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, fmt.Errorf("Failed to disable write deadline: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		return nil, fmt.Errorf("Streaming not supported: %w", err)
+	}
+
+	return &sseWriter{w: w, rc: rc}, nil
+}
+
+func (s *sseWriter) send(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
@@ -0,0 +1,73 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileEventsHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		subscribe      func(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name: "success",
+			subscribe: func(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error) {
+				events := make(chan []model.FileEvent, 2)
+				events <- []model.FileEvent{{Type: model.FileCreated, Path: "main.go"}}
+				events <- []model.FileEvent{{Type: model.FileRenamed, Path: "new.go", OldPath: "old.go"}}
+				close(events)
+				return events, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody: "event: change\ndata: {\"type\":\"create\",\"path\":\"main.go\"}\n\n" +
+				"event: change\ndata: {\"type\":\"rename\",\"path\":\"new.go\",\"oldPath\":\"old.go\"}\n\n",
+		},
+		{
+			name: "project not found",
+			subscribe: func(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error) {
+				return nil, project.NewProjectNotFoundError(projectId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 123 not found",
+		},
+		{
+			name: "subscription fails",
+			subscribe: func(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error) {
+				return nil, errors.New("not watched")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to subscribe to file events: not watched",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{
+				SubscribeFileEventsFunc: tt.subscribe,
+			}
+
+			handler := handlers.FileEventsHandler{ProjectManager: mockManager}
+			router := handlers.NewRouter().WithFileEventsHandler(handler).Build()
+
+			request := httptest.NewRequest(http.MethodGet, "/projects/123/events", nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
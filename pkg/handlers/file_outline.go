@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type FileOutlineHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h FileOutlineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	filePath, err := GetFilePath(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid file path: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// a file named outline is read, the outline route shadows the read file route for it
+	if _, err := h.ProjectManager.ReadFile(r.Context(), projectID, filePath+outlineSuffix, project.ReadFileWithoutDiagnostics()); err == nil {
+		r = mux.SetURLVars(r, map[string]string{"id": projectID, "path": filePath + outlineSuffix})
+		ReadFileHandler{ProjectManager: h.ProjectManager}.ServeHTTP(w, r)
+		return
+	}
+
+	symbols, err := h.ProjectManager.GetOutline(r.Context(), projectID, filePath)
+	if err != nil {
+		handleLanguageFeatureError(w, err, "Failed to get outline")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(symbols)
+}
@@ -0,0 +1,117 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileOutlineHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		getOutline     func(ctx context.Context, projectId model.ProjectId, path string) ([]lsp.DocumentSymbol, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:   "success",
+			target: "/projects/123/files/src/server.go/outline",
+			getOutline: func(ctx context.Context, projectId model.ProjectId, path string) ([]lsp.DocumentSymbol, error) {
+				if path != "src/server.go" {
+					return nil, errors.New("unexpected path")
+				}
+				return []lsp.DocumentSymbol{{Name: "Server", Kind: "Struct", Range: lsp.Range{Start: lsp.Position{Line: 3}, End: lsp.Position{Line: 5, Character: 1}}}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"name":"Server","kind":"Struct","range":{"start":{"line":3,"character":0},"end":{"line":5,"character":1}}}]`,
+		},
+		{
+			name:   "file not found",
+			target: "/projects/123/files/missing.go/outline",
+			getOutline: func(ctx context.Context, projectId model.ProjectId, path string) ([]lsp.DocumentSymbol, error) {
+				return nil, files.NewFileNotFoundError(path)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "file missing.go not found",
+		},
+		{
+			name:   "internal error",
+			target: "/projects/123/files/main.go/outline",
+			getOutline: func(ctx context.Context, projectId model.ProjectId, path string) ([]lsp.DocumentSymbol, error) {
+				return nil, errors.New("server crashed")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to get outline: server crashed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{
+				GetOutlineFunc: tt.getOutline,
+				ReadFileFunc: func(ctx context.Context, projectId, path string, opts ...project.ReadFileOption) (*model.File, error) {
+					return nil, files.NewFileNotFoundError(path)
+				},
+			}
+
+			router := handlers.NewRouter().WithFileOutlineHandler(handlers.FileOutlineHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestFileOutlineHandler_Routes(t *testing.T) {
+	var outlinePath, readPath string
+	mockManager := &project_mocks.MockProjectManager{
+		GetOutlineFunc: func(ctx context.Context, projectId model.ProjectId, path string) ([]lsp.DocumentSymbol, error) {
+			outlinePath = path
+			return []lsp.DocumentSymbol{}, nil
+		},
+		ReadFileFunc: func(ctx context.Context, projectId, path string, opts ...project.ReadFileOption) (*model.File, error) {
+			if path != "docs/outline" && path != "src/main.go" {
+				return nil, files.NewFileNotFoundError(path)
+			}
+			readPath = path
+			return model.NewFile(path, ""), nil
+		},
+	}
+
+	// the outline route is registered first, the read file route matches any path
+	router := handlers.NewRouter().
+		WithFileOutlineHandler(handlers.FileOutlineHandler{ProjectManager: mockManager}).
+		WithReadFileHandler(handlers.ReadFileHandler{ProjectManager: mockManager}).
+		Build()
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/projects/123/files/src/main.go/outline", nil))
+	assert.Equal(t, "src/main.go", outlinePath)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/projects/123/files/src/main.go", nil))
+	assert.Equal(t, "src/main.go", readPath)
+
+	// a file named outline is read like any other file
+	outlinePath = ""
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/projects/123/files/docs/outline", nil))
+	assert.Equal(t, "docs/outline", readPath)
+	assert.Empty(t, outlinePath)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"path":"docs/outline"`)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type FindDefinitionHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h FindDefinitionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	path, position, err := getDocumentPosition(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid position: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	kind := lsp.DefinitionKindDefinition
+	if r.URL.Query().Has("kind") {
+		kind = lsp.DefinitionKind(r.URL.Query().Get("kind"))
+		if !kind.Valid() {
+			http.Error(w, fmt.Sprintf("Invalid kind: %s, must be %s, %s or %s", kind, lsp.DefinitionKindDefinition, lsp.DefinitionKindType, lsp.DefinitionKindImplementation), http.StatusBadRequest)
+			return
+		}
+	}
+
+	locations, err := h.ProjectManager.FindDefinition(r.Context(), projectID, path, position, kind)
+	if err != nil {
+		handleLanguageFeatureError(w, err, "Failed to find definition")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(locations)
+}
+
+// handleLanguageFeatureError maps errors of requests to language servers about a file to a response
+func handleLanguageFeatureError(w http.ResponseWriter, err error, message string) {
+	if status, fileErr := matchFileError(err); fileErr != nil {
+		http.Error(w, fileErr.Error(), status)
+		return
+	}
+
+	var languageServerNotFoundError *lsp.LanguageServerNotFoundError
+	if errors.As(err, &languageServerNotFoundError) {
+		http.Error(w, languageServerNotFoundError.Error(), http.StatusNotFound)
+		return
+	}
+
+	var codeActionNotPreviewableError *lsp.CodeActionNotPreviewableError
+	if errors.As(err, &codeActionNotPreviewableError) {
+		http.Error(w, codeActionNotPreviewableError.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var featureNotSupportedError *lsp.FeatureNotSupportedError
+	if errors.As(err, &featureNotSupportedError) {
+		http.Error(w, featureNotSupportedError.Error(), http.StatusNotImplemented)
+		return
+	}
+
+	http.Error(w, fmt.Sprintf("%s: %s", message, err), http.StatusInternalServerError)
+}
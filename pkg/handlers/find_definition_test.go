@@ -0,0 +1,148 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDefinitionHandler_ServeHTTP(t *testing.T) {
+	location := lsp.Location{Path: "lib.go", Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 5}, End: lsp.Position{Line: 3, Character: 8}}, Snippet: []model.Line{{Number: 3, Content: "func Foo() {}"}}}
+
+	tests := []struct {
+		name           string
+		target         string
+		findDefinition func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:   "definition",
+			target: "/projects/123/definition?path=main.go&line=10&character=4",
+			findDefinition: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error) {
+				if path != "main.go" || position != (lsp.Position{Line: 10, Character: 4}) || kind != lsp.DefinitionKindDefinition {
+					return nil, errors.New("unexpected arguments")
+				}
+				return []lsp.Location{location}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"path":"lib.go","range":{"start":{"line":3,"character":5},"end":{"line":3,"character":8}},"snippet":[{"number":3,"content":"func Foo() {}"}]}]`,
+		},
+		{
+			name:   "type definition",
+			target: "/projects/123/definition?path=main.go&line=10&kind=type",
+			findDefinition: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error) {
+				if kind != lsp.DefinitionKindType {
+					return nil, errors.New("unexpected kind")
+				}
+				return []lsp.Location{}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       "[]",
+		},
+		{
+			name:           "invalid kind",
+			target:         "/projects/123/definition?path=main.go&line=10&kind=usages",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Invalid kind: usages",
+		},
+		{
+			name:           "missing path",
+			target:         "/projects/123/definition?line=10",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "path not specified",
+		},
+		{
+			name:           "invalid line",
+			target:         "/projects/123/definition?path=main.go&line=0",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "line must be a positive number",
+		},
+		{
+			name:   "file not found",
+			target: "/projects/123/definition?path=missing.go&line=1",
+			findDefinition: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error) {
+				return nil, files.NewFileNotFoundError(path)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "file missing.go not found",
+		},
+		{
+			name:   "project not found",
+			target: "/projects/123/definition?path=main.go&line=1",
+			findDefinition: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error) {
+				return nil, project.NewProjectNotFoundError(projectId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 123 not found",
+		},
+		{
+			name:   "language server not found",
+			target: "/projects/123/definition?path=main.py&line=1",
+			findDefinition: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error) {
+				return nil, lsp.NewLanguageServerNotFoundError(projectId, lsp.Python)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "Language server not found for project 123 and language Python",
+		},
+		{
+			name:   "internal error",
+			target: "/projects/123/definition?path=main.go&line=1",
+			findDefinition: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error) {
+				return nil, errors.New("server crashed")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to find definition: server crashed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{FindDefinitionFunc: tt.findDefinition}
+
+			handler := handlers.FindDefinitionHandler{ProjectManager: mockManager}
+			router := handlers.NewRouter().WithFindDefinitionHandler(handler).Build()
+
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestFindReferencesHandler_ServeHTTP(t *testing.T) {
+	var gotIncludeDeclaration bool
+	mockManager := &project_mocks.MockProjectManager{
+		FindReferencesFunc: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, includeDeclaration bool) ([]lsp.Location, error) {
+			gotIncludeDeclaration = includeDeclaration
+			return []lsp.Location{}, nil
+		},
+	}
+
+	router := handlers.NewRouter().WithFindReferencesHandler(handlers.FindReferencesHandler{ProjectManager: mockManager}).Build()
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/projects/123/references?path=main.go&line=1&character=2&includeDeclaration=false", nil))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.False(t, gotIncludeDeclaration)
+
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/projects/123/references?path=main.go&line=1&includeDeclaration=maybe", nil))
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
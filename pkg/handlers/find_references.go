@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type FindReferencesHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h FindReferencesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	path, position, err := getDocumentPosition(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid position: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	includeDeclaration := true
+	if r.URL.Query().Has("includeDeclaration") {
+		if includeDeclaration, err = strconv.ParseBool(r.URL.Query().Get("includeDeclaration")); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid includeDeclaration: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	locations, err := h.ProjectManager.FindReferences(r.Context(), projectID, path, position, includeDeclaration)
+	if err != nil {
+		handleLanguageFeatureError(w, err, "Failed to find references")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(locations)
+}
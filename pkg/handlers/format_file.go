@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type FormatFileRequest struct {
+	// StartLine and EndLine limit the formatting to the lines between them, including both
+	StartLine       int  `json:"startLine"`
+	EndLine         int  `json:"endLine"`
+	OrganizeImports bool `json:"organizeImports"`
+	DryRun          bool `json:"dryRun"`
+}
+
+func (r *FormatFileRequest) Options() lsp.FormatOptions {
+	opts := lsp.FormatOptions{OrganizeImports: r.OrganizeImports}
+	if r.StartLine != 0 {
+		opts.Range = &lsp.Range{Start: lsp.Position{Line: r.StartLine}, End: lsp.Position{Line: r.EndLine + 1}}
+	}
+
+	return opts
+}
+
+func (r *FormatFileRequest) Validate() error {
+	if r.StartLine == 0 && r.EndLine == 0 {
+		return nil
+	}
+
+	if r.StartLine < 1 || r.EndLine < 1 {
+		return errors.New("startLine and endLine must be positive numbers")
+	}
+
+	if r.EndLine < r.StartLine {
+		return errors.New("endLine must not be before startLine")
+	}
+
+	return nil
+}
+
+type FormatFileHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h FormatFileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	filePath, err := GetFilePath(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid file path: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// the body is optional, without it the whole file is formatted
+	var request FormatFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, fmt.Sprintf("Failed parsing request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.ProjectManager.FormatFile(r.Context(), projectID, filePath, request.Options(), request.DryRun)
+	if err != nil {
+		handleLanguageFeatureError(w, err, "Failed to format file")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
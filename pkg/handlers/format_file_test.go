@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatFileHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		body           string
+		formatFile     func(ctx context.Context, projectId model.ProjectId, path string, opts lsp.FormatOptions, dryRun bool) (*project.WorkspaceEditResult, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:   "whole file without body",
+			target: "/projects/123/files/cmd/main.go/format",
+			formatFile: func(ctx context.Context, projectId model.ProjectId, path string, opts lsp.FormatOptions, dryRun bool) (*project.WorkspaceEditResult, error) {
+				if path != "cmd/main.go" || opts.Range != nil || opts.OrganizeImports || dryRun {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &project.WorkspaceEditResult{Files: []files.FileDiff{{Path: "cmd/main.go", Diff: "-x:=1\n+x := 1\n"}}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"files":[{"path":"cmd/main.go","diff":"-x:=1\n+x := 1\n"}]}`,
+		},
+		{
+			name:   "lines with organized imports",
+			target: "/projects/123/files/main.go/format",
+			body:   `{"startLine": 3, "endLine": 5, "organizeImports": true, "dryRun": true}`,
+			formatFile: func(ctx context.Context, projectId model.ProjectId, path string, opts lsp.FormatOptions, dryRun bool) (*project.WorkspaceEditResult, error) {
+				want := lsp.Range{Start: lsp.Position{Line: 3}, End: lsp.Position{Line: 6}}
+				if opts.Range == nil || *opts.Range != want || !opts.OrganizeImports || !dryRun {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &project.WorkspaceEditResult{Files: []files.FileDiff{}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"files":[]}`,
+		},
+		{
+			name:           "invalid body",
+			target:         "/projects/123/files/main.go/format",
+			body:           `{"startLine": `,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Failed parsing request body",
+		},
+		{
+			name:           "end before start",
+			target:         "/projects/123/files/main.go/format",
+			body:           `{"startLine": 5, "endLine": 3}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "endLine must not be before startLine",
+		},
+		{
+			name:   "language server not found",
+			target: "/projects/123/files/main.go/format",
+			formatFile: func(ctx context.Context, projectId model.ProjectId, path string, opts lsp.FormatOptions, dryRun bool) (*project.WorkspaceEditResult, error) {
+				return nil, lsp.NewLanguageServerNotFoundError(projectId, lsp.Go)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "Language server not found",
+		},
+		{
+			name:   "formatting not supported",
+			target: "/projects/123/files/main.go/format",
+			formatFile: func(ctx context.Context, projectId model.ProjectId, path string, opts lsp.FormatOptions, dryRun bool) (*project.WorkspaceEditResult, error) {
+				return nil, lsp.NewFeatureNotSupportedError("formatting")
+			},
+			wantStatusCode: http.StatusNotImplemented,
+			wantBody:       "Language server does not support formatting",
+		},
+		{
+			name:   "internal error",
+			target: "/projects/123/files/main.go/format",
+			formatFile: func(ctx context.Context, projectId model.ProjectId, path string, opts lsp.FormatOptions, dryRun bool) (*project.WorkspaceEditResult, error) {
+				return nil, errors.New("boom")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to format file: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{FormatFileFunc: tt.formatFile}
+
+			// the file routes are registered too, so that the format route is checked against them
+			router := handlers.NewRouter().
+				WithFormatFileHandler(handlers.FormatFileHandler{ProjectManager: mockManager}).
+				WithUpdateFileHandler(handlers.UpdateFileHandler{ProjectManager: mockManager}).
+				WithCreateFileHandler(handlers.CreateFileHandler{ProjectManager: mockManager}).
+				Build()
+
+			request := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoverHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		hover          func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position) (*lsp.HoverInfo, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:   "success",
+			target: "/projects/123/hover?path=main.go&line=3&character=6",
+			hover: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position) (*lsp.HoverInfo, error) {
+				if path != "main.go" || position != (lsp.Position{Line: 3, Character: 6}) {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &lsp.HoverInfo{Contents: "func Foo()", Signature: &lsp.SignatureInfo{Label: "Foo()"}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"contents":"func Foo()","signature":{"label":"Foo()"}}`,
+		},
+		{
+			name:           "missing line",
+			target:         "/projects/123/hover?path=main.go",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "line must be a positive number",
+		},
+		{
+			name:           "negative character",
+			target:         "/projects/123/hover?path=main.go&line=1&character=-1",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "character must not be negative",
+		},
+		{
+			name:   "language server not found",
+			target: "/projects/123/hover?path=README.md&line=1",
+			hover: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position) (*lsp.HoverInfo, error) {
+				return nil, lsp.NewLanguageServerNotFoundError(projectId, "Markdown")
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "Language server not found",
+		},
+		{
+			name:   "internal error",
+			target: "/projects/123/hover?path=main.go&line=1",
+			hover: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position) (*lsp.HoverInfo, error) {
+				return nil, errors.New("server crashed")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to get hover: server crashed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{HoverFunc: tt.hover}
+
+			router := handlers.NewRouter().WithHoverHandler(handlers.HoverHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
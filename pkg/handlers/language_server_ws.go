@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+	"github.com/rs/zerolog/log"
+	jsonrpc2websocket "github.com/sourcegraph/jsonrpc2/websocket"
+)
+
+// LanguageServerWebSocketHandler passes raw JSON-RPC messages of a WebSocket client through to a running language server of the project.
+// All requests are passed through, see lsp.Service.ServeClient for the ones that are answered by Hide or serialized with it.
+type LanguageServerWebSocketHandler struct {
+	ProjectManager project.Manager
+	Upgrader       websocket.Upgrader
+}
+
+func (h LanguageServerWebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	language, err := getPathValue(r, "language")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid language: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	// errors can only be answered with a status before the connection is upgraded
+	servers, err := h.ProjectManager.GetLanguageServers(r.Context(), projectID)
+	if err != nil {
+		var projectNotFoundError *project.ProjectNotFoundError
+		if errors.As(err, &projectNotFoundError) {
+			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("Failed to get language servers: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	server, ok := findServer(servers, language)
+	if !ok {
+		http.Error(w, lsp.NewLanguageServerNotFoundError(projectID, language).Error(), http.StatusNotFound)
+		return
+	}
+
+	if server.Status != lsp.ServerStatusRunning {
+		http.Error(w, fmt.Sprintf("Language server for %s is %s", language, server.Status), http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := h.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already answered the request
+		log.Error().Err(err).Str("projectId", projectID).Msg("Failed to upgrade connection to WebSocket")
+		return
+	}
+	defer conn.Close()
+
+	if err := h.ProjectManager.ConnectLanguageServer(r.Context(), projectID, language, jsonrpc2websocket.NewObjectStream(conn)); err != nil {
+		log.Error().Err(err).Str("projectId", projectID).Str("languageId", language).Msg("Failed to pass client through to language server")
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+	}
+}
+
+func findServer(servers []lsp.ServerHealth, languageId lsp.LanguageId) (lsp.ServerHealth, bool) {
+	for _, server := range servers {
+		if server.LanguageId == languageId {
+			return server, true
+		}
+	}
+
+	return lsp.ServerHealth{}, false
+}
@@ -0,0 +1,125 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageServerWebSocketHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name               string
+		getLanguageServers func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error)
+		wantStatusCode     int
+		wantBody           string
+	}{
+		{
+			name: "project not found",
+			getLanguageServers: func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error) {
+				return nil, project.NewProjectNotFoundError(projectId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 123 not found",
+		},
+		{
+			name: "server not found",
+			getLanguageServers: func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error) {
+				return []lsp.ServerHealth{{LanguageId: lsp.Python, Status: lsp.ServerStatusRunning}}, nil
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "Language server not found for project 123 and language Go",
+		},
+		{
+			name: "server not running",
+			getLanguageServers: func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error) {
+				return []lsp.ServerHealth{{LanguageId: lsp.Go, Status: lsp.ServerStatusRestarting}}, nil
+			},
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantBody:       "Language server for Go is restarting",
+		},
+		{
+			name: "internal error",
+			getLanguageServers: func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error) {
+				return nil, errors.New("boom")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to get language servers: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{GetLanguageServersFunc: tt.getLanguageServers}
+
+			router := handlers.NewRouter().WithLanguageServerWebSocketHandler(handlers.LanguageServerWebSocketHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodGet, "/projects/123/lsp/Go/ws", nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestLanguageServerWebSocketHandler_PassesMessagesThrough(t *testing.T) {
+	mockManager := &project_mocks.MockProjectManager{
+		GetLanguageServersFunc: func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error) {
+			return []lsp.ServerHealth{{LanguageId: lsp.Go, Status: lsp.ServerStatusRunning}}, nil
+		},
+		ConnectLanguageServerFunc: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId, stream jsonrpc2.ObjectStream) error {
+			// answers a single request with its method
+			var request jsonrpc2.Request
+			if err := stream.ReadObject(&request); err != nil {
+				return err
+			}
+
+			response := jsonrpc2.Response{ID: request.ID}
+			if err := response.SetResult(request.Method); err != nil {
+				return err
+			}
+
+			return stream.WriteObject(&response)
+		},
+	}
+
+	router := handlers.NewRouter().WithLanguageServerWebSocketHandler(handlers.LanguageServerWebSocketHandler{ProjectManager: mockManager}).Build()
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/projects/123/lsp/Go/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "textDocument/hover"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var response struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := conn.ReadJSON(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, response.ID)
+	assert.JSONEq(t, `"textDocument/hover"`, string(response.Result))
+}
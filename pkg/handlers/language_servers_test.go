@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageServersHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name               string
+		getLanguageServers func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error)
+		wantStatusCode     int
+		wantBody           string
+	}{
+		{
+			name: "ok",
+			getLanguageServers: func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error) {
+				if projectId != "123" {
+					return nil, errors.New("unexpected project")
+				}
+				return []lsp.ServerHealth{{LanguageId: "Go", Command: "gopls", Status: lsp.ServerStatusRestarting, Restarts: 2, LastError: "exit status 2", Stderr: []string{"panic: oops"}}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"languageId":"Go","command":"gopls","status":"restarting","uptimeSeconds":0,"restarts":2,"lastError":"exit status 2","stderr":["panic: oops"]}]`,
+		},
+		{
+			name: "project not found",
+			getLanguageServers: func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error) {
+				return nil, project.NewProjectNotFoundError(projectId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 123 not found",
+		},
+		{
+			name: "internal error",
+			getLanguageServers: func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error) {
+				return nil, errors.New("boom")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to get language servers: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{GetLanguageServersFunc: tt.getLanguageServers}
+
+			router := handlers.NewRouter().WithLanguageServersHandler(handlers.LanguageServersHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodGet, "/projects/123/lsp", nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type ListCodeActionsHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h ListCodeActionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	path, rng, err := getDocumentRange(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid range: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	actions, err := h.ProjectManager.GetCodeActions(r.Context(), projectID, path, rng)
+	if err != nil {
+		handleLanguageFeatureError(w, err, "Failed to get code actions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(actions)
+}
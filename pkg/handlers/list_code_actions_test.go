@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListCodeActionsHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		getCodeActions func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range) ([]lsp.CodeAction, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:   "position",
+			target: "/projects/123/code-actions?path=main.go&startLine=5&startCharacter=1",
+			getCodeActions: func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range) ([]lsp.CodeAction, error) {
+				position := lsp.Position{Line: 5, Character: 1}
+				if path != "main.go" || rng != (lsp.Range{Start: position, End: position}) {
+					return nil, errors.New("unexpected arguments")
+				}
+				return []lsp.CodeAction{{Title: "Remove variable x", Kind: "quickfix", Diagnostics: []string{"declared and not used: x"}}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[{"title":"Remove variable x","kind":"quickfix","diagnostics":["declared and not used: x"]}]`,
+		},
+		{
+			name:   "range",
+			target: "/projects/123/code-actions?path=main.go&startLine=5&endLine=7&endCharacter=2",
+			getCodeActions: func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range) ([]lsp.CodeAction, error) {
+				if rng != (lsp.Range{Start: lsp.Position{Line: 5}, End: lsp.Position{Line: 7, Character: 2}}) {
+					return nil, errors.New("unexpected arguments")
+				}
+				return []lsp.CodeAction{}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `[]`,
+		},
+		{
+			name:           "end before start",
+			target:         "/projects/123/code-actions?path=main.go&startLine=5&endLine=4",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "end must not be before start",
+		},
+		{
+			name:           "missing path",
+			target:         "/projects/123/code-actions?startLine=5",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "path not specified",
+		},
+		{
+			name:   "language server not found",
+			target: "/projects/123/code-actions?path=README.md&startLine=1",
+			getCodeActions: func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range) ([]lsp.CodeAction, error) {
+				return nil, lsp.NewLanguageServerNotFoundError(projectId, "Markdown")
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "Language server not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{GetCodeActionsFunc: tt.getCodeActions}
+
+			router := handlers.NewRouter().WithListCodeActionsHandler(handlers.ListCodeActionsHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
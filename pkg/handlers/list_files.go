@@ -10,7 +10,8 @@ import (
 )
 
 type FileInfo struct {
-	Path string `json:"path"`
+	Path       string `json:"path"`
+	LinkTarget string `json:"linkTarget,omitempty"`
 }
 
 type ListFilesHandler struct {
@@ -48,7 +49,7 @@ func (h ListFilesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var response []FileInfo
 
 	for _, file := range files {
-		response = append(response, FileInfo{Path: file.Path})
+		response = append(response, FileInfo{Path: file.Path, LinkTarget: file.LinkTarget})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
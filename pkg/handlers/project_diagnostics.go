@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type ProjectDiagnosticsHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h ProjectDiagnosticsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := getDiagnosticsFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	refresh := false
+	if r.URL.Query().Has("refresh") {
+		if refresh, err = strconv.ParseBool(r.URL.Query().Get("refresh")); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid refresh: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.ProjectManager.GetDiagnostics(r.Context(), projectID, filter, refresh)
+	if err != nil {
+		var projectNotFoundError *project.ProjectNotFoundError
+		if errors.As(err, &projectNotFoundError) {
+			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("Failed to get diagnostics: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// getDiagnosticsFilter parses the severity, source, include and exclude query parameters
+func getDiagnosticsFilter(r *http.Request) (lsp.DiagnosticsFilter, error) {
+	filter := lsp.DiagnosticsFilter{Sources: r.URL.Query()["source"], Paths: getPatternFilter(r)}
+	for _, name := range r.URL.Query()["severity"] {
+		severity, ok := lsp.ParseDiagnosticSeverity(name)
+		if !ok {
+			return filter, fmt.Errorf("Invalid severity: %s, must be error, warning, information or hint", name)
+		}
+		filter.Severities = append(filter.Severities, severity)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return filter, fmt.Errorf("Invalid filter: %w", err)
+	}
+
+	return filter, nil
+}
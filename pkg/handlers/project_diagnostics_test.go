@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestProjectDiagnosticsHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		getDiagnostics func(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter, refresh bool) (*lsp.DiagnosticsReport, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:   "filtered",
+			target: "/projects/123/diagnostics?severity=error&severity=warning&source=compiler&include=src/**&exclude=*_test.go",
+			getDiagnostics: func(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter, refresh bool) (*lsp.DiagnosticsReport, error) {
+				want := lsp.DiagnosticsFilter{
+					Severities: []protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError, protocol.DiagnosticSeverityWarning},
+					Sources:    []string{"compiler"},
+					Paths:      files.PatternFilter{Include: []string{"src/**"}, Exclude: []string{"*_test.go"}},
+				}
+				if projectId != "123" || !assert.ObjectsAreEqual(want, filter) || refresh {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &lsp.DiagnosticsReport{
+					Summary: lsp.DiagnosticsCount{Errors: 1},
+					Files:   []lsp.FileDiagnostics{{Path: "src/main.go", Counts: lsp.DiagnosticsCount{Errors: 1}, Diagnostics: []protocol.Diagnostic{{Message: "undefined: foo"}}}},
+				}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"summary":{"errors":1,"warnings":0,"information":0,"hints":0},"files":[{"path":"src/main.go","counts":{"errors":1,"warnings":0,"information":0,"hints":0},"diagnostics":[`,
+		},
+		{
+			name:   "refresh",
+			target: "/projects/123/diagnostics?refresh=true",
+			getDiagnostics: func(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter, refresh bool) (*lsp.DiagnosticsReport, error) {
+				if !refresh {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &lsp.DiagnosticsReport{Files: []lsp.FileDiagnostics{}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `"files":[]`,
+		},
+		{
+			name:           "invalid refresh",
+			target:         "/projects/123/diagnostics?refresh=maybe",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Invalid refresh",
+		},
+		{
+			name:           "invalid severity",
+			target:         "/projects/123/diagnostics?severity=fatal",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Invalid severity: fatal",
+		},
+		{
+			name:           "invalid pattern",
+			target:         "/projects/123/diagnostics?include=src/[",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Invalid filter",
+		},
+		{
+			name:   "project not found",
+			target: "/projects/123/diagnostics",
+			getDiagnostics: func(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter, refresh bool) (*lsp.DiagnosticsReport, error) {
+				return nil, project.NewProjectNotFoundError(projectId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 123 not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{GetDiagnosticsFunc: tt.getDiagnostics}
+
+			router := handlers.NewRouter().WithProjectDiagnosticsHandler(handlers.ProjectDiagnosticsHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
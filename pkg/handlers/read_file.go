@@ -2,12 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/hide-org/hide/pkg/project"
-	"github.com/hide-org/hide/pkg/files"
 )
 
 type ReadFileHandler struct {
@@ -41,21 +40,22 @@ func (h ReadFileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, err := h.ProjectManager.ReadFile(r.Context(), projectID, filePath)
-	if err != nil {
-		var projectNotFoundError *project.ProjectNotFoundError
-		if errors.As(err, &projectNotFoundError) {
-			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
+	var opts []project.ReadFileOption
+	if queryParams.Has("diagnostics") {
+		diagnostics, err := strconv.ParseBool(queryParams.Get("diagnostics"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid diagnostics: %s", err), http.StatusBadRequest)
 			return
 		}
 
-		var fileNotFoundError *files.FileNotFoundError
-		if errors.As(err, &fileNotFoundError) {
-			http.Error(w, fileNotFoundError.Error(), http.StatusNotFound)
-			return
+		if !diagnostics {
+			opts = append(opts, project.ReadFileWithoutDiagnostics())
 		}
+	}
 
-		http.Error(w, fmt.Sprintf("Failed to read file: %s", err), http.StatusInternalServerError)
+	file, err := h.ProjectManager.ReadFile(r.Context(), projectID, filePath, opts...)
+	if err != nil {
+		handleFileError(w, err, "Failed to read file")
 		return
 	}
 
@@ -67,7 +67,7 @@ func TestReadFileHandler_Success(t *testing.T) {
 				},
 			}
 			mockManager := &project_mocks.MockProjectManager{
-				ReadFileFunc: func(ctx context.Context, projectId string, path string) (*model.File, error) {
+				ReadFileFunc: func(ctx context.Context, projectId string, path string, opts ...project.ReadFileOption) (*model.File, error) {
 					return file, nil
 				},
 			}
@@ -112,6 +112,11 @@ func TestReadFileHandler_Fails_WithInvalidQueryParams(t *testing.T) {
 			target:   "/projects/123/files/test.txt?numLines=invalid",
 			wantCode: http.StatusBadRequest,
 		},
+		{
+			name:     "Read file with invalid diagnostics param",
+			target:   "/projects/123/files/test.txt?diagnostics=invalid",
+			wantCode: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -132,10 +137,62 @@ func TestReadFileHandler_Fails_WithInvalidQueryParams(t *testing.T) {
 	}
 }
 
+func TestReadFileHandler_SkipsDiagnostics(t *testing.T) {
+	tests := []struct {
+		name                string
+		target              string
+		wantSkipDiagnostics bool
+	}{
+		{
+			name:   "Read file with diagnostics by default",
+			target: "/projects/123/files/test.txt",
+		},
+		{
+			name:   "Read file with diagnostics",
+			target: "/projects/123/files/test.txt?diagnostics=true",
+		},
+		{
+			name:                "Read file without diagnostics",
+			target:              "/projects/123/files/test.txt?diagnostics=false",
+			wantSkipDiagnostics: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var options project.ReadFileOptions
+			mockManager := &project_mocks.MockProjectManager{
+				ReadFileFunc: func(ctx context.Context, projectId string, path string, opts ...project.ReadFileOption) (*model.File, error) {
+					for _, opt := range opts {
+						opt(&options)
+					}
+					return model.NewFile(path, "line1\n"), nil
+				},
+			}
+
+			handler := handlers.ReadFileHandler{ProjectManager: mockManager}
+			router := handlers.NewRouter().WithReadFileHandler(handler).Build()
+
+			request, _ := http.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			if response.Code != http.StatusOK {
+				t.Fatalf("want status 200, got %d", response.Code)
+			}
+
+			if options.SkipDiagnostics != tt.wantSkipDiagnostics {
+				t.Errorf("want skip diagnostics %t, got %t", tt.wantSkipDiagnostics, options.SkipDiagnostics)
+			}
+		})
+	}
+}
+
 func TestReadFileHandler_Returns404_WhenProjectNotFound(t *testing.T) {
 	t.Run("Read file with invalid project ID", func(t *testing.T) {
 		mockManager := &project_mocks.MockProjectManager{
-			ReadFileFunc: func(ctx context.Context, projectId string, path string) (*model.File, error) {
+			ReadFileFunc: func(ctx context.Context, projectId string, path string, opts ...project.ReadFileOption) (*model.File, error) {
 				return nil, project.NewProjectNotFoundError(projectId)
 			},
 		}
@@ -157,7 +214,7 @@ func TestReadFileHandler_Returns404_WhenProjectNotFound(t *testing.T) {
 func TestReadFileHandler_Returns500_WhenReadFileFails(t *testing.T) {
 	t.Run("Read file with invalid file path", func(t *testing.T) {
 		mockManager := &project_mocks.MockProjectManager{
-			ReadFileFunc: func(ctx context.Context, projectId string, path string) (*model.File, error) {
+			ReadFileFunc: func(ctx context.Context, projectId string, path string, opts ...project.ReadFileOption) (*model.File, error) {
 				return nil, errors.New("file not found")
 			},
 		}
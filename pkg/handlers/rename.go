@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type RenameRequest struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Character int    `json:"character"`
+	NewName   string `json:"newName"`
+	DryRun    bool   `json:"dryRun"`
+}
+
+func (r *RenameRequest) Validate() error {
+	if r.Path == "" {
+		return errors.New("path must be provided")
+	}
+
+	if r.Line < 1 {
+		return errors.New("line must be a positive number")
+	}
+
+	if r.Character < 0 {
+		return errors.New("character must not be negative")
+	}
+
+	if r.NewName == "" {
+		return errors.New("newName must be provided")
+	}
+
+	return nil
+}
+
+type RenameHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h RenameHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var request RenameRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("Failed parsing request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := request.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	position := lsp.Position{Line: request.Line, Character: request.Character}
+	result, err := h.ProjectManager.Rename(r.Context(), projectID, request.Path, position, request.NewName, request.DryRun)
+	if err != nil {
+		var renameNotPossibleError *lsp.RenameNotPossibleError
+		if errors.As(err, &renameNotPossibleError) {
+			http.Error(w, renameNotPossibleError.Error(), http.StatusBadRequest)
+			return
+		}
+
+		handleLanguageFeatureError(w, err, "Failed to rename")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		rename         func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, newName string, dryRun bool) (*project.WorkspaceEditResult, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name: "dry run",
+			body: `{"path": "main.go", "line": 3, "character": 5, "newName": "bar", "dryRun": true}`,
+			rename: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, newName string, dryRun bool) (*project.WorkspaceEditResult, error) {
+				if path != "main.go" || position != (lsp.Position{Line: 3, Character: 5}) || newName != "bar" || !dryRun {
+					return nil, errors.New("unexpected arguments")
+				}
+				return &project.WorkspaceEditResult{Files: []files.FileDiff{{Path: "main.go", Diff: "-foo\n+bar\n"}}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"files":[{"path":"main.go","diff":"-foo\n+bar\n"}]}`,
+		},
+		{
+			name:           "invalid body",
+			body:           `{"path": `,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Failed parsing request body",
+		},
+		{
+			name:           "missing new name",
+			body:           `{"path": "main.go", "line": 1}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "newName must be provided",
+		},
+		{
+			name:           "invalid line",
+			body:           `{"path": "main.go", "line": 0, "newName": "bar"}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "line must be a positive number",
+		},
+		{
+			name: "rename not possible",
+			body: `{"path": "main.go", "line": 1, "newName": "bar"}`,
+			rename: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, newName string, dryRun bool) (*project.WorkspaceEditResult, error) {
+				return nil, lsp.NewRenameNotPossibleError(path, "no identifier found")
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Cannot rename symbol in main.go: no identifier found",
+		},
+		{
+			name: "renamed file exists",
+			body: `{"path": "foo.py", "line": 1, "newName": "bar"}`,
+			rename: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, newName string, dryRun bool) (*project.WorkspaceEditResult, error) {
+				return nil, files.NewFileAlreadyExistsError("bar.py")
+			},
+			wantStatusCode: http.StatusConflict,
+			wantBody:       "bar.py",
+		},
+		{
+			name: "file not found",
+			body: `{"path": "missing.go", "line": 1, "newName": "bar"}`,
+			rename: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, newName string, dryRun bool) (*project.WorkspaceEditResult, error) {
+				return nil, files.NewFileNotFoundError(path)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "missing.go",
+		},
+		{
+			name: "internal error",
+			body: `{"path": "main.go", "line": 1, "newName": "bar"}`,
+			rename: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, newName string, dryRun bool) (*project.WorkspaceEditResult, error) {
+				return nil, errors.New("server crashed")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to rename: server crashed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{RenameFunc: tt.rename}
+
+			router := handlers.NewRouter().WithRenameHandler(handlers.RenameHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodPost, "/projects/123/refactor/rename", strings.NewReader(tt.body))
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -73,6 +73,162 @@ func (r *Router) WithSearchSymbolsHandler(handler http.Handler) *Router {
 	return r
 }
 
+func (r *Router) WithFileEventsHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/events", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithDownloadArchiveHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/archive", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithUploadArchiveHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/archive", handler).Methods("PUT")
+	return r
+}
+
+func (r *Router) WithProjectDiagnosticsHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/diagnostics", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithDiagnosticsStreamHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/diagnostics/stream", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithLanguageServersHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/lsp", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithStartLanguageServerHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/lsp/{language}", handler).Methods("POST")
+	return r
+}
+
+func (r *Router) WithStopLanguageServerHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/lsp/{language}", handler).Methods("DELETE")
+	return r
+}
+
+func (r *Router) WithLanguageServerWebSocketHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/lsp/{language}/ws", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithFindDefinitionHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/definition", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithFindReferencesHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/references", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithHoverHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/hover", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithCompletionHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/completion", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithCallHierarchyHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/call-hierarchy", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithTypeHierarchyHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/type-hierarchy", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithRenameHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/refactor/rename", handler).Methods("POST")
+	return r
+}
+
+func (r *Router) WithListCodeActionsHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/code-actions", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithApplyCodeActionHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/code-actions/apply", handler).Methods("POST")
+	return r
+}
+
+// WithFileOutlineHandler has to be registered before WithReadFileHandler, whose path matches outline requests as well.
+func (r *Router) WithFileOutlineHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/files/{path:.*}"+outlineSuffix, handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithFormatFileHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/files/{path:.*}"+formatSuffix, handler).Methods("POST")
+	return r
+}
+
+func (r *Router) WithLaunchDebugSessionHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/debug/sessions", handler).Methods("POST")
+	return r
+}
+
+func (r *Router) WithListDebugSessionsHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/debug/sessions", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithGetDebugSessionHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/debug/sessions/{session}", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithTerminateDebugSessionHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/debug/sessions/{session}", handler).Methods("DELETE")
+	return r
+}
+
+func (r *Router) WithSetBreakpointsHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/debug/sessions/{session}/breakpoints", handler).Methods("PUT")
+	return r
+}
+
+func (r *Router) WithStepDebugSessionHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/debug/sessions/{session}/step", handler).Methods("POST")
+	return r
+}
+
+func (r *Router) WithDebugThreadsHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/debug/sessions/{session}/threads", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithDebugStackTraceHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/debug/sessions/{session}/stack", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithDebugScopesHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/debug/sessions/{session}/scopes", handler).Methods("GET")
+	return r
+}
+
+func (r *Router) WithDebugVariablesHandler(handler http.Handler) *Router {
+	r.Handle("/projects/{id}/debug/sessions/{session}/variables", handler).Methods("GET")
+	return r
+}
+
 func (r *Router) Build() *mux.Router {
 	return r.Router
 }
+
+const (
+	outlineSuffix = "/outline"
+	formatSuffix  = "/format"
+)
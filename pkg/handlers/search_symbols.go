@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/hide-org/hide/pkg/lsp"
 	"github.com/hide-org/hide/pkg/project"
@@ -97,7 +99,19 @@ func (h SearchSymbolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	symbols, err := h.pm.SearchSymbols(r.Context(), projectID, query, h.opts.symbolFilter)
+	offset, err := decodeSymbolsCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid cursor: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	symbolFilter, err := h.symbolFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	symbols, err := h.pm.SearchSymbols(r.Context(), projectID, query, symbolFilter)
 	if err != nil {
 		var projectNotFoundError *project.ProjectNotFoundError
 		if errors.As(err, &projectNotFoundError) {
@@ -109,7 +123,84 @@ func (h SearchSymbolsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	offset = min(offset, len(symbols))
+	page := symbols[offset:min(offset+limit, len(symbols))]
+	if offset+limit < len(symbols) {
+		w.Header().Set(nextCursorHeader, encodeSymbolsCursor(offset+limit))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(symbols[:min(limit, len(symbols))])
+	json.NewEncoder(w).Encode(page)
+}
+
+// symbolFilter returns the filter of the request, or the filter of the handler if the request does not filter kinds.
+func (h SearchSymbolsHandler) symbolFilter(r *http.Request) (lsp.SymbolFilter, error) {
+	include, err := parseSymbolKinds(r.URL.Query()["kinds"])
+	if err != nil {
+		return lsp.SymbolFilter{}, err
+	}
+
+	exclude, err := parseSymbolKinds(r.URL.Query()["excludeKinds"])
+	if err != nil {
+		return lsp.SymbolFilter{}, err
+	}
+
+	filter := lsp.NewSymbolFilter(include, exclude, getPatternFilter(r))
+	if len(include) == 0 && len(exclude) == 0 {
+		filter = h.opts.symbolFilter.WithPaths(getPatternFilter(r))
+	}
+
+	if err := filter.Validate(); err != nil {
+		return lsp.SymbolFilter{}, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	return filter, nil
+}
+
+// parseSymbolKinds parses kinds given as repeated parameters or as a comma separated list, e.g. kinds=Function,Method
+func parseSymbolKinds(values []string) ([]protocol.SymbolKind, error) {
+	var kinds []protocol.SymbolKind
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			kind, ok := lsp.ParseSymbolKind(name)
+			if !ok {
+				return nil, fmt.Errorf("invalid symbol kind %s", name)
+			}
+			kinds = append(kinds, kind)
+		}
+	}
+
+	return kinds, nil
+}
+
+// nextCursorHeader is set to the cursor of the next page of symbols if there are more symbols
+const nextCursorHeader = "X-Next-Cursor"
+
+// symbol search results are ranked deterministically, so a cursor is the offset of the next page
+func encodeSymbolsCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeSymbolsCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	offset, err := strconv.Atoi(string(decoded))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("malformed cursor %s", cursor)
+	}
+
+	return offset, nil
 }
@@ -7,14 +7,17 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
+	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/handlers"
 	"github.com/hide-org/hide/pkg/lsp"
 	"github.com/hide-org/hide/pkg/model"
 	"github.com/hide-org/hide/pkg/project"
 	"github.com/hide-org/hide/pkg/project/mocks"
 	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
 func TestSearchSymbolsHandler_ServeHTTP(t *testing.T) {
@@ -87,6 +90,55 @@ func TestSearchSymbolsHandler_ServeHTTP(t *testing.T) {
 			wantStatusCode: http.StatusBadRequest,
 			wantBody:       "limit must be between 1 and 100",
 		},
+		{
+			name:   "next page",
+			target: "/projects/123/search?type=symbol&query=test-query&limit=1&cursor=MQ",
+			mockSearchSymbols: func(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error) {
+				return symbols, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       fmt.Sprintf("[%s]", mustMarshal(t, symbols[1])),
+		},
+		{
+			name:   "invalid cursor",
+			target: "/projects/123/search?type=symbol&query=test-query&cursor=invalid",
+			mockSearchSymbols: func(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error) {
+				return nil, nil
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "invalid cursor",
+		},
+		{
+			name:   "filter kinds",
+			target: "/projects/123/search?type=symbol&query=test-query&kinds=function,Method&excludeKinds=class&include=src/**",
+			mockSearchSymbols: func(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error) {
+				want := lsp.NewSymbolFilter([]protocol.SymbolKind{protocol.SymbolKindFunction, protocol.SymbolKindMethod}, []protocol.SymbolKind{protocol.SymbolKindClass}, files.PatternFilter{Include: []string{"src/**"}})
+				if !reflect.DeepEqual(want, symbolFilter) {
+					return nil, errors.New("unexpected filter")
+				}
+				return symbols, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       string(symbolsJson),
+		},
+		{
+			name:   "invalid kind",
+			target: "/projects/123/search?type=symbol&query=test-query&kinds=unknown",
+			mockSearchSymbols: func(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error) {
+				return nil, nil
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "invalid symbol kind unknown",
+		},
+		{
+			name:   "invalid path pattern",
+			target: "/projects/123/search?type=symbol&query=test-query&include=[",
+			mockSearchSymbols: func(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error) {
+				return nil, nil
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "invalid filter",
+		},
 		{
 			name:   "project not found",
 			target: "/projects/123/search?type=symbol&query=test-query",
@@ -127,6 +179,56 @@ func TestSearchSymbolsHandler_ServeHTTP(t *testing.T) {
 	}
 }
 
+func TestSearchSymbolsHandler_Pagination(t *testing.T) {
+	symbols := []lsp.SymbolInfo{
+		newSymbolInfo("symbol1", "kind1", "path1"),
+		newSymbolInfo("symbol2", "kind2", "path2"),
+		newSymbolInfo("symbol3", "kind3", "path3"),
+	}
+
+	mockManager := &mocks.MockProjectManager{
+		SearchSymbolsFunc: func(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error) {
+			return symbols, nil
+		},
+	}
+	router := handlers.NewRouter().WithSearchSymbolsHandler(handlers.NewSearchSymbolsHandler(mockManager)).Build()
+
+	var got []lsp.SymbolInfo
+	target := "/projects/123/search?type=symbol&query=test-query&limit=2"
+	for pages := 0; target != ""; pages++ {
+		if pages == len(symbols) {
+			t.Fatal("too many pages")
+		}
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var page []lsp.SymbolInfo
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, page...)
+
+		target = ""
+		if cursor := rr.Header().Get("X-Next-Cursor"); cursor != "" {
+			target = "/projects/123/search?type=symbol&query=test-query&limit=2&cursor=" + cursor
+		}
+	}
+
+	assert.Equal(t, symbols, got)
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
 func newSymbolInfo(name, kind, path string) lsp.SymbolInfo {
 	return lsp.SymbolInfo{
 		Name: name,
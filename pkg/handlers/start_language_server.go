@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type StartLanguageServerHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h StartLanguageServerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	language, err := getPathValue(r, "language")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid language: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ProjectManager.StartLanguageServer(r.Context(), projectID, language); err != nil {
+		var projectNotFoundError *project.ProjectNotFoundError
+		if errors.As(err, &projectNotFoundError) {
+			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
+			return
+		}
+
+		var languageNotSupportedError *lsp.LanguageNotSupportedError
+		if errors.As(err, &languageNotSupportedError) {
+			http.Error(w, languageNotSupportedError.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var languageServerAlreadyExistsError *lsp.LanguageServerAlreadyExistsError
+		if errors.As(err, &languageServerAlreadyExistsError) {
+			http.Error(w, languageServerAlreadyExistsError.Error(), http.StatusConflict)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("Failed to start language server: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartLanguageServerHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name                string
+		target              string
+		startLanguageServer func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error
+		wantStatusCode      int
+		wantBody            string
+	}{
+		{
+			name:   "ok",
+			target: "/projects/123/lsp/TypeScript",
+			startLanguageServer: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+				if projectId != "123" || languageId != lsp.TypeScript {
+					return errors.New("unexpected arguments")
+				}
+				return nil
+			},
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name:   "escaped language",
+			target: "/projects/123/lsp/C%23",
+			startLanguageServer: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+				if languageId != "C#" {
+					return errors.New("unexpected language")
+				}
+				return nil
+			},
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name:   "project not found",
+			target: "/projects/123/lsp/Go",
+			startLanguageServer: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+				return project.NewProjectNotFoundError(projectId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 123 not found",
+		},
+		{
+			name:   "language not supported",
+			target: "/projects/123/lsp/COBOL",
+			startLanguageServer: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+				return lsp.NewLanguageNotSupportedError(languageId)
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Language COBOL is not supported",
+		},
+		{
+			name:   "already running",
+			target: "/projects/123/lsp/Go",
+			startLanguageServer: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+				return lsp.NewLanguageServerAlreadyExistsError(projectId, languageId)
+			},
+			wantStatusCode: http.StatusConflict,
+			wantBody:       "Language server already exists for project 123 and language Go",
+		},
+		{
+			name:   "internal error",
+			target: "/projects/123/lsp/Go",
+			startLanguageServer: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+				return errors.New("gopls not found")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to start language server: gopls not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{StartLanguageServerFunc: tt.startLanguageServer}
+
+			router := handlers.NewRouter().WithStartLanguageServerHandler(handlers.StartLanguageServerHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type StopLanguageServerHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h StopLanguageServerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	language, err := getPathValue(r, "language")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid language: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ProjectManager.StopLanguageServer(r.Context(), projectID, language); err != nil {
+		var projectNotFoundError *project.ProjectNotFoundError
+		if errors.As(err, &projectNotFoundError) {
+			http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
+			return
+		}
+
+		var languageServerNotFoundError *lsp.LanguageServerNotFoundError
+		if errors.As(err, &languageServerNotFoundError) {
+			http.Error(w, languageServerNotFoundError.Error(), http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, fmt.Sprintf("Failed to stop language server: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,76 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/project"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopLanguageServerHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name               string
+		stopLanguageServer func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error
+		wantStatusCode     int
+		wantBody           string
+	}{
+		{
+			name: "ok",
+			stopLanguageServer: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+				if projectId != "123" || languageId != lsp.Go {
+					return errors.New("unexpected arguments")
+				}
+				return nil
+			},
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "project not found",
+			stopLanguageServer: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+				return project.NewProjectNotFoundError(projectId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "project 123 not found",
+		},
+		{
+			name: "server not found",
+			stopLanguageServer: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+				return lsp.NewLanguageServerNotFoundError(projectId, languageId)
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "Language server not found for project 123 and language Go",
+		},
+		{
+			name: "internal error",
+			stopLanguageServer: func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+				return errors.New("boom")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       "Failed to stop language server: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{StopLanguageServerFunc: tt.stopLanguageServer}
+
+			router := handlers.NewRouter().WithStopLanguageServerHandler(handlers.StopLanguageServerHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodDelete, "/projects/123/lsp/Go", nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
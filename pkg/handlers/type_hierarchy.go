@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type TypeHierarchyHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h TypeHierarchyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	path, position, err := getDocumentPosition(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid position: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	direction := lsp.TypeHierarchySupertypes
+	if r.URL.Query().Has("direction") {
+		direction = lsp.TypeHierarchyDirection(r.URL.Query().Get("direction"))
+		if !direction.Valid() {
+			http.Error(w, fmt.Sprintf("Invalid direction: %s, must be %s or %s", direction, lsp.TypeHierarchySupertypes, lsp.TypeHierarchySubtypes), http.StatusBadRequest)
+			return
+		}
+	}
+
+	depth, err := getHierarchyDepth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.ProjectManager.GetTypeHierarchy(r.Context(), projectID, path, position, direction, depth)
+	if err != nil {
+		handleLanguageFeatureError(w, err, "Failed to get type hierarchy")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
@@ -0,0 +1,72 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeHierarchyHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name             string
+		target           string
+		getTypeHierarchy func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.TypeHierarchyDirection, depth int) ([]lsp.HierarchyItem, error)
+		wantStatusCode   int
+		wantBody         string
+	}{
+		{
+			name:   "subtypes",
+			target: "/projects/123/type-hierarchy?path=main.py&line=3&direction=subtypes&depth=2",
+			getTypeHierarchy: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.TypeHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+				if path != "main.py" || position != (lsp.Position{Line: 3}) || direction != lsp.TypeHierarchySubtypes || depth != 2 {
+					return nil, errors.New("unexpected arguments")
+				}
+				return []lsp.HierarchyItem{{Name: "Base", Kind: "Class", Children: []lsp.HierarchyItem{{Name: "Derived", Kind: "Class"}}}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `"children":[{"name":"Derived","kind":"Class"`,
+		},
+		{
+			name:           "invalid direction",
+			target:         "/projects/123/type-hierarchy?path=main.py&line=3&direction=incoming",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Invalid direction: incoming, must be supertypes or subtypes",
+		},
+		{
+			name:   "not supported",
+			target: "/projects/123/type-hierarchy?path=main.go&line=3",
+			getTypeHierarchy: func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.TypeHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+				if direction != lsp.TypeHierarchySupertypes || depth != 1 {
+					return nil, errors.New("unexpected arguments")
+				}
+				return nil, lsp.NewFeatureNotSupportedError("type hierarchy")
+			},
+			wantStatusCode: http.StatusNotImplemented,
+			wantBody:       "Language server does not support type hierarchy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{GetTypeHierarchyFunc: tt.getTypeHierarchy}
+
+			router := handlers.NewRouter().WithTypeHierarchyHandler(handlers.TypeHierarchyHandler{ProjectManager: mockManager}).Build()
+
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -86,6 +86,12 @@ func (h UpdateFileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := getWriteFileOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var request UpdateFileRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -102,60 +108,24 @@ func (h UpdateFileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	switch request.Type {
 	case Udiff:
-		updatedFile, err := h.ProjectManager.ApplyPatch(r.Context(), projectID, filePath, request.Udiff.Patch)
+		updatedFile, err := h.ProjectManager.ApplyPatch(r.Context(), projectID, filePath, request.Udiff.Patch, opts...)
 		if err != nil {
-			var projectNotFoundError *project.ProjectNotFoundError
-			if errors.As(err, &projectNotFoundError) {
-				http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
-				return
-			}
-
-			var fileNotFoundError *files.FileNotFoundError
-			if errors.As(err, &fileNotFoundError) {
-				http.Error(w, fileNotFoundError.Error(), http.StatusNotFound)
-				return
-			}
-
-			http.Error(w, fmt.Sprintf("Failed to update file: %s", err), http.StatusInternalServerError)
+			handleFileError(w, err, "Failed to update file")
 			return
 		}
 		file = updatedFile
 	case LineDiff:
 		lineDiff := request.LineDiff
-		updatedFile, err := h.ProjectManager.UpdateLines(r.Context(), projectID, filePath, files.LineDiffChunk{StartLine: lineDiff.StartLine, EndLine: lineDiff.EndLine, Content: lineDiff.Content})
+		updatedFile, err := h.ProjectManager.UpdateLines(r.Context(), projectID, filePath, files.LineDiffChunk{StartLine: lineDiff.StartLine, EndLine: lineDiff.EndLine, Content: lineDiff.Content}, opts...)
 		if err != nil {
-			var projectNotFoundError *project.ProjectNotFoundError
-			if errors.As(err, &projectNotFoundError) {
-				http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
-				return
-			}
-
-			var fileNotFoundError *files.FileNotFoundError
-			if errors.As(err, &fileNotFoundError) {
-				http.Error(w, fileNotFoundError.Error(), http.StatusNotFound)
-				return
-			}
-
-			http.Error(w, fmt.Sprintf("Failed to update file: %s", err), http.StatusInternalServerError)
+			handleFileError(w, err, "Failed to update file")
 			return
 		}
 		file = updatedFile
 	case Overwrite:
-		updatedFile, err := h.ProjectManager.UpdateFile(r.Context(), projectID, filePath, request.Overwrite.Content)
+		updatedFile, err := h.ProjectManager.UpdateFile(r.Context(), projectID, filePath, request.Overwrite.Content, opts...)
 		if err != nil {
-			var projectNotFoundError *project.ProjectNotFoundError
-			if errors.As(err, &projectNotFoundError) {
-				http.Error(w, projectNotFoundError.Error(), http.StatusNotFound)
-				return
-			}
-
-			var fileNotFoundError *files.FileNotFoundError
-			if errors.As(err, &fileNotFoundError) {
-				http.Error(w, fileNotFoundError.Error(), http.StatusNotFound)
-				return
-			}
-
-			http.Error(w, fmt.Sprintf("Failed to update file: %s", err), http.StatusInternalServerError)
+			handleFileError(w, err, "Failed to update file")
 			return
 		}
 		file = updatedFile
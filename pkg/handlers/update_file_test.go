@@ -82,13 +82,13 @@ func TestUpdateFileHandler_Success(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockManager := &project_mocks.MockProjectManager{
-				ApplyPatchFunc: func(ctx context.Context, projectId string, path, patch string) (*model.File, error) {
+				ApplyPatchFunc: func(ctx context.Context, projectId string, path, patch string, opts ...project.WriteFileOption) (*model.File, error) {
 					return &tt.want, nil
 				},
-				UpdateLinesFunc: func(ctx context.Context, projectId string, path string, lineDiff files.LineDiffChunk) (*model.File, error) {
+				UpdateLinesFunc: func(ctx context.Context, projectId string, path string, lineDiff files.LineDiffChunk, opts ...project.WriteFileOption) (*model.File, error) {
 					return &tt.want, nil
 				},
-				UpdateFileFunc: func(ctx context.Context, projectId string, path, content string) (*model.File, error) {
+				UpdateFileFunc: func(ctx context.Context, projectId string, path, content string, opts ...project.WriteFileOption) (*model.File, error) {
 					return &tt.want, nil
 				},
 			}
@@ -219,7 +219,7 @@ func TestUpdateFileHandler_RespondsWithBadRequest_IfRequestIsInvalid(t *testing.
 
 func TestUpdateFileHandler_RespondsWithInternalServerError_IfFileManagerFails(t *testing.T) {
 	mockManager := &project_mocks.MockProjectManager{
-		ApplyPatchFunc: func(ctx context.Context, projectId string, path, patch string) (*model.File, error) {
+		ApplyPatchFunc: func(ctx context.Context, projectId string, path, patch string, opts ...project.WriteFileOption) (*model.File, error) {
 			return nil, errors.New("file manager error")
 		},
 	}
@@ -250,7 +250,7 @@ func TestUpdateFileHandler_RespondsWithInternalServerError_IfFileManagerFails(t
 
 func TestUpdateFileHandler_RespondsWithNotFound_IfProjectNotFound(t *testing.T) {
 	mockManager := &project_mocks.MockProjectManager{
-		ApplyPatchFunc: func(ctx context.Context, projectId string, path, patch string) (*model.File, error) {
+		ApplyPatchFunc: func(ctx context.Context, projectId string, path, patch string, opts ...project.WriteFileOption) (*model.File, error) {
 			return nil, project.NewProjectNotFoundError(projectId)
 		},
 	}
@@ -283,7 +283,7 @@ func TestPathStartingWithSlash(t *testing.T) {
 	t.Run("Update file with invalid path should return 400", func(t *testing.T) {
 		// Setup
 		mockManager := &project_mocks.MockProjectManager{
-			UpdateLinesFunc: func(ctx context.Context, projectId string, path string, lineDiff files.LineDiffChunk) (*model.File, error) {
+			UpdateLinesFunc: func(ctx context.Context, projectId string, path string, lineDiff files.LineDiffChunk, opts ...project.WriteFileOption) (*model.File, error) {
 				return nil, nil
 			},
 		}
@@ -318,7 +318,7 @@ func TestEmptyPath(t *testing.T) {
 	t.Run("Update file with invalid path should return 400", func(t *testing.T) {
 		// Setup
 		mockManager := &project_mocks.MockProjectManager{
-			UpdateLinesFunc: func(ctx context.Context, projectId string, path string, lineDiff files.LineDiffChunk) (*model.File, error) {
+			UpdateLinesFunc: func(ctx context.Context, projectId string, path string, lineDiff files.LineDiffChunk, opts ...project.WriteFileOption) (*model.File, error) {
 				return nil, nil
 			},
 		}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/project"
+)
+
+type UploadArchiveHandler struct {
+	ProjectManager project.Manager
+}
+
+func (h UploadArchiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	projectID, err := getProjectID(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid project ID: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	format, err := getArchiveFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	overwrite := files.OverwritePolicy(r.URL.Query().Get("overwrite"))
+	if overwrite == "" {
+		overwrite = files.OverwriteError
+	}
+
+	if !overwrite.Valid() {
+		http.Error(w, fmt.Sprintf("Invalid overwrite policy: %s, must be %s, %s or %s", overwrite, files.OverwriteError, files.OverwriteSkip, files.OverwriteReplace), http.StatusBadRequest)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, files.MaxArchiveSize)
+	result, err := h.ProjectManager.UploadArchive(r.Context(), projectID, body, r.URL.Query().Get("path"), format, overwrite)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(w, files.NewArchiveTooLargeError(fmt.Sprintf("more than %d bytes", maxBytesError.Limit)).Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		var archiveTooLargeError *files.ArchiveTooLargeError
+		if errors.As(err, &archiveTooLargeError) {
+			http.Error(w, archiveTooLargeError.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		var invalidArchiveError *files.InvalidArchiveError
+		if errors.As(err, &invalidArchiveError) {
+			http.Error(w, invalidArchiveError.Error(), http.StatusBadRequest)
+			return
+		}
+
+		handleFileError(w, err, "Failed to upload archive")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/handlers"
+	"github.com/hide-org/hide/pkg/model"
+	project_mocks "github.com/hide-org/hide/pkg/project/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadArchiveHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		uploadArchive  func(ctx context.Context, projectId model.ProjectId, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error)
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:   "success",
+			target: "/projects/123/archive?path=fixtures&format=zip&overwrite=skip",
+			uploadArchive: func(ctx context.Context, projectId model.ProjectId, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error) {
+				content, _ := io.ReadAll(r)
+				assert.Equal(t, "archive", string(content))
+				assert.Equal(t, "fixtures", path)
+				assert.Equal(t, files.ArchiveZip, format)
+				assert.Equal(t, files.OverwriteSkip, overwrite)
+				return &files.ExtractResult{Files: []string{"fixtures/a.json"}, Skipped: []string{"b.json"}}, nil
+			},
+			wantStatusCode: http.StatusOK,
+			wantBody:       `{"files":["fixtures/a.json"],"skipped":["b.json"]}`,
+		},
+		{
+			name:           "invalid overwrite policy",
+			target:         "/projects/123/archive?overwrite=always",
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "Invalid overwrite policy: always",
+		},
+		{
+			name:   "invalid archive",
+			target: "/projects/123/archive",
+			uploadArchive: func(ctx context.Context, projectId model.ProjectId, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error) {
+				assert.Equal(t, files.OverwriteError, overwrite)
+				return nil, files.NewInvalidArchiveError(errors.New("gzip: invalid header"))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantBody:       "invalid archive: gzip: invalid header",
+		},
+		{
+			name:   "file exists",
+			target: "/projects/123/archive",
+			uploadArchive: func(ctx context.Context, projectId model.ProjectId, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error) {
+				return nil, files.NewFileAlreadyExistsError("a.json")
+			},
+			wantStatusCode: http.StatusConflict,
+			wantBody:       "file a.json already exists",
+		},
+		{
+			name:   "path traversal",
+			target: "/projects/123/archive",
+			uploadArchive: func(ctx context.Context, projectId model.ProjectId, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error) {
+				return nil, files.NewPathOutsideProjectError("../etc/passwd")
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantBody:       "path ../etc/passwd is outside of the project",
+		},
+		{
+			name:   "archive too large",
+			target: "/projects/123/archive",
+			uploadArchive: func(ctx context.Context, projectId model.ProjectId, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error) {
+				return nil, files.NewArchiveTooLargeError("archive has more than 100000 entries")
+			},
+			wantStatusCode: http.StatusRequestEntityTooLarge,
+			wantBody:       "archive too large: archive has more than 100000 entries",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &project_mocks.MockProjectManager{UploadArchiveFunc: tt.uploadArchive}
+
+			handler := handlers.UploadArchiveHandler{ProjectManager: mockManager}
+			router := handlers.NewRouter().WithUploadArchiveHandler(handler).Build()
+
+			request := httptest.NewRequest(http.MethodPut, tt.target, strings.NewReader("archive"))
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.wantStatusCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/project"
 )
 
 func getProjectID(r *http.Request) (string, error) {
@@ -65,6 +67,146 @@ func parseIntQueryParam(params url.Values, paramName string) (int, bool, error)
 	return value, true, nil
 }
 
+// getDocumentPosition reads the path, the 1-based line and the 0-based character from the query.
+func getDocumentPosition(r *http.Request) (string, lsp.Position, error) {
+	params := r.URL.Query()
+
+	path := params.Get("path")
+	if path == "" {
+		return "", lsp.Position{}, errors.New("path not specified")
+	}
+
+	line, ok, err := parseIntQueryParam(params, "line")
+	if err != nil {
+		return "", lsp.Position{}, err
+	}
+	if !ok || line < 1 {
+		return "", lsp.Position{}, errors.New("line must be a positive number")
+	}
+
+	character, _, err := parseIntQueryParam(params, "character")
+	if err != nil {
+		return "", lsp.Position{}, err
+	}
+	if character < 0 {
+		return "", lsp.Position{}, errors.New("character must not be negative")
+	}
+
+	return path, lsp.Position{Line: line, Character: character}, nil
+}
+
+// getDocumentRange reads the path and a range from the query. The end defaults to the start, so that a position can be given instead of a range.
+func getDocumentRange(r *http.Request) (string, lsp.Range, error) {
+	params := r.URL.Query()
+
+	path := params.Get("path")
+	if path == "" {
+		return "", lsp.Range{}, errors.New("path not specified")
+	}
+
+	var values [4]int
+	for i, name := range []string{"startLine", "startCharacter", "endLine", "endCharacter"} {
+		value, _, err := parseIntQueryParam(params, name)
+		if err != nil {
+			return "", lsp.Range{}, err
+		}
+		values[i] = value
+	}
+
+	rng := documentRange(values[0], values[1], values[2], values[3])
+	if err := validateRange(rng); err != nil {
+		return "", lsp.Range{}, err
+	}
+
+	return path, rng, nil
+}
+
+// documentRange returns the range between the 1-based lines and 0-based characters, without an end line the range is empty.
+func documentRange(startLine, startCharacter, endLine, endCharacter int) lsp.Range {
+	start := lsp.Position{Line: startLine, Character: startCharacter}
+	if endLine == 0 {
+		return lsp.Range{Start: start, End: start}
+	}
+
+	return lsp.Range{Start: start, End: lsp.Position{Line: endLine, Character: endCharacter}}
+}
+
+func validateRange(rng lsp.Range) error {
+	if rng.Start.Line < 1 || rng.End.Line < 1 {
+		return errors.New("startLine and endLine must be positive numbers")
+	}
+
+	if rng.Start.Character < 0 || rng.End.Character < 0 {
+		return errors.New("startCharacter and endCharacter must not be negative")
+	}
+
+	if rng.End.Line < rng.Start.Line || (rng.End.Line == rng.Start.Line && rng.End.Character < rng.Start.Character) {
+		return errors.New("end must not be before start")
+	}
+
+	return nil
+}
+
+// getWriteFileOptions parses the format query parameter of the requests that write a file
+func getWriteFileOptions(r *http.Request) ([]project.WriteFileOption, error) {
+	queryParams := r.URL.Query()
+	if !queryParams.Has("format") {
+		return nil, nil
+	}
+
+	format, err := strconv.ParseBool(queryParams.Get("format"))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid format: %w", err)
+	}
+
+	if !format {
+		return nil, nil
+	}
+
+	return []project.WriteFileOption{project.WriteFileWithFormatting()}, nil
+}
+
 func getAcceptFormat(r *http.Request) string {
 	return r.Header.Get("Accept")
 }
+
+// matchFileError returns the status of the errors that file operations have in common, e.g. a path outside of the project, and the error that matched.
+// The error is nil if none matched.
+func matchFileError(err error) (int, error) {
+	var projectNotFoundError *project.ProjectNotFoundError
+	if errors.As(err, &projectNotFoundError) {
+		return http.StatusNotFound, projectNotFoundError
+	}
+
+	var fileNotFoundError *files.FileNotFoundError
+	if errors.As(err, &fileNotFoundError) {
+		return http.StatusNotFound, fileNotFoundError
+	}
+
+	var fileAlreadyExistsError *files.FileAlreadyExistsError
+	if errors.As(err, &fileAlreadyExistsError) {
+		return http.StatusConflict, fileAlreadyExistsError
+	}
+
+	var pathOutsideProjectError *files.PathOutsideProjectError
+	if errors.As(err, &pathOutsideProjectError) {
+		return http.StatusForbidden, pathOutsideProjectError
+	}
+
+	var pathProtectedError *files.PathProtectedError
+	if errors.As(err, &pathProtectedError) {
+		return http.StatusForbidden, pathProtectedError
+	}
+
+	return http.StatusInternalServerError, nil
+}
+
+// handleFileError maps errors of file operations to a response, other errors are internal errors with the message.
+func handleFileError(w http.ResponseWriter, err error, message string) {
+	if status, fileErr := matchFileError(err); fileErr != nil {
+		http.Error(w, fileErr.Error(), status)
+		return
+	}
+
+	http.Error(w, fmt.Sprintf("%s: %s", message, err), http.StatusInternalServerError)
+}
@@ -3,6 +3,8 @@ package lsp
 import (
 	"context"
 	"encoding/json"
+	"strings"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 	"github.com/sourcegraph/jsonrpc2"
@@ -11,10 +13,18 @@ import (
 )
 
 type lspHandler struct {
-	diagnosticsHandler func(protocol.PublishDiagnosticsParams)
+	// notificationHandler gets every notification of the server, before it's handled
+	notificationHandler func(Notification)
+	diagnosticsHandler  func(protocol.PublishDiagnosticsParams)
+	applyEditHandler    func(protocol.ApplyWorkspaceEditParams) protocol.ApplyWorkspaceEditResponse
+	settings            any
 }
 
 func (h *lspHandler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result interface{}, err error) {
+	if req.Notif {
+		h.notificationHandler(Notification{Method: req.Method, Params: req.Params})
+	}
+
 	switch req.Method {
 	case "textDocument/publishDiagnostics":
 		select {
@@ -27,6 +37,39 @@ func (h *lspHandler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonr
 			}
 			h.diagnosticsHandler(params)
 		}
+	case "workspace/applyEdit":
+		var params struct {
+			Label *string         `json:"label"`
+			Edit  json.RawMessage `json:"edit"`
+		}
+		if err := json.Unmarshal(*req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		edit, err := parseWorkspaceEdit(params.Edit)
+		if err != nil {
+			return nil, err
+		}
+		if edit == nil {
+			edit = &protocol.WorkspaceEdit{}
+		}
+
+		return h.applyEditHandler(protocol.ApplyWorkspaceEditParams{Label: params.Label, Edit: *edit}), nil
+	case "workspace/configuration":
+		var params protocol.ConfigurationParams
+		if err := json.Unmarshal(*req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		result := make([]any, 0, len(params.Items))
+		for _, item := range params.Items {
+			section := ""
+			if item.Section != nil {
+				section = *item.Section
+			}
+			result = append(result, configurationSection(h.settings, section))
+		}
+		return result, nil
 	}
 
 	return nil, nil
@@ -34,32 +77,123 @@ func (h *lspHandler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonr
 
 type Client interface {
 	GetWorkspaceSymbols(ctx context.Context, params protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error)
-	Initialize(ctx context.Context, params protocol.InitializeParams) (protocol.InitializeResult, error)
+	Initialize(ctx context.Context, params InitializeParams) (protocol.InitializeResult, error)
 	NotifyInitialized(ctx context.Context) error
 	NotifyDidOpen(ctx context.Context, params protocol.DidOpenTextDocumentParams) error
+	NotifyDidChange(ctx context.Context, params protocol.DidChangeTextDocumentParams) error
 	NotifyDidClose(ctx context.Context, params protocol.DidCloseTextDocumentParams) error
-	// TODO: check if any LSP server supports this
-	// PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error)
+	NotifyDidChangeWatchedFiles(ctx context.Context, params protocol.DidChangeWatchedFilesParams) error
+	NotifyDidChangeConfiguration(ctx context.Context, params protocol.DidChangeConfigurationParams) error
+	GetDefinition(ctx context.Context, params protocol.DefinitionParams) ([]protocol.Location, error)
+	GetTypeDefinition(ctx context.Context, params protocol.TypeDefinitionParams) ([]protocol.Location, error)
+	GetImplementation(ctx context.Context, params protocol.ImplementationParams) ([]protocol.Location, error)
+	GetReferences(ctx context.Context, params protocol.ReferenceParams) ([]protocol.Location, error)
+	// GetHover returns nil if there is nothing to show at the position, contents are always MarkupContent
+	GetHover(ctx context.Context, params protocol.HoverParams) (*protocol.Hover, error)
+	GetSignatureHelp(ctx context.Context, params protocol.SignatureHelpParams) (*protocol.SignatureHelp, error)
+	// GetCompletion returns nil if there are no completions, a list of items is returned as a complete CompletionList
+	GetCompletion(ctx context.Context, params protocol.CompletionParams) (*protocol.CompletionList, error)
+	ResolveCompletionItem(ctx context.Context, item protocol.CompletionItem) (protocol.CompletionItem, error)
+	// GetDocumentSymbols always returns a hierarchy, also for servers that return flat SymbolInformation
+	GetDocumentSymbols(ctx context.Context, params protocol.DocumentSymbolParams) ([]protocol.DocumentSymbol, error)
+	PrepareCallHierarchy(ctx context.Context, params protocol.CallHierarchyPrepareParams) ([]protocol.CallHierarchyItem, error)
+	GetIncomingCalls(ctx context.Context, params protocol.CallHierarchyIncomingCallsParams) ([]protocol.CallHierarchyIncomingCall, error)
+	GetOutgoingCalls(ctx context.Context, params protocol.CallHierarchyOutgoingCallsParams) ([]protocol.CallHierarchyOutgoingCall, error)
+	PrepareTypeHierarchy(ctx context.Context, params TypeHierarchyPrepareParams) ([]TypeHierarchyItem, error)
+	GetSupertypes(ctx context.Context, params TypeHierarchySupertypesParams) ([]TypeHierarchyItem, error)
+	GetSubtypes(ctx context.Context, params TypeHierarchySubtypesParams) ([]TypeHierarchyItem, error)
+	// PrepareRename returns nil if the symbol at the position can't be renamed. For servers that answer with the default behavior the range and placeholder are empty.
+	PrepareRename(ctx context.Context, params protocol.PrepareRenameParams) (*protocol.RangeWithPlaceholder, error)
+	// Rename returns nil if there is nothing to change. Document changes are TextDocumentEdit, CreateFile, RenameFile or DeleteFile values and edits are TextEdit values.
+	Rename(ctx context.Context, params protocol.RenameParams) (*protocol.WorkspaceEdit, error)
+	// GetCodeActions returns commands as code actions with only a title and the command. Edits are parsed like the result of Rename.
+	GetCodeActions(ctx context.Context, params protocol.CodeActionParams) ([]protocol.CodeAction, error)
+	// ExecuteCommand returns the edits the server asked to apply while executing the command. They are acknowledged, but it's up to the caller to apply them.
+	ExecuteCommand(ctx context.Context, params protocol.ExecuteCommandParams) ([]protocol.WorkspaceEdit, error)
+	// FormatDocument returns nil if the document is already formatted
+	FormatDocument(ctx context.Context, params protocol.DocumentFormattingParams) ([]protocol.TextEdit, error)
+	// FormatRange returns nil if the range is already formatted
+	FormatRange(ctx context.Context, params protocol.DocumentRangeFormattingParams) ([]protocol.TextEdit, error)
+	// OrganizeImports returns the source.organizeImports code actions of the document, the kind of the context is set by the client
+	OrganizeImports(ctx context.Context, params protocol.CodeActionParams) ([]protocol.CodeAction, error)
+	// PullDiagnostics requests the diagnostics of a document, the result id of a previous report lets the server answer that they are unchanged
+	PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error)
+	// PullWorkspaceDiagnostics requests the diagnostics of all documents of the workspace, reports of documents with a previous result id may be unchanged
+	PullWorkspaceDiagnostics(ctx context.Context, params WorkspaceDiagnosticParams) (WorkspaceDiagnosticReport, error)
+	// DiagnosticProvider returns the pull diagnostics options of the server, it is nil if the server only publishes diagnostics
+	DiagnosticProvider() *DiagnosticOptions
+	// CompletionResolveProvider reports whether the server resolves completion items
+	CompletionResolveProvider() bool
 	Shutdown(ctx context.Context) error
+	// Call sends a raw request to the server, e.g. of a client that is passed through. Errors of the server are returned as *jsonrpc2.Error.
+	Call(ctx context.Context, method string, params *json.RawMessage) (json.RawMessage, error)
+	// CallWithEdits sends a raw request like Call, e.g. workspace/executeCommand of a client that is passed through, and returns the edits the server
+	// asked to apply meanwhile like ExecuteCommand. It is serialized with the commands of Hide.
+	CallWithEdits(ctx context.Context, method string, params *json.RawMessage) (json.RawMessage, []protocol.WorkspaceEdit, error)
+	// Notify sends a raw notification to the server
+	Notify(ctx context.Context, method string, params *json.RawMessage) error
+	// InitializeResult returns the raw result of the initialize request, it is nil before the server is initialized
+	InitializeResult() json.RawMessage
+	// SubscribeNotifications returns the notifications the server sends until unsubscribe is called. The channel is closed when the connection is closed.
+	// Notifications are dropped if the subscriber doesn't keep up.
+	SubscribeNotifications() (notifications <-chan Notification, unsubscribe func())
 }
+
+// Notification is a raw notification of a language server.
+type Notification struct {
+	Method string
+	Params *json.RawMessage
+}
+
+// notificationBuffer is how many notifications a subscriber can lag behind before they are dropped
+const notificationBuffer = 100
+
 type ClientImpl struct {
-	conn               Connection
-	server             Process
-	diagnosticsChannel chan protocol.PublishDiagnosticsParams
+	conn   Connection
+	server Process
+
+	initializeResult          json.RawMessage
+	diagnosticProvider        *DiagnosticOptions
+	completionResolveProvider bool
+
+	subscribersMu sync.Mutex
+	subscribers   map[chan Notification]struct{}
+	// closed is true once the connection is closed, subscribers are not added anymore
+	closed bool
+
+	// commandMu serializes commands, so that the edits the server asks to apply belong to the running command
+	commandMu sync.Mutex
+	editsMu   sync.Mutex
+	// edits is nil if no command is running
+	edits []protocol.WorkspaceEdit
 }
 
+// Diagnostics is closed when the connection to the server is closed, also when the server crashed.
 type Diagnostics <-chan protocol.PublishDiagnosticsParams
 
-func NewClient(server Process) (Client, Diagnostics) {
+// NewClient returns a client of the server, settings are the answers to the workspace/configuration requests of the server.
+func NewClient(server Process, mapping PathMapping, settings any) (Client, Diagnostics) {
 	d := make(chan protocol.PublishDiagnosticsParams)
 
+	client := &ClientImpl{server: server, subscribers: make(map[chan Notification]struct{})}
 	handler := &lspHandler{
+		notificationHandler: client.broadcast,
 		diagnosticsHandler: func(params protocol.PublishDiagnosticsParams) {
 			d <- params
 		},
+		applyEditHandler: client.applyEdit,
+		settings:         settings,
 	}
-	conn := NewConnection(context.Background(), server.ReadWriteCloser(), jsonrpc2.HandlerWithError(handler.Handle))
-	return &ClientImpl{conn: conn, server: server, diagnosticsChannel: d}, d
+	client.conn = NewConnection(context.Background(), server.ReadWriteCloser(), jsonrpc2.HandlerWithError(handler.Handle), mapping)
+
+	// the handler is not called anymore once the connection is closed
+	go func() {
+		<-client.conn.DisconnectNotify()
+		close(d)
+		client.closeSubscribers()
+	}()
+
+	return client, d
 }
 
 func (c *ClientImpl) GetWorkspaceSymbols(ctx context.Context, params protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error) {
@@ -68,12 +202,320 @@ func (c *ClientImpl) GetWorkspaceSymbols(ctx context.Context, params protocol.Wo
 	return result, err
 }
 
-func (c *ClientImpl) Initialize(ctx context.Context, params protocol.InitializeParams) (protocol.InitializeResult, error) {
+func (c *ClientImpl) GetDefinition(ctx context.Context, params protocol.DefinitionParams) ([]protocol.Location, error) {
+	return c.callForLocations(ctx, "textDocument/definition", params)
+}
+
+func (c *ClientImpl) GetTypeDefinition(ctx context.Context, params protocol.TypeDefinitionParams) ([]protocol.Location, error) {
+	return c.callForLocations(ctx, "textDocument/typeDefinition", params)
+}
+
+func (c *ClientImpl) GetImplementation(ctx context.Context, params protocol.ImplementationParams) ([]protocol.Location, error) {
+	return c.callForLocations(ctx, "textDocument/implementation", params)
+}
+
+func (c *ClientImpl) GetReferences(ctx context.Context, params protocol.ReferenceParams) ([]protocol.Location, error) {
+	var result []protocol.Location
+	err := c.conn.Call(ctx, "textDocument/references", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) GetHover(ctx context.Context, params protocol.HoverParams) (*protocol.Hover, error) {
+	var result json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/hover", params, &result); err != nil {
+		return nil, err
+	}
+
+	return parseHover(result)
+}
+
+func (c *ClientImpl) GetSignatureHelp(ctx context.Context, params protocol.SignatureHelpParams) (*protocol.SignatureHelp, error) {
+	var result *protocol.SignatureHelp
+	err := c.conn.Call(ctx, "textDocument/signatureHelp", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) GetCompletion(ctx context.Context, params protocol.CompletionParams) (*protocol.CompletionList, error) {
+	var result json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/completion", params, &result); err != nil {
+		return nil, err
+	}
+
+	return parseCompletion(result)
+}
+
+func (c *ClientImpl) ResolveCompletionItem(ctx context.Context, item protocol.CompletionItem) (protocol.CompletionItem, error) {
+	var result protocol.CompletionItem
+	err := c.conn.Call(ctx, "completionItem/resolve", item, &result)
+	return result, err
+}
+
+func (c *ClientImpl) GetDocumentSymbols(ctx context.Context, params protocol.DocumentSymbolParams) ([]protocol.DocumentSymbol, error) {
+	var result json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/documentSymbol", params, &result); err != nil {
+		return nil, err
+	}
+
+	return parseDocumentSymbols(result)
+}
+
+func (c *ClientImpl) PrepareCallHierarchy(ctx context.Context, params protocol.CallHierarchyPrepareParams) ([]protocol.CallHierarchyItem, error) {
+	var result []protocol.CallHierarchyItem
+	err := c.conn.Call(ctx, "textDocument/prepareCallHierarchy", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) GetIncomingCalls(ctx context.Context, params protocol.CallHierarchyIncomingCallsParams) ([]protocol.CallHierarchyIncomingCall, error) {
+	var result []protocol.CallHierarchyIncomingCall
+	err := c.conn.Call(ctx, "callHierarchy/incomingCalls", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) GetOutgoingCalls(ctx context.Context, params protocol.CallHierarchyOutgoingCallsParams) ([]protocol.CallHierarchyOutgoingCall, error) {
+	var result []protocol.CallHierarchyOutgoingCall
+	err := c.conn.Call(ctx, "callHierarchy/outgoingCalls", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) PrepareTypeHierarchy(ctx context.Context, params TypeHierarchyPrepareParams) ([]TypeHierarchyItem, error) {
+	var result []TypeHierarchyItem
+	err := c.conn.Call(ctx, "textDocument/prepareTypeHierarchy", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) GetSupertypes(ctx context.Context, params TypeHierarchySupertypesParams) ([]TypeHierarchyItem, error) {
+	var result []TypeHierarchyItem
+	err := c.conn.Call(ctx, "typeHierarchy/supertypes", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) GetSubtypes(ctx context.Context, params TypeHierarchySubtypesParams) ([]TypeHierarchyItem, error) {
+	var result []TypeHierarchyItem
+	err := c.conn.Call(ctx, "typeHierarchy/subtypes", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) PrepareRename(ctx context.Context, params protocol.PrepareRenameParams) (*protocol.RangeWithPlaceholder, error) {
+	var result json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/prepareRename", params, &result); err != nil {
+		return nil, err
+	}
+
+	return parsePrepareRename(result)
+}
+
+func (c *ClientImpl) Rename(ctx context.Context, params protocol.RenameParams) (*protocol.WorkspaceEdit, error) {
+	var result json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/rename", params, &result); err != nil {
+		return nil, err
+	}
+
+	return parseWorkspaceEdit(result)
+}
+
+func (c *ClientImpl) GetCodeActions(ctx context.Context, params protocol.CodeActionParams) ([]protocol.CodeAction, error) {
+	var result json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/codeAction", params, &result); err != nil {
+		return nil, err
+	}
+
+	return parseCodeActions(result)
+}
+
+func (c *ClientImpl) FormatDocument(ctx context.Context, params protocol.DocumentFormattingParams) ([]protocol.TextEdit, error) {
+	var result []protocol.TextEdit
+	err := c.conn.Call(ctx, "textDocument/formatting", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) FormatRange(ctx context.Context, params protocol.DocumentRangeFormattingParams) ([]protocol.TextEdit, error) {
+	var result []protocol.TextEdit
+	err := c.conn.Call(ctx, "textDocument/rangeFormatting", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) OrganizeImports(ctx context.Context, params protocol.CodeActionParams) ([]protocol.CodeAction, error) {
+	params.Context.Only = []protocol.CodeActionKind{protocol.CodeActionKindSourceOrganizeImports}
+
+	actions, err := c.GetCodeActions(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// servers may ignore the requested kind, commands have no kind at all
+	result := []protocol.CodeAction{}
+	for _, action := range actions {
+		if action.Kind != nil && isCodeActionKind(*action.Kind, protocol.CodeActionKindSourceOrganizeImports) {
+			result = append(result, action)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *ClientImpl) ExecuteCommand(ctx context.Context, params protocol.ExecuteCommandParams) ([]protocol.WorkspaceEdit, error) {
+	_, edits, err := c.callWithEdits(ctx, "workspace/executeCommand", params)
+	return edits, err
+}
+
+func (c *ClientImpl) CallWithEdits(ctx context.Context, method string, params *json.RawMessage) (json.RawMessage, []protocol.WorkspaceEdit, error) {
+	return c.callWithEdits(ctx, method, rawParams(params))
+}
+
+// callWithEdits sends the request and collects the edits the server asks to apply until it answers.
+func (c *ClientImpl) callWithEdits(ctx context.Context, method string, params any) (json.RawMessage, []protocol.WorkspaceEdit, error) {
+	c.commandMu.Lock()
+	defer c.commandMu.Unlock()
+
+	c.editsMu.Lock()
+	c.edits = []protocol.WorkspaceEdit{}
+	c.editsMu.Unlock()
+
+	var result json.RawMessage
+	err := c.conn.Call(ctx, method, params, &result)
+
+	c.editsMu.Lock()
+	edits := c.edits
+	c.edits = nil
+	c.editsMu.Unlock()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, edits, nil
+}
+
+// applyEdit collects the edits of the running command, edits at other times are rejected because nobody would apply them
+func (c *ClientImpl) applyEdit(params protocol.ApplyWorkspaceEditParams) protocol.ApplyWorkspaceEditResponse {
+	c.editsMu.Lock()
+	defer c.editsMu.Unlock()
+
+	if c.edits == nil {
+		reason := "edits are only applied while executing a command"
+		return protocol.ApplyWorkspaceEditResponse{Applied: false, FailureReason: &reason}
+	}
+
+	c.edits = append(c.edits, params.Edit)
+	return protocol.ApplyWorkspaceEditResponse{Applied: true}
+}
+
+// callForLocations calls a method that returns Location | Location[] | LocationLink[] | null
+func (c *ClientImpl) callForLocations(ctx context.Context, method string, params interface{}) ([]protocol.Location, error) {
+	var result json.RawMessage
+	if err := c.conn.Call(ctx, method, params, &result); err != nil {
+		return nil, err
+	}
+
+	return parseLocations(result)
+}
+
+func (c *ClientImpl) Initialize(ctx context.Context, params InitializeParams) (protocol.InitializeResult, error) {
+	var raw json.RawMessage
+	if err := c.conn.Call(ctx, "initialize", params, &raw); err != nil {
+		return protocol.InitializeResult{}, err
+	}
+
 	var result protocol.InitializeResult
-	err := c.conn.Call(ctx, "initialize", params, &result)
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return protocol.InitializeResult{}, err
+	}
+
+	// glsp doesn't know the diagnostic provider, it is only parsed from the raw result
+	var capabilities struct {
+		Capabilities struct {
+			DiagnosticProvider *DiagnosticOptions `json:"diagnosticProvider"`
+		} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(raw, &capabilities); err != nil {
+		log.Warn().Err(err).Msg("Failed to parse diagnostic provider")
+	}
+
+	// the raw result keeps the capabilities glsp doesn't know, for clients that are passed through
+	c.initializeResult = raw
+	c.diagnosticProvider = capabilities.Capabilities.DiagnosticProvider
+	if provider := result.Capabilities.CompletionProvider; provider != nil && provider.ResolveProvider != nil {
+		c.completionResolveProvider = *provider.ResolveProvider
+	}
+	return result, nil
+}
+
+func (c *ClientImpl) InitializeResult() json.RawMessage {
+	return c.initializeResult
+}
+
+func (c *ClientImpl) DiagnosticProvider() *DiagnosticOptions {
+	return c.diagnosticProvider
+}
+
+func (c *ClientImpl) CompletionResolveProvider() bool {
+	return c.completionResolveProvider
+}
+
+func (c *ClientImpl) Call(ctx context.Context, method string, params *json.RawMessage) (json.RawMessage, error) {
+	var result json.RawMessage
+	err := c.conn.Call(ctx, method, rawParams(params), &result)
 	return result, err
 }
 
+func (c *ClientImpl) Notify(ctx context.Context, method string, params *json.RawMessage) error {
+	return c.conn.Notify(ctx, method, rawParams(params))
+}
+
+// rawParams returns nil for missing params, so that they are omitted instead of sent as null
+func rawParams(params *json.RawMessage) any {
+	if params == nil {
+		return nil
+	}
+	return params
+}
+
+func (c *ClientImpl) SubscribeNotifications() (<-chan Notification, func()) {
+	c.subscribersMu.Lock()
+	defer c.subscribersMu.Unlock()
+
+	notifications := make(chan Notification, notificationBuffer)
+	if c.closed {
+		close(notifications)
+		return notifications, func() {}
+	}
+
+	c.subscribers[notifications] = struct{}{}
+	return notifications, func() {
+		c.subscribersMu.Lock()
+		defer c.subscribersMu.Unlock()
+
+		if _, ok := c.subscribers[notifications]; ok {
+			delete(c.subscribers, notifications)
+			close(notifications)
+		}
+	}
+}
+
+func (c *ClientImpl) broadcast(notification Notification) {
+	c.subscribersMu.Lock()
+	defer c.subscribersMu.Unlock()
+
+	for subscriber := range c.subscribers {
+		select {
+		case subscriber <- notification:
+		default:
+			log.Warn().Str("method", notification.Method).Msg("Dropped notification of language server, subscriber is too slow")
+		}
+	}
+}
+
+func (c *ClientImpl) closeSubscribers() {
+	c.subscribersMu.Lock()
+	defer c.subscribersMu.Unlock()
+
+	for subscriber := range c.subscribers {
+		close(subscriber)
+	}
+	c.subscribers = nil
+	c.closed = true
+}
+
 func (c *ClientImpl) NotifyInitialized(ctx context.Context) error {
 	return c.conn.Notify(ctx, "initialized", nil)
 }
@@ -82,15 +524,33 @@ func (c *ClientImpl) NotifyDidOpen(ctx context.Context, params protocol.DidOpenT
 	return c.conn.Notify(ctx, "textDocument/didOpen", params)
 }
 
+func (c *ClientImpl) NotifyDidChange(ctx context.Context, params protocol.DidChangeTextDocumentParams) error {
+	return c.conn.Notify(ctx, "textDocument/didChange", params)
+}
+
 func (c *ClientImpl) NotifyDidClose(ctx context.Context, params protocol.DidCloseTextDocumentParams) error {
 	return c.conn.Notify(ctx, "textDocument/didClose", params)
 }
 
-// func (c *ClientImpl) PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error) {
-// 	var result DocumentDiagnosticReport
-// 	err := c.conn.Call(ctx, "textDocument/diagnostic", params, &result)
-// 	return result, err
-// }
+func (c *ClientImpl) NotifyDidChangeConfiguration(ctx context.Context, params protocol.DidChangeConfigurationParams) error {
+	return c.conn.Notify(ctx, "workspace/didChangeConfiguration", params)
+}
+
+func (c *ClientImpl) NotifyDidChangeWatchedFiles(ctx context.Context, params protocol.DidChangeWatchedFilesParams) error {
+	return c.conn.Notify(ctx, "workspace/didChangeWatchedFiles", params)
+}
+
+func (c *ClientImpl) PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error) {
+	var result DocumentDiagnosticReport
+	err := c.conn.Call(ctx, "textDocument/diagnostic", params, &result)
+	return result, err
+}
+
+func (c *ClientImpl) PullWorkspaceDiagnostics(ctx context.Context, params WorkspaceDiagnosticParams) (WorkspaceDiagnosticReport, error) {
+	var result WorkspaceDiagnosticReport
+	err := c.conn.Call(ctx, "workspace/diagnostic", params, &result)
+	return result, err
+}
 
 func (c *ClientImpl) Shutdown(ctx context.Context) error {
 	err := c.conn.Call(ctx, "shutdown", nil, nil)
@@ -105,7 +565,29 @@ func (c *ClientImpl) Shutdown(ctx context.Context) error {
 		return err
 	}
 
-	close(c.diagnosticsChannel)
-
 	return c.server.Wait()
 }
+
+// configurationSection returns the value of the dotted section of the settings, or all settings for an empty section.
+// Sections are looked up as a key first, then as a path of nested keys. Unknown sections are null.
+func configurationSection(settings any, section string) any {
+	if section == "" {
+		return settings
+	}
+
+	values, ok := settings.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	if value, ok := values[section]; ok {
+		return value
+	}
+
+	key, rest, ok := strings.Cut(section, ".")
+	if !ok {
+		return nil
+	}
+
+	return configurationSection(values[key], rest)
+}
@@ -0,0 +1,456 @@
+package lsp_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestClient_GetHover(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		want   *protocol.Hover
+	}{
+		{
+			name:   "markup content",
+			result: `{"contents":{"kind":"markdown","value":"**func** Foo()"},"range":{"start":{"line":1,"character":5},"end":{"line":1,"character":8}}}`,
+			want: &protocol.Hover{
+				Contents: protocol.MarkupContent{Kind: protocol.MarkupKindMarkdown, Value: "**func** Foo()"},
+				Range:    &protocol.Range{Start: protocol.Position{Line: 1, Character: 5}, End: protocol.Position{Line: 1, Character: 8}},
+			},
+		},
+		{
+			name:   "marked strings",
+			result: `{"contents":[{"language":"python","value":"def foo() -> None"},"Does foo."]}`,
+			want:   &protocol.Hover{Contents: protocol.MarkupContent{Kind: protocol.MarkupKindMarkdown, Value: "```python\ndef foo() -> None\n```\n\nDoes foo."}},
+		},
+		{
+			name:   "marked string",
+			result: `{"contents":"Does foo."}`,
+			want:   &protocol.Hover{Contents: protocol.MarkupContent{Kind: protocol.MarkupKindMarkdown, Value: "Does foo."}},
+		},
+		{
+			name:   "nothing to show",
+			result: `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+				return json.RawMessage(tt.result), nil
+			})
+
+			got, err := client.GetHover(context.Background(), protocol.HoverParams{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_GetCompletion(t *testing.T) {
+	function := protocol.CompletionItemKindFunction
+
+	tests := []struct {
+		name   string
+		result string
+		want   *protocol.CompletionList
+	}{
+		{
+			name:   "list",
+			result: `{"isIncomplete":true,"items":[{"label":"Println","kind":3,"detail":"func(a ...any) (n int, err error)","documentation":{"kind":"markdown","value":"Println formats"}}]}`,
+			want: &protocol.CompletionList{IsIncomplete: true, Items: []protocol.CompletionItem{{
+				Label:         "Println",
+				Kind:          &function,
+				Detail:        stringPointer("func(a ...any) (n int, err error)"),
+				Documentation: protocol.MarkupContent{Kind: protocol.MarkupKindMarkdown, Value: "Println formats"},
+			}}},
+		},
+		{
+			name:   "items",
+			result: `[{"label":"Println","documentation":"Println formats"}]`,
+			want:   &protocol.CompletionList{Items: []protocol.CompletionItem{{Label: "Println", Documentation: "Println formats"}}},
+		},
+		{
+			name:   "no completions",
+			result: `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+				return json.RawMessage(tt.result), nil
+			})
+
+			got, err := client.GetCompletion(context.Background(), protocol.CompletionParams{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_PrepareRename(t *testing.T) {
+	r := protocol.Range{Start: protocol.Position{Line: 1, Character: 5}, End: protocol.Position{Line: 1, Character: 8}}
+
+	tests := []struct {
+		name   string
+		result string
+		want   *protocol.RangeWithPlaceholder
+	}{
+		{
+			name:   "range",
+			result: `{"start":{"line":1,"character":5},"end":{"line":1,"character":8}}`,
+			want:   &protocol.RangeWithPlaceholder{Range: r},
+		},
+		{
+			name:   "range with placeholder",
+			result: `{"range":{"start":{"line":1,"character":5},"end":{"line":1,"character":8}},"placeholder":"foo"}`,
+			want:   &protocol.RangeWithPlaceholder{Range: r, Placeholder: "foo"},
+		},
+		{
+			name:   "default behavior",
+			result: `{"defaultBehavior":true}`,
+			want:   &protocol.RangeWithPlaceholder{},
+		},
+		{
+			name:   "not possible",
+			result: `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+				return json.RawMessage(tt.result), nil
+			})
+
+			got, err := client.PrepareRename(context.Background(), protocol.PrepareRenameParams{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_Rename(t *testing.T) {
+	edit := protocol.TextEdit{
+		Range:   protocol.Range{Start: protocol.Position{Line: 0, Character: 6}, End: protocol.Position{Line: 0, Character: 9}},
+		NewText: "Bar",
+	}
+
+	tests := []struct {
+		name   string
+		result string
+		want   *protocol.WorkspaceEdit
+	}{
+		{
+			name:   "changes",
+			result: `{"changes":{"file:///project/foo.py":[{"range":{"start":{"line":0,"character":6},"end":{"line":0,"character":9}},"newText":"Bar"}]}}`,
+			want:   &protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{"file:///project/foo.py": {edit}}},
+		},
+		{
+			name: "document changes",
+			result: `{"documentChanges":[
+				{"textDocument":{"uri":"file:///project/foo.py","version":1},"edits":[{"range":{"start":{"line":0,"character":6},"end":{"line":0,"character":9}},"newText":"Bar","annotationId":"rename"}]},
+				{"kind":"rename","oldUri":"file:///project/foo.py","newUri":"file:///project/bar.py"},
+				{"kind":"create","uri":"file:///project/new.py"},
+				{"kind":"delete","uri":"file:///project/old.py"}
+			]}`,
+			want: &protocol.WorkspaceEdit{DocumentChanges: []any{
+				protocol.TextDocumentEdit{
+					TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: "file:///project/foo.py"}, Version: intPointer(1)},
+					Edits:        []any{edit},
+				},
+				protocol.RenameFile{Kind: "rename", OldURI: "file:///project/foo.py", NewURI: "file:///project/bar.py"},
+				protocol.CreateFile{Kind: "create", URI: "file:///project/new.py"},
+				protocol.DeleteFile{Kind: "delete", URI: "file:///project/old.py"},
+			}},
+		},
+		{
+			name:   "nothing to change",
+			result: `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+				return json.RawMessage(tt.result), nil
+			})
+
+			got, err := client.Rename(context.Background(), protocol.RenameParams{NewName: "Bar"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_GetCodeActions(t *testing.T) {
+	client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+		return json.RawMessage(`[
+			{"title":"Organize imports","command":"source.organizeImports","arguments":["file:///project/main.go"]},
+			{"title":"Add import: fmt","kind":"quickfix","isPreferred":true,"diagnostics":[{"range":{"start":{"line":3,"character":1},"end":{"line":3,"character":4}},"message":"undefined: fmt"}],
+			 "edit":{"documentChanges":[{"textDocument":{"uri":"file:///project/main.go","version":null},"edits":[{"range":{"start":{"line":1,"character":0},"end":{"line":1,"character":0}},"newText":"import \"fmt\"\n"}]}]}},
+			{"title":"Extract function","kind":"refactor.extract","disabled":{"reason":"no statements selected"},"command":{"title":"Extract function","command":"gopls.extract_function"}}
+		]`), nil
+	})
+
+	got, err := client.GetCodeActions(context.Background(), protocol.CodeActionParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quickFix, refactor := protocol.CodeActionKindQuickFix, protocol.CodeActionKindRefactorExtract
+	preferred := true
+	assert.Equal(t, []protocol.CodeAction{
+		{Title: "Organize imports", Command: &protocol.Command{Title: "Organize imports", Command: "source.organizeImports", Arguments: []any{"file:///project/main.go"}}},
+		{
+			Title:       "Add import: fmt",
+			Kind:        &quickFix,
+			IsPreferred: &preferred,
+			Diagnostics: []protocol.Diagnostic{{Range: protocol.Range{Start: protocol.Position{Line: 3, Character: 1}, End: protocol.Position{Line: 3, Character: 4}}, Message: "undefined: fmt"}},
+			Edit: &protocol.WorkspaceEdit{DocumentChanges: []any{protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: "file:///project/main.go"}},
+				Edits:        []any{protocol.TextEdit{Range: protocol.Range{Start: protocol.Position{Line: 1}, End: protocol.Position{Line: 1}}, NewText: "import \"fmt\"\n"}},
+			}}},
+		},
+		{
+			Title: "Extract function",
+			Kind:  &refactor,
+			Disabled: &struct {
+				Reason string `json:"reason"`
+			}{Reason: "no statements selected"},
+			Command: &protocol.Command{Title: "Extract function", Command: "gopls.extract_function"},
+		},
+	}, got)
+}
+
+func TestClient_OrganizeImports(t *testing.T) {
+	var only []protocol.CodeActionKind
+	client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+		var codeActionParams protocol.CodeActionParams
+		if err := json.Unmarshal(params, &codeActionParams); err != nil {
+			return nil, err
+		}
+		only = codeActionParams.Context.Only
+
+		// the server ignores the requested kind
+		return json.RawMessage(`[
+			{"title":"Organize Imports","kind":"source.organizeImports","edit":{"changes":{"file:///project/main.go":[{"range":{"start":{"line":2,"character":0},"end":{"line":3,"character":0}},"newText":""}]}}},
+			{"title":"Add import: fmt","kind":"quickfix"},
+			{"title":"Organize imports","command":"source.organizeImports"}
+		]`), nil
+	})
+
+	got, err := client.OrganizeImports(context.Background(), protocol.CodeActionParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kind := protocol.CodeActionKindSourceOrganizeImports
+	assert.Equal(t, []protocol.CodeActionKind{kind}, only)
+	assert.Equal(t, []protocol.CodeAction{{
+		Title: "Organize Imports",
+		Kind:  &kind,
+		Edit: &protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+			"file:///project/main.go": {{Range: protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 3}}}},
+		}},
+	}}, got)
+}
+
+func TestClient_PullDiagnostics(t *testing.T) {
+	var capabilities json.RawMessage
+	client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+		switch method {
+		case "initialize":
+			var initializeParams struct {
+				Capabilities struct {
+					TextDocument struct {
+						Diagnostic json.RawMessage `json:"diagnostic"`
+					} `json:"textDocument"`
+				} `json:"capabilities"`
+			}
+			if err := json.Unmarshal(params, &initializeParams); err != nil {
+				return nil, err
+			}
+			capabilities = initializeParams.Capabilities.TextDocument.Diagnostic
+
+			return json.RawMessage(`{"capabilities":{"diagnosticProvider":{"identifier":"pyright","interFileDependencies":true,"workspaceDiagnostics":false}}}`), nil
+		case "textDocument/diagnostic":
+			return json.RawMessage(`{
+				"kind":"full",
+				"resultId":"1",
+				"items":[{"range":{"start":{"line":2,"character":0},"end":{"line":2,"character":3}},"message":"\"foo\" is not defined"}],
+				"relatedDocuments":{"file:///project/lib.py":{"kind":"unchanged","resultId":"2"}}
+			}`), nil
+		}
+		return nil, nil
+	})
+
+	assert.Nil(t, client.DiagnosticProvider(), "nothing is known before the server is initialized")
+
+	_, err := client.Initialize(context.Background(), lsp.InitializeParams{
+		Capabilities: lsp.ClientCapabilities{TextDocument: &lsp.TextDocumentClientCapabilities{Diagnostic: &lsp.DiagnosticClientCapabilities{}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the 3.17 capabilities are sent next to the ones glsp knows
+	assert.JSONEq(t, `{}`, string(capabilities))
+
+	identifier := "pyright"
+	assert.Equal(t, &lsp.DiagnosticOptions{Identifier: &identifier, InterFileDependencies: true}, client.DiagnosticProvider())
+
+	got, err := client.PullDiagnostics(context.Background(), lsp.DocumentDiagnosticParams{TextDocument: protocol.TextDocumentIdentifier{URI: "file:///project/main.py"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resultId, relatedResultId := "1", "2"
+	assert.Equal(t, lsp.DocumentDiagnosticReport{
+		Kind:     lsp.DocumentDiagnosticReportKindFull,
+		ResultID: &resultId,
+		Items:    []protocol.Diagnostic{{Range: protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 2, Character: 3}}, Message: `"foo" is not defined`}},
+		RelatedDocuments: map[protocol.DocumentUri]lsp.DocumentDiagnosticReport{
+			"file:///project/lib.py": {Kind: lsp.DocumentDiagnosticReportKindUnchanged, ResultID: &relatedResultId},
+		},
+	}, got)
+}
+
+func TestClient_ExecuteCommand(t *testing.T) {
+	var applyResponse protocol.ApplyWorkspaceEditResponse
+	client := newTestClientWithHandler(t, jsonrpc2.AsyncHandler(jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+		if req.Method != "workspace/executeCommand" {
+			return nil, nil
+		}
+
+		// the server applies its edit through the client before it answers
+		edit := json.RawMessage(`{"edit":{"changes":{"file:///project/go.mod":[{"range":{"start":{"line":2,"character":0},"end":{"line":3,"character":0}},"newText":""}]}}}`)
+		if err := conn.Call(ctx, "workspace/applyEdit", edit, &applyResponse); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})))
+
+	edits, err := client.ExecuteCommand(context.Background(), protocol.ExecuteCommandParams{Command: "gopls.tidy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, applyResponse.Applied)
+	assert.Equal(t, []protocol.WorkspaceEdit{{Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+		"file:///project/go.mod": {{Range: protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 3}}}},
+	}}}, edits)
+}
+
+func TestClient_Configuration(t *testing.T) {
+	settings := map[string]any{
+		"gopls":          map[string]any{"ui": map[string]any{"semanticTokens": true}},
+		"python.linting": map[string]any{"enabled": false},
+	}
+
+	var got []any
+	client := newTestClientWithSettings(t, jsonrpc2.AsyncHandler(jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+		if req.Method != "workspace/symbol" {
+			return nil, nil
+		}
+
+		// the server asks for its settings while it handles a request
+		params := protocol.ConfigurationParams{Items: []protocol.ConfigurationItem{
+			{Section: stringPointer("gopls.ui")},
+			{Section: stringPointer("python.linting")},
+			{Section: stringPointer("rust-analyzer")},
+			{},
+		}}
+		if err := conn.Call(ctx, "workspace/configuration", params, &got); err != nil {
+			return nil, err
+		}
+		return []protocol.SymbolInformation{}, nil
+	})), settings)
+
+	if _, err := client.GetWorkspaceSymbols(context.Background(), protocol.WorkspaceSymbolParams{Query: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []any{
+		map[string]any{"semanticTokens": true},
+		map[string]any{"enabled": false},
+		nil,
+		map[string]any{"gopls": map[string]any{"ui": map[string]any{"semanticTokens": true}}, "python.linting": map[string]any{"enabled": false}},
+	}, got)
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
+
+func intPointer(i protocol.Integer) *protocol.Integer {
+	return &i
+}
+
+// newTestClient returns a client connected to a server that answers requests with handle.
+func newTestClient(t *testing.T, handle func(method string, params json.RawMessage) (any, error)) lsp.Client {
+	t.Helper()
+
+	return newTestClientWithHandler(t, jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+		var params json.RawMessage
+		if req.Params != nil {
+			params = *req.Params
+		}
+		return handle(req.Method, params)
+	}))
+}
+
+// newTestClientWithHandler is like newTestClient, for servers that send requests to the client.
+func newTestClientWithHandler(t *testing.T, handler jsonrpc2.Handler) lsp.Client {
+	t.Helper()
+
+	return newTestClientWithSettings(t, handler, nil)
+}
+
+// newTestClientWithSettings is like newTestClientWithHandler, for servers that ask for their settings.
+func newTestClientWithSettings(t *testing.T, handler jsonrpc2.Handler, settings any) lsp.Client {
+	t.Helper()
+
+	clientConn, serverConn := net.Pipe()
+	t.Cleanup(func() {
+		clientConn.Close()
+		serverConn.Close()
+	})
+
+	server := jsonrpc2.NewConn(context.Background(), jsonrpc2.NewBufferedStream(serverConn, jsonrpc2.VSCodeObjectCodec{}), handler)
+	t.Cleanup(func() { server.Close() })
+
+	client, _ := lsp.NewClient(&testProcess{rwc: clientConn}, lsp.PathMapping{}, settings)
+	return client
+}
+
+type testProcess struct {
+	rwc io.ReadWriteCloser
+}
+
+func (p *testProcess) Start() error                        { return nil }
+func (p *testProcess) Stop() error                         { return p.rwc.Close() }
+func (p *testProcess) ReadWriteCloser() io.ReadWriteCloser { return p.rwc }
+func (p *testProcess) Wait() error                         { return nil }
@@ -0,0 +1,208 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// GetCodeActions implements Service.
+func (s *ServiceImpl) GetCodeActions(ctx context.Context, file model.File, rng Range) ([]CodeAction, error) {
+	_, _, actions, err := s.getCodeActions(ctx, file, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]CodeAction, 0, len(actions))
+	for _, action := range actions {
+		result = append(result, toCodeAction(action))
+	}
+
+	return result, nil
+}
+
+// GetCodeActionChanges implements Service.
+func (s *ServiceImpl) GetCodeActionChanges(ctx context.Context, file model.File, rng Range, title string, dryRun bool) ([]files.FileChange, error) {
+	project, client, actions, err := s.getCodeActions(ctx, file, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	var action *protocol.CodeAction
+	for i := range actions {
+		if actions[i].Title == title {
+			action = &actions[i]
+			break
+		}
+	}
+
+	if action == nil {
+		return nil, NewCodeActionNotFoundError(file.Path, title)
+	}
+
+	if action.Disabled != nil {
+		return nil, NewCodeActionDisabledError(title, action.Disabled.Reason)
+	}
+
+	return codeActionChanges(ctx, project, client, *action, dryRun)
+}
+
+// codeActionChanges returns the changes of the edit of the action and the edits its command asks to apply.
+// Commands are not executed with dryRun, the server may change its state or the files while it runs them.
+func codeActionChanges(ctx context.Context, project *model.Project, client Client, action protocol.CodeAction, dryRun bool) ([]files.FileChange, error) {
+	if dryRun && action.Command != nil {
+		return nil, NewCodeActionNotPreviewableError(action.Title, action.Command.Command)
+	}
+
+	changes := []files.FileChange{}
+	if action.Edit != nil {
+		var err error
+		if changes, err = toFileChanges(project, *action.Edit); err != nil {
+			log.Error().Err(err).Str("projectId", project.Id).Str("title", action.Title).Msg("Failed to convert code action edit")
+			return nil, fmt.Errorf("Failed to convert code action edit: %w", err)
+		}
+	}
+
+	// the edit is applied before the command, the command sends the edits it wants to apply back to the client
+	if action.Command != nil {
+		edits, err := client.ExecuteCommand(ctx, protocol.ExecuteCommandParams{Command: action.Command.Command, Arguments: action.Command.Arguments})
+		if err != nil {
+			log.Error().Err(err).Str("projectId", project.Id).Str("command", action.Command.Command).Msg("Failed to execute command")
+			return nil, fmt.Errorf("Failed to execute command %s: %w", action.Command.Command, err)
+		}
+
+		for _, edit := range edits {
+			commandChanges, err := toFileChanges(project, edit)
+			if err != nil {
+				log.Error().Err(err).Str("projectId", project.Id).Str("command", action.Command.Command).Msg("Failed to convert command edit")
+				return nil, fmt.Errorf("Failed to convert command edit: %w", err)
+			}
+			changes = append(changes, commandChanges...)
+		}
+	}
+
+	return changes, nil
+}
+
+// getCodeActions requests the code actions for the range, with the known diagnostics of the range as context.
+func (s *ServiceImpl) getCodeActions(ctx context.Context, file model.File, rng Range) (*model.Project, Client, []protocol.CodeAction, error) {
+	project, client, err := s.getClientForFile(ctx, file)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	protocolRange := protocol.Range{Start: toProtocolPosition(rng.Start), End: toProtocolPosition(rng.End)}
+
+	diagnostics := []protocol.Diagnostic{}
+	if stored, ok := s.diagnosticsStore.Get(project.Id, PathToURI(filepath.Join(project.Path, file.Path))); ok {
+		for _, diagnostic := range stored {
+			if rangesOverlap(diagnostic.Range, protocolRange) {
+				diagnostics = append(diagnostics, diagnostic)
+			}
+		}
+	}
+
+	actions, err := client.GetCodeActions(ctx, protocol.CodeActionParams{
+		TextDocument: textDocument(project, file),
+		Range:        protocolRange,
+		Context:      protocol.CodeActionContext{Diagnostics: diagnostics},
+	})
+	if err != nil {
+		log.Error().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to get code actions")
+		return nil, nil, nil, fmt.Errorf("Failed to get code actions: %w", err)
+	}
+
+	return project, client, actions, nil
+}
+
+func toCodeAction(action protocol.CodeAction) CodeAction {
+	result := CodeAction{Title: action.Title}
+
+	if action.Kind != nil {
+		result.Kind = *action.Kind
+	}
+
+	if action.IsPreferred != nil {
+		result.IsPreferred = *action.IsPreferred
+	}
+
+	if action.Disabled != nil {
+		result.Disabled = action.Disabled.Reason
+	}
+
+	if action.Command != nil {
+		result.Command = action.Command.Command
+	}
+
+	for _, diagnostic := range action.Diagnostics {
+		result.Diagnostics = append(result.Diagnostics, diagnostic.Message)
+	}
+
+	return result
+}
+
+// rangesOverlap also counts ranges that touch as overlapping, so that an empty range at a position matches the diagnostics around it.
+func rangesOverlap(a, b protocol.Range) bool {
+	return comparePositions(a.Start, b.End) <= 0 && comparePositions(b.Start, a.End) <= 0
+}
+
+// parseCodeActions parses a result that is a list of Commands and CodeActions, or null.
+func parseCodeActions(data json.RawMessage) ([]protocol.CodeAction, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse code actions: %w", err)
+	}
+
+	actions := make([]protocol.CodeAction, 0, len(items))
+	for _, item := range items {
+		var value struct {
+			protocol.CodeAction
+			// the command of a Command is a string, the command of a CodeAction is a Command
+			Command json.RawMessage `json:"command"`
+			Edit    json.RawMessage `json:"edit"`
+		}
+		if err := json.Unmarshal(item, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse code action: %w", err)
+		}
+
+		action := value.CodeAction
+		if bytes.HasPrefix(bytes.TrimSpace(value.Command), []byte(`"`)) {
+			var command protocol.Command
+			if err := json.Unmarshal(item, &command); err != nil {
+				return nil, fmt.Errorf("failed to parse command: %w", err)
+			}
+			actions = append(actions, protocol.CodeAction{Title: command.Title, Command: &command})
+			continue
+		}
+
+		if len(value.Command) > 0 {
+			if err := json.Unmarshal(value.Command, &action.Command); err != nil {
+				return nil, fmt.Errorf("failed to parse code action command: %w", err)
+			}
+		}
+
+		if len(value.Edit) > 0 {
+			edit, err := parseWorkspaceEdit(value.Edit)
+			if err != nil {
+				return nil, err
+			}
+			action.Edit = edit
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
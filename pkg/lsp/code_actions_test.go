@@ -0,0 +1,138 @@
+package lsp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestService_GetCodeActions(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+
+	unused := protocol.Diagnostic{Range: protocol.Range{Start: protocol.Position{Line: 4, Character: 1}, End: protocol.Position{Line: 4, Character: 2}}, Message: "declared and not used: x"}
+	other := protocol.Diagnostic{Range: protocol.Range{Start: protocol.Position{Line: 9, Character: 1}, End: protocol.Position{Line: 9, Character: 2}}, Message: "undefined: y"}
+
+	diagnosticsStore := lsp.NewDiagnosticsStore()
+	diagnosticsStore.Set("project-id", "file:///test/project/main.go", []protocol.Diagnostic{unused, other})
+
+	quickFix := protocol.CodeActionKindQuickFix
+	client := &mocks.MockClient{}
+	client.On("GetCodeActions", mock.MatchedBy(isContext), protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: "file:///test/project/main.go"},
+		Range:        protocol.Range{Start: protocol.Position{Line: 4, Character: 1}, End: protocol.Position{Line: 4, Character: 1}},
+		Context:      protocol.CodeActionContext{Diagnostics: []protocol.Diagnostic{unused}},
+	}).Return([]protocol.CodeAction{
+		{Title: "Remove variable x", Kind: &quickFix, Diagnostics: []protocol.Diagnostic{unused}},
+		{Title: "Organize imports", Command: &protocol.Command{Command: "source.organizeImports"}},
+	}, nil)
+
+	clientPool := &mocks.MockClientPool{}
+	clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), diagnosticsStore, clientPool, nil)
+
+	position := lsp.Position{Line: 5, Character: 1}
+	got, err := service.GetCodeActions(ctx, *model.NewFile("main.go", "package main"), lsp.Range{Start: position, End: position})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []lsp.CodeAction{
+		{Title: "Remove variable x", Kind: "quickfix", Diagnostics: []string{"declared and not used: x"}},
+		{Title: "Organize imports", Command: "source.organizeImports"},
+	}, got)
+}
+
+func TestService_GetCodeActionChanges(t *testing.T) {
+	edit := protocol.TextEdit{
+		Range:   protocol.Range{Start: protocol.Position{Line: 4, Character: 0}, End: protocol.Position{Line: 5, Character: 0}},
+		NewText: "",
+	}
+	command := &protocol.Command{Command: "gopls.tidy", Arguments: []any{"file:///test/project/go.mod"}}
+
+	tests := []struct {
+		name    string
+		title   string
+		dryRun  bool
+		want    []files.FileChange
+		wantErr error
+	}{
+		{
+			name:  "edit",
+			title: "Remove variable x",
+			want:  []files.FileChange{{Path: "main.go", Edits: []files.TextEdit{{StartLine: 5, EndLine: 6}}}},
+		},
+		{
+			name:  "edit and command",
+			title: "Remove variable x and tidy",
+			want: []files.FileChange{
+				{Path: "main.go", Edits: []files.TextEdit{{StartLine: 5, EndLine: 6}}},
+				{Path: "go.mod", Edits: []files.TextEdit{{StartLine: 5, EndLine: 6}}},
+			},
+		},
+		{
+			name:   "edit with dry run",
+			title:  "Remove variable x",
+			dryRun: true,
+			want:   []files.FileChange{{Path: "main.go", Edits: []files.TextEdit{{StartLine: 5, EndLine: 6}}}},
+		},
+		{
+			name:    "command with dry run",
+			title:   "Remove variable x and tidy",
+			dryRun:  true,
+			wantErr: lsp.NewCodeActionNotPreviewableError("Remove variable x and tidy", "gopls.tidy"),
+		},
+		{
+			name:    "disabled",
+			title:   "Extract function",
+			wantErr: lsp.NewCodeActionDisabledError("Extract function", "no statements selected"),
+		},
+		{
+			name:    "not found",
+			title:   "Inline call",
+			wantErr: lsp.NewCodeActionNotFoundError("main.go", "Inline call"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+
+			client := &mocks.MockClient{}
+			client.On("GetCodeActions", mock.MatchedBy(isContext), mock.Anything).Return([]protocol.CodeAction{
+				{Title: "Remove variable x", Edit: &protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{"file:///test/project/main.go": {edit}}}},
+				{Title: "Remove variable x and tidy", Edit: &protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{"file:///test/project/main.go": {edit}}}, Command: command},
+				{Title: "Extract function", Disabled: &struct {
+					Reason string `json:"reason"`
+				}{Reason: "no statements selected"}},
+			}, nil)
+			client.On("ExecuteCommand", mock.MatchedBy(isContext), protocol.ExecuteCommandParams{Command: command.Command, Arguments: command.Arguments}).Return([]protocol.WorkspaceEdit{
+				{Changes: map[protocol.DocumentUri][]protocol.TextEdit{"file:///test/project/go.mod": {edit}}},
+			}, nil)
+
+			clientPool := &mocks.MockClientPool{}
+			clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+			service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), lsp.NewDiagnosticsStore(), clientPool, nil)
+
+			position := lsp.Position{Line: 5, Character: 1}
+			got, err := service.GetCodeActionChanges(ctx, *model.NewFile("main.go", "package main"), lsp.Range{Start: position, End: position}, tt.title, tt.dryRun)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
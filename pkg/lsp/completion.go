@@ -0,0 +1,139 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// maxConcurrentResolves limits the completion items that are resolved at the same time
+const maxConcurrentResolves = 8
+
+// GetCompletions implements Service.
+func (s *ServiceImpl) GetCompletions(ctx context.Context, file model.File, position Position, limit int) (*CompletionList, error) {
+	project, client, err := s.getClientForFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	completions, err := client.GetCompletion(ctx, protocol.CompletionParams{
+		TextDocumentPositionParams: textDocumentPosition(project, file, position),
+		Context:                    &protocol.CompletionContext{TriggerKind: protocol.CompletionTriggerKindInvoked},
+	})
+	if err != nil {
+		log.Error().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to get completions")
+		return nil, fmt.Errorf("Failed to get completions: %w", err)
+	}
+
+	result := &CompletionList{Items: []CompletionItem{}}
+	if completions == nil {
+		return result, nil
+	}
+
+	items := completions.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return sortText(items[i]) < sortText(items[j])
+	})
+
+	result.IsIncomplete = completions.IsIncomplete
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+		result.IsIncomplete = true
+	}
+
+	if client.CompletionResolveProvider() {
+		items = resolveCompletionItems(ctx, project, file, client, items)
+	}
+
+	for _, item := range items {
+		result.Items = append(result.Items, toCompletionItem(item))
+	}
+
+	return result, nil
+}
+
+// resolveCompletionItems resolves the items without detail or documentation, servers can leave them out until the item is resolved.
+// Items that fail to resolve are returned as they are.
+func resolveCompletionItems(ctx context.Context, project *model.Project, file model.File, client Client, items []protocol.CompletionItem) []protocol.CompletionItem {
+	resolved := make([]protocol.CompletionItem, len(items))
+	copy(resolved, items)
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxConcurrentResolves)
+	for i, item := range items {
+		if item.Detail != nil && item.Documentation != nil {
+			continue
+		}
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, item protocol.CompletionItem) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result, err := client.ResolveCompletionItem(ctx, item)
+			if err != nil {
+				log.Warn().Err(err).Str("projectId", project.Id).Str("path", file.Path).Str("label", item.Label).Msg("Failed to resolve completion item")
+				return
+			}
+
+			resolved[i] = result
+		}(i, item)
+	}
+
+	wg.Wait()
+	return resolved
+}
+
+// sortText is the text that sorts the item among the other items, it defaults to the label.
+func sortText(item protocol.CompletionItem) string {
+	if item.SortText != nil {
+		return *item.SortText
+	}
+
+	return item.Label
+}
+
+func toCompletionItem(item protocol.CompletionItem) CompletionItem {
+	result := CompletionItem{Label: item.Label, Documentation: documentationToText(item.Documentation)}
+
+	if item.Kind != nil {
+		result.Kind = completionItemKindToString(*item.Kind)
+	}
+
+	if item.Detail != nil {
+		result.Detail = *item.Detail
+	}
+
+	return result
+}
+
+// parseCompletion parses a result that is a list of items, a completion list or null. A list of items is returned as a complete completion list.
+func parseCompletion(data json.RawMessage) (*protocol.CompletionList, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	if data[0] == '[' {
+		var items []protocol.CompletionItem
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("failed to parse completion items: %w", err)
+		}
+		return &protocol.CompletionList{Items: items}, nil
+	}
+
+	var list protocol.CompletionList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse completion list: %w", err)
+	}
+
+	return &list, nil
+}
@@ -0,0 +1,111 @@
+package lsp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestService_GetCompletions(t *testing.T) {
+	method, field := protocol.CompletionItemKindMethod, protocol.CompletionItemKindField
+	first, second := "00001", "00002"
+	detail := "func(s string) int"
+
+	println := protocol.CompletionItem{Label: "Println", Kind: &method, SortText: &second}
+	printf := protocol.CompletionItem{Label: "Printf", Kind: &method, SortText: &first, Detail: &detail, Documentation: protocol.MarkupContent{Kind: protocol.MarkupKindMarkdown, Value: "Printf **formats**"}}
+	name := protocol.CompletionItem{Label: "Name", Kind: &field, Detail: &detail, Documentation: "The name"}
+
+	tests := []struct {
+		name        string
+		completions *protocol.CompletionList
+		limit       int
+		resolve     bool
+		resolveErr  error
+		want        *lsp.CompletionList
+	}{
+		{
+			name:        "sorted and resolved",
+			completions: &protocol.CompletionList{Items: []protocol.CompletionItem{println, printf}},
+			resolve:     true,
+			want: &lsp.CompletionList{Items: []lsp.CompletionItem{
+				{Label: "Printf", Kind: "Method", Detail: "func(s string) int", Documentation: "Printf formats"},
+				{Label: "Println", Kind: "Method", Detail: "func(a ...any)", Documentation: "Println formats"},
+			}},
+		},
+		{
+			name:        "limit",
+			completions: &protocol.CompletionList{Items: []protocol.CompletionItem{println, printf}},
+			limit:       1,
+			resolve:     true,
+			want: &lsp.CompletionList{IsIncomplete: true, Items: []lsp.CompletionItem{
+				{Label: "Printf", Kind: "Method", Detail: "func(s string) int", Documentation: "Printf formats"},
+			}},
+		},
+		{
+			name:        "resolve not supported",
+			completions: &protocol.CompletionList{IsIncomplete: true, Items: []protocol.CompletionItem{println, name}},
+			want: &lsp.CompletionList{IsIncomplete: true, Items: []lsp.CompletionItem{
+				{Label: "Println", Kind: "Method"},
+				{Label: "Name", Kind: "Field", Detail: "func(s string) int", Documentation: "The name"},
+			}},
+		},
+		{
+			name:        "resolve failed",
+			completions: &protocol.CompletionList{Items: []protocol.CompletionItem{println, name}},
+			resolve:     true,
+			resolveErr:  errors.New("item expired"),
+			want: &lsp.CompletionList{Items: []lsp.CompletionItem{
+				{Label: "Println", Kind: "Method"},
+				{Label: "Name", Kind: "Field", Detail: "func(s string) int", Documentation: "The name"},
+			}},
+		},
+		{
+			name: "no completions",
+			want: &lsp.CompletionList{Items: []lsp.CompletionItem{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+
+			resolvedDetail := "func(a ...any)"
+			resolved := println
+			resolved.Detail = &resolvedDetail
+			resolved.Documentation = "Println formats"
+
+			client := &mocks.MockClient{}
+			client.On("GetCompletion", mock.MatchedBy(isContext), protocol.CompletionParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{URI: "file:///test/project/main.go"},
+					Position:     protocol.Position{Line: 4, Character: 5},
+				},
+				Context: &protocol.CompletionContext{TriggerKind: protocol.CompletionTriggerKindInvoked},
+			}).Return(tt.completions, nil)
+			client.On("CompletionResolveProvider").Return(tt.resolve)
+			client.On("ResolveCompletionItem", mock.MatchedBy(isContext), println).Return(resolved, tt.resolveErr)
+
+			clientPool := &mocks.MockClientPool{}
+			clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+			service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), nil, clientPool, nil)
+
+			got, err := service.GetCompletions(ctx, *model.NewFile("main.go", "package main"), lsp.Position{Line: 5, Character: 5}, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+			if !tt.resolve {
+				client.AssertNotCalled(t, "ResolveCompletionItem", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
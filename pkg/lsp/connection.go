@@ -10,15 +10,17 @@ import (
 type Connection interface {
 	Call(ctx context.Context, method string, params interface{}, result interface{}) error
 	Notify(ctx context.Context, method string, params interface{}) error
+	// DisconnectNotify returns a channel that is closed when the connection is closed, e.g. when the server exited
+	DisconnectNotify() <-chan struct{}
 }
 
 type ConnectionImpl struct {
 	conn *jsonrpc2.Conn
 }
 
-func NewConnection(ctx context.Context, rwc io.ReadWriteCloser, handler jsonrpc2.Handler) Connection {
+func NewConnection(ctx context.Context, rwc io.ReadWriteCloser, handler jsonrpc2.Handler, mapping PathMapping) Connection {
 	// TODO: understand codecs
-	stream := jsonrpc2.NewBufferedStream(rwc, jsonrpc2.VSCodeObjectCodec{})
+	stream := newPathMappingStream(jsonrpc2.NewBufferedStream(rwc, jsonrpc2.VSCodeObjectCodec{}), mapping)
 	conn := jsonrpc2.NewConn(ctx, stream, handler)
 	return &ConnectionImpl{conn: conn}
 }
@@ -32,3 +34,8 @@ func (c *ConnectionImpl) Call(ctx context.Context, method string, params interfa
 func (c *ConnectionImpl) Notify(ctx context.Context, method string, params interface{}) error {
 	return c.conn.Notify(ctx, method, params)
 }
+
+// DisconnectNotify implements Connection.
+func (c *ConnectionImpl) DisconnectNotify() <-chan struct{} {
+	return c.conn.DisconnectNotify()
+}
@@ -0,0 +1,198 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// killTimeout bounds the exec that kills a language server left running after its exec connection is closed.
+const killTimeout = 5 * time.Second
+
+// pidScript prints the PID of the shell before replacing it with the language server, so the server keeps the PID.
+const pidScript = `echo $$; exec "$@"`
+
+// ContainerProcessFactory runs language servers inside the project container, so they use the toolchain and dependencies of the container.
+type ContainerProcessFactory struct {
+	containerManager devcontainer.ContainerManager
+}
+
+func NewContainerProcessFactory(containerManager devcontainer.ContainerManager) ProcessFactory {
+	return &ContainerProcessFactory{containerManager: containerManager}
+}
+
+func (f *ContainerProcessFactory) NewProcess(project model.Project, command Command, stderr io.Writer) (Process, PathMapping, error) {
+	if project.ContainerId == "" {
+		return nil, PathMapping{}, fmt.Errorf("Project %s has no container", project.Id)
+	}
+
+	config := project.Config.DevContainerConfig
+	mapping := PathMapping{Host: devcontainer.WorkspaceMountSource(config, project.Path), Server: devcontainer.WorkspaceMountTarget(config)}
+
+	workingDir := mapping.Server
+	if config.WorkspaceFolder != "" {
+		workingDir = config.WorkspaceFolder
+	}
+
+	return NewContainerProcess(f.containerManager, project.ContainerId, command, workingDir, stderr), mapping, nil
+}
+
+// ContainerProcess is a language server started with docker exec, communicating over its stdin and stdout.
+type ContainerProcess struct {
+	containerManager devcontainer.ContainerManager
+	containerId      string
+	command          Command
+	workingDir       string
+	stdin            *io.PipeReader
+	stdout           *io.PipeWriter
+	stderr           io.Writer
+	rwc              io.ReadWriteCloser
+	cancel           context.CancelFunc
+	done             chan struct{}
+	err              error
+	pid              *pidWriter
+}
+
+func NewContainerProcess(containerManager devcontainer.ContainerManager, containerId string, command Command, workingDir string, stderr io.Writer) *ContainerProcess {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	return &ContainerProcess{
+		containerManager: containerManager,
+		containerId:      containerId,
+		command:          command,
+		workingDir:       workingDir,
+		stdin:            stdinReader,
+		stdout:           stdoutWriter,
+		stderr:           stderr,
+		rwc:              &readWriteCloser{stdoutReader, stdinWriter},
+	}
+}
+
+func (p *ContainerProcess) Start() error {
+	if p.done != nil {
+		return errors.New("Language server already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	p.pid = &pidWriter{w: p.stdout}
+
+	cmd := append([]string{"sh", "-c", pidScript, "sh", p.command.name}, p.command.args...)
+
+	go func() {
+		defer close(p.done)
+
+		exitCode, err := p.containerManager.ExecStream(ctx, p.containerId, cmd, p.workingDir, p.stdin, p.pid, p.stderr)
+		if err == nil && exitCode != 0 {
+			err = fmt.Errorf("Language server %s exited with code %d", p.command.name, exitCode)
+		}
+
+		p.err = err
+		// unblock the connection reading from the server and the writer to its stdin
+		p.stdout.CloseWithError(err)
+		p.stdin.Close()
+	}()
+
+	return nil
+}
+
+func (p *ContainerProcess) Stop() error {
+	if p.cancel == nil {
+		return nil
+	}
+
+	p.cancel()
+
+	// closing the exec connection does not stop the process in the container, so kill it by its PID
+	pid, ok := p.pid.get()
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), killTimeout)
+	defer cancel()
+
+	result, err := p.containerManager.Exec(ctx, p.containerId, []string{"kill", strconv.Itoa(pid)})
+	if err != nil {
+		return fmt.Errorf("Failed to kill language server %s: %w", p.command.name, err)
+	}
+
+	if result.ExitCode != 0 {
+		// the server has most likely exited already
+		log.Debug().Str("containerId", p.containerId).Int("pid", pid).Str("stderr", result.StdErr).Msg("Failed to kill language server")
+	}
+
+	return nil
+}
+
+func (p *ContainerProcess) ReadWriteCloser() io.ReadWriteCloser {
+	return p.rwc
+}
+
+func (p *ContainerProcess) Wait() error {
+	if p.done == nil {
+		return errors.New("Language server not started")
+	}
+
+	<-p.done
+	return p.err
+}
+
+// pidWriter reads the PID printed by pidScript from the first line of the output and forwards the rest to w.
+type pidWriter struct {
+	w    io.Writer
+	mu   sync.Mutex
+	line []byte
+	pid  int
+	read bool
+}
+
+func (p *pidWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	if p.read {
+		p.mu.Unlock()
+		return p.w.Write(b)
+	}
+
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		p.line = append(p.line, b...)
+		p.mu.Unlock()
+		return len(b), nil
+	}
+
+	p.line = append(p.line, b[:i]...)
+	p.read = true
+	if pid, err := strconv.Atoi(string(bytes.TrimSpace(p.line))); err == nil {
+		p.pid = pid
+	}
+	p.mu.Unlock()
+
+	if i+1 == len(b) {
+		return len(b), nil
+	}
+
+	n, err := p.w.Write(b[i+1:])
+	return i + 1 + n, err
+}
+
+func (p *pidWriter) get() (int, bool) {
+	if p == nil {
+		return 0, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pid, p.pid > 0
+}
@@ -0,0 +1,154 @@
+package lsp_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/devcontainer"
+	dcmocks "github.com/hide-org/hide/pkg/devcontainer/mocks"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestContainerProcess_TranslatesPaths(t *testing.T) {
+	project := model.Project{
+		Id:          "project-id",
+		Path:        "/home/user/.hide/projects/project-id",
+		ContainerId: "container-id",
+		Config: model.Config{DevContainerConfig: devcontainer.Config{DockerImageProps: devcontainer.DockerImageProps{
+			WorkspaceMount:  &devcontainer.Mount{Source: "/home/user/.hide/projects/project-id", Destination: "/workspaces/app"},
+			WorkspaceFolder: "/workspaces/app/src",
+		}}},
+	}
+
+	received := make(chan protocol.InitializeParams, 1)
+	killed := make(chan []string, 1)
+
+	// testify mocks format their arguments, which races with the pipes used by the process
+	containerManager := &fakeContainerManager{execStream: func(ctx context.Context, containerId string, command []string, workingDir string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+		assert.Equal(t, "container-id", containerId)
+		assert.Equal(t, []string{"sh", "-c", `echo $$; exec "$@"`, "sh", "gopls"}, command)
+		assert.Equal(t, "/workspaces/app/src", workingDir)
+
+		if _, err := io.WriteString(stdout, "42\n"); err != nil {
+			return 0, err
+		}
+
+		rwc := &serverStdio{Reader: stdin, Writer: stdout}
+		conn := jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(rwc, jsonrpc2.VSCodeObjectCodec{}), jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+			if req.Method != "initialize" {
+				return nil, nil
+			}
+
+			var params protocol.InitializeParams
+			if err := json.Unmarshal(*req.Params, &params); err != nil {
+				return nil, err
+			}
+			received <- params
+
+			go conn.Notify(ctx, "textDocument/publishDiagnostics", protocol.PublishDiagnosticsParams{URI: "file:///workspaces/app/src/main.go"})
+			return protocol.InitializeResult{}, nil
+		}))
+		<-ctx.Done()
+		conn.Close()
+		return 0, ctx.Err()
+	}, exec: func(ctx context.Context, containerId string, command []string) (devcontainer.ExecResult, error) {
+		killed <- command
+		return devcontainer.ExecResult{}, nil
+	}}
+
+	process, mapping, err := lsp.NewContainerProcessFactory(containerManager).NewProcess(project, lsp.NewCommand("gopls", []string{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, lsp.PathMapping{Host: project.Path, Server: "/workspaces/app"}, mapping)
+
+	if err := process.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	client, diagnostics := lsp.NewClient(process, mapping, nil)
+
+	root := lsp.PathToURI(project.Path)
+	other := lsp.PathToURI(project.Path + "2")
+	if _, err := client.Initialize(context.Background(), lsp.InitializeParams{InitializeParams: protocol.InitializeParams{
+		RootURI:          &root,
+		WorkspaceFolders: []protocol.WorkspaceFolder{{URI: other}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	params := <-received
+	assert.Equal(t, protocol.DocumentUri("file:///workspaces/app"), *params.RootURI)
+	assert.Equal(t, other, params.WorkspaceFolders[0].URI, "only whole path segments are translated")
+
+	select {
+	case d := <-diagnostics:
+		assert.Equal(t, protocol.DocumentUri("file:///home/user/.hide/projects/project-id/src/main.go"), d.URI)
+	case <-time.After(time.Second):
+		t.Fatal("no diagnostics received")
+	}
+
+	if err := process.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Error(t, process.Wait())
+	assert.Equal(t, []string{"kill", "42"}, <-killed, "the server is killed in the container")
+}
+
+func TestContainerProcessFactory_WorkspaceMount(t *testing.T) {
+	project := model.Project{
+		Id:          "project-id",
+		Path:        "/home/user/.hide/projects/project-id",
+		ContainerId: "container-id",
+		Config: model.Config{DevContainerConfig: devcontainer.Config{DockerImageProps: devcontainer.DockerImageProps{
+			WorkspaceMount:  &devcontainer.Mount{Source: "/home/user/src/app", Destination: "/workspaces/app"},
+			WorkspaceFolder: "/workspaces/app",
+		}}},
+	}
+
+	_, mapping, err := lsp.NewContainerProcessFactory(&dcmocks.MockContainerManager{}).NewProcess(project, lsp.NewCommand("gopls", []string{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, lsp.PathMapping{Host: "/home/user/src/app", Server: "/workspaces/app"}, mapping)
+
+	project.Config.DevContainerConfig = devcontainer.Config{}
+	_, mapping, err = lsp.NewContainerProcessFactory(&dcmocks.MockContainerManager{}).NewProcess(project, lsp.NewCommand("gopls", []string{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, lsp.PathMapping{Host: project.Path, Server: devcontainer.DefaultWorkingDir}, mapping)
+}
+
+func TestContainerProcessFactory_NoContainer(t *testing.T) {
+	_, _, err := lsp.NewContainerProcessFactory(&dcmocks.MockContainerManager{}).NewProcess(model.Project{Id: "project-id"}, lsp.NewCommand("gopls", []string{}), nil)
+	assert.Error(t, err)
+}
+
+type fakeContainerManager struct {
+	devcontainer.ContainerManager
+	execStream func(ctx context.Context, containerId string, command []string, workingDir string, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+	exec       func(ctx context.Context, containerId string, command []string) (devcontainer.ExecResult, error)
+}
+
+func (f *fakeContainerManager) Exec(ctx context.Context, containerId string, command []string) (devcontainer.ExecResult, error) {
+	return f.exec(ctx, containerId, command)
+}
+
+func (f *fakeContainerManager) ExecStream(ctx context.Context, containerId string, command []string, workingDir string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	return f.execStream(ctx, containerId, command, workingDir, stdin, stdout, stderr)
+}
+
+type serverStdio struct {
+	io.Reader
+	io.Writer
+}
+
+func (s *serverStdio) Close() error { return nil }
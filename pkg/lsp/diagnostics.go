@@ -25,6 +25,19 @@ func (d *DiagnosticsStore) Get(projectId ProjectId, uri protocol.DocumentUri) ([
 	return nil, false
 }
 
+// GetAllForProject returns a copy of the diagnostics of all files of the project.
+func (d *DiagnosticsStore) GetAllForProject(projectId ProjectId) map[protocol.DocumentUri][]protocol.Diagnostic {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := make(map[protocol.DocumentUri][]protocol.Diagnostic, len(d.diagnostics[projectId]))
+	for uri, diagnostics := range d.diagnostics[projectId] {
+		result[uri] = diagnostics
+	}
+
+	return result
+}
+
 func (d *DiagnosticsStore) Set(projectId ProjectId, uri protocol.DocumentUri, diagnostics []protocol.Diagnostic) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
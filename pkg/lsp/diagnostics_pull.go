@@ -0,0 +1,148 @@
+package lsp
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// workspaceDiagnosticsTimeout bounds the workspace/diagnostic request, servers may take long to check a whole project
+const workspaceDiagnosticsTimeout = 30 * time.Second
+
+// pullDiagnostics requests the diagnostics of the file from a server that supports pull diagnostics. They are stored like
+// published diagnostics, together with the diagnostics of the related documents the server reports.
+func (s *ServiceImpl) pullDiagnostics(ctx context.Context, project *model.Project, client Client, file model.File) error {
+	uri := PathToURI(filepath.Join(project.Path, file.Path))
+	report, err := client.PullDiagnostics(ctx, DocumentDiagnosticParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Identifier:   client.DiagnosticProvider().Identifier,
+	})
+	if err != nil {
+		return requestError(project, file, "pull diagnostics", err)
+	}
+
+	s.storeDiagnosticReport(project.Id, uri, report)
+	for related, relatedReport := range report.RelatedDocuments {
+		s.storeDiagnosticReport(project.Id, related, relatedReport)
+	}
+
+	return nil
+}
+
+// pullWorkspaceDiagnostics refreshes the stored diagnostics of the project from the servers that support workspace diagnostics.
+// The result ids of the previous pull are sent along, so that the servers only report the documents whose diagnostics changed.
+// Failures are only logged, the diagnostics the servers published are still known.
+func (s *ServiceImpl) pullWorkspaceDiagnostics(ctx context.Context, project *model.Project) {
+	clients := s.getClients(ctx)
+	s.resultIds.retain(project.Id, clients)
+
+	for _, client := range clients {
+		provider := client.DiagnosticProvider()
+		if provider == nil || !provider.WorkspaceDiagnostics {
+			continue
+		}
+
+		pullCtx, cancel := context.WithTimeout(ctx, workspaceDiagnosticsTimeout)
+		report, err := client.PullWorkspaceDiagnostics(pullCtx, WorkspaceDiagnosticParams{
+			Identifier:        provider.Identifier,
+			PreviousResultIDs: s.resultIds.previous(project.Id, client),
+		})
+		cancel()
+
+		if err != nil {
+			log.Warn().Err(err).Str("projectId", project.Id).Msg("Failed to pull workspace diagnostics")
+			continue
+		}
+
+		for _, item := range report.Items {
+			s.storeDiagnosticReport(project.Id, item.URI, item.DocumentDiagnosticReport)
+			s.resultIds.set(project.Id, client, item.URI, item.ResultID)
+		}
+	}
+}
+
+// resultIdStore keeps the result ids of the workspace diagnostics each server of a project reported last.
+type resultIdStore struct {
+	mu  sync.Mutex
+	ids map[ProjectId]map[Client]map[protocol.DocumentUri]string
+}
+
+func newResultIdStore() *resultIdStore {
+	return &resultIdStore{ids: make(map[ProjectId]map[Client]map[protocol.DocumentUri]string)}
+}
+
+// previous returns the result ids the server reported for the documents of the project, ordered by document.
+func (r *resultIdStore) previous(projectId ProjectId, client Client) []PreviousResultID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := []PreviousResultID{}
+	for uri, value := range r.ids[projectId][client] {
+		previous = append(previous, PreviousResultID{URI: uri, Value: value})
+	}
+
+	sort.Slice(previous, func(i, j int) bool {
+		return previous[i].URI < previous[j].URI
+	})
+
+	return previous
+}
+
+// set stores the result id of the report of the document, a report without result id forgets the previous one.
+func (r *resultIdStore) set(projectId ProjectId, client Client, uri protocol.DocumentUri, resultId *string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if resultId == nil {
+		delete(r.ids[projectId][client], uri)
+		return
+	}
+
+	if _, ok := r.ids[projectId]; !ok {
+		r.ids[projectId] = make(map[Client]map[protocol.DocumentUri]string)
+	}
+	if _, ok := r.ids[projectId][client]; !ok {
+		r.ids[projectId][client] = make(map[protocol.DocumentUri]string)
+	}
+	r.ids[projectId][client][uri] = *resultId
+}
+
+// retain forgets the result ids of servers of the project that are no longer running, e.g. because they were restarted.
+func (r *resultIdStore) retain(projectId ProjectId, clients []Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for client := range r.ids[projectId] {
+		if !slices.Contains(clients, client) {
+			delete(r.ids[projectId], client)
+		}
+	}
+}
+
+func (r *resultIdStore) deleteAllForProject(projectId ProjectId) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.ids, projectId)
+}
+
+// storeDiagnosticReport stores the diagnostics of a full report, the stored diagnostics are still valid if the report is unchanged.
+func (s *ServiceImpl) storeDiagnosticReport(projectId ProjectId, uri protocol.DocumentUri, report DocumentDiagnosticReport) {
+	if report.Kind != DocumentDiagnosticReportKindFull {
+		return
+	}
+
+	diagnostics := report.Items
+	if diagnostics == nil {
+		diagnostics = []protocol.Diagnostic{}
+	}
+
+	s.updateDiagnostics(projectId, protocol.PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
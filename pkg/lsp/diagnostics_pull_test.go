@@ -0,0 +1,166 @@
+package lsp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestService_WaitForDiagnostics_Pull(t *testing.T) {
+	uri := protocol.DocumentUri("file:///test/project/main.go")
+	libURI := protocol.DocumentUri("file:///test/project/lib.go")
+	stale := []protocol.Diagnostic{{Message: "undefined: foo"}}
+	undefined := []protocol.Diagnostic{{Message: "undefined: bar"}}
+	unused := []protocol.Diagnostic{{Message: "x declared and not used"}}
+
+	tests := []struct {
+		name        string
+		report      lsp.DocumentDiagnosticReport
+		err         error
+		timeout     time.Duration
+		want        []protocol.Diagnostic
+		wantRelated []protocol.Diagnostic
+	}{
+		{
+			name: "full",
+			report: lsp.DocumentDiagnosticReport{
+				Kind:             lsp.DocumentDiagnosticReportKindFull,
+				Items:            undefined,
+				RelatedDocuments: map[protocol.DocumentUri]lsp.DocumentDiagnosticReport{libURI: {Kind: lsp.DocumentDiagnosticReportKindFull, Items: unused}},
+			},
+			// the pulled diagnostics are returned right away, published ones would only come after the timeout
+			timeout:     time.Minute,
+			want:        undefined,
+			wantRelated: unused,
+		},
+		{
+			name:    "fixed",
+			report:  lsp.DocumentDiagnosticReport{Kind: lsp.DocumentDiagnosticReportKindFull},
+			timeout: time.Minute,
+			want:    []protocol.Diagnostic{},
+		},
+		{
+			name:    "unchanged",
+			report:  lsp.DocumentDiagnosticReport{Kind: lsp.DocumentDiagnosticReportKindUnchanged},
+			timeout: time.Minute,
+			want:    stale,
+		},
+		{
+			name:    "not supported",
+			err:     &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: "method not found"},
+			timeout: 10 * time.Millisecond,
+			want:    stale,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+			identifier := "gopls"
+
+			diagnosticsStore := lsp.NewDiagnosticsStore()
+			diagnosticsStore.Set("project-id", uri, stale)
+
+			client := &mocks.MockClient{}
+			client.On("NotifyDidOpen", mock.MatchedBy(isContext), mock.Anything).Return(nil)
+			client.On("DiagnosticProvider").Return(&lsp.DiagnosticOptions{Identifier: &identifier})
+			client.On("PullDiagnostics", mock.MatchedBy(isContext), lsp.DocumentDiagnosticParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+				Identifier:   &identifier,
+			}).Return(tt.report, tt.err)
+
+			clientPool := &mocks.MockClientPool{}
+			clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+			service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), diagnosticsStore, clientPool, nil)
+
+			got, err := service.WaitForDiagnostics(ctx, *model.NewFile("main.go", "package main"), tt.timeout)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+
+			related, _ := diagnosticsStore.Get("project-id", libURI)
+			assert.Equal(t, tt.wantRelated, related)
+			client.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_GetProjectDiagnostics_Workspace(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+	undefined := protocol.Diagnostic{Message: "undefined: foo"}
+	mainResultID, fixedResultID := "1", "2"
+
+	diagnosticsStore := lsp.NewDiagnosticsStore()
+	diagnosticsStore.Set("project-id", "file:///test/project/fixed.go", []protocol.Diagnostic{undefined})
+
+	// the diagnostics of the whole workspace are pulled, also of files that were never opened
+	client := &mocks.MockClient{}
+	client.On("DiagnosticProvider").Return(&lsp.DiagnosticOptions{WorkspaceDiagnostics: true})
+	client.On("PullWorkspaceDiagnostics", mock.MatchedBy(isContext), lsp.WorkspaceDiagnosticParams{PreviousResultIDs: []lsp.PreviousResultID{}}).Return(lsp.WorkspaceDiagnosticReport{
+		Items: []lsp.WorkspaceDocumentDiagnosticReport{
+			{URI: "file:///test/project/main.go", DocumentDiagnosticReport: lsp.DocumentDiagnosticReport{Kind: lsp.DocumentDiagnosticReportKindFull, ResultID: &mainResultID, Items: []protocol.Diagnostic{undefined}}},
+			{URI: "file:///test/project/fixed.go", DocumentDiagnosticReport: lsp.DocumentDiagnosticReport{Kind: lsp.DocumentDiagnosticReportKindFull, ResultID: &fixedResultID}},
+		},
+	}, nil).Once()
+
+	// servers without workspace diagnostics are not asked
+	pythonClient := &mocks.MockClient{}
+	pythonClient.On("DiagnosticProvider").Return(nil)
+
+	clientPool := lsp.NewClientPool()
+	clientPool.Set("project-id", lsp.Go, client)
+	clientPool.Set("project-id", lsp.Python, pythonClient)
+
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), diagnosticsStore, clientPool, nil)
+
+	// without refresh only the known diagnostics are returned
+	got, err := service.GetProjectDiagnostics(ctx, lsp.DiagnosticsFilter{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []lsp.FileDiagnostics{{Path: "fixed.go", Counts: lsp.DiagnosticsCount{Errors: 1}, Diagnostics: []protocol.Diagnostic{undefined}}}, got.Files)
+	client.AssertNotCalled(t, "PullWorkspaceDiagnostics", mock.Anything, mock.Anything)
+
+	want := &lsp.DiagnosticsReport{
+		Summary: lsp.DiagnosticsCount{Errors: 1},
+		Files:   []lsp.FileDiagnostics{{Path: "main.go", Counts: lsp.DiagnosticsCount{Errors: 1}, Diagnostics: []protocol.Diagnostic{undefined}}},
+	}
+
+	got, err = service.GetProjectDiagnostics(ctx, lsp.DiagnosticsFilter{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, want, got)
+
+	// the next refresh sends the result ids, so that the server only reports what changed
+	client.On("PullWorkspaceDiagnostics", mock.MatchedBy(isContext), lsp.WorkspaceDiagnosticParams{PreviousResultIDs: []lsp.PreviousResultID{
+		{URI: "file:///test/project/fixed.go", Value: fixedResultID},
+		{URI: "file:///test/project/main.go", Value: mainResultID},
+	}}).Return(lsp.WorkspaceDiagnosticReport{
+		Items: []lsp.WorkspaceDocumentDiagnosticReport{
+			{URI: "file:///test/project/main.go", DocumentDiagnosticReport: lsp.DocumentDiagnosticReport{Kind: lsp.DocumentDiagnosticReportKindUnchanged, ResultID: &mainResultID}},
+		},
+	}, nil).Once()
+
+	got, err = service.GetProjectDiagnostics(ctx, lsp.DiagnosticsFilter{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, want, got)
+	client.AssertExpectations(t)
+	pythonClient.AssertExpectations(t)
+}
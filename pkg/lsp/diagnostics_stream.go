@@ -0,0 +1,150 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// diagnosticsBuffer is how many events a subscriber can fall behind before its subscription is closed
+const diagnosticsBuffer = 256
+
+// SubscribeDiagnostics implements Service.
+func (s *ServiceImpl) SubscribeDiagnostics(ctx context.Context, filter DiagnosticsFilter) (<-chan FileDiagnostics, error) {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		log.Error().Msg("Project not found in context")
+		return nil, fmt.Errorf("Project not found in context")
+	}
+
+	matchPath, err := filter.Paths.Matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	subscriber := &diagnosticsSubscriber{
+		project:   *project,
+		filter:    filter,
+		matchPath: matchPath,
+		reported:  make(map[string]bool),
+	}
+
+	s.subscriptions.subscribe(project.Id, subscriber, func() map[protocol.DocumentUri][]protocol.Diagnostic {
+		return s.diagnosticsStore.GetAllForProject(project.Id)
+	})
+
+	go func() {
+		<-ctx.Done()
+		s.subscriptions.unsubscribe(project.Id, subscriber)
+	}()
+
+	return subscriber.events, nil
+}
+
+type diagnosticsSubscriber struct {
+	project   model.Project
+	filter    DiagnosticsFilter
+	matchPath func(path string) bool
+	events    chan FileDiagnostics
+	// reported are the paths whose last event had diagnostics, only those get an event when their diagnostics are cleared
+	reported map[string]bool
+}
+
+// event converts published diagnostics to the event of the subscriber, it returns false if the subscriber isn't interested in them.
+func (s *diagnosticsSubscriber) event(uri protocol.DocumentUri, diagnostics []protocol.Diagnostic) (FileDiagnostics, bool) {
+	path, err := uriToProjectPath(&s.project, uri)
+	if err != nil || !s.matchPath(path) {
+		return FileDiagnostics{}, false
+	}
+
+	file := filterDiagnostics(path, diagnostics, s.filter)
+	if len(file.Diagnostics) == 0 && !s.reported[path] {
+		return FileDiagnostics{}, false
+	}
+
+	return file, true
+}
+
+// send queues the event without blocking, it returns false if the subscriber has fallen too far behind.
+func (s *diagnosticsSubscriber) send(event FileDiagnostics) bool {
+	select {
+	case s.events <- event:
+		s.reported[event.Path] = len(event.Diagnostics) > 0
+		return true
+	default:
+		return false
+	}
+}
+
+// diagnosticsSubscriptions fans the diagnostics the language servers publish out to the subscribers of the project.
+type diagnosticsSubscriptions struct {
+	mu          sync.Mutex
+	subscribers map[ProjectId]map[*diagnosticsSubscriber]struct{}
+}
+
+func newDiagnosticsSubscriptions() *diagnosticsSubscriptions {
+	return &diagnosticsSubscriptions{subscribers: make(map[ProjectId]map[*diagnosticsSubscriber]struct{})}
+}
+
+// subscribe sends the current diagnostics to the subscriber before it gets the published ones. They are read while the
+// subscriptions are locked, so that no diagnostics published in between are missed.
+func (d *diagnosticsSubscriptions) subscribe(projectId ProjectId, subscriber *diagnosticsSubscriber, current func() map[protocol.DocumentUri][]protocol.Diagnostic) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	diagnostics := current()
+	subscriber.events = make(chan FileDiagnostics, diagnosticsBuffer+len(diagnostics))
+	for uri, fileDiagnostics := range diagnostics {
+		if event, ok := subscriber.event(uri, fileDiagnostics); ok {
+			subscriber.send(event)
+		}
+	}
+
+	if _, ok := d.subscribers[projectId]; !ok {
+		d.subscribers[projectId] = make(map[*diagnosticsSubscriber]struct{})
+	}
+	d.subscribers[projectId][subscriber] = struct{}{}
+}
+
+func (d *diagnosticsSubscriptions) unsubscribe(projectId ProjectId, subscriber *diagnosticsSubscriber) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.subscribers[projectId][subscriber]; ok {
+		delete(d.subscribers[projectId], subscriber)
+		close(subscriber.events)
+	}
+}
+
+// publish sends the diagnostics to the subscribers of the project. The subscription of a subscriber that has fallen too far behind
+// is closed rather than dropping the event, so that it never misses a change; it can subscribe again to get the current diagnostics.
+func (d *diagnosticsSubscriptions) publish(projectId ProjectId, uri protocol.DocumentUri, diagnostics []protocol.Diagnostic) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for subscriber := range d.subscribers[projectId] {
+		event, ok := subscriber.event(uri, diagnostics)
+		if !ok || subscriber.send(event) {
+			continue
+		}
+
+		log.Warn().Str("projectId", projectId).Str("path", event.Path).Msg("Closed diagnostics subscription, subscriber is too slow")
+		delete(d.subscribers[projectId], subscriber)
+		close(subscriber.events)
+	}
+}
+
+// closeAllForProject ends the subscriptions of a project that is cleaned up.
+func (d *diagnosticsSubscriptions) closeAllForProject(projectId ProjectId) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for subscriber := range d.subscribers[projectId] {
+		close(subscriber.events)
+	}
+	delete(d.subscribers, projectId)
+}
@@ -0,0 +1,126 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestService_SubscribeDiagnostics(t *testing.T) {
+	project := model.Project{Id: "project-id", Path: "/test/project"}
+	errorSeverity, warningSeverity := protocol.DiagnosticSeverityError, protocol.DiagnosticSeverityWarning
+	undefined := protocol.Diagnostic{Severity: &errorSeverity, Message: "undefined: foo"}
+	unused := protocol.Diagnostic{Severity: &warningSeverity, Message: "x declared and not used"}
+
+	publish := func(service *ServiceImpl, path string, diagnostics ...protocol.Diagnostic) {
+		service.updateDiagnostics(project.Id, protocol.PublishDiagnosticsParams{URI: PathToURI(project.Path + "/" + path), Diagnostics: diagnostics})
+	}
+
+	newService := func() *ServiceImpl {
+		service := NewService(NewLanguageDetector(), NewRegistry(), NewDiagnosticsStore(), NewClientPool(), nil).(*ServiceImpl)
+		publish(service, "main.go", undefined)
+		publish(service, "lib.go", unused)
+		return service
+	}
+
+	t.Run("current and published diagnostics", func(t *testing.T) {
+		service := newService()
+		ctx, cancel := context.WithCancel(model.NewContextWithProject(context.Background(), &project))
+		defer cancel()
+
+		events, err := service.SubscribeDiagnostics(ctx, DiagnosticsFilter{Severities: []protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the warnings of lib.go are filtered out
+		assert.Equal(t, FileDiagnostics{Path: "main.go", Counts: DiagnosticsCount{Errors: 1}, Diagnostics: []protocol.Diagnostic{undefined}}, receive(t, events))
+
+		publish(service, "lib.go", undefined)
+		assert.Equal(t, FileDiagnostics{Path: "lib.go", Counts: DiagnosticsCount{Errors: 1}, Diagnostics: []protocol.Diagnostic{undefined}}, receive(t, events))
+
+		// files without reported diagnostics don't get an event when they are cleared
+		publish(service, "util.go")
+		publish(service, "main.go", unused)
+		assert.Equal(t, FileDiagnostics{Path: "main.go", Diagnostics: []protocol.Diagnostic{}}, receive(t, events))
+
+		publish(service, "lib.go")
+		assert.Equal(t, FileDiagnostics{Path: "lib.go", Diagnostics: []protocol.Diagnostic{}}, receive(t, events))
+
+		cancel()
+		assertClosed(t, events)
+	})
+
+	t.Run("closed when the project is cleaned up", func(t *testing.T) {
+		service := newService()
+		ctx := model.NewContextWithProject(context.Background(), &project)
+
+		events, err := service.SubscribeDiagnostics(ctx, DiagnosticsFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := service.CleanupProject(ctx, project.Id); err != nil {
+			t.Fatal(err)
+		}
+
+		// the current diagnostics are still delivered before the channel is closed
+		receive(t, events)
+		receive(t, events)
+		assertClosed(t, events)
+	})
+
+	t.Run("closed when the subscriber is too slow", func(t *testing.T) {
+		service := newService()
+		ctx, cancel := context.WithCancel(model.NewContextWithProject(context.Background(), &project))
+		defer cancel()
+
+		events, err := service.SubscribeDiagnostics(ctx, DiagnosticsFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i <= diagnosticsBuffer; i++ {
+			publish(service, fmt.Sprintf("file%d.go", i), undefined)
+		}
+
+		// the current diagnostics and the buffered events are delivered, the event that didn't fit ends the subscription
+		for i := 0; i < 2+diagnosticsBuffer; i++ {
+			receive(t, events)
+		}
+		assertClosed(t, events)
+	})
+}
+
+func receive(t *testing.T, events <-chan FileDiagnostics) FileDiagnostics {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("events closed")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for diagnostics")
+		return FileDiagnostics{}
+	}
+}
+
+func assertClosed(t *testing.T, events <-chan FileDiagnostics) {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		if ok {
+			t.Fatalf("unexpected event %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("events not closed")
+	}
+}
@@ -0,0 +1,316 @@
+package lsp
+
+import (
+	"sync"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// maxOpenDocuments is how many documents a project keeps open in its language servers, the least recently used ones are closed
+const maxOpenDocuments = 100
+
+// documentStore tracks the documents that are open in the language servers, so that changes are sent with increasing versions
+// and callers can wait for the diagnostics of a version.
+//
+// Notifications of a document are sent while the lock of the document is held, so that they reach the server in order,
+// but not the lock of the store, so that slow servers don't block the other documents and the published diagnostics.
+type documentStore struct {
+	documents map[ProjectId]map[protocol.DocumentUri]*document
+	// limit is the number of open documents of a project before the least recently used ones are evicted
+	limit int
+	// clock orders the uses of the documents
+	clock uint64
+	mu    sync.Mutex
+}
+
+type document struct {
+	// mu is held while notifications of the document are sent
+	mu sync.Mutex
+
+	// the fields below are guarded by the lock of the store, they are only changed while mu is held as well
+	languageId LanguageId
+	open       bool
+	version    protocol.Integer
+	content    string
+	// published is closed when the diagnostics of the version are published
+	published chan struct{}
+	// closed is set once the document is removed from the store, a document that is synced again gets a new entry
+	closed bool
+	// evicting is set once the document is picked to be closed, so that it isn't picked twice
+	evicting bool
+	used     uint64
+}
+
+// evictedDocument is a document that has to be closed, because the project has too many open documents.
+type evictedDocument struct {
+	uri        protocol.DocumentUri
+	languageId LanguageId
+}
+
+func newDocumentStore() *documentStore {
+	return &documentStore{documents: make(map[ProjectId]map[protocol.DocumentUri]*document), limit: maxOpenDocuments}
+}
+
+// sync opens the document or updates its content. notify is called while the document is locked, so that notifications are sent
+// in the order of their versions. If notify fails, the document is left as it was.
+// The returned channel is closed when the diagnostics of the content are published. The evicted documents are the least recently used
+// documents of the project beyond the limit, they have to be closed by the caller.
+func (s *documentStore) sync(projectId ProjectId, uri protocol.DocumentUri, languageId LanguageId, content string, notify func(version protocol.Integer, open bool) error) (<-chan struct{}, []evictedDocument, error) {
+	for {
+		doc := s.lockDocument(projectId, uri, languageId)
+		published, evicted, ok, err := s.syncLocked(projectId, uri, doc, content, notify)
+		doc.mu.Unlock()
+
+		// the document was closed while waiting for its lock, it is opened again as a new document
+		if ok {
+			return published, evicted, err
+		}
+	}
+}
+
+// lockDocument returns the locked entry of the document, it is added if the document isn't in the store yet.
+func (s *documentStore) lockDocument(projectId ProjectId, uri protocol.DocumentUri, languageId LanguageId) *document {
+	s.mu.Lock()
+	if _, ok := s.documents[projectId]; !ok {
+		s.documents[projectId] = make(map[protocol.DocumentUri]*document)
+	}
+
+	doc, ok := s.documents[projectId][uri]
+	if !ok {
+		doc = &document{languageId: languageId}
+		s.documents[projectId][uri] = doc
+	}
+
+	s.clock++
+	doc.used = s.clock
+	s.mu.Unlock()
+
+	doc.mu.Lock()
+	return doc
+}
+
+// syncLocked syncs the locked document, it returns false if the document was closed in the meantime.
+func (s *documentStore) syncLocked(projectId ProjectId, uri protocol.DocumentUri, doc *document, content string, notify func(version protocol.Integer, open bool) error) (<-chan struct{}, []evictedDocument, bool, error) {
+	s.mu.Lock()
+	if doc.closed {
+		s.mu.Unlock()
+		return nil, nil, false, nil
+	}
+
+	if doc.open && doc.content == content {
+		published := doc.published
+		s.mu.Unlock()
+		return published, nil, true, nil
+	}
+
+	// the new version is set before the notification is sent, so that diagnostics the server publishes right away belong to it
+	previousVersion, previousContent, previousPublished := doc.version, doc.content, doc.published
+	open := !doc.open
+	doc.open = true
+	doc.version++
+	doc.content = content
+	doc.published = make(chan struct{})
+	version, published := doc.version, doc.published
+	s.mu.Unlock()
+
+	err := notify(version, open)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		doc.version, doc.content, doc.published = previousVersion, previousContent, previousPublished
+		if open {
+			doc.open = false
+			s.remove(projectId, uri, doc)
+		}
+		return nil, nil, true, err
+	}
+
+	var evicted []evictedDocument
+	if open {
+		evicted = s.evict(projectId, uri)
+	}
+
+	return published, evicted, true, nil
+}
+
+// evict picks the least recently used open documents of the project beyond the limit, except the document that was just opened.
+func (s *documentStore) evict(projectId ProjectId, uri protocol.DocumentUri) []evictedDocument {
+	open := 0
+	for _, doc := range s.documents[projectId] {
+		if doc.open && !doc.evicting {
+			open++
+		}
+	}
+
+	var evicted []evictedDocument
+	for ; open > s.limit; open-- {
+		var oldestURI protocol.DocumentUri
+		var oldest *document
+		for candidateURI, doc := range s.documents[projectId] {
+			if candidateURI == uri || !doc.open || doc.evicting {
+				continue
+			}
+			if oldest == nil || doc.used < oldest.used {
+				oldestURI, oldest = candidateURI, doc
+			}
+		}
+
+		if oldest == nil {
+			break
+		}
+
+		oldest.evicting = true
+		evicted = append(evicted, evictedDocument{uri: oldestURI, languageId: oldest.languageId})
+	}
+
+	return evicted
+}
+
+// remove forgets the document, the store must be locked.
+func (s *documentStore) remove(projectId ProjectId, uri protocol.DocumentUri, doc *document) {
+	doc.closed = true
+	if s.documents[projectId][uri] == doc {
+		delete(s.documents[projectId], uri)
+	}
+}
+
+// withDocument calls fn while the document is locked, so that it doesn't change until fn returns. fn is called right away if the document isn't in the store.
+func (s *documentStore) withDocument(projectId ProjectId, uri protocol.DocumentUri, fn func() error) error {
+	s.mu.Lock()
+	doc, ok := s.documents[projectId][uri]
+	s.mu.Unlock()
+
+	if !ok {
+		return fn()
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+
+	return fn()
+}
+
+// isOpen returns true if the document is open.
+func (s *documentStore) isOpen(projectId ProjectId, uri protocol.DocumentUri) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.documents[projectId][uri]
+	return ok && doc.open
+}
+
+// content returns the content of the open document.
+func (s *documentStore) content(projectId ProjectId, uri protocol.DocumentUri) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.documents[projectId][uri]
+	if !ok || !doc.open {
+		return "", false
+	}
+	return doc.content, true
+}
+
+// published marks the diagnostics of the version as published. Servers that don't send versions publish the diagnostics of the latest version.
+func (s *documentStore) published(projectId ProjectId, uri protocol.DocumentUri, version *protocol.UInteger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.documents[projectId][uri]
+	if !ok || !doc.open || (version != nil && protocol.Integer(*version) < doc.version) {
+		return
+	}
+
+	select {
+	case <-doc.published:
+	default:
+		close(doc.published)
+	}
+}
+
+// close forgets the document and calls notify with the language it was open in, while the document is locked.
+// It returns false if the document wasn't open.
+func (s *documentStore) close(projectId ProjectId, uri protocol.DocumentUri, notify func(languageId LanguageId) error) (bool, error) {
+	s.mu.Lock()
+	doc, ok := s.documents[projectId][uri]
+	s.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+
+	s.mu.Lock()
+	if doc.closed || !doc.open {
+		s.mu.Unlock()
+		return false, nil
+	}
+	languageId := doc.languageId
+	s.mu.Unlock()
+
+	// the document is forgotten even if the notification fails, the server most likely went away
+	err := notify(languageId)
+
+	s.mu.Lock()
+	s.remove(projectId, uri, doc)
+	s.mu.Unlock()
+
+	return true, err
+}
+
+// reopen calls open for the documents of the language, e.g. to open them in a restarted server. Documents that fail to open are forgotten.
+func (s *documentStore) reopen(projectId ProjectId, languageId LanguageId, open func(uri protocol.DocumentUri, version protocol.Integer, content string) error) {
+	s.mu.Lock()
+	docs := make(map[protocol.DocumentUri]*document)
+	for uri, doc := range s.documents[projectId] {
+		if doc.languageId == languageId {
+			docs[uri] = doc
+		}
+	}
+	s.mu.Unlock()
+
+	for uri, doc := range docs {
+		doc.mu.Lock()
+
+		// the version and the content only change while the document is locked
+		s.mu.Lock()
+		reopen := !doc.closed && doc.open
+		version, content := doc.version, doc.content
+		s.mu.Unlock()
+
+		if reopen {
+			if err := open(uri, version, content); err != nil {
+				s.mu.Lock()
+				s.remove(projectId, uri, doc)
+				s.mu.Unlock()
+			}
+		}
+
+		doc.mu.Unlock()
+	}
+}
+
+func (s *documentStore) deleteAllForLanguage(projectId ProjectId, languageId LanguageId) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for uri, doc := range s.documents[projectId] {
+		if doc.languageId == languageId {
+			s.remove(projectId, uri, doc)
+		}
+	}
+}
+
+func (s *documentStore) deleteAllForProject(projectId ProjectId) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for uri, doc := range s.documents[projectId] {
+		s.remove(projectId, uri, doc)
+	}
+	delete(s.documents, projectId)
+}
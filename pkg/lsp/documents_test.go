@@ -0,0 +1,169 @@
+package lsp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestDocumentStore_Sync(t *testing.T) {
+	type notification struct {
+		version protocol.Integer
+		open    bool
+	}
+
+	var got []notification
+	notify := func(version protocol.Integer, open bool) error {
+		got = append(got, notification{version, open})
+		return nil
+	}
+
+	store := newDocumentStore()
+	for _, content := range []string{"a", "a", "b", "c"} {
+		if _, _, err := store.sync("project-id", "file:///main.go", Go, content, notify); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := store.sync("project-id", "file:///main.go", Go, "d", func(protocol.Integer, bool) error { return errors.New("connection closed") }); err == nil {
+		t.Fatal("want error")
+	}
+
+	if _, _, err := store.sync("project-id", "file:///main.go", Go, "d", notify); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []notification{{1, true}, {2, false}, {3, false}, {4, false}}, got)
+}
+
+func TestDocumentStore_Published(t *testing.T) {
+	version := func(v protocol.UInteger) *protocol.UInteger { return &v }
+
+	tests := []struct {
+		name          string
+		version       *protocol.UInteger
+		wantPublished bool
+	}{
+		{name: "current version", version: version(2), wantPublished: true},
+		{name: "old version", version: version(1), wantPublished: false},
+		{name: "no version", version: nil, wantPublished: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newDocumentStore()
+			notify := func(protocol.Integer, bool) error { return nil }
+
+			if _, _, err := store.sync("project-id", "file:///main.go", Go, "a", notify); err != nil {
+				t.Fatal(err)
+			}
+
+			published, _, err := store.sync("project-id", "file:///main.go", Go, "b", notify)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			store.published("project-id", "file:///main.go", tt.version)
+
+			select {
+			case <-published:
+				assert.True(t, tt.wantPublished, "published")
+			default:
+				assert.False(t, tt.wantPublished, "not published")
+			}
+		})
+	}
+}
+
+func TestDocumentStore_Evict(t *testing.T) {
+	notify := func(protocol.Integer, bool) error { return nil }
+
+	store := newDocumentStore()
+	store.limit = 2
+
+	for _, uri := range []protocol.DocumentUri{"file:///a.go", "file:///b.go", "file:///a.go"} {
+		if _, evicted, err := store.sync("project-id", uri, Go, "a", notify); err != nil || len(evicted) > 0 {
+			t.Fatal(evicted, err)
+		}
+	}
+
+	// b.go is the least recently used document
+	_, evicted, err := store.sync("project-id", "file:///c.go", Go, "a", notify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []evictedDocument{{uri: "file:///b.go", languageId: Go}}, evicted)
+
+	var closed []LanguageId
+	ok, err := store.close("project-id", "file:///b.go", func(languageId LanguageId) error {
+		closed = append(closed, languageId)
+		return nil
+	})
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, []LanguageId{Go}, closed)
+	assert.False(t, store.isOpen("project-id", "file:///b.go"))
+	assert.True(t, store.isOpen("project-id", "file:///a.go"))
+}
+
+func TestDocumentStore_PublishedWhileNotifying(t *testing.T) {
+	store := newDocumentStore()
+
+	sending, release := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.sync("project-id", "file:///main.go", Go, "a", func(protocol.Integer, bool) error {
+			close(sending)
+			<-release
+			return nil
+		})
+	}()
+
+	<-sending
+	// diagnostics are published while the server is slow to take the notification
+	version := protocol.UInteger(1)
+	store.published("project-id", "file:///main.go", &version)
+	assert.True(t, store.isOpen("project-id", "file:///main.go"))
+
+	close(release)
+	<-done
+}
+
+func TestDocumentStore_WithDocument(t *testing.T) {
+	store := newDocumentStore()
+	notify := func(protocol.Integer, bool) error { return nil }
+	if _, _, err := store.sync("project-id", "file:///main.go", Go, "a", notify); err != nil {
+		t.Fatal(err)
+	}
+
+	locked, release := make(chan struct{}), make(chan struct{})
+	go store.withDocument("project-id", "file:///main.go", func() error {
+		close(locked)
+		<-release
+		return nil
+	})
+	<-locked
+
+	// the document doesn't change while it is locked
+	synced := make(chan struct{})
+	go func() {
+		defer close(synced)
+		store.sync("project-id", "file:///main.go", Go, "b", notify)
+	}()
+
+	select {
+	case <-synced:
+		t.Fatal("document changed while it was locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-synced
+
+	content, _ := store.content("project-id", "file:///main.go")
+	assert.Equal(t, "b", content)
+}
@@ -39,3 +39,67 @@ func (e LanguageServerNotFoundError) Error() string {
 func NewLanguageServerNotFoundError(projectId ProjectId, languageId LanguageId) *LanguageServerNotFoundError {
 	return &LanguageServerNotFoundError{ProjectId: projectId, LanguageId: languageId}
 }
+
+type RenameNotPossibleError struct {
+	Path   string
+	Reason string
+}
+
+func (e RenameNotPossibleError) Error() string {
+	return fmt.Sprintf("Cannot rename symbol in %s: %s", e.Path, e.Reason)
+}
+
+func NewRenameNotPossibleError(path, reason string) *RenameNotPossibleError {
+	return &RenameNotPossibleError{Path: path, Reason: reason}
+}
+
+type CodeActionNotFoundError struct {
+	Path  string
+	Title string
+}
+
+func (e CodeActionNotFoundError) Error() string {
+	return fmt.Sprintf("Code action %q not found in %s", e.Title, e.Path)
+}
+
+func NewCodeActionNotFoundError(path, title string) *CodeActionNotFoundError {
+	return &CodeActionNotFoundError{Path: path, Title: title}
+}
+
+type CodeActionDisabledError struct {
+	Title  string
+	Reason string
+}
+
+func (e CodeActionDisabledError) Error() string {
+	return fmt.Sprintf("Code action %q is disabled: %s", e.Title, e.Reason)
+}
+
+func NewCodeActionDisabledError(title, reason string) *CodeActionDisabledError {
+	return &CodeActionDisabledError{Title: title, Reason: reason}
+}
+
+type CodeActionNotPreviewableError struct {
+	Title   string
+	Command string
+}
+
+func (e CodeActionNotPreviewableError) Error() string {
+	return fmt.Sprintf("Code action %q runs command %s on the language server, it can't be applied with dryRun", e.Title, e.Command)
+}
+
+func NewCodeActionNotPreviewableError(title, command string) *CodeActionNotPreviewableError {
+	return &CodeActionNotPreviewableError{Title: title, Command: command}
+}
+
+type FeatureNotSupportedError struct {
+	Feature string
+}
+
+func (e FeatureNotSupportedError) Error() string {
+	return fmt.Sprintf("Language server does not support %s", e.Feature)
+}
+
+func NewFeatureNotSupportedError(feature string) *FeatureNotSupportedError {
+	return &FeatureNotSupportedError{Feature: feature}
+}
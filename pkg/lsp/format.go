@@ -0,0 +1,164 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// Format implements Service. The imports are organized first, the edits of the formatting then refer to the organized content,
+// so the server gets the organized content while the file is formatted.
+func (s *ServiceImpl) Format(ctx context.Context, file model.File, opts FormatOptions) ([]files.FileChange, error) {
+	project, client, err := s.getClientForFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []files.FileChange{}
+	rng := opts.Range
+
+	if opts.OrganizeImports {
+		organized, err := s.organizeImports(ctx, project, client, file, opts.DryRun)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, organized...)
+
+		var edits []files.TextEdit
+		for _, change := range organized {
+			if change.Path == file.Path {
+				edits = append(edits, change.Edits...)
+			}
+		}
+
+		if len(edits) > 0 {
+			content, err := files.ApplyTextEdits(file.GetContent(), edits)
+			if err != nil {
+				log.Error().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to apply organized imports")
+				return nil, fmt.Errorf("Failed to apply organized imports: %w", err)
+			}
+
+			if err := s.NotifyDidChange(ctx, *model.NewFile(file.Path, content)); err != nil {
+				return nil, fmt.Errorf("Failed to notify didChange for file %s: %w", file.Path, err)
+			}
+
+			// the server gets the content of the file back, the organized content is only written when the changes are applied
+			defer func(original model.File) {
+				if err := s.NotifyDidChange(ctx, original); err != nil {
+					log.Warn().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to restore document")
+				}
+			}(file)
+
+			file = *model.NewFile(file.Path, content)
+			if rng != nil {
+				shifted := shiftRange(*rng, edits)
+				rng = &shifted
+			}
+		}
+	}
+
+	var edits []protocol.TextEdit
+	if rng != nil {
+		edits, err = client.FormatRange(ctx, protocol.DocumentRangeFormattingParams{
+			TextDocument: textDocument(project, file),
+			Range:        protocol.Range{Start: toProtocolPosition(rng.Start), End: toProtocolPosition(rng.End)},
+			Options:      formattingOptions(file.GetContent()),
+		})
+	} else {
+		edits, err = client.FormatDocument(ctx, protocol.DocumentFormattingParams{
+			TextDocument: textDocument(project, file),
+			Options:      formattingOptions(file.GetContent()),
+		})
+	}
+	if err != nil {
+		return nil, requestError(project, file, "formatting", err)
+	}
+
+	if len(edits) > 0 {
+		changes = append(changes, files.FileChange{Path: file.Path, Edits: toTextEdits(edits)})
+	}
+
+	return changes, nil
+}
+
+// organizeImports returns the changes of the organize imports actions of the file.
+func (s *ServiceImpl) organizeImports(ctx context.Context, project *model.Project, client Client, file model.File, dryRun bool) ([]files.FileChange, error) {
+	lines := len(file.Lines)
+	actions, err := client.OrganizeImports(ctx, protocol.CodeActionParams{
+		TextDocument: textDocument(project, file),
+		Range:        protocol.Range{End: toProtocolPosition(Position{Line: lines + 1})},
+		Context:      protocol.CodeActionContext{Diagnostics: []protocol.Diagnostic{}},
+	})
+	if err != nil {
+		return nil, requestError(project, file, "organize imports", err)
+	}
+
+	changes := []files.FileChange{}
+	for _, action := range actions {
+		if action.Disabled != nil {
+			continue
+		}
+
+		actionChanges, err := codeActionChanges(ctx, project, client, action, dryRun)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, actionChanges...)
+	}
+
+	return changes, nil
+}
+
+// formattingOptions guesses the indentation of the content, servers with their own style, e.g. gopls, ignore it.
+func formattingOptions(content string) protocol.FormattingOptions {
+	tabs, spaces, width := 0, 0, 0
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			tabs++
+		case strings.HasPrefix(line, " "):
+			spaces++
+			if indent := len(line) - len(strings.TrimLeft(line, " ")); width == 0 || indent < width {
+				width = indent
+			}
+		}
+	}
+
+	if width == 0 || width > 8 {
+		width = 4
+	}
+
+	return protocol.FormattingOptions{
+		protocol.FormattingOptionTabSize:      width,
+		protocol.FormattingOptionInsertSpaces: spaces > tabs,
+	}
+}
+
+// shiftRange moves the range by the lines that the edits above it add or remove.
+func shiftRange(rng Range, edits []files.TextEdit) Range {
+	delta := 0
+	for _, edit := range edits {
+		if edit.EndLine < rng.Start.Line {
+			delta += strings.Count(edit.NewText, "\n") - (edit.EndLine - edit.StartLine)
+		}
+	}
+
+	rng.Start.Line += delta
+	rng.End.Line += delta
+	return rng
+}
+
+func isCodeActionKind(kind, base protocol.CodeActionKind) bool {
+	return kind == base || strings.HasPrefix(kind, base+".")
+}
@@ -0,0 +1,135 @@
+package lsp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+const unformattedGo = `package main
+
+import (
+	"os"
+	"fmt"
+)
+
+func main() {
+fmt.Println("hi")
+}
+`
+
+func TestService_Format(t *testing.T) {
+	uri := protocol.DocumentUri("file:///test/project/main.go")
+	options := protocol.FormattingOptions{protocol.FormattingOptionTabSize: 4, protocol.FormattingOptionInsertSpaces: false}
+
+	// removes the unused import "os"
+	organizeImports := protocol.CodeAction{
+		Title: "Organize Imports",
+		Edit:  &protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{uri: {{Range: protocol.Range{Start: protocol.Position{Line: 3}, End: protocol.Position{Line: 4}}}}}},
+	}
+	organized := files.FileChange{Path: "main.go", Edits: []files.TextEdit{{StartLine: 4, EndLine: 5}}}
+
+	// indents the call, which is one line up after the imports are organized
+	indent := func(line protocol.UInteger) []protocol.TextEdit {
+		return []protocol.TextEdit{{Range: protocol.Range{Start: protocol.Position{Line: line}, End: protocol.Position{Line: line}}, NewText: "\t"}}
+	}
+	indented := func(line int) files.FileChange {
+		return files.FileChange{Path: "main.go", Edits: []files.TextEdit{{StartLine: line, EndLine: line, NewText: "\t"}}}
+	}
+
+	tests := []struct {
+		name    string
+		opts    lsp.FormatOptions
+		setup   func(client *mocks.MockClient)
+		want    []files.FileChange
+		wantErr error
+	}{
+		{
+			name: "document",
+			opts: lsp.FormatOptions{},
+			setup: func(client *mocks.MockClient) {
+				client.On("FormatDocument", mock.MatchedBy(isContext), protocol.DocumentFormattingParams{
+					TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+					Options:      options,
+				}).Return(indent(8), nil)
+			},
+			want: []files.FileChange{indented(9)},
+		},
+		{
+			name: "already formatted",
+			opts: lsp.FormatOptions{},
+			setup: func(client *mocks.MockClient) {
+				client.On("FormatDocument", mock.MatchedBy(isContext), mock.Anything).Return(nil, nil)
+			},
+			want: []files.FileChange{},
+		},
+		{
+			name: "range with organized imports",
+			opts: lsp.FormatOptions{Range: &lsp.Range{Start: lsp.Position{Line: 9}, End: lsp.Position{Line: 10}}, OrganizeImports: true},
+			setup: func(client *mocks.MockClient) {
+				client.On("OrganizeImports", mock.MatchedBy(isContext), mock.Anything).Return([]protocol.CodeAction{organizeImports}, nil)
+				// the range is moved up by the removed import
+				client.On("FormatRange", mock.MatchedBy(isContext), protocol.DocumentRangeFormattingParams{
+					TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+					Range:        protocol.Range{Start: protocol.Position{Line: 7}, End: protocol.Position{Line: 8}},
+					Options:      options,
+				}).Return(indent(7), nil)
+			},
+			want: []files.FileChange{organized, indented(8)},
+		},
+		{
+			name: "not supported",
+			opts: lsp.FormatOptions{},
+			setup: func(client *mocks.MockClient) {
+				client.On("FormatDocument", mock.MatchedBy(isContext), mock.Anything).Return(nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: "method not found"})
+			},
+			wantErr: lsp.NewFeatureNotSupportedError("formatting"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+
+			client := &mocks.MockClient{}
+			tt.setup(client)
+
+			clientPool := &mocks.MockClientPool{}
+			clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+			service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), lsp.NewDiagnosticsStore(), clientPool, nil)
+
+			got, err := service.Format(ctx, *model.NewFile("main.go", unformattedGo), tt.opts)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+
+			if len(got) == 0 {
+				return
+			}
+
+			// the changes are applied in order, the edits of the formatting refer to the organized content
+			content := unformattedGo
+			for _, change := range got {
+				if content, err = files.ApplyTextEdits(content, change.Edits); err != nil {
+					t.Fatal(err)
+				}
+			}
+			assert.Contains(t, content, "\tfmt.Println")
+		})
+	}
+}
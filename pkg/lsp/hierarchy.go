@@ -0,0 +1,210 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	"github.com/sourcegraph/jsonrpc2"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// maxHierarchyItems limits the items of a hierarchy, a few levels of callers can already have thousands of items
+const maxHierarchyItems = 200
+
+// relatedItem is an item of a hierarchy with the ranges of the calls between it and its parent.
+type relatedItem[T any] struct {
+	item   T
+	ranges []protocol.Range
+}
+
+// GetCallHierarchy implements Service.
+func (s *ServiceImpl) GetCallHierarchy(ctx context.Context, file model.File, position Position, direction CallHierarchyDirection, depth int) ([]HierarchyItem, error) {
+	project, client, err := s.getClientForFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	roots, err := client.PrepareCallHierarchy(ctx, protocol.CallHierarchyPrepareParams{TextDocumentPositionParams: textDocumentPosition(project, file, position)})
+	if err != nil {
+		return nil, requestError(project, file, "call hierarchy", err)
+	}
+
+	related := func(item protocol.CallHierarchyItem) ([]relatedItem[protocol.CallHierarchyItem], error) {
+		var result []relatedItem[protocol.CallHierarchyItem]
+
+		if direction == CallHierarchyOutgoing {
+			calls, err := client.GetOutgoingCalls(ctx, protocol.CallHierarchyOutgoingCallsParams{Item: item})
+			if err != nil {
+				return nil, requestError(project, file, "outgoing calls", err)
+			}
+			for _, call := range calls {
+				result = append(result, relatedItem[protocol.CallHierarchyItem]{item: call.To, ranges: call.FromRanges})
+			}
+			return result, nil
+		}
+
+		calls, err := client.GetIncomingCalls(ctx, protocol.CallHierarchyIncomingCallsParams{Item: item})
+		if err != nil {
+			return nil, requestError(project, file, "incoming calls", err)
+		}
+		for _, call := range calls {
+			result = append(result, relatedItem[protocol.CallHierarchyItem]{item: call.From, ranges: call.FromRanges})
+		}
+		return result, nil
+	}
+
+	return buildHierarchy(ctx, project, roots, depth, func(item protocol.CallHierarchyItem) protocol.CallHierarchyItem { return item }, related)
+}
+
+// GetTypeHierarchy implements Service.
+func (s *ServiceImpl) GetTypeHierarchy(ctx context.Context, file model.File, position Position, direction TypeHierarchyDirection, depth int) ([]HierarchyItem, error) {
+	project, client, err := s.getClientForFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	roots, err := client.PrepareTypeHierarchy(ctx, TypeHierarchyPrepareParams{TextDocumentPositionParams: textDocumentPosition(project, file, position)})
+	if err != nil {
+		return nil, requestError(project, file, "type hierarchy", err)
+	}
+
+	related := func(item TypeHierarchyItem) ([]relatedItem[TypeHierarchyItem], error) {
+		var types []TypeHierarchyItem
+		var err error
+		if direction == TypeHierarchySubtypes {
+			types, err = client.GetSubtypes(ctx, TypeHierarchySubtypesParams{Item: item})
+		} else {
+			types, err = client.GetSupertypes(ctx, TypeHierarchySupertypesParams{Item: item})
+		}
+		if err != nil {
+			return nil, requestError(project, file, string(direction), err)
+		}
+
+		result := make([]relatedItem[TypeHierarchyItem], 0, len(types))
+		for _, t := range types {
+			result = append(result, relatedItem[TypeHierarchyItem]{item: t})
+		}
+		return result, nil
+	}
+
+	return buildHierarchy(ctx, project, roots, depth, func(item TypeHierarchyItem) protocol.CallHierarchyItem { return protocol.CallHierarchyItem(item) }, related)
+}
+
+// hierarchyBuilder expands the related items of a hierarchy.
+type hierarchyBuilder[T any] struct {
+	project *model.Project
+	toItem  func(T) protocol.CallHierarchyItem
+	related func(T) ([]relatedItem[T], error)
+	// ancestors are the keys of the items between the root and the item that is expanded
+	ancestors map[string]bool
+	// expanded are the related items by key, an item can be in several places of a hierarchy but is only requested once
+	expanded map[string][]relatedItem[T]
+	items    int
+}
+
+// buildHierarchy expands the related items of the roots up to the depth, with at most maxHierarchyItems items.
+func buildHierarchy[T any](ctx context.Context, project *model.Project, roots []T, depth int, toItem func(T) protocol.CallHierarchyItem, related func(T) ([]relatedItem[T], error)) ([]HierarchyItem, error) {
+	b := &hierarchyBuilder[T]{
+		project:   project,
+		toItem:    toItem,
+		related:   related,
+		ancestors: map[string]bool{},
+		expanded:  map[string][]relatedItem[T]{},
+		items:     len(roots),
+	}
+
+	result := make([]HierarchyItem, 0, len(roots))
+	for _, root := range roots {
+		item, err := b.expand(ctx, relatedItem[T]{item: root}, depth)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// expand doesn't expand items that are their own ancestors, so that recursive calls end the hierarchy.
+func (b *hierarchyBuilder[T]) expand(ctx context.Context, node relatedItem[T], depth int) (HierarchyItem, error) {
+	if err := ctx.Err(); err != nil {
+		return HierarchyItem{}, err
+	}
+
+	item := b.toItem(node.item)
+	result, err := toHierarchyItem(b.project, item, node.ranges)
+	if err != nil {
+		log.Error().Err(err).Str("URI", item.URI).Msg("Failed to convert hierarchy item")
+		return HierarchyItem{}, fmt.Errorf("Failed to convert hierarchy item: %w", err)
+	}
+
+	key := fmt.Sprintf("%s:%d:%d", item.URI, item.SelectionRange.Start.Line, item.SelectionRange.Start.Character)
+	if depth <= 0 || b.ancestors[key] {
+		return result, nil
+	}
+
+	if b.items >= maxHierarchyItems {
+		result.Truncated = true
+		return result, nil
+	}
+
+	children, ok := b.expanded[key]
+	if !ok {
+		if children, err = b.related(node.item); err != nil {
+			return HierarchyItem{}, err
+		}
+		b.expanded[key] = children
+	}
+
+	b.ancestors[key] = true
+	defer delete(b.ancestors, key)
+
+	for _, child := range children {
+		if b.items >= maxHierarchyItems {
+			result.Truncated = true
+			break
+		}
+		b.items++
+
+		childItem, err := b.expand(ctx, child, depth-1)
+		if err != nil {
+			return HierarchyItem{}, err
+		}
+		result.Children = append(result.Children, childItem)
+	}
+
+	return result, nil
+}
+
+func toHierarchyItem(project *model.Project, item protocol.CallHierarchyItem, callRanges []protocol.Range) (HierarchyItem, error) {
+	location, err := toLocation(project, protocol.Location{URI: item.URI, Range: item.Range})
+	if err != nil {
+		return HierarchyItem{}, err
+	}
+
+	result := HierarchyItem{Name: item.Name, Kind: symbolKindToString(item.Kind), Location: location}
+
+	if item.Detail != nil {
+		result.Detail = *item.Detail
+	}
+
+	for _, r := range callRanges {
+		result.CallRanges = append(result.CallRanges, toRange(r))
+	}
+
+	return result, nil
+}
+
+// requestError reports requests that the server doesn't implement as a feature that is not supported.
+func requestError(project *model.Project, file model.File, request string, err error) error {
+	var rpcErr *jsonrpc2.Error
+	if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc2.CodeMethodNotFound {
+		return NewFeatureNotSupportedError(request)
+	}
+
+	log.Error().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msgf("Failed to get %s", request)
+	return fmt.Errorf("Failed to get %s: %w", request, err)
+}
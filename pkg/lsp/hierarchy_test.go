@@ -0,0 +1,162 @@
+package lsp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestService_GetCallHierarchy(t *testing.T) {
+	item := func(name string, line protocol.UInteger) protocol.CallHierarchyItem {
+		r := protocol.Range{Start: protocol.Position{Line: line}, End: protocol.Position{Line: line + 2}}
+		return protocol.CallHierarchyItem{Name: name, Kind: protocol.SymbolKindFunction, URI: "file:///test/project/main.go", Range: r, SelectionRange: r}
+	}
+	location := func(line int) lsp.Location {
+		return lsp.Location{Path: "main.go", Range: lsp.Range{Start: lsp.Position{Line: line}, End: lsp.Position{Line: line + 2}}}
+	}
+	call := protocol.Range{Start: protocol.Position{Line: 11, Character: 1}, End: protocol.Position{Line: 11, Character: 4}}
+	callRange := lsp.Range{Start: lsp.Position{Line: 12, Character: 1}, End: lsp.Position{Line: 12, Character: 4}}
+
+	foo, bar, recurse, printf := item("foo", 0), item("bar", 10), item("recurse", 20), item("Printf", 30)
+	printf.URI = "file:///usr/lib/go/src/fmt/print.go"
+
+	tests := []struct {
+		name      string
+		direction lsp.CallHierarchyDirection
+		depth     int
+		want      []lsp.HierarchyItem
+	}{
+		{
+			name:      "incoming",
+			direction: lsp.CallHierarchyIncoming,
+			depth:     1,
+			want: []lsp.HierarchyItem{{Name: "foo", Kind: "Function", Location: location(1), Children: []lsp.HierarchyItem{
+				{Name: "bar", Kind: "Function", Location: location(11), CallRanges: []lsp.Range{callRange}},
+			}}},
+		},
+		{
+			name:      "incoming stops at recursion",
+			direction: lsp.CallHierarchyIncoming,
+			depth:     5,
+			want: []lsp.HierarchyItem{{Name: "foo", Kind: "Function", Location: location(1), Children: []lsp.HierarchyItem{
+				{Name: "bar", Kind: "Function", Location: location(11), CallRanges: []lsp.Range{callRange}, Children: []lsp.HierarchyItem{
+					{Name: "recurse", Kind: "Function", Location: location(21), Children: []lsp.HierarchyItem{
+						{Name: "recurse", Kind: "Function", Location: location(21)},
+					}},
+				}},
+			}}},
+		},
+		{
+			name:      "outgoing",
+			direction: lsp.CallHierarchyOutgoing,
+			depth:     2,
+			want: []lsp.HierarchyItem{{Name: "foo", Kind: "Function", Location: location(1), Children: []lsp.HierarchyItem{
+				{Name: "Printf", Kind: "Function", Location: lsp.Location{Path: "/usr/lib/go/src/fmt/print.go", Range: lsp.Range{Start: lsp.Position{Line: 31}, End: lsp.Position{Line: 33}}}, CallRanges: []lsp.Range{callRange}},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+
+			client := &mocks.MockClient{}
+			client.On("PrepareCallHierarchy", mock.MatchedBy(isContext), protocol.CallHierarchyPrepareParams{TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: "file:///test/project/main.go"},
+				Position:     protocol.Position{Line: 0, Character: 5},
+			}}).Return([]protocol.CallHierarchyItem{foo}, nil)
+			client.On("GetIncomingCalls", mock.MatchedBy(isContext), protocol.CallHierarchyIncomingCallsParams{Item: foo}).Return([]protocol.CallHierarchyIncomingCall{{From: bar, FromRanges: []protocol.Range{call}}}, nil)
+			client.On("GetIncomingCalls", mock.MatchedBy(isContext), protocol.CallHierarchyIncomingCallsParams{Item: bar}).Return([]protocol.CallHierarchyIncomingCall{{From: recurse}}, nil)
+			client.On("GetIncomingCalls", mock.MatchedBy(isContext), protocol.CallHierarchyIncomingCallsParams{Item: recurse}).Return([]protocol.CallHierarchyIncomingCall{{From: recurse}}, nil)
+			client.On("GetOutgoingCalls", mock.MatchedBy(isContext), protocol.CallHierarchyOutgoingCallsParams{Item: foo}).Return([]protocol.CallHierarchyOutgoingCall{{To: printf, FromRanges: []protocol.Range{call}}}, nil)
+			client.On("GetOutgoingCalls", mock.MatchedBy(isContext), protocol.CallHierarchyOutgoingCallsParams{Item: printf}).Return(nil, nil)
+
+			clientPool := &mocks.MockClientPool{}
+			clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+			service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), nil, clientPool, nil)
+
+			got, err := service.GetCallHierarchy(ctx, *model.NewFile("main.go", "package main"), lsp.Position{Line: 1, Character: 5}, tt.direction, tt.depth)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_GetCallHierarchy_Truncated(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+
+	item := func(name string, line protocol.UInteger) protocol.CallHierarchyItem {
+		r := protocol.Range{Start: protocol.Position{Line: line}, End: protocol.Position{Line: line + 2}}
+		return protocol.CallHierarchyItem{Name: name, Kind: protocol.SymbolKindFunction, URI: "file:///test/project/main.go", Range: r, SelectionRange: r}
+	}
+	foo, bar := item("foo", 0), item("bar", 10)
+
+	// bar calls foo at many places, each call is an item of the hierarchy
+	calls := make([]protocol.CallHierarchyIncomingCall, 300)
+	for i := range calls {
+		calls[i] = protocol.CallHierarchyIncomingCall{From: bar}
+	}
+
+	client := &mocks.MockClient{}
+	client.On("PrepareCallHierarchy", mock.MatchedBy(isContext), mock.Anything).Return([]protocol.CallHierarchyItem{foo}, nil)
+	client.On("GetIncomingCalls", mock.MatchedBy(isContext), protocol.CallHierarchyIncomingCallsParams{Item: foo}).Return(calls, nil)
+	client.On("GetIncomingCalls", mock.MatchedBy(isContext), protocol.CallHierarchyIncomingCallsParams{Item: bar}).Return(nil, nil)
+
+	clientPool := &mocks.MockClientPool{}
+	clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), nil, clientPool, nil)
+
+	got, err := service.GetCallHierarchy(ctx, *model.NewFile("main.go", "package main"), lsp.Position{Line: 1, Character: 5}, lsp.CallHierarchyIncoming, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, got, 1)
+	assert.True(t, got[0].Truncated)
+	assert.Len(t, got[0].Children, 199)
+	// the callers of bar are requested once, although bar is expanded for each call
+	client.AssertNumberOfCalls(t, "GetIncomingCalls", 2)
+}
+
+func TestService_GetTypeHierarchy(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+	r := protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 4}}
+	base := lsp.TypeHierarchyItem{Name: "Base", Kind: protocol.SymbolKindClass, URI: "file:///test/project/main.py", Range: r, SelectionRange: r}
+	derived := lsp.TypeHierarchyItem{Name: "Derived", Kind: protocol.SymbolKindClass, URI: "file:///test/project/derived.py", Range: r, SelectionRange: r}
+
+	client := &mocks.MockClient{}
+	client.On("PrepareTypeHierarchy", mock.MatchedBy(isContext), mock.Anything).Return([]lsp.TypeHierarchyItem{base}, nil)
+	client.On("GetSubtypes", mock.MatchedBy(isContext), lsp.TypeHierarchySubtypesParams{Item: base}).Return([]lsp.TypeHierarchyItem{derived}, nil)
+	client.On("GetSupertypes", mock.MatchedBy(isContext), mock.Anything).Return(nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: "method not found"})
+
+	clientPool := &mocks.MockClientPool{}
+	clientPool.On("Get", "project-id", lsp.Python).Return(client, true)
+
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), nil, clientPool, nil)
+	file := *model.NewFile("main.py", "class Base: pass")
+
+	got, err := service.GetTypeHierarchy(ctx, file, lsp.Position{Line: 3}, lsp.TypeHierarchySubtypes, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := lsp.Range{Start: lsp.Position{Line: 3}, End: lsp.Position{Line: 5}}
+	assert.Equal(t, []lsp.HierarchyItem{{Name: "Base", Kind: "Class", Location: lsp.Location{Path: "main.py", Range: rng}, Children: []lsp.HierarchyItem{
+		{Name: "Derived", Kind: "Class", Location: lsp.Location{Path: "derived.py", Range: rng}},
+	}}}, got)
+
+	_, err = service.GetTypeHierarchy(ctx, file, lsp.Position{Line: 3}, lsp.TypeHierarchySupertypes, 1)
+	assert.Equal(t, lsp.NewFeatureNotSupportedError("supertypes"), err)
+}
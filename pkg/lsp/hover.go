@@ -0,0 +1,178 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// GetHover implements Service.
+func (s *ServiceImpl) GetHover(ctx context.Context, file model.File, position Position) (*HoverInfo, error) {
+	project, client, err := s.getClientForFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	params := textDocumentPosition(project, file, position)
+
+	hover, err := client.GetHover(ctx, protocol.HoverParams{TextDocumentPositionParams: params})
+	if err != nil {
+		log.Error().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to get hover")
+		return nil, fmt.Errorf("Failed to get hover: %w", err)
+	}
+
+	result := &HoverInfo{}
+	if hover != nil {
+		if contents, ok := hover.Contents.(protocol.MarkupContent); ok {
+			result.Contents = markupToText(contents)
+		}
+
+		if hover.Range != nil {
+			r := toRange(*hover.Range)
+			result.Range = &r
+		}
+	}
+
+	// not every server supports signature help, hover is still useful without it
+	signatureHelp, err := client.GetSignatureHelp(ctx, protocol.SignatureHelpParams{TextDocumentPositionParams: params})
+	if err != nil {
+		log.Warn().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to get signature help")
+	} else {
+		result.Signature = toSignatureInfo(signatureHelp)
+	}
+
+	return result, nil
+}
+
+func toSignatureInfo(help *protocol.SignatureHelp) *SignatureInfo {
+	if help == nil || len(help.Signatures) == 0 {
+		return nil
+	}
+
+	active := 0
+	if help.ActiveSignature != nil && int(*help.ActiveSignature) < len(help.Signatures) {
+		active = int(*help.ActiveSignature)
+	}
+	signature := help.Signatures[active]
+
+	info := &SignatureInfo{Label: signature.Label, Documentation: documentationToText(signature.Documentation)}
+
+	// the active parameter of the signature takes precedence over the one of the help
+	activeParameter := help.ActiveParameter
+	if signature.ActiveParameter != nil {
+		activeParameter = signature.ActiveParameter
+	}
+
+	if activeParameter != nil && int(*activeParameter) < len(signature.Parameters) {
+		info.ActiveParameter = parameterLabel(signature.Label, signature.Parameters[*activeParameter])
+	}
+
+	return info
+}
+
+// parameterLabel returns the label of the parameter, which is either a string or offsets into the signature label.
+func parameterLabel(signatureLabel string, parameter protocol.ParameterInformation) string {
+	switch label := parameter.Label.(type) {
+	case string:
+		return label
+	case []protocol.UInteger:
+		// offsets are in UTF-16 code units, which match bytes for the ASCII labels servers produce
+		if len(label) == 2 && label[0] <= label[1] && int(label[1]) <= len(signatureLabel) {
+			return signatureLabel[label[0]:label[1]]
+		}
+	}
+
+	return ""
+}
+
+func documentationToText(documentation any) string {
+	switch doc := documentation.(type) {
+	case string:
+		return doc
+	case protocol.MarkupContent:
+		return markupToText(doc)
+	default:
+		return ""
+	}
+}
+
+func markupToText(content protocol.MarkupContent) string {
+	if content.Kind == protocol.MarkupKindMarkdown {
+		return markdownToText(content.Value)
+	}
+
+	return strings.TrimSpace(content.Value)
+}
+
+// parseHover parses a hover result, the deprecated MarkedString contents are converted to markdown.
+func parseHover(data json.RawMessage) (*protocol.Hover, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	var value struct {
+		Contents json.RawMessage `json:"contents"`
+		Range    *protocol.Range `json:"range,omitempty"`
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse hover: %w", err)
+	}
+
+	contents, err := parseHoverContents(value.Contents)
+	if err != nil {
+		return nil, err
+	}
+
+	return &protocol.Hover{Contents: contents, Range: value.Range}, nil
+}
+
+func parseHoverContents(data json.RawMessage) (protocol.MarkupContent, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return protocol.MarkupContent{Kind: protocol.MarkupKindPlainText}, nil
+	}
+
+	var items []json.RawMessage
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &items); err != nil {
+			return protocol.MarkupContent{}, fmt.Errorf("failed to parse hover contents: %w", err)
+		}
+	} else {
+		items = []json.RawMessage{data}
+	}
+
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		var markdown string
+		if err := json.Unmarshal(item, &markdown); err == nil {
+			parts = append(parts, markdown)
+			continue
+		}
+
+		var content struct {
+			Kind     protocol.MarkupKind `json:"kind"`
+			Language string              `json:"language"`
+			Value    string              `json:"value"`
+		}
+		if err := json.Unmarshal(item, &content); err != nil {
+			return protocol.MarkupContent{}, fmt.Errorf("failed to parse hover contents: %w", err)
+		}
+
+		switch {
+		case content.Kind != "":
+			// MarkupContent is never part of a list
+			return protocol.MarkupContent{Kind: content.Kind, Value: content.Value}, nil
+		default:
+			parts = append(parts, fmt.Sprintf("```%s\n%s\n```", content.Language, content.Value))
+		}
+	}
+
+	return protocol.MarkupContent{Kind: protocol.MarkupKindMarkdown, Value: strings.Join(parts, "\n\n")}, nil
+}
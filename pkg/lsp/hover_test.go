@@ -0,0 +1,85 @@
+package lsp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestService_GetHover(t *testing.T) {
+	activeParameter := protocol.UInteger(1)
+
+	tests := []struct {
+		name          string
+		hover         *protocol.Hover
+		signatureHelp *protocol.SignatureHelp
+		signatureErr  error
+		want          *lsp.HoverInfo
+	}{
+		{
+			name: "markdown",
+			hover: &protocol.Hover{
+				Contents: protocol.MarkupContent{Kind: protocol.MarkupKindMarkdown, Value: "```go\nfunc Foo(a int, b string) error\n```\n\n---\n\n# Foo\n\nFoo does **things** with `a_b`, see [docs](https://example.com) \\_not\\_ escaped &amp; more.\n\n\n\nEnd"},
+				Range:    &protocol.Range{Start: protocol.Position{Line: 2, Character: 5}, End: protocol.Position{Line: 2, Character: 8}},
+			},
+			signatureErr: errors.New("method not found"),
+			want: &lsp.HoverInfo{
+				Contents: "func Foo(a int, b string) error\n\nFoo\n\nFoo does things with a_b, see docs _not_ escaped & more.\n\nEnd",
+				Range:    &lsp.Range{Start: lsp.Position{Line: 3, Character: 5}, End: lsp.Position{Line: 3, Character: 8}},
+			},
+		},
+		{
+			name:  "plain text with signature",
+			hover: &protocol.Hover{Contents: protocol.MarkupContent{Kind: protocol.MarkupKindPlainText, Value: "a: int "}},
+			signatureHelp: &protocol.SignatureHelp{
+				Signatures: []protocol.SignatureInformation{{
+					Label:         "Foo(a int, b string) error",
+					Documentation: protocol.MarkupContent{Kind: protocol.MarkupKindMarkdown, Value: "Foo does **things**"},
+					Parameters:    []protocol.ParameterInformation{{Label: []protocol.UInteger{4, 9}}, {Label: []protocol.UInteger{11, 19}}},
+				}},
+				ActiveParameter: &activeParameter,
+			},
+			want: &lsp.HoverInfo{
+				Contents:  "a: int",
+				Signature: &lsp.SignatureInfo{Label: "Foo(a int, b string) error", Documentation: "Foo does things", ActiveParameter: "b string"},
+			},
+		},
+		{
+			name: "nothing to show",
+			want: &lsp.HoverInfo{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+			params := protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: "file:///test/project/main.go"},
+				Position:     protocol.Position{Line: 2, Character: 6},
+			}
+
+			client := &mocks.MockClient{}
+			client.On("GetHover", mock.MatchedBy(isContext), protocol.HoverParams{TextDocumentPositionParams: params}).Return(tt.hover, nil)
+			client.On("GetSignatureHelp", mock.MatchedBy(isContext), protocol.SignatureHelpParams{TextDocumentPositionParams: params}).Return(tt.signatureHelp, tt.signatureErr)
+
+			clientPool := &mocks.MockClientPool{}
+			clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+			service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), nil, clientPool, nil)
+
+			got, err := service.GetHover(ctx, *model.NewFile("main.go", "package main"), lsp.Position{Line: 3, Character: 6})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// SymbolIndexService answers symbol searches, outlines and definitions from an index of the declarations in the project files
+// for languages without a running language server, e.g. because the server is not installed. Everything else is passed to the
+// language servers. The index of a project is built on its first use and refreshed with the file events of the project.
+type SymbolIndexService struct {
+	Service
+	registry         Registry
+	languageDetector LanguageDetector
+	fileManager      files.FileManager
+
+	mu       sync.Mutex
+	projects map[ProjectId]map[string]indexedFile
+}
+
+type indexedFile struct {
+	languageId LanguageId
+	symbols    []DocumentSymbol
+}
+
+func NewSymbolIndexService(service Service, registry Registry, languageDetector LanguageDetector, fileManager files.FileManager) Service {
+	return &SymbolIndexService{
+		Service:          service,
+		registry:         registry,
+		languageDetector: languageDetector,
+		fileManager:      fileManager,
+		projects:         make(map[ProjectId]map[string]indexedFile),
+	}
+}
+
+// GetWorkspaceSymbols implements Service.
+func (s *SymbolIndexService) GetWorkspaceSymbols(ctx context.Context, query string, symbolFilter SymbolFilter) ([]SymbolInfo, error) {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		log.Error().Msg("Project not found in context")
+		return nil, fmt.Errorf("project not found in context")
+	}
+
+	symbols, err := s.Service.GetWorkspaceSymbols(ctx, query, symbolFilter)
+	if err != nil {
+		log.Warn().Err(err).Str("projectId", project.Id).Msg("Failed to get workspace symbols from language servers, using symbol index only")
+	}
+
+	matchPath, err := symbolFilter.paths.Matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureIndex(ctx, *project); err != nil {
+		log.Warn().Err(err).Str("projectId", project.Id).Msg("Failed to build symbol index")
+		return symbols, nil
+	}
+
+	running := s.runningLanguages(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for path, file := range s.projects[project.Id] {
+		if running[file.languageId] || !matchPath(path) {
+			continue
+		}
+
+		walkSymbols(file.symbols, "", func(symbol DocumentSymbol, containerName string) {
+			kind, _ := ParseSymbolKind(symbol.Kind)
+			if !symbolFilter.matchesKind(kind) || matchSymbol(query, symbol.Name) == symbolMatchOther {
+				return
+			}
+
+			symbols = append(symbols, SymbolInfo{Name: symbol.Name, Kind: symbol.Kind, ContainerName: containerName, Location: Location{Path: path, Range: nameRange(symbol)}})
+		})
+	}
+
+	return rankSymbols(query, symbols), nil
+}
+
+// GetDocumentSymbols implements Service.
+func (s *SymbolIndexService) GetDocumentSymbols(ctx context.Context, file model.File) ([]DocumentSymbol, error) {
+	symbols, err := s.Service.GetDocumentSymbols(ctx, file)
+
+	var languageServerNotFoundError *LanguageServerNotFoundError
+	if !errors.As(err, &languageServerNotFoundError) {
+		return symbols, err
+	}
+
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		return nil, err
+	}
+
+	languageId := s.detectLanguage(*project, file)
+	if _, ok := tags[languageId]; !ok {
+		return nil, err
+	}
+
+	symbols = extractTags(languageId, file.GetContent())
+	if symbols == nil {
+		symbols = []DocumentSymbol{}
+	}
+
+	return symbols, nil
+}
+
+// GetDefinition implements Service. Without a language server, the declarations of the identifier at the position
+// in files of the same language are returned, the ones in the file itself first.
+func (s *SymbolIndexService) GetDefinition(ctx context.Context, file model.File, position Position) ([]Location, error) {
+	locations, err := s.Service.GetDefinition(ctx, file, position)
+
+	var languageServerNotFoundError *LanguageServerNotFoundError
+	if !errors.As(err, &languageServerNotFoundError) {
+		return locations, err
+	}
+
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		return nil, err
+	}
+
+	languageId := s.detectLanguage(*project, file)
+	if _, ok := tags[languageId]; !ok {
+		return nil, err
+	}
+
+	name := identifierAt(file.GetLine(position.Line).Content, position.Character)
+	if name == "" {
+		return []Location{}, nil
+	}
+
+	if err := s.ensureIndex(ctx, *project); err != nil {
+		return nil, fmt.Errorf("Failed to build symbol index: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the file may not be indexed yet, or differ from the indexed one
+	indexed := s.projects[project.Id]
+	candidates := map[string][]DocumentSymbol{file.Path: extractTags(languageId, file.GetContent())}
+	for path, f := range indexed {
+		if path != file.Path && f.languageId == languageId {
+			candidates[path] = f.symbols
+		}
+	}
+
+	result := []Location{}
+	for path, symbols := range candidates {
+		walkSymbols(symbols, "", func(symbol DocumentSymbol, containerName string) {
+			if symbol.Name == name {
+				result = append(result, Location{Path: path, Range: nameRange(symbol)})
+			}
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if (result[i].Path == file.Path) != (result[j].Path == file.Path) {
+			return result[i].Path == file.Path
+		}
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Range.Start.Line < result[j].Range.Start.Line
+	})
+
+	return result, nil
+}
+
+// NotifyDidOpen implements Service. Files without a language server are indexed instead.
+func (s *SymbolIndexService) NotifyDidOpen(ctx context.Context, file model.File) error {
+	err := s.Service.NotifyDidOpen(ctx, file)
+
+	var languageServerNotFoundError *LanguageServerNotFoundError
+	if !errors.As(err, &languageServerNotFoundError) {
+		return err
+	}
+
+	if project, ok := model.ProjectFromContext(ctx); ok {
+		s.update(*project, file)
+	}
+
+	return nil
+}
+
+// NotifyDidChangeWatchedFiles implements Service.
+func (s *SymbolIndexService) NotifyDidChangeWatchedFiles(ctx context.Context, events []model.FileEvent) error {
+	err := s.Service.NotifyDidChangeWatchedFiles(ctx, events)
+
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		return err
+	}
+
+	s.mu.Lock()
+	_, indexed := s.projects[project.Id]
+	s.mu.Unlock()
+
+	// the index is built from the current files when it is first used
+	if !indexed {
+		return err
+	}
+
+	fs := files.NewProjectFs(project.Path)
+	for _, event := range events {
+		switch event.Type {
+		case model.FileDeleted:
+			s.remove(project.Id, event.Path)
+		case model.FileRenamed:
+			s.remove(project.Id, event.OldPath)
+		}
+
+		if event.Type == model.FileDeleted {
+			continue
+		}
+
+		file, readErr := s.fileManager.ReadFile(ctx, fs, event.Path)
+		if readErr != nil {
+			log.Debug().Err(readErr).Str("projectId", project.Id).Str("path", event.Path).Msg("Failed to read file for symbol index")
+			continue
+		}
+
+		s.update(*project, *file)
+	}
+
+	return err
+}
+
+// CleanupProject implements Service.
+func (s *SymbolIndexService) CleanupProject(ctx context.Context, projectId ProjectId) error {
+	s.mu.Lock()
+	delete(s.projects, projectId)
+	s.mu.Unlock()
+
+	return s.Service.CleanupProject(ctx, projectId)
+}
+
+// ensureIndex builds the index of the project if it doesn't exist yet.
+func (s *SymbolIndexService) ensureIndex(ctx context.Context, project model.Project) error {
+	s.mu.Lock()
+	_, ok := s.projects[project.Id]
+	s.mu.Unlock()
+
+	if ok {
+		return nil
+	}
+
+	projectFiles, err := s.fileManager.ListFiles(ctx, files.NewProjectFs(project.Path), files.ListFilesWithContent())
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	index := make(map[string]indexedFile)
+	for _, file := range projectFiles {
+		if f, ok := s.indexFile(project, *file); ok {
+			index[file.Path] = f
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// files may have been indexed while the project was listed
+	if _, ok := s.projects[project.Id]; !ok {
+		s.projects[project.Id] = index
+		log.Debug().Str("projectId", project.Id).Msgf("Indexed symbols of %d files", len(index))
+	}
+
+	return nil
+}
+
+func (s *SymbolIndexService) indexFile(project model.Project, file model.File) (indexedFile, bool) {
+	languageId := s.detectLanguage(project, file)
+	if _, ok := tags[languageId]; !ok {
+		return indexedFile{}, false
+	}
+
+	return indexedFile{languageId: languageId, symbols: extractTags(languageId, file.GetContent())}, true
+}
+
+// detectLanguage returns the language of the file like ServiceImpl does, file patterns of the language servers take precedence
+// over the language detector.
+func (s *SymbolIndexService) detectLanguage(project model.Project, file model.File) LanguageId {
+	if languageId, ok := s.registry.MatchFile(project, file.Path); ok {
+		return languageId
+	}
+
+	return s.languageDetector.DetectLanguage(&file)
+}
+
+// update indexes the file if the index of the project exists.
+func (s *SymbolIndexService) update(project model.Project, file model.File) {
+	f, ok := s.indexFile(project, file)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, indexed := s.projects[project.Id]
+	if !indexed {
+		return
+	}
+
+	if ok {
+		index[file.Path] = f
+	} else {
+		delete(index, file.Path)
+	}
+}
+
+func (s *SymbolIndexService) remove(projectId ProjectId, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.projects[projectId], path)
+}
+
+// runningLanguages returns the languages that have a running language server in the project of the context.
+func (s *SymbolIndexService) runningLanguages(ctx context.Context) map[LanguageId]bool {
+	running := make(map[LanguageId]bool)
+
+	servers, err := s.Service.GetServerHealth(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get language servers")
+		return running
+	}
+
+	for _, server := range servers {
+		if server.Status == ServerStatusRunning {
+			running[server.LanguageId] = true
+		}
+	}
+
+	return running
+}
+
+// walkSymbols calls fn for the symbols and their children with the name of the symbol containing them.
+func walkSymbols(symbols []DocumentSymbol, containerName string, fn func(symbol DocumentSymbol, containerName string)) {
+	for _, symbol := range symbols {
+		fn(symbol, containerName)
+		walkSymbols(symbol.Children, symbol.Name, fn)
+	}
+}
+
+// nameRange is the range of the name of an indexed symbol.
+func nameRange(symbol DocumentSymbol) Range {
+	start := symbol.Range.Start
+	return Range{Start: start, End: Position{Line: start.Line, Character: start.Character + utf16Len(symbol.Name)}}
+}
+
+// identifierAt returns the identifier around the UTF-16 character of the line.
+func identifierAt(line string, character int) string {
+	isIdentifier := func(r rune) bool {
+		return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	if character < 0 {
+		return ""
+	}
+
+	offset := files.ByteOffset(line, character)
+	start, end := offset, offset
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(line[:start])
+		if !isIdentifier(r) {
+			break
+		}
+		start -= size
+	}
+	for end < len(line) {
+		r, size := utf8.DecodeRuneInString(line[end:])
+		if !isIdentifier(r) {
+			break
+		}
+		end += size
+	}
+
+	return line[start:end]
+}
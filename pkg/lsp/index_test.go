@@ -0,0 +1,277 @@
+package lsp_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/gitignore"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const pythonSource = `MAX_RETRIES = 3
+
+
+class Client:
+    def __init__(self, url):
+        self.url = url
+
+    def fetch(self, path):
+        return request(self.url + path)
+
+
+def request(url):
+    pass
+`
+
+func TestSymbolIndexService_GetDocumentSymbols(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "python",
+			path:    "client.py",
+			content: pythonSource,
+			want:    []string{"Constant MAX_RETRIES", "Class Client", "Method Client.__init__", "Method Client.fetch", "Function request"},
+		},
+		{
+			name:    "go",
+			path:    "main.go",
+			content: "package main\n\ntype Server struct {\n\taddr string\n}\n\nfunc (s *Server) Serve() error {\n\treturn nil\n}\n\nfunc main() {}\n",
+			want:    []string{"Struct Server", "Method Serve", "Function main"},
+		},
+		{
+			name:    "typescript",
+			path:    "app.ts",
+			content: "export interface Options {\n  port: number\n}\n\nexport class App {\n  constructor(private options: Options) {}\n\n  async start(): Promise<void> {\n    if (this.options) {\n      return\n    }\n  }\n}\n\nexport const handler = async (req: Request) => {}\n",
+			want:    []string{"Interface Options", "Class App", "Method App.constructor", "Method App.start", "Function handler"},
+		},
+		{
+			name:    "rust",
+			path:    "lib.rs",
+			content: "pub struct Config {\n    name: String,\n}\n\npub trait Load {\n    fn load(&self) -> Config;\n}\n\npub fn parse(input: &str) -> Config {\n    todo!()\n}\n",
+			want:    []string{"Struct Config", "Interface Load", "Method Load.load", "Function parse"},
+		},
+		{
+			name:    "ruby",
+			path:    "user.rb",
+			content: "module Accounts\n  class User\n    def self.find(id)\n    end\n\n    def admin?\n    end\n  end\nend\n",
+			want:    []string{"Module Accounts", "Class Accounts.User", "Method User.find", "Method User.admin?"},
+		},
+		{
+			name:    "java",
+			path:    "Main.java",
+			content: "public class Main {\n    public static void main(String[] args) {\n        if (args.length > 0) {\n            run(args);\n        }\n    }\n\n    private static int run(String[] args) {\n        return 0;\n    }\n}\n",
+			want:    []string{"Class Main", "Method Main.main", "Method Main.run"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: t.TempDir()})
+			file := model.NewFile(tt.path, tt.content)
+
+			service := &mocks.MockLspService{}
+			service.On("GetDocumentSymbols", ctx, *file).Return(nil, lsp.NewLanguageServerNotFoundError("project-id", "test-lang"))
+
+			index := lsp.NewSymbolIndexService(service, lsp.NewRegistry(), lsp.NewLanguageDetector(), files.NewFileManager(gitignore.NewMatcherFactory(), nil))
+
+			symbols, err := index.GetDocumentSymbols(ctx, *file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, outlineNames(symbols, ""))
+		})
+	}
+}
+
+func TestSymbolIndexService_GetDocumentSymbols_LanguageServer(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: t.TempDir()})
+	file := model.NewFile("client.py", pythonSource)
+	want := []lsp.DocumentSymbol{{Name: "Client", Kind: "Class"}}
+
+	service := &mocks.MockLspService{}
+	service.On("GetDocumentSymbols", ctx, *file).Return(want, nil)
+
+	index := lsp.NewSymbolIndexService(service, lsp.NewRegistry(), lsp.NewLanguageDetector(), files.NewFileManager(gitignore.NewMatcherFactory(), nil))
+
+	symbols, err := index.GetDocumentSymbols(ctx, *file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, want, symbols)
+}
+
+func TestSymbolIndexService_GetWorkspaceSymbols(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "client.py", pythonSource)
+	writeFile(t, root, "main.go", "package main\n\nfunc request() {}\n")
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: root})
+
+	fromServer := lsp.SymbolInfo{Name: "request", Kind: "Function", Location: lsp.Location{Path: "main.go", Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 5}, End: lsp.Position{Line: 3, Character: 12}}}}
+
+	service := &mocks.MockLspService{}
+	service.On("GetWorkspaceSymbols", ctx, "req").Return([]lsp.SymbolInfo{fromServer}, nil)
+	service.On("GetServerHealth", ctx).Return([]lsp.ServerHealth{{LanguageId: lsp.Go, Status: lsp.ServerStatusRunning}}, nil)
+	service.On("NotifyDidChangeWatchedFiles", mock.Anything, mock.Anything).Return(nil)
+
+	index := lsp.NewSymbolIndexService(service, lsp.NewRegistry(), lsp.NewLanguageDetector(), files.NewFileManager(gitignore.NewMatcherFactory(), nil))
+
+	symbols, err := index.GetWorkspaceSymbols(ctx, "req", lsp.SymbolFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Go has a running language server, so only Python files are found in the index
+	fromIndex := lsp.SymbolInfo{Name: "request", Kind: "Function", Location: lsp.Location{Path: "client.py", Range: lsp.Range{Start: lsp.Position{Line: 12, Character: 4}, End: lsp.Position{Line: 12, Character: 11}}}}
+	assert.Equal(t, []lsp.SymbolInfo{fromIndex, fromServer}, symbols)
+
+	t.Run("index is refreshed with file events", func(t *testing.T) {
+		writeFile(t, root, "retry.py", "def request_with_retry(url):\n    pass\n")
+		if err := os.Remove(filepath.Join(root, "client.py")); err != nil {
+			t.Fatal(err)
+		}
+
+		events := []model.FileEvent{{Type: model.FileCreated, Path: "retry.py"}, {Type: model.FileDeleted, Path: "client.py"}}
+		if err := index.NotifyDidChangeWatchedFiles(ctx, events); err != nil {
+			t.Fatal(err)
+		}
+
+		symbols, err := index.GetWorkspaceSymbols(ctx, "req", lsp.NewIncludeSymbolFilter())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := make([]string, 0, len(symbols))
+		for _, symbol := range symbols {
+			names = append(names, symbol.Location.Path+":"+symbol.Name)
+		}
+		assert.Equal(t, []string{"main.go:request", "retry.py:request_with_retry"}, names)
+	})
+}
+
+func TestSymbolIndexService_GetDefinition(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "client.py", pythonSource)
+	writeFile(t, root, "retry.py", "def request(url, retries):\n    pass\n")
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: root})
+
+	file := model.NewFile("client.py", pythonSource)
+	position := lsp.Position{Line: 9, Character: 16}
+
+	service := &mocks.MockLspService{}
+	service.On("GetDefinition", ctx, *file, position).Return(nil, lsp.NewLanguageServerNotFoundError("project-id", lsp.Python))
+
+	index := lsp.NewSymbolIndexService(service, lsp.NewRegistry(), lsp.NewLanguageDetector(), files.NewFileManager(gitignore.NewMatcherFactory(), nil))
+
+	locations, err := index.GetDefinition(ctx, *file, position)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []lsp.Location{
+		{Path: "client.py", Range: lsp.Range{Start: lsp.Position{Line: 12, Character: 4}, End: lsp.Position{Line: 12, Character: 11}}},
+		{Path: "retry.py", Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 4}, End: lsp.Position{Line: 1, Character: 11}}},
+	}, locations)
+}
+
+func TestSymbolIndexService_GetDefinition_UTF16(t *testing.T) {
+	content := "def request(url):\n    pass\n\n\nnaïve = \"😀\" + request(\"x\")\n"
+	root := t.TempDir()
+	writeFile(t, root, "client.py", content)
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: root})
+
+	file := model.NewFile("client.py", content)
+	// the character is in UTF-16 code units, 😀 takes two of them
+	position := lsp.Position{Line: 5, Character: 15}
+
+	service := &mocks.MockLspService{}
+	service.On("GetDefinition", ctx, *file, position).Return(nil, lsp.NewLanguageServerNotFoundError("project-id", lsp.Python))
+
+	index := lsp.NewSymbolIndexService(service, lsp.NewRegistry(), lsp.NewLanguageDetector(), files.NewFileManager(gitignore.NewMatcherFactory(), nil))
+
+	locations, err := index.GetDefinition(ctx, *file, position)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []lsp.Location{
+		{Path: "client.py", Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 4}, End: lsp.Position{Line: 1, Character: 11}}},
+	}, locations)
+}
+
+func TestSymbolIndexService_GetWorkspaceSymbols_UTF16(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main\n\nfunc (ü *Ünit) Run() {}\n")
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: root})
+
+	service := &mocks.MockLspService{}
+	service.On("GetWorkspaceSymbols", ctx, "Run").Return([]lsp.SymbolInfo{}, nil)
+	service.On("GetServerHealth", ctx).Return([]lsp.ServerHealth{}, nil)
+
+	index := lsp.NewSymbolIndexService(service, lsp.NewRegistry(), lsp.NewLanguageDetector(), files.NewFileManager(gitignore.NewMatcherFactory(), nil))
+
+	symbols, err := index.GetWorkspaceSymbols(ctx, "Run", lsp.SymbolFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the characters are in UTF-16 code units, ü and Ü take one each but two bytes
+	assert.Equal(t, []lsp.SymbolInfo{
+		{Name: "Run", Kind: "Method", Location: lsp.Location{Path: "main.go", Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 15}, End: lsp.Position{Line: 3, Character: 18}}}},
+	}, symbols)
+}
+
+func TestSymbolIndexService_GetDocumentSymbols_FilePatterns(t *testing.T) {
+	project := projectWithLanguageServers(devcontainer.LanguageServer{Command: "pylsp", LanguageIds: []string{"Python"}, FilePatterns: []string{"*.build"}})
+	ctx := model.NewContextWithProject(context.Background(), &project)
+	file := model.NewFile("site.build", "def build(env):\n    pass\n")
+
+	service := &mocks.MockLspService{}
+	service.On("GetDocumentSymbols", ctx, *file).Return(nil, lsp.NewLanguageServerNotFoundError("project-id", lsp.Python))
+
+	index := lsp.NewSymbolIndexService(service, lsp.NewRegistry(), lsp.NewLanguageDetector(), files.NewFileManager(gitignore.NewMatcherFactory(), nil))
+
+	symbols, err := index.GetDocumentSymbols(ctx, *file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"Function build"}, outlineNames(symbols, ""))
+}
+
+// outlineNames returns the kinds and names of the symbols, nested symbols are prefixed with the name of their parent
+func outlineNames(symbols []lsp.DocumentSymbol, parent string) []string {
+	var names []string
+	for _, symbol := range symbols {
+		name := symbol.Name
+		if parent != "" {
+			name = parent + "." + name
+		}
+
+		names = append(names, symbol.Kind+" "+name)
+		names = append(names, outlineNames(symbol.Children, symbol.Name)...)
+	}
+
+	return names
+}
+
+func writeFile(t *testing.T, root, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
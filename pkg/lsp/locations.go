@@ -0,0 +1,52 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// parseLocations parses a result that is either a Location, a list of Locations, a list of LocationLinks or null.
+func parseLocations(data json.RawMessage) ([]protocol.Location, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	if data[0] == '{' {
+		var location protocol.Location
+		if err := json.Unmarshal(data, &location); err != nil {
+			return nil, fmt.Errorf("failed to parse location: %w", err)
+		}
+		return []protocol.Location{location}, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse locations: %w", err)
+	}
+
+	locations := make([]protocol.Location, 0, len(items))
+	for _, item := range items {
+		var link protocol.LocationLink
+		if err := json.Unmarshal(item, &link); err != nil {
+			return nil, fmt.Errorf("failed to parse location: %w", err)
+		}
+
+		// a location link points to the whole target, the selection range is the symbol itself
+		if link.TargetURI != "" {
+			locations = append(locations, protocol.Location{URI: link.TargetURI, Range: link.TargetSelectionRange})
+			continue
+		}
+
+		var location protocol.Location
+		if err := json.Unmarshal(item, &location); err != nil {
+			return nil, fmt.Errorf("failed to parse location: %w", err)
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, nil
+}
@@ -0,0 +1,70 @@
+package lsp
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownCodeSpan  = regexp.MustCompile("`+[^`]*`+")
+	markdownLink      = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownBold      = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	markdownItalic    = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	markdownEscape    = regexp.MustCompile("\\\\([\\\\`*_{}\\[\\]()#+\\-.!|<>~])")
+	markdownHeading   = regexp.MustCompile(`^#{1,6}\s+`)
+	markdownRule      = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+	markdownBlankRuns = regexp.MustCompile(`\n{3,}`)
+)
+
+// markdownToText renders the markdown returned by language servers as plain text.
+// Code blocks are kept as they are, only the fences are removed.
+func markdownToText(markdown string) string {
+	var lines []string
+	inCode := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+
+		if inCode {
+			lines = append(lines, line)
+			continue
+		}
+
+		if markdownRule.MatchString(line) {
+			lines = append(lines, "")
+			continue
+		}
+
+		lines = append(lines, markdownLineToText(markdownHeading.ReplaceAllString(line, "")))
+	}
+
+	text := markdownBlankRuns.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// markdownLineToText removes inline formatting, code spans are kept verbatim without the backticks.
+func markdownLineToText(line string) string {
+	var b strings.Builder
+
+	last := 0
+	for _, span := range markdownCodeSpan.FindAllStringIndex(line, -1) {
+		b.WriteString(markdownInlineToText(line[last:span[0]]))
+		b.WriteString(strings.Trim(line[span[0]:span[1]], "`"))
+		last = span[1]
+	}
+	b.WriteString(markdownInlineToText(line[last:]))
+
+	return strings.TrimRight(b.String(), " ")
+}
+
+func markdownInlineToText(text string) string {
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownBold.ReplaceAllString(text, "$1")
+	text = markdownItalic.ReplaceAllString(text, "$1")
+	text = markdownEscape.ReplaceAllString(text, "$1")
+	return html.UnescapeString(text)
+}
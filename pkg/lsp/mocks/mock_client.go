@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/hide-org/hide/pkg/lsp"
 	"github.com/stretchr/testify/mock"
@@ -22,7 +23,7 @@ func (m *MockClient) GetWorkspaceSymbols(ctx context.Context, params protocol.Wo
 	return args.Get(0).([]protocol.SymbolInformation), args.Error(1)
 }
 
-func (m *MockClient) Initialize(ctx context.Context, params protocol.InitializeParams) (protocol.InitializeResult, error) {
+func (m *MockClient) Initialize(ctx context.Context, params lsp.InitializeParams) (protocol.InitializeResult, error) {
 	args := m.Called(ctx, params)
 	return args.Get(0).(protocol.InitializeResult), args.Error(1)
 }
@@ -37,12 +38,262 @@ func (m *MockClient) NotifyDidOpen(ctx context.Context, params protocol.DidOpenT
 	return args.Error(0)
 }
 
+func (m *MockClient) NotifyDidChange(ctx context.Context, params protocol.DidChangeTextDocumentParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
 func (m *MockClient) NotifyDidClose(ctx context.Context, params protocol.DidCloseTextDocumentParams) error {
 	args := m.Called(ctx, params)
 	return args.Error(0)
 }
 
+func (m *MockClient) NotifyDidChangeWatchedFiles(ctx context.Context, params protocol.DidChangeWatchedFilesParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (m *MockClient) NotifyDidChangeConfiguration(ctx context.Context, params protocol.DidChangeConfigurationParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
 func (m *MockClient) Shutdown(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
 }
+
+func (m *MockClient) GetDefinition(ctx context.Context, params protocol.DefinitionParams) ([]protocol.Location, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.Location), args.Error(1)
+}
+
+func (m *MockClient) GetTypeDefinition(ctx context.Context, params protocol.TypeDefinitionParams) ([]protocol.Location, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.Location), args.Error(1)
+}
+
+func (m *MockClient) GetImplementation(ctx context.Context, params protocol.ImplementationParams) ([]protocol.Location, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.Location), args.Error(1)
+}
+
+func (m *MockClient) GetReferences(ctx context.Context, params protocol.ReferenceParams) ([]protocol.Location, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.Location), args.Error(1)
+}
+
+func (m *MockClient) GetHover(ctx context.Context, params protocol.HoverParams) (*protocol.Hover, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*protocol.Hover), args.Error(1)
+}
+
+func (m *MockClient) GetSignatureHelp(ctx context.Context, params protocol.SignatureHelpParams) (*protocol.SignatureHelp, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*protocol.SignatureHelp), args.Error(1)
+}
+
+func (m *MockClient) GetCompletion(ctx context.Context, params protocol.CompletionParams) (*protocol.CompletionList, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*protocol.CompletionList), args.Error(1)
+}
+
+func (m *MockClient) ResolveCompletionItem(ctx context.Context, item protocol.CompletionItem) (protocol.CompletionItem, error) {
+	args := m.Called(ctx, item)
+	return args.Get(0).(protocol.CompletionItem), args.Error(1)
+}
+
+func (m *MockClient) GetDocumentSymbols(ctx context.Context, params protocol.DocumentSymbolParams) ([]protocol.DocumentSymbol, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.DocumentSymbol), args.Error(1)
+}
+
+func (m *MockClient) PrepareCallHierarchy(ctx context.Context, params protocol.CallHierarchyPrepareParams) ([]protocol.CallHierarchyItem, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.CallHierarchyItem), args.Error(1)
+}
+
+func (m *MockClient) GetIncomingCalls(ctx context.Context, params protocol.CallHierarchyIncomingCallsParams) ([]protocol.CallHierarchyIncomingCall, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.CallHierarchyIncomingCall), args.Error(1)
+}
+
+func (m *MockClient) GetOutgoingCalls(ctx context.Context, params protocol.CallHierarchyOutgoingCallsParams) ([]protocol.CallHierarchyOutgoingCall, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.CallHierarchyOutgoingCall), args.Error(1)
+}
+
+func (m *MockClient) PrepareTypeHierarchy(ctx context.Context, params lsp.TypeHierarchyPrepareParams) ([]lsp.TypeHierarchyItem, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.TypeHierarchyItem), args.Error(1)
+}
+
+func (m *MockClient) GetSupertypes(ctx context.Context, params lsp.TypeHierarchySupertypesParams) ([]lsp.TypeHierarchyItem, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.TypeHierarchyItem), args.Error(1)
+}
+
+func (m *MockClient) GetSubtypes(ctx context.Context, params lsp.TypeHierarchySubtypesParams) ([]lsp.TypeHierarchyItem, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.TypeHierarchyItem), args.Error(1)
+}
+
+func (m *MockClient) PrepareRename(ctx context.Context, params protocol.PrepareRenameParams) (*protocol.RangeWithPlaceholder, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*protocol.RangeWithPlaceholder), args.Error(1)
+}
+
+func (m *MockClient) Rename(ctx context.Context, params protocol.RenameParams) (*protocol.WorkspaceEdit, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*protocol.WorkspaceEdit), args.Error(1)
+}
+
+func (m *MockClient) GetCodeActions(ctx context.Context, params protocol.CodeActionParams) ([]protocol.CodeAction, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.CodeAction), args.Error(1)
+}
+
+func (m *MockClient) ExecuteCommand(ctx context.Context, params protocol.ExecuteCommandParams) ([]protocol.WorkspaceEdit, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.WorkspaceEdit), args.Error(1)
+}
+
+func (m *MockClient) FormatDocument(ctx context.Context, params protocol.DocumentFormattingParams) ([]protocol.TextEdit, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.TextEdit), args.Error(1)
+}
+
+func (m *MockClient) FormatRange(ctx context.Context, params protocol.DocumentRangeFormattingParams) ([]protocol.TextEdit, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.TextEdit), args.Error(1)
+}
+
+func (m *MockClient) OrganizeImports(ctx context.Context, params protocol.CodeActionParams) ([]protocol.CodeAction, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.CodeAction), args.Error(1)
+}
+
+func (m *MockClient) Call(ctx context.Context, method string, params *json.RawMessage) (json.RawMessage, error) {
+	args := m.Called(ctx, method, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(json.RawMessage), args.Error(1)
+}
+
+func (m *MockClient) CallWithEdits(ctx context.Context, method string, params *json.RawMessage) (json.RawMessage, []protocol.WorkspaceEdit, error) {
+	args := m.Called(ctx, method, params)
+	var result json.RawMessage
+	if args.Get(0) != nil {
+		result = args.Get(0).(json.RawMessage)
+	}
+	var edits []protocol.WorkspaceEdit
+	if args.Get(1) != nil {
+		edits = args.Get(1).([]protocol.WorkspaceEdit)
+	}
+	return result, edits, args.Error(2)
+}
+
+func (m *MockClient) Notify(ctx context.Context, method string, params *json.RawMessage) error {
+	args := m.Called(ctx, method, params)
+	return args.Error(0)
+}
+
+func (m *MockClient) InitializeResult() json.RawMessage {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(json.RawMessage)
+}
+
+func (m *MockClient) SubscribeNotifications() (<-chan lsp.Notification, func()) {
+	args := m.Called()
+	return args.Get(0).(<-chan lsp.Notification), args.Get(1).(func())
+}
+
+func (m *MockClient) PullDiagnostics(ctx context.Context, params lsp.DocumentDiagnosticParams) (lsp.DocumentDiagnosticReport, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(lsp.DocumentDiagnosticReport), args.Error(1)
+}
+
+func (m *MockClient) PullWorkspaceDiagnostics(ctx context.Context, params lsp.WorkspaceDiagnosticParams) (lsp.WorkspaceDiagnosticReport, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(lsp.WorkspaceDiagnosticReport), args.Error(1)
+}
+
+func (m *MockClient) CompletionResolveProvider() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockClient) DiagnosticProvider() *lsp.DiagnosticOptions {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*lsp.DiagnosticOptions)
+}
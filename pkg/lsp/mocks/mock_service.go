@@ -2,9 +2,12 @@ package mocks
 
 import (
 	"context"
+	"time"
 
+	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/lsp"
 	"github.com/hide-org/hide/pkg/model"
+	"github.com/sourcegraph/jsonrpc2"
 	"github.com/stretchr/testify/mock"
 
 	protocol "github.com/tliron/glsp/protocol_3_16"
@@ -24,6 +27,27 @@ func (m *MockLspService) StopServer(ctx context.Context, languageId lsp.Language
 	return args.Error(0)
 }
 
+func (m *MockLspService) ServeClient(ctx context.Context, languageId lsp.LanguageId, stream jsonrpc2.ObjectStream) error {
+	args := m.Called(ctx, languageId, stream)
+	return args.Error(0)
+}
+
+func (m *MockLspService) GetServerHealth(ctx context.Context) ([]lsp.ServerHealth, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.ServerHealth), args.Error(1)
+}
+
+func (m *MockLspService) GetSupportedLanguages(ctx context.Context) []lsp.LanguageId {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]lsp.LanguageId)
+}
+
 func (m *MockLspService) GetWorkspaceSymbols(ctx context.Context, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error) {
 	args := m.Called(ctx, query)
 	if args.Get(0) == nil {
@@ -37,17 +61,155 @@ func (m *MockLspService) NotifyDidOpen(ctx context.Context, file model.File) err
 	return args.Error(0)
 }
 
+func (m *MockLspService) NotifyDidChange(ctx context.Context, file model.File) error {
+	args := m.Called(ctx, file)
+	return args.Error(0)
+}
+
 func (m *MockLspService) NotifyDidClose(ctx context.Context, file model.File) error {
 	args := m.Called(ctx, file)
 	return args.Error(0)
 }
 
+func (m *MockLspService) NotifyDidChangeWatchedFiles(ctx context.Context, events []model.FileEvent) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
 func (m *MockLspService) GetDiagnostics(ctx context.Context, file model.File) ([]protocol.Diagnostic, error) {
 	args := m.Called(ctx, file)
 	return args.Get(0).([]protocol.Diagnostic), args.Error(1)
 }
 
+func (m *MockLspService) WaitForDiagnostics(ctx context.Context, file model.File, timeout time.Duration) ([]protocol.Diagnostic, error) {
+	args := m.Called(ctx, file, timeout)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]protocol.Diagnostic), args.Error(1)
+}
+
 func (m *MockLspService) CleanupProject(ctx context.Context, projectId lsp.ProjectId) error {
 	args := m.Called(ctx, projectId)
 	return args.Error(0)
 }
+
+func (m *MockLspService) GetDefinition(ctx context.Context, file model.File, position lsp.Position) ([]lsp.Location, error) {
+	args := m.Called(ctx, file, position)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.Location), args.Error(1)
+}
+
+func (m *MockLspService) GetTypeDefinition(ctx context.Context, file model.File, position lsp.Position) ([]lsp.Location, error) {
+	args := m.Called(ctx, file, position)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.Location), args.Error(1)
+}
+
+func (m *MockLspService) GetImplementation(ctx context.Context, file model.File, position lsp.Position) ([]lsp.Location, error) {
+	args := m.Called(ctx, file, position)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.Location), args.Error(1)
+}
+
+func (m *MockLspService) GetReferences(ctx context.Context, file model.File, position lsp.Position, includeDeclaration bool) ([]lsp.Location, error) {
+	args := m.Called(ctx, file, position, includeDeclaration)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.Location), args.Error(1)
+}
+
+func (m *MockLspService) GetCompletions(ctx context.Context, file model.File, position lsp.Position, limit int) (*lsp.CompletionList, error) {
+	args := m.Called(ctx, file, position, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lsp.CompletionList), args.Error(1)
+}
+
+func (m *MockLspService) GetCallHierarchy(ctx context.Context, file model.File, position lsp.Position, direction lsp.CallHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+	args := m.Called(ctx, file, position, direction, depth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.HierarchyItem), args.Error(1)
+}
+
+func (m *MockLspService) GetTypeHierarchy(ctx context.Context, file model.File, position lsp.Position, direction lsp.TypeHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+	args := m.Called(ctx, file, position, direction, depth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.HierarchyItem), args.Error(1)
+}
+
+func (m *MockLspService) GetProjectDiagnostics(ctx context.Context, filter lsp.DiagnosticsFilter, refresh bool) (*lsp.DiagnosticsReport, error) {
+	args := m.Called(ctx, filter, refresh)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lsp.DiagnosticsReport), args.Error(1)
+}
+
+func (m *MockLspService) SubscribeDiagnostics(ctx context.Context, filter lsp.DiagnosticsFilter) (<-chan lsp.FileDiagnostics, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(<-chan lsp.FileDiagnostics), args.Error(1)
+}
+
+func (m *MockLspService) GetHover(ctx context.Context, file model.File, position lsp.Position) (*lsp.HoverInfo, error) {
+	args := m.Called(ctx, file, position)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lsp.HoverInfo), args.Error(1)
+}
+
+func (m *MockLspService) GetDocumentSymbols(ctx context.Context, file model.File) ([]lsp.DocumentSymbol, error) {
+	args := m.Called(ctx, file)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.DocumentSymbol), args.Error(1)
+}
+
+func (m *MockLspService) Rename(ctx context.Context, file model.File, position lsp.Position, newName string) ([]files.FileChange, error) {
+	args := m.Called(ctx, file, position, newName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]files.FileChange), args.Error(1)
+}
+
+func (m *MockLspService) GetCodeActions(ctx context.Context, file model.File, rng lsp.Range) ([]lsp.CodeAction, error) {
+	args := m.Called(ctx, file, rng)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]lsp.CodeAction), args.Error(1)
+}
+
+func (m *MockLspService) GetCodeActionChanges(ctx context.Context, file model.File, rng lsp.Range, title string, dryRun bool) ([]files.FileChange, error) {
+	args := m.Called(ctx, file, rng, title, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]files.FileChange), args.Error(1)
+}
+
+func (m *MockLspService) Format(ctx context.Context, file model.File, opts lsp.FormatOptions) ([]files.FileChange, error) {
+	args := m.Called(ctx, file, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]files.FileChange), args.Error(1)
+}
@@ -1,18 +1,38 @@
 package lsp
 
 import (
+	"time"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/model"
 	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
 type SymbolInfo struct {
-	Name     string   `json:"name"`
-	Kind     string   `json:"kind"`
-	Location Location `json:"location"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// ContainerName is the name of the symbol containing this one, e.g. the class of a method
+	ContainerName string   `json:"containerName,omitempty"`
+	Location      Location `json:"location"`
+}
+
+// DocumentSymbol is a symbol in a file, with the symbols it contains, e.g. the methods of a class.
+type DocumentSymbol struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Detail is e.g. the signature of a function
+	Detail string `json:"detail,omitempty"`
+	// Range covers the whole symbol, including its body and doc comment
+	Range    Range            `json:"range"`
+	Children []DocumentSymbol `json:"children,omitempty"`
 }
 
 type Location struct {
+	// Path is relative to the project root, or absolute for locations outside of the project, e.g. in the standard library
 	Path  string `json:"path"`
 	Range Range  `json:"range"`
+	// Snippet contains the lines of the range
+	Snippet []model.Line `json:"snippet,omitempty"`
 }
 
 type Range struct {
@@ -25,6 +45,170 @@ type Position struct {
 	Character int `json:"character"`
 }
 
+type HoverInfo struct {
+	// Contents describes the symbol at the position, e.g. its type and documentation, as plain text
+	Contents string `json:"contents"`
+	// Range is the range of the symbol the contents are about
+	Range *Range `json:"range,omitempty"`
+	// Signature is the signature of the call at the position, if the position is within the arguments of a call
+	Signature *SignatureInfo `json:"signature,omitempty"`
+}
+
+type SignatureInfo struct {
+	Label         string `json:"label"`
+	Documentation string `json:"documentation,omitempty"`
+	// ActiveParameter is the label of the parameter at the position
+	ActiveParameter string `json:"activeParameter,omitempty"`
+}
+
+type CompletionList struct {
+	// IsIncomplete is true if there are more completions than the items, e.g. because of the limit
+	IsIncomplete bool             `json:"isIncomplete"`
+	Items        []CompletionItem `json:"items"`
+}
+
+type CompletionItem struct {
+	Label string `json:"label"`
+	// Kind is e.g. Method, Field or Keyword
+	Kind string `json:"kind,omitempty"`
+	// Detail is e.g. the type or the signature of the item
+	Detail        string `json:"detail,omitempty"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+// HierarchyItem is a symbol in a call or type hierarchy. Its children are the symbols related to it in the direction of the hierarchy, e.g. its callers.
+type HierarchyItem struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+	// Location covers the whole symbol
+	Location Location `json:"location"`
+	// CallRanges are the ranges of the calls between the item and its parent, in the file of the caller
+	CallRanges []Range         `json:"callRanges,omitempty"`
+	Children   []HierarchyItem `json:"children,omitempty"`
+	// Truncated is set if related items of the item were left out, because the hierarchy reached its limit of items
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// DiagnosticsFilter selects diagnostics, empty fields select all diagnostics.
+type DiagnosticsFilter struct {
+	Severities []protocol.DiagnosticSeverity
+	Sources    []string
+	Paths      files.PatternFilter
+}
+
+// Validate checks the path patterns.
+func (f DiagnosticsFilter) Validate() error {
+	_, err := f.Paths.Matcher()
+	return err
+}
+
+// DiagnosticsReport contains the current diagnostics of all files of a project.
+type DiagnosticsReport struct {
+	Summary DiagnosticsCount  `json:"summary"`
+	Files   []FileDiagnostics `json:"files"`
+}
+
+type FileDiagnostics struct {
+	// Path is relative to the project root, or absolute for files outside of the project
+	Path        string                `json:"path"`
+	Counts      DiagnosticsCount      `json:"counts"`
+	Diagnostics []protocol.Diagnostic `json:"diagnostics"`
+}
+
+type DiagnosticsCount struct {
+	Errors      int `json:"errors"`
+	Warnings    int `json:"warnings"`
+	Information int `json:"information"`
+	Hints       int `json:"hints"`
+}
+
+// CodeAction is a change the language server offers for a range, e.g. a quick fix for a diagnostic or a refactoring.
+type CodeAction struct {
+	Title string `json:"title"`
+	// Kind is e.g. quickfix, refactor.extract or source.organizeImports
+	Kind string `json:"kind,omitempty"`
+	// Diagnostics are the messages of the diagnostics the action fixes
+	Diagnostics []string `json:"diagnostics,omitempty"`
+	IsPreferred bool     `json:"isPreferred,omitempty"`
+	// Disabled is the reason why the action can't be applied
+	Disabled string `json:"disabled,omitempty"`
+	// Command is run on the language server when the action is applied, so the action can't be applied with dryRun
+	Command string `json:"command,omitempty"`
+}
+
+// FormatOptions selects how a file is formatted.
+type FormatOptions struct {
+	// Range limits the formatting to the range, the whole file is formatted if it is nil
+	Range *Range
+	// OrganizeImports sorts the imports and removes unused ones before the file is formatted
+	OrganizeImports bool
+	// DryRun rejects organize imports actions that run a command, because commands can change the server state
+	DryRun bool
+}
+
+// ServerStatus is the state of a supervised language server.
+type ServerStatus string
+
+const (
+	ServerStatusRunning ServerStatus = "running"
+	// ServerStatusRestarting is the state of a server that exited and waits to be restarted
+	ServerStatusRestarting ServerStatus = "restarting"
+	// ServerStatusFailed is the state of a server that could not be started or crashed too often to be restarted
+	ServerStatusFailed ServerStatus = "failed"
+)
+
+// ServerHealth describes a language server of a project.
+type ServerHealth struct {
+	LanguageId LanguageId   `json:"languageId"`
+	Command    string       `json:"command"`
+	Status     ServerStatus `json:"status"`
+	// StartedAt is when the running process was started, it is nil if the server is not running
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	UptimeSeconds int64      `json:"uptimeSeconds"`
+	Restarts      int        `json:"restarts"`
+	LastError     string     `json:"lastError,omitempty"`
+	// Stderr contains the last lines the server wrote to stderr, also the ones of previous processes
+	Stderr []string `json:"stderr"`
+}
+
+// DefinitionKind selects what a definition request looks for.
+type DefinitionKind string
+
+const (
+	DefinitionKindDefinition     DefinitionKind = "definition"
+	DefinitionKindType           DefinitionKind = "type"
+	DefinitionKindImplementation DefinitionKind = "implementation"
+)
+
+func (k DefinitionKind) Valid() bool {
+	return k == DefinitionKindDefinition || k == DefinitionKindType || k == DefinitionKindImplementation
+}
+
+// CallHierarchyDirection selects if a call hierarchy contains the callers or the callees of a function.
+type CallHierarchyDirection string
+
+const (
+	CallHierarchyIncoming CallHierarchyDirection = "incoming"
+	CallHierarchyOutgoing CallHierarchyDirection = "outgoing"
+)
+
+func (d CallHierarchyDirection) Valid() bool {
+	return d == CallHierarchyIncoming || d == CallHierarchyOutgoing
+}
+
+// TypeHierarchyDirection selects if a type hierarchy contains the supertypes or the subtypes of a type.
+type TypeHierarchyDirection string
+
+const (
+	TypeHierarchySupertypes TypeHierarchyDirection = "supertypes"
+	TypeHierarchySubtypes   TypeHierarchyDirection = "subtypes"
+)
+
+func (d TypeHierarchyDirection) Valid() bool {
+	return d == TypeHierarchySupertypes || d == TypeHierarchySubtypes
+}
+
 func symbolKindToString(kind protocol.SymbolKind) string {
 	switch kind {
 	case protocol.SymbolKindFile:
@@ -83,3 +267,60 @@ func symbolKindToString(kind protocol.SymbolKind) string {
 		return "Unknown"
 	}
 }
+
+func completionItemKindToString(kind protocol.CompletionItemKind) string {
+	switch kind {
+	case protocol.CompletionItemKindText:
+		return "Text"
+	case protocol.CompletionItemKindMethod:
+		return "Method"
+	case protocol.CompletionItemKindFunction:
+		return "Function"
+	case protocol.CompletionItemKindConstructor:
+		return "Constructor"
+	case protocol.CompletionItemKindField:
+		return "Field"
+	case protocol.CompletionItemKindVariable:
+		return "Variable"
+	case protocol.CompletionItemKindClass:
+		return "Class"
+	case protocol.CompletionItemKindInterface:
+		return "Interface"
+	case protocol.CompletionItemKindModule:
+		return "Module"
+	case protocol.CompletionItemKindProperty:
+		return "Property"
+	case protocol.CompletionItemKindUnit:
+		return "Unit"
+	case protocol.CompletionItemKindValue:
+		return "Value"
+	case protocol.CompletionItemKindEnum:
+		return "Enum"
+	case protocol.CompletionItemKindKeyword:
+		return "Keyword"
+	case protocol.CompletionItemKindSnippet:
+		return "Snippet"
+	case protocol.CompletionItemKindColor:
+		return "Color"
+	case protocol.CompletionItemKindFile:
+		return "File"
+	case protocol.CompletionItemKindReference:
+		return "Reference"
+	case protocol.CompletionItemKindFolder:
+		return "Folder"
+	case protocol.CompletionItemKindEnumMember:
+		return "EnumMember"
+	case protocol.CompletionItemKindConstant:
+		return "Constant"
+	case protocol.CompletionItemKindStruct:
+		return "Struct"
+	case protocol.CompletionItemKindEvent:
+		return "Event"
+	case protocol.CompletionItemKindOperator:
+		return "Operator"
+	case protocol.CompletionItemKindTypeParameter:
+		return "TypeParameter"
+	default:
+		return "Unknown"
+	}
+}
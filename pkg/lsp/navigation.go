@@ -0,0 +1,134 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// GetDefinition implements Service.
+func (s *ServiceImpl) GetDefinition(ctx context.Context, file model.File, position Position) ([]Location, error) {
+	return s.findLocations(ctx, file, position, "definition", func(client Client, params protocol.TextDocumentPositionParams) ([]protocol.Location, error) {
+		return client.GetDefinition(ctx, protocol.DefinitionParams{TextDocumentPositionParams: params})
+	})
+}
+
+// GetTypeDefinition implements Service.
+func (s *ServiceImpl) GetTypeDefinition(ctx context.Context, file model.File, position Position) ([]Location, error) {
+	return s.findLocations(ctx, file, position, "type definition", func(client Client, params protocol.TextDocumentPositionParams) ([]protocol.Location, error) {
+		return client.GetTypeDefinition(ctx, protocol.TypeDefinitionParams{TextDocumentPositionParams: params})
+	})
+}
+
+// GetImplementation implements Service.
+func (s *ServiceImpl) GetImplementation(ctx context.Context, file model.File, position Position) ([]Location, error) {
+	return s.findLocations(ctx, file, position, "implementation", func(client Client, params protocol.TextDocumentPositionParams) ([]protocol.Location, error) {
+		return client.GetImplementation(ctx, protocol.ImplementationParams{TextDocumentPositionParams: params})
+	})
+}
+
+// GetReferences implements Service.
+func (s *ServiceImpl) GetReferences(ctx context.Context, file model.File, position Position, includeDeclaration bool) ([]Location, error) {
+	return s.findLocations(ctx, file, position, "references", func(client Client, params protocol.TextDocumentPositionParams) ([]protocol.Location, error) {
+		return client.GetReferences(ctx, protocol.ReferenceParams{
+			TextDocumentPositionParams: params,
+			Context:                    protocol.ReferenceContext{IncludeDeclaration: includeDeclaration},
+		})
+	})
+}
+
+func (s *ServiceImpl) findLocations(ctx context.Context, file model.File, position Position, name string, find func(Client, protocol.TextDocumentPositionParams) ([]protocol.Location, error)) ([]Location, error) {
+	project, client, err := s.getClientForFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := find(client, textDocumentPosition(project, file, position))
+	if err != nil {
+		log.Error().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msgf("Failed to get %s", name)
+		return nil, fmt.Errorf("Failed to get %s: %w", name, err)
+	}
+
+	result := make([]Location, 0, len(locations))
+	for _, location := range locations {
+		loc, err := toLocation(project, location)
+		if err != nil {
+			log.Error().Err(err).Str("URI", location.URI).Msg("Failed to convert location")
+			return nil, fmt.Errorf("Failed to convert location: %w", err)
+		}
+		result = append(result, loc)
+	}
+
+	return result, nil
+}
+
+// getClientForFile returns the client of the language server responsible for the file.
+func (s *ServiceImpl) getClientForFile(ctx context.Context, file model.File) (*model.Project, Client, error) {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		log.Error().Msg("Project not found in context")
+		return nil, nil, fmt.Errorf("Project not found in context")
+	}
+
+	languageId := s.detectLanguage(*project, file)
+	client, ok := s.getClient(ctx, languageId)
+	if !ok {
+		log.Warn().Str("languageId", languageId).Str("projectId", project.Id).Msg("LSP client not found")
+		return nil, nil, NewLanguageServerNotFoundError(project.Id, languageId)
+	}
+
+	return project, client, nil
+}
+
+func textDocument(project *model.Project, file model.File) protocol.TextDocumentIdentifier {
+	return protocol.TextDocumentIdentifier{URI: PathToURI(filepath.Join(project.Path, file.Path))}
+}
+
+func textDocumentPosition(project *model.Project, file model.File, position Position) protocol.TextDocumentPositionParams {
+	return protocol.TextDocumentPositionParams{
+		TextDocument: textDocument(project, file),
+		Position:     toProtocolPosition(position),
+	}
+}
+
+// NOTE: LSP uses 0-based line numbers, but Hide uses 1-based. Characters remain 0-based.
+func toProtocolPosition(position Position) protocol.Position {
+	return protocol.Position{Line: protocol.UInteger(max(position.Line-1, 0)), Character: protocol.UInteger(max(position.Character, 0))}
+}
+
+func toPosition(position protocol.Position) Position {
+	return Position{Line: int(position.Line) + 1, Character: int(position.Character)}
+}
+
+func toRange(r protocol.Range) Range {
+	return Range{Start: toPosition(r.Start), End: toPosition(r.End)}
+}
+
+// toLocation converts the URI to a path relative to the project, paths outside of the project stay absolute.
+func toLocation(project *model.Project, location protocol.Location) (Location, error) {
+	path, err := uriToProjectPath(project, location.URI)
+	if err != nil {
+		return Location{}, err
+	}
+
+	return Location{Path: path, Range: toRange(location.Range)}, nil
+}
+
+func uriToProjectPath(project *model.Project, uri protocol.DocumentUri) (string, error) {
+	path, err := removeFilePrefix(uri)
+	if err != nil {
+		return "", err
+	}
+
+	relativePath, err := filepath.Rel(project.Path, path)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return path, nil
+	}
+
+	return relativePath, nil
+}
@@ -0,0 +1,59 @@
+package lsp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestService_GetReferences(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+	file := model.NewFile("pkg/main.go", "package main")
+
+	client := &mocks.MockClient{}
+	client.On("GetReferences", mock.MatchedBy(isContext), protocol.ReferenceParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: "file:///test/project/pkg/main.go"},
+			Position:     protocol.Position{Line: 9, Character: 4},
+		},
+		Context: protocol.ReferenceContext{IncludeDeclaration: true},
+	}).Return([]protocol.Location{
+		{URI: "file:///test/project/pkg/lib.go", Range: protocol.Range{Start: protocol.Position{Line: 0, Character: 5}, End: protocol.Position{Line: 0, Character: 8}}},
+		{URI: "file:///usr/local/go/src/fmt/print.go", Range: protocol.Range{Start: protocol.Position{Line: 2, Character: 0}, End: protocol.Position{Line: 3, Character: 1}}},
+	}, nil)
+
+	clientPool := &mocks.MockClientPool{}
+	clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), nil, clientPool, nil)
+
+	locations, err := service.GetReferences(ctx, *file, lsp.Position{Line: 10, Character: 4}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []lsp.Location{
+		{Path: "pkg/lib.go", Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 5}, End: lsp.Position{Line: 1, Character: 8}}},
+		{Path: "/usr/local/go/src/fmt/print.go", Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 0}, End: lsp.Position{Line: 4, Character: 1}}},
+	}, locations)
+}
+
+func TestService_GetDefinition_LanguageServerNotFound(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+
+	clientPool := &mocks.MockClientPool{}
+	clientPool.On("Get", "project-id", lsp.Python).Return((*mocks.MockClient)(nil), false)
+
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), nil, clientPool, nil)
+
+	_, err := service.GetDefinition(ctx, *model.NewFile("main.py", "print()"), lsp.Position{Line: 1})
+
+	var notFound *lsp.LanguageServerNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
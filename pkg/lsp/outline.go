@@ -0,0 +1,159 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// GetDocumentSymbols implements Service.
+func (s *ServiceImpl) GetDocumentSymbols(ctx context.Context, file model.File) ([]DocumentSymbol, error) {
+	project, client, err := s.getClientForFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols, err := client.GetDocumentSymbols(ctx, protocol.DocumentSymbolParams{TextDocument: textDocument(project, file)})
+	if err != nil {
+		log.Error().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to get document symbols")
+		return nil, fmt.Errorf("Failed to get document symbols: %w", err)
+	}
+
+	return toDocumentSymbols(symbols), nil
+}
+
+func toDocumentSymbols(symbols []protocol.DocumentSymbol) []DocumentSymbol {
+	result := make([]DocumentSymbol, 0, len(symbols))
+	for _, symbol := range symbols {
+		s := DocumentSymbol{
+			Name:     symbol.Name,
+			Kind:     symbolKindToString(symbol.Kind),
+			Range:    toRange(symbol.Range),
+			Children: toDocumentSymbols(symbol.Children),
+		}
+		if symbol.Detail != nil {
+			s.Detail = *symbol.Detail
+		}
+		if len(s.Children) == 0 {
+			s.Children = nil
+		}
+		result = append(result, s)
+	}
+
+	return result
+}
+
+// parseDocumentSymbols parses a result that is either a list of DocumentSymbols or a flat list of SymbolInformation.
+// The hierarchy of a flat list is restored from the ranges of the symbols.
+func parseDocumentSymbols(data json.RawMessage) ([]protocol.DocumentSymbol, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse document symbols: %w", err)
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var probe struct {
+		Location *protocol.Location `json:"location"`
+	}
+	if err := json.Unmarshal(items[0], &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse document symbols: %w", err)
+	}
+
+	if probe.Location == nil {
+		var symbols []protocol.DocumentSymbol
+		if err := json.Unmarshal(data, &symbols); err != nil {
+			return nil, fmt.Errorf("failed to parse document symbols: %w", err)
+		}
+		return symbols, nil
+	}
+
+	var infos []protocol.SymbolInformation
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return nil, fmt.Errorf("failed to parse document symbols: %w", err)
+	}
+
+	return symbolTree(infos), nil
+}
+
+type symbolNode struct {
+	symbol   protocol.DocumentSymbol
+	children []*symbolNode
+}
+
+func symbolTree(infos []protocol.SymbolInformation) []protocol.DocumentSymbol {
+	// outer symbols come before the symbols they contain
+	sort.SliceStable(infos, func(i, j int) bool {
+		a, b := infos[i].Location.Range, infos[j].Location.Range
+		if a.Start != b.Start {
+			return comparePositions(a.Start, b.Start) < 0
+		}
+		return comparePositions(a.End, b.End) > 0
+	})
+
+	var roots []*symbolNode
+	var stack []*symbolNode
+
+	for _, info := range infos {
+		node := &symbolNode{symbol: protocol.DocumentSymbol{
+			Name:           info.Name,
+			Kind:           info.Kind,
+			Range:          info.Location.Range,
+			SelectionRange: info.Location.Range,
+		}}
+
+		for len(stack) > 0 && !containsRange(stack[len(stack)-1].symbol.Range, info.Location.Range) {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+		}
+
+		stack = append(stack, node)
+	}
+
+	return symbolNodesToSymbols(roots)
+}
+
+func symbolNodesToSymbols(nodes []*symbolNode) []protocol.DocumentSymbol {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	symbols := make([]protocol.DocumentSymbol, 0, len(nodes))
+	for _, node := range nodes {
+		symbol := node.symbol
+		symbol.Children = symbolNodesToSymbols(node.children)
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols
+}
+
+func containsRange(outer, inner protocol.Range) bool {
+	return comparePositions(outer.Start, inner.Start) <= 0 && comparePositions(inner.End, outer.End) <= 0
+}
+
+func comparePositions(a, b protocol.Position) int {
+	if a.Line != b.Line {
+		return int(a.Line) - int(b.Line)
+	}
+	return int(a.Character) - int(b.Character)
+}
@@ -0,0 +1,87 @@
+package lsp_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestClient_GetDocumentSymbols_Flat(t *testing.T) {
+	// flat symbols of a class with a method that contains a nested function, followed by a function
+	result := `[
+		{"name":"nested","kind":12,"location":{"uri":"file:///project/main.py","range":{"start":{"line":3,"character":8},"end":{"line":4,"character":16}}}},
+		{"name":"Foo","kind":5,"location":{"uri":"file:///project/main.py","range":{"start":{"line":0,"character":0},"end":{"line":5,"character":0}}}},
+		{"name":"method","kind":6,"location":{"uri":"file:///project/main.py","range":{"start":{"line":2,"character":4},"end":{"line":4,"character":16}}}},
+		{"name":"bar","kind":12,"location":{"uri":"file:///project/main.py","range":{"start":{"line":7,"character":0},"end":{"line":8,"character":8}}}}
+	]`
+
+	client := newTestClient(t, func(method string, params json.RawMessage) (any, error) {
+		return json.RawMessage(result), nil
+	})
+
+	symbols, err := client.GetDocumentSymbols(context.Background(), protocol.DocumentSymbolParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Len(t, symbols, 2) {
+		assert.Equal(t, "Foo", symbols[0].Name)
+		assert.Equal(t, "bar", symbols[1].Name)
+		assert.Empty(t, symbols[1].Children)
+
+		if assert.Len(t, symbols[0].Children, 1) {
+			method := symbols[0].Children[0]
+			assert.Equal(t, "method", method.Name)
+			if assert.Len(t, method.Children, 1) {
+				assert.Equal(t, "nested", method.Children[0].Name)
+			}
+		}
+	}
+}
+
+func TestService_GetDocumentSymbols(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+	detail := "func(ctx context.Context) error"
+
+	client := &mocks.MockClient{}
+	client.On("GetDocumentSymbols", mock.MatchedBy(isContext), protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: "file:///test/project/server.go"},
+	}).Return([]protocol.DocumentSymbol{
+		{
+			Name:  "Server",
+			Kind:  protocol.SymbolKindStruct,
+			Range: protocol.Range{Start: protocol.Position{Line: 2, Character: 0}, End: protocol.Position{Line: 4, Character: 1}},
+			Children: []protocol.DocumentSymbol{
+				{Name: "Run", Kind: protocol.SymbolKindMethod, Detail: &detail, Range: protocol.Range{Start: protocol.Position{Line: 6, Character: 0}, End: protocol.Position{Line: 8, Character: 1}}},
+			},
+		},
+	}, nil)
+
+	clientPool := &mocks.MockClientPool{}
+	clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), nil, clientPool, nil)
+
+	symbols, err := service.GetDocumentSymbols(ctx, *model.NewFile("server.go", "package main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []lsp.DocumentSymbol{
+		{
+			Name:  "Server",
+			Kind:  "Struct",
+			Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 0}, End: lsp.Position{Line: 5, Character: 1}},
+			Children: []lsp.DocumentSymbol{
+				{Name: "Run", Kind: "Method", Detail: detail, Range: lsp.Range{Start: lsp.Position{Line: 7, Character: 0}, End: lsp.Position{Line: 9, Character: 1}}},
+			},
+		},
+	}, symbols)
+}
@@ -0,0 +1,301 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	"github.com/sourcegraph/jsonrpc2"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// codeRequestCancelled is the LSP error code of requests that were cancelled by the client
+const codeRequestCancelled = -32800
+
+// editingMethods are the requests of a document whose result are edits of it. The document is locked while they run, so that Hide
+// doesn't change it before the client applies the edits.
+var editingMethods = map[string]bool{
+	"textDocument/codeAction":        true,
+	"textDocument/formatting":        true,
+	"textDocument/onTypeFormatting":  true,
+	"textDocument/rangeFormatting":   true,
+	"textDocument/rename":            true,
+	"textDocument/willSaveWaitUntil": true,
+}
+
+// ServeClient implements Service.
+func (s *ServiceImpl) ServeClient(ctx context.Context, languageId LanguageId, stream jsonrpc2.ObjectStream) error {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		log.Error().Msg("Project not found in context")
+		return fmt.Errorf("Project not found in context")
+	}
+
+	client, ok := s.getClient(ctx, languageId)
+	if !ok {
+		return NewLanguageServerNotFoundError(project.Id, languageId)
+	}
+
+	notifications, unsubscribe := client.SubscribeNotifications()
+	defer unsubscribe()
+
+	session := &passthroughSession{service: s, project: *project, client: client, pending: make(map[jsonrpc2.ID]context.CancelFunc)}
+	session.handler = jsonrpc2.HandlerWithError(session.handle).SuppressErrClosed()
+
+	// the client sees the URIs of project files as file:///<path relative to the project>
+	stream = newPathMappingStream(stream, PathMapping{Host: project.Path, Server: ""})
+	conn := jsonrpc2.NewConn(ctx, stream, session)
+	defer conn.Close()
+
+	log.Debug().Str("projectId", project.Id).Str("languageId", languageId).Msg("Passing client through to language server")
+
+	for {
+		select {
+		case <-conn.DisconnectNotify():
+			return nil
+		case <-ctx.Done():
+			return nil
+		case notification, ok := <-notifications:
+			if !ok {
+				// the language server exited, the client has to reconnect to the restarted one
+				return nil
+			}
+
+			if err := conn.Notify(ctx, notification.Method, rawParams(notification.Params)); err != nil {
+				log.Debug().Err(err).Str("projectId", project.Id).Str("method", notification.Method).Msg("Failed to pass notification to client")
+			}
+		}
+	}
+}
+
+// passthroughSession forwards the messages of a client to a language server it shares with Hide. The lifecycle of the server and
+// the documents are managed by Hide, so the client is answered for them without forwarding its messages as is. Requests that
+// return edits are serialized with Hide, either by the lock of their document or, for commands, by the lock of the commands.
+type passthroughSession struct {
+	service *ServiceImpl
+	project model.Project
+	client  Client
+	handler jsonrpc2.Handler
+
+	mu sync.Mutex
+	// pending cancels the requests of the client that are being forwarded
+	pending map[jsonrpc2.ID]context.CancelFunc
+}
+
+// Handle handles notifications in the order they are received and requests concurrently,
+// so that document changes are applied in order and slow requests don't block the client.
+func (s *passthroughSession) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	if req.Notif {
+		s.handler.Handle(ctx, conn, req)
+		return
+	}
+
+	// the request is registered before the next message is read, so that it can be cancelled by it
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.pending[req.ID] = cancel
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.pending, req.ID)
+			s.mu.Unlock()
+			cancel()
+		}()
+
+		s.handler.Handle(ctx, conn, req)
+	}()
+}
+
+func (s *passthroughSession) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		// the server is already initialized, the client gets its capabilities
+		return s.client.InitializeResult(), nil
+	case "initialized", "shutdown":
+		return nil, nil
+	case "exit":
+		go conn.Close()
+		return nil, nil
+	case "$/cancelRequest":
+		var params struct {
+			ID jsonrpc2.ID `json:"id"`
+		}
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		s.cancel(params.ID)
+		return nil, nil
+	case "textDocument/didOpen":
+		return nil, s.didOpen(ctx, req)
+	case "textDocument/didChange":
+		return nil, s.didChange(ctx, req)
+	case "textDocument/didClose":
+		// documents stay open for the requests of Hide
+		return nil, nil
+	}
+
+	if req.Notif {
+		return nil, s.client.Notify(ctx, req.Method, req.Params)
+	}
+
+	var result json.RawMessage
+	var err error
+	switch {
+	case req.Method == "workspace/executeCommand":
+		result, err = s.executeCommand(ctx, conn, req)
+	case editingMethods[req.Method]:
+		err = s.service.documents.withDocument(s.project.Id, documentURI(req), func() error {
+			result, err = s.client.Call(ctx, req.Method, req.Params)
+			return err
+		})
+	default:
+		result, err = s.client.Call(ctx, req.Method, req.Params)
+	}
+
+	if ctx.Err() != nil {
+		return nil, &jsonrpc2.Error{Code: codeRequestCancelled, Message: "Request cancelled"}
+	}
+
+	return result, err
+}
+
+// executeCommand runs the command like the commands of Hide, one at a time. The server sends the edits of the command to Hide,
+// which acknowledges them, and they are passed on to the client to apply them.
+func (s *passthroughSession) executeCommand(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (json.RawMessage, error) {
+	result, edits, err := s.client.CallWithEdits(ctx, req.Method, req.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, edit := range edits {
+		var response protocol.ApplyWorkspaceEditResponse
+		if err := conn.Call(ctx, "workspace/applyEdit", protocol.ApplyWorkspaceEditParams{Edit: edit}, &response); err != nil {
+			log.Warn().Err(err).Str("projectId", s.project.Id).Msg("Failed to pass edit of command to client")
+		} else if !response.Applied {
+			log.Debug().Str("projectId", s.project.Id).Msg("Client didn't apply edit of command")
+		}
+	}
+
+	return result, nil
+}
+
+// documentURI returns the URI of the text document of the request, it is empty if the request has none.
+func documentURI(req *jsonrpc2.Request) protocol.DocumentUri {
+	var params struct {
+		TextDocument protocol.TextDocumentIdentifier `json:"textDocument"`
+	}
+	if req.Params != nil {
+		_ = json.Unmarshal(*req.Params, &params)
+	}
+
+	return params.TextDocument.URI
+}
+
+func (s *passthroughSession) cancel(id jsonrpc2.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cancel, ok := s.pending[id]; ok {
+		cancel()
+	}
+}
+
+// didOpen opens the document like Hide does, so that versions of the document keep increasing for both.
+func (s *passthroughSession) didOpen(ctx context.Context, req *jsonrpc2.Request) error {
+	var params protocol.DidOpenTextDocumentParams
+	if err := unmarshalParams(req, &params); err != nil {
+		return err
+	}
+
+	path, ok := s.relativePath(params.TextDocument.URI)
+	if !ok {
+		return s.client.Notify(ctx, req.Method, req.Params)
+	}
+
+	return s.sync(ctx, path, params.TextDocument.Text)
+}
+
+// didChange applies the changes to the content of the document and sends the whole content like Hide does.
+func (s *passthroughSession) didChange(ctx context.Context, req *jsonrpc2.Request) error {
+	var params struct {
+		TextDocument   protocol.VersionedTextDocumentIdentifier `json:"textDocument"`
+		ContentChanges []struct {
+			Range *protocol.Range `json:"range"`
+			Text  string          `json:"text"`
+		} `json:"contentChanges"`
+	}
+	if err := unmarshalParams(req, &params); err != nil {
+		return err
+	}
+
+	uri := params.TextDocument.URI
+	path, ok := s.relativePath(uri)
+	if !ok {
+		return s.client.Notify(ctx, req.Method, req.Params)
+	}
+
+	content, ok := s.service.documents.content(s.project.Id, uri)
+	if !ok {
+		return fmt.Errorf("Document %s is not open", path)
+	}
+
+	for _, change := range params.ContentChanges {
+		if change.Range == nil {
+			content = change.Text
+			continue
+		}
+
+		// changes are applied one after the other, each to the result of the previous one
+		edit := files.TextEdit{
+			StartLine:      int(change.Range.Start.Line) + 1,
+			StartCharacter: int(change.Range.Start.Character),
+			EndLine:        int(change.Range.End.Line) + 1,
+			EndCharacter:   int(change.Range.End.Character),
+			NewText:        change.Text,
+		}
+
+		var err error
+		if content, err = files.ApplyTextEdits(content, []files.TextEdit{edit}); err != nil {
+			return fmt.Errorf("Failed to apply change to %s: %w", path, err)
+		}
+	}
+
+	return s.sync(ctx, path, content)
+}
+
+func (s *passthroughSession) sync(ctx context.Context, path, content string) error {
+	_, err := s.service.syncDocument(model.NewContextWithProject(ctx, &s.project), *model.NewFile(path, content), true)
+	return err
+}
+
+// relativePath returns the path of a file in the project, or false for files outside of it
+func (s *passthroughSession) relativePath(uri protocol.DocumentUri) (string, bool) {
+	path, err := removeFilePrefix(uri)
+	if err != nil {
+		return "", false
+	}
+
+	relative, err := filepath.Rel(s.project.Path, path)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return relative, true
+}
+
+func unmarshalParams(req *jsonrpc2.Request, v any) error {
+	if req.Params == nil {
+		return fmt.Errorf("Missing params of %s", req.Method)
+	}
+
+	return json.Unmarshal(*req.Params, v)
+}
@@ -0,0 +1,242 @@
+package lsp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_ServeClient(t *testing.T) {
+	server := &passthroughServer{release: make(chan struct{})}
+	client := newTestClientWithHandler(t, jsonrpc2.AsyncHandler(jsonrpc2.HandlerWithError(server.handle)))
+	if _, err := client.Initialize(context.Background(), lsp.InitializeParams{}); err != nil {
+		t.Fatal(err)
+	}
+
+	clientPool := lsp.NewClientPool()
+	clientPool.Set("project-id", lsp.Go, client)
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), lsp.NewDiagnosticsStore(), clientPool, nil)
+
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+	remote := connectClient(t, ctx, service)
+
+	t.Run("initialize returns the capabilities of the running server", func(t *testing.T) {
+		var result map[string]any
+		if err := remote.conn.Call(ctx, "initialize", map[string]any{}, &result); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, map[string]any{"capabilities": map[string]any{"definitionProvider": true, "experimentalFeature": true}}, result)
+	})
+
+	t.Run("requests and notifications are passed through with relative URIs", func(t *testing.T) {
+		var result []map[string]any
+		err := remote.conn.Call(ctx, "textDocument/definition", map[string]any{"textDocument": map[string]any{"uri": "file:///main.go"}, "position": map[string]any{"line": 1, "character": 2}}, &result)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, "file:///test/project/main.go", server.lastURI())
+		assert.Equal(t, "file:///util/util.go", result[0]["uri"])
+
+		select {
+		case notification := <-remote.notifications:
+			assert.Equal(t, "window/logMessage", notification.Method)
+			assert.JSONEq(t, `{"type":3,"message":"file:///main.go"}`, string(*notification.Params))
+		case <-time.After(time.Second):
+			t.Fatal("notification not passed through")
+		}
+	})
+
+	t.Run("errors of the server are passed through", func(t *testing.T) {
+		err := remote.conn.Call(ctx, "custom/unknown", nil, nil)
+
+		var rpcErr *jsonrpc2.Error
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected jsonrpc2 error, got %v", err)
+		}
+		assert.Equal(t, int64(jsonrpc2.CodeMethodNotFound), rpcErr.Code)
+		assert.Equal(t, "method not found", rpcErr.Message)
+	})
+
+	t.Run("commands are passed through with their edits", func(t *testing.T) {
+		var result string
+		if err := remote.conn.Call(ctx, "workspace/executeCommand", map[string]any{"command": "gopls.tidy"}, &result); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "tidied", result)
+
+		select {
+		case request := <-remote.notifications:
+			assert.Equal(t, "workspace/applyEdit", request.Method)
+			assert.JSONEq(t, `{"edit":{"changes":{"file:///go.mod":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":0}},"newText":"module app\n"}]}}}`, string(*request.Params))
+		case <-time.After(time.Second):
+			t.Fatal("edit not passed to client")
+		}
+	})
+
+	t.Run("documents are synced with full content", func(t *testing.T) {
+		if err := remote.conn.Notify(ctx, "textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": "file:///main.go", "languageId": "go", "version": 7, "text": "package main\n\nfunc main() {}\n"}}); err != nil {
+			t.Fatal(err)
+		}
+
+		change := map[string]any{"range": map[string]any{"start": map[string]any{"line": 2, "character": 5}, "end": map[string]any{"line": 2, "character": 9}}, "text": "run"}
+		if err := remote.conn.Notify(ctx, "textDocument/didChange", map[string]any{"textDocument": map[string]any{"uri": "file:///main.go", "version": 8}, "contentChanges": []any{change}}); err != nil {
+			t.Fatal(err)
+		}
+
+		waitForCondition(t, func() bool { return len(server.documentSync()) == 2 })
+		changes := server.documentSync()
+		assert.Equal(t, "textDocument/didOpen", changes[0].Method)
+		assert.JSONEq(t, `{"textDocument":{"uri":"file:///test/project/main.go","languageId":"","version":1,"text":"package main\n\nfunc main() {}\n"}}`, string(*changes[0].Params))
+		assert.Equal(t, "textDocument/didChange", changes[1].Method)
+		assert.JSONEq(t, `{"textDocument":{"uri":"file:///test/project/main.go","version":2},"contentChanges":[{"text":"package main\n\nfunc run() {}\n"}]}`, string(*changes[1].Params))
+	})
+
+	t.Run("requests are cancelled", func(t *testing.T) {
+		call, err := remote.conn.DispatchCall(ctx, "textDocument/hover", nil, jsonrpc2.PickID(jsonrpc2.ID{Num: 1000}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := remote.conn.Notify(ctx, "$/cancelRequest", map[string]any{"id": 1000}); err != nil {
+			t.Fatal(err)
+		}
+
+		err = call.Wait(ctx, nil)
+		close(server.release)
+
+		var rpcErr *jsonrpc2.Error
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected jsonrpc2 error, got %v", err)
+		}
+		assert.Equal(t, int64(-32800), rpcErr.Code)
+	})
+}
+
+func TestService_ServeClient_NotFound(t *testing.T) {
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), lsp.NewDiagnosticsStore(), lsp.NewClientPool(), nil)
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	err := service.ServeClient(ctx, lsp.Go, jsonrpc2.NewBufferedStream(a, jsonrpc2.VSCodeObjectCodec{}))
+
+	var notFound *lsp.LanguageServerNotFoundError
+	assert.True(t, errors.As(err, &notFound))
+}
+
+type remoteClient struct {
+	conn          *jsonrpc2.Conn
+	notifications chan *jsonrpc2.Request
+}
+
+// connectClient connects a JSON-RPC client like an editor to the Go server of the service.
+func connectClient(t *testing.T, ctx context.Context, service lsp.Service) *remoteClient {
+	t.Helper()
+
+	serviceConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		serviceConn.Close()
+		clientConn.Close()
+	})
+
+	go service.ServeClient(ctx, lsp.Go, jsonrpc2.NewBufferedStream(serviceConn, jsonrpc2.VSCodeObjectCodec{}))
+
+	remote := &remoteClient{notifications: make(chan *jsonrpc2.Request, 10)}
+	remote.conn = jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(clientConn, jsonrpc2.VSCodeObjectCodec{}), jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+		remote.notifications <- req
+		return nil, nil
+	}))
+	t.Cleanup(func() { remote.conn.Close() })
+
+	return remote
+}
+
+// passthroughServer is a language server that answers definition requests and records the synced documents.
+type passthroughServer struct {
+	release chan struct{}
+
+	mu      sync.Mutex
+	uri     string
+	changes []*jsonrpc2.Request
+}
+
+func (s *passthroughServer) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return json.RawMessage(`{"capabilities":{"definitionProvider":true,"experimentalFeature":true}}`), nil
+	case "textDocument/definition":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(*req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		s.uri = params.TextDocument.URI
+		s.mu.Unlock()
+
+		if err := conn.Notify(ctx, "window/logMessage", map[string]any{"type": 3, "message": params.TextDocument.URI}); err != nil {
+			return nil, err
+		}
+
+		return []any{map[string]any{"uri": "file:///test/project/util/util.go", "range": map[string]any{}}}, nil
+	case "textDocument/didOpen", "textDocument/didChange":
+		s.mu.Lock()
+		s.changes = append(s.changes, req)
+		s.mu.Unlock()
+		return nil, nil
+	case "textDocument/hover":
+		<-s.release
+		return nil, nil
+	case "workspace/executeCommand":
+		edit := map[string]any{"changes": map[string]any{"file:///test/project/go.mod": []any{map[string]any{"range": map[string]any{"start": map[string]any{"line": 0, "character": 0}, "end": map[string]any{"line": 0, "character": 0}}, "newText": "module app\n"}}}}
+		if err := conn.Call(ctx, "workspace/applyEdit", map[string]any{"edit": edit}, nil); err != nil {
+			return nil, err
+		}
+		return "tidied", nil
+	}
+
+	return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: "method not found"}
+}
+
+func (s *passthroughServer) lastURI() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.uri
+}
+
+func (s *passthroughServer) documentSync() []*jsonrpc2.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*jsonrpc2.Request{}, s.changes...)
+}
+
+func waitForCondition(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
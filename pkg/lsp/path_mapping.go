@@ -0,0 +1,78 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// PathMapping maps the project path on the host to the path seen by the language server.
+// The zero value maps paths to themselves.
+type PathMapping struct {
+	Host   string
+	Server string
+}
+
+func (m PathMapping) identity() bool {
+	return m.Host == m.Server
+}
+
+// pathMappingStream rewrites file URIs of messages sent to the language server from host to server paths, and back for the messages it sends.
+type pathMappingStream struct {
+	jsonrpc2.ObjectStream
+	toServer *uriRewriter
+	toHost   *uriRewriter
+}
+
+func newPathMappingStream(stream jsonrpc2.ObjectStream, mapping PathMapping) jsonrpc2.ObjectStream {
+	if mapping.identity() {
+		return stream
+	}
+
+	return &pathMappingStream{
+		ObjectStream: stream,
+		toServer:     newURIRewriter(mapping.Host, mapping.Server),
+		toHost:       newURIRewriter(mapping.Server, mapping.Host),
+	}
+}
+
+func (s *pathMappingStream) WriteObject(obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	return s.ObjectStream.WriteObject(json.RawMessage(s.toServer.rewrite(data)))
+}
+
+func (s *pathMappingStream) ReadObject(v interface{}) error {
+	var data json.RawMessage
+	if err := s.ObjectStream.ReadObject(&data); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(s.toHost.rewrite(data), v)
+}
+
+type uriRewriter struct {
+	pattern     *regexp.Regexp
+	replacement []byte
+}
+
+func newURIRewriter(from, to string) *uriRewriter {
+	// only whole path segments are replaced, /project must not match /project2
+	return &uriRewriter{
+		pattern:     regexp.MustCompile(`"file://` + regexp.QuoteMeta(from) + `([/"])`),
+		replacement: []byte(`"file://` + to + `$1`),
+	}
+}
+
+func (r *uriRewriter) rewrite(data []byte) []byte {
+	if !bytes.Contains(data, []byte(`"file://`)) {
+		return data
+	}
+
+	return r.pattern.ReplaceAll(data, r.replacement)
+}
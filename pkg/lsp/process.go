@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/hide-org/hide/pkg/model"
 )
 
 type Command struct {
@@ -15,6 +19,16 @@ func NewCommand(name string, args []string) Command {
 	return Command{name: name, args: args}
 }
 
+func (c Command) String() string {
+	return strings.Join(append([]string{c.name}, c.args...), " ")
+}
+
+// ProcessFactory creates language server processes for a project.
+type ProcessFactory interface {
+	// NewProcess returns the process and how the project path maps to the path seen by the language server. The stderr of the process is written to stderr if it's not nil.
+	NewProcess(project model.Project, command Command, stderr io.Writer) (Process, PathMapping, error)
+}
+
 type Process interface {
 	Start() error
 	Stop() error
@@ -39,10 +53,14 @@ func (rwc *readWriteCloser) Close() error {
 type ProcessImpl struct {
 	cmd *exec.Cmd
 	rwc io.ReadWriteCloser
+	// wait makes Wait safe to call more than once, e.g. by the client on shutdown and by the supervisor
+	wait    sync.Once
+	waitErr error
 }
 
-func NewProcess(command Command) (Process, error) {
+func NewProcess(command Command, stderr io.Writer) (Process, error) {
 	cmd := exec.Command(command.name, command.args...)
+	cmd.Stderr = stderr
 	stdin, err := cmd.StdinPipe()
 
 	if err != nil {
@@ -76,5 +94,20 @@ func (p *ProcessImpl) ReadWriteCloser() io.ReadWriteCloser {
 }
 
 func (p *ProcessImpl) Wait() error {
-	return p.cmd.Wait()
+	p.wait.Do(func() {
+		p.waitErr = p.cmd.Wait()
+	})
+	return p.waitErr
+}
+
+// HostProcessFactory runs language servers on the host, where they see the project path as is.
+type HostProcessFactory struct{}
+
+func NewHostProcessFactory() ProcessFactory {
+	return &HostProcessFactory{}
+}
+
+func (f *HostProcessFactory) NewProcess(project model.Project, command Command, stderr io.Writer) (Process, PathMapping, error) {
+	process, err := NewProcess(command, stderr)
+	return process, PathMapping{}, err
 }
@@ -0,0 +1,133 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// GetProjectDiagnostics implements Service.
+func (s *ServiceImpl) GetProjectDiagnostics(ctx context.Context, filter DiagnosticsFilter, refresh bool) (*DiagnosticsReport, error) {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		log.Error().Msg("Project not found in context")
+		return nil, fmt.Errorf("Project not found in context")
+	}
+
+	matchPath, err := filter.Paths.Matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if refresh {
+		s.pullWorkspaceDiagnostics(ctx, project)
+	}
+
+	report := &DiagnosticsReport{Files: []FileDiagnostics{}}
+	for uri, diagnostics := range s.diagnosticsStore.GetAllForProject(project.Id) {
+		path, err := uriToProjectPath(project, uri)
+		if err != nil {
+			log.Warn().Err(err).Str("projectId", project.Id).Str("URI", uri).Msg("Failed to convert diagnostics URI")
+			continue
+		}
+
+		if !matchPath(path) {
+			continue
+		}
+
+		file := filterDiagnostics(path, diagnostics, filter)
+		if len(file.Diagnostics) == 0 {
+			continue
+		}
+
+		report.Summary.merge(file.Counts)
+		report.Files = append(report.Files, file)
+	}
+
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+
+	return report, nil
+}
+
+// filterDiagnostics returns the diagnostics of the file that match the filter, ordered by their position.
+func filterDiagnostics(path string, diagnostics []protocol.Diagnostic, filter DiagnosticsFilter) FileDiagnostics {
+	file := FileDiagnostics{Path: path, Diagnostics: []protocol.Diagnostic{}}
+	for _, diagnostic := range diagnostics {
+		if !filter.matches(diagnostic) {
+			continue
+		}
+
+		file.Diagnostics = append(file.Diagnostics, diagnostic)
+		file.Counts.add(severity(diagnostic))
+	}
+
+	sort.SliceStable(file.Diagnostics, func(i, j int) bool {
+		return comparePositions(file.Diagnostics[i].Range.Start, file.Diagnostics[j].Range.Start) < 0
+	})
+
+	return file
+}
+
+func (f DiagnosticsFilter) matches(diagnostic protocol.Diagnostic) bool {
+	if len(f.Severities) > 0 && !slices.Contains(f.Severities, severity(diagnostic)) {
+		return false
+	}
+
+	if len(f.Sources) > 0 && (diagnostic.Source == nil || !slices.Contains(f.Sources, *diagnostic.Source)) {
+		return false
+	}
+
+	return true
+}
+
+// severity returns the severity of the diagnostic, diagnostics without one are errors like in most clients.
+func severity(diagnostic protocol.Diagnostic) protocol.DiagnosticSeverity {
+	if diagnostic.Severity == nil {
+		return protocol.DiagnosticSeverityError
+	}
+
+	return *diagnostic.Severity
+}
+
+func (c *DiagnosticsCount) add(severity protocol.DiagnosticSeverity) {
+	switch severity {
+	case protocol.DiagnosticSeverityWarning:
+		c.Warnings++
+	case protocol.DiagnosticSeverityInformation:
+		c.Information++
+	case protocol.DiagnosticSeverityHint:
+		c.Hints++
+	default:
+		c.Errors++
+	}
+}
+
+func (c *DiagnosticsCount) merge(other DiagnosticsCount) {
+	c.Errors += other.Errors
+	c.Warnings += other.Warnings
+	c.Information += other.Information
+	c.Hints += other.Hints
+}
+
+// ParseDiagnosticSeverity parses the name of a severity, e.g. error or warning.
+func ParseDiagnosticSeverity(name string) (protocol.DiagnosticSeverity, bool) {
+	switch name {
+	case "error":
+		return protocol.DiagnosticSeverityError, true
+	case "warning":
+		return protocol.DiagnosticSeverityWarning, true
+	case "information":
+		return protocol.DiagnosticSeverityInformation, true
+	case "hint":
+		return protocol.DiagnosticSeverityHint, true
+	default:
+		return 0, false
+	}
+}
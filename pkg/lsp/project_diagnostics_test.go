@@ -0,0 +1,86 @@
+package lsp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestService_GetProjectDiagnostics(t *testing.T) {
+	severity := func(s protocol.DiagnosticSeverity) *protocol.DiagnosticSeverity { return &s }
+	source := func(s string) *string { return &s }
+	at := func(line protocol.UInteger) protocol.Range {
+		return protocol.Range{Start: protocol.Position{Line: line}, End: protocol.Position{Line: line, Character: 3}}
+	}
+
+	unused := protocol.Diagnostic{Range: at(4), Severity: severity(protocol.DiagnosticSeverityWarning), Source: source("unusedfunc"), Message: "function unused is unused"}
+	undefined := protocol.Diagnostic{Range: at(2), Severity: severity(protocol.DiagnosticSeverityError), Source: source("compiler"), Message: "undefined: foo"}
+	noSeverity := protocol.Diagnostic{Range: at(7), Source: source("compiler"), Message: "missing return"}
+	hint := protocol.Diagnostic{Range: at(1), Severity: severity(protocol.DiagnosticSeverityHint), Message: "could be simplified"}
+
+	diagnosticsStore := lsp.NewDiagnosticsStore()
+	diagnosticsStore.Set("project-id", "file:///test/project/main.go", []protocol.Diagnostic{unused, undefined})
+	diagnosticsStore.Set("project-id", "file:///test/project/util/util.go", []protocol.Diagnostic{noSeverity, hint})
+	diagnosticsStore.Set("project-id", "file:///test/project/fixed.go", []protocol.Diagnostic{})
+	diagnosticsStore.Set("other-project", "file:///test/other/main.go", []protocol.Diagnostic{undefined})
+
+	tests := []struct {
+		name   string
+		filter lsp.DiagnosticsFilter
+		want   *lsp.DiagnosticsReport
+	}{
+		{
+			name: "all",
+			want: &lsp.DiagnosticsReport{
+				Summary: lsp.DiagnosticsCount{Errors: 2, Warnings: 1, Hints: 1},
+				Files: []lsp.FileDiagnostics{
+					{Path: "main.go", Counts: lsp.DiagnosticsCount{Errors: 1, Warnings: 1}, Diagnostics: []protocol.Diagnostic{undefined, unused}},
+					{Path: "util/util.go", Counts: lsp.DiagnosticsCount{Errors: 1, Hints: 1}, Diagnostics: []protocol.Diagnostic{hint, noSeverity}},
+				},
+			},
+		},
+		{
+			name:   "errors",
+			filter: lsp.DiagnosticsFilter{Severities: []protocol.DiagnosticSeverity{protocol.DiagnosticSeverityError}},
+			want: &lsp.DiagnosticsReport{
+				Summary: lsp.DiagnosticsCount{Errors: 2},
+				Files: []lsp.FileDiagnostics{
+					{Path: "main.go", Counts: lsp.DiagnosticsCount{Errors: 1}, Diagnostics: []protocol.Diagnostic{undefined}},
+					{Path: "util/util.go", Counts: lsp.DiagnosticsCount{Errors: 1}, Diagnostics: []protocol.Diagnostic{noSeverity}},
+				},
+			},
+		},
+		{
+			name:   "source and path",
+			filter: lsp.DiagnosticsFilter{Sources: []string{"compiler"}, Paths: files.PatternFilter{Include: []string{"util/**"}}},
+			want: &lsp.DiagnosticsReport{
+				Summary: lsp.DiagnosticsCount{Errors: 1},
+				Files:   []lsp.FileDiagnostics{{Path: "util/util.go", Counts: lsp.DiagnosticsCount{Errors: 1}, Diagnostics: []protocol.Diagnostic{noSeverity}}},
+			},
+		},
+		{
+			name:   "nothing found",
+			filter: lsp.DiagnosticsFilter{Paths: files.PatternFilter{Exclude: []string{"*.go", "**/*.go"}}},
+			want:   &lsp.DiagnosticsReport{Files: []lsp.FileDiagnostics{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+			service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), diagnosticsStore, lsp.NewClientPool(), nil)
+
+			got, err := service.GetProjectDiagnostics(ctx, tt.filter, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
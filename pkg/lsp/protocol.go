@@ -0,0 +1,116 @@
+package lsp
+
+import protocol "github.com/tliron/glsp/protocol_3_16"
+
+// Type hierarchy was added in LSP 3.17, which glsp doesn't implement yet.
+
+type TypeHierarchyPrepareParams struct {
+	protocol.TextDocumentPositionParams
+	protocol.WorkDoneProgressParams
+}
+
+// TypeHierarchyItem has the same fields as a CallHierarchyItem.
+type TypeHierarchyItem struct {
+	Name           string               `json:"name"`
+	Kind           protocol.SymbolKind  `json:"kind"`
+	Tags           []protocol.SymbolTag `json:"tags,omitempty"`
+	Detail         *string              `json:"detail,omitempty"`
+	URI            protocol.DocumentUri `json:"uri"`
+	Range          protocol.Range       `json:"range"`
+	SelectionRange protocol.Range       `json:"selectionRange"`
+	Data           any                  `json:"data,omitempty"`
+}
+
+type TypeHierarchySupertypesParams struct {
+	protocol.WorkDoneProgressParams
+	protocol.PartialResultParams
+
+	Item TypeHierarchyItem `json:"item"`
+}
+
+type TypeHierarchySubtypesParams struct {
+	protocol.WorkDoneProgressParams
+	protocol.PartialResultParams
+
+	Item TypeHierarchyItem `json:"item"`
+}
+
+// Pull diagnostics were added in LSP 3.17 as well, the capabilities of the client are extended with them.
+
+type InitializeParams struct {
+	protocol.InitializeParams
+
+	Capabilities ClientCapabilities `json:"capabilities"`
+}
+
+type ClientCapabilities struct {
+	protocol.ClientCapabilities
+
+	TextDocument *TextDocumentClientCapabilities `json:"textDocument,omitempty"`
+}
+
+type TextDocumentClientCapabilities struct {
+	protocol.TextDocumentClientCapabilities
+
+	Diagnostic *DiagnosticClientCapabilities `json:"diagnostic,omitempty"`
+}
+
+type DiagnosticClientCapabilities struct {
+	DynamicRegistration    *bool `json:"dynamicRegistration,omitempty"`
+	RelatedDocumentSupport *bool `json:"relatedDocumentSupport,omitempty"`
+}
+
+// DiagnosticOptions is the diagnosticProvider of the server capabilities, registration options have the same fields and more.
+type DiagnosticOptions struct {
+	Identifier            *string `json:"identifier,omitempty"`
+	InterFileDependencies bool    `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool    `json:"workspaceDiagnostics"`
+}
+
+const (
+	DocumentDiagnosticReportKindFull      = "full"
+	DocumentDiagnosticReportKindUnchanged = "unchanged"
+)
+
+type DocumentDiagnosticParams struct {
+	protocol.WorkDoneProgressParams
+	protocol.PartialResultParams
+
+	TextDocument     protocol.TextDocumentIdentifier `json:"textDocument"`
+	Identifier       *string                         `json:"identifier,omitempty"`
+	PreviousResultID *string                         `json:"previousResultId,omitempty"`
+}
+
+// DocumentDiagnosticReport is a full report with items or an unchanged report with only the result id, depending on its kind.
+type DocumentDiagnosticReport struct {
+	Kind     string                `json:"kind"`
+	ResultID *string               `json:"resultId,omitempty"`
+	Items    []protocol.Diagnostic `json:"items,omitempty"`
+	// RelatedDocuments are the reports of other documents whose diagnostics changed with the document
+	RelatedDocuments map[protocol.DocumentUri]DocumentDiagnosticReport `json:"relatedDocuments,omitempty"`
+}
+
+type PreviousResultID struct {
+	URI   protocol.DocumentUri `json:"uri"`
+	Value string               `json:"value"`
+}
+
+type WorkspaceDiagnosticParams struct {
+	protocol.WorkDoneProgressParams
+	protocol.PartialResultParams
+
+	Identifier        *string            `json:"identifier,omitempty"`
+	PreviousResultIDs []PreviousResultID `json:"previousResultIds"`
+}
+
+type WorkspaceDiagnosticReport struct {
+	Items []WorkspaceDocumentDiagnosticReport `json:"items"`
+}
+
+type WorkspaceDocumentDiagnosticReport struct {
+	DocumentDiagnosticReport
+
+	URI protocol.DocumentUri `json:"uri"`
+	// Version is nil if the document is not open
+	Version *protocol.Integer `json:"version"`
+}
@@ -0,0 +1,198 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/jsonc"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// ServerConfig describes how to start a language server and which files it serves.
+type ServerConfig struct {
+	Command Command
+	// Languages are the languages the server is started for, the IDs of the LanguageDetector
+	Languages []LanguageId
+	// FilePatterns are glob patterns of files that belong to the first language of the server, even if they are detected as another language
+	FilePatterns []string
+	// InitializationOptions are sent with the initialize request
+	InitializationOptions any
+	// Settings are returned for workspace/configuration requests and sent with workspace/didChangeConfiguration
+	Settings any
+
+	matchFile func(path string) bool
+}
+
+// NewServerConfig converts and validates a language server of the Hide configuration or of a devcontainer.json.
+func NewServerConfig(server devcontainer.LanguageServer) (ServerConfig, error) {
+	if server.Command == "" {
+		return ServerConfig{}, errors.New("command must be provided")
+	}
+
+	if len(server.LanguageIds) == 0 {
+		return ServerConfig{}, fmt.Errorf("languageIds of %s must be provided", server.Command)
+	}
+
+	matchFile, err := files.PatternFilter{Include: server.FilePatterns}.Matcher()
+	if err != nil {
+		return ServerConfig{}, fmt.Errorf("invalid filePatterns of %s: %w", server.Command, err)
+	}
+
+	config := ServerConfig{
+		Command:               NewCommand(server.Command, server.Args),
+		Languages:             server.LanguageIds,
+		FilePatterns:          server.FilePatterns,
+		InitializationOptions: server.InitializationOptions,
+		Settings:              server.Settings,
+	}
+
+	if len(server.FilePatterns) > 0 {
+		// patterns without a directory, e.g. Gemfile, match the file in any directory
+		config.matchFile = func(path string) bool {
+			return matchFile(path) || matchFile(filepath.Base(path))
+		}
+	}
+
+	return config, nil
+}
+
+// ParseServers parses a JSON list of language servers, with comments, in the format of the languageServers of a devcontainer.json.
+func ParseServers(content []byte) ([]ServerConfig, error) {
+	var languageServers []devcontainer.LanguageServer
+	if err := json.Unmarshal(jsonc.ToJSON(content), &languageServers); err != nil {
+		return nil, fmt.Errorf("failed to parse language servers: %w", err)
+	}
+
+	servers := make([]ServerConfig, 0, len(languageServers))
+	for _, languageServer := range languageServers {
+		server, err := NewServerConfig(languageServer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid language server: %w", err)
+		}
+		servers = append(servers, server)
+	}
+
+	return servers, nil
+}
+
+// DefaultServers are the language servers Hide knows without any configuration.
+var DefaultServers = []ServerConfig{
+	{Command: NewCommand("gopls", []string{}), Languages: []LanguageId{Go}},
+	{Command: NewCommand("pyright-langserver", []string{"--stdio"}), Languages: []LanguageId{Python}},
+	{Command: NewCommand("typescript-language-server", []string{"--stdio"}), Languages: []LanguageId{JavaScript, TypeScript}},
+}
+
+// Registry knows the language servers of Hide and of the projects. Servers of a project, configured in its devcontainer.json, replace the servers of Hide for the same languages.
+type Registry interface {
+	// Get returns the server that is started for the language in the project
+	Get(project model.Project, languageId LanguageId) (ServerConfig, bool)
+	// Languages returns the languages that have a server in the project, sorted by name
+	Languages(project model.Project) []LanguageId
+	// MatchFile returns the language of the file if it matches the file patterns of a server of the project
+	MatchFile(project model.Project, path string) (LanguageId, bool)
+	// DeleteAllForProject forgets the servers of the project
+	DeleteAllForProject(projectId ProjectId)
+}
+
+type RegistryImpl struct {
+	servers []ServerConfig
+
+	// projects caches the servers of the projects, so that their file patterns are compiled once
+	projects map[ProjectId]projectServers
+	mu       sync.Mutex
+}
+
+// projectServers are the servers of a project and the language servers of its configuration they were created from.
+type projectServers struct {
+	languageServers []devcontainer.LanguageServer
+	servers         []ServerConfig
+}
+
+// NewRegistry returns a registry of the servers, later servers replace earlier ones for the same languages.
+func NewRegistry(servers ...ServerConfig) Registry {
+	return &RegistryImpl{servers: servers, projects: make(map[ProjectId]projectServers)}
+}
+
+// Get implements Registry.
+func (r *RegistryImpl) Get(project model.Project, languageId LanguageId) (ServerConfig, bool) {
+	servers := r.projectServers(project)
+	for i := len(servers) - 1; i >= 0; i-- {
+		if slices.Contains(servers[i].Languages, languageId) {
+			return servers[i], true
+		}
+	}
+
+	return ServerConfig{}, false
+}
+
+// Languages implements Registry.
+func (r *RegistryImpl) Languages(project model.Project) []LanguageId {
+	var languages []LanguageId
+	for _, server := range r.projectServers(project) {
+		for _, language := range server.Languages {
+			if !slices.Contains(languages, language) {
+				languages = append(languages, language)
+			}
+		}
+	}
+
+	slices.Sort(languages)
+	return languages
+}
+
+// MatchFile implements Registry.
+func (r *RegistryImpl) MatchFile(project model.Project, path string) (LanguageId, bool) {
+	servers := r.projectServers(project)
+	for i := len(servers) - 1; i >= 0; i-- {
+		if servers[i].matchFile != nil && servers[i].matchFile(path) {
+			return servers[i].Languages[0], true
+		}
+	}
+
+	return "", false
+}
+
+// DeleteAllForProject implements Registry.
+func (r *RegistryImpl) DeleteAllForProject(projectId ProjectId) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.projects, projectId)
+}
+
+// projectServers returns the servers of Hide followed by the servers of the project, invalid servers of the project are left out.
+// The servers are cached until the language servers of the project configuration change.
+func (r *RegistryImpl) projectServers(project model.Project) []ServerConfig {
+	languageServers := project.GetLanguageServers()
+	if len(languageServers) == 0 {
+		return r.servers
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cached, ok := r.projects[project.Id]; ok && reflect.DeepEqual(cached.languageServers, languageServers) {
+		return cached.servers
+	}
+
+	servers := slices.Clone(r.servers)
+	for _, languageServer := range languageServers {
+		server, err := NewServerConfig(languageServer)
+		if err != nil {
+			log.Warn().Err(err).Str("projectId", project.Id).Msg("Ignoring invalid language server of devcontainer.json")
+			continue
+		}
+		servers = append(servers, server)
+	}
+
+	r.projects[project.Id] = projectServers{languageServers: slices.Clone(languageServers), servers: servers}
+	return servers
+}
@@ -0,0 +1,124 @@
+package lsp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/devcontainer"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func projectWithLanguageServers(servers ...devcontainer.LanguageServer) model.Project {
+	return model.Project{
+		Id:   "project-id",
+		Path: "/test/project",
+		Config: model.Config{DevContainerConfig: devcontainer.Config{GeneralProperties: devcontainer.GeneralProperties{
+			Customizations: devcontainer.Customizations{Hide: &devcontainer.HideCustomization{LanguageServers: servers}},
+		}}},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := lsp.NewRegistry(lsp.DefaultServers...)
+
+	project := projectWithLanguageServers(
+		devcontainer.LanguageServer{Command: "rust-analyzer", LanguageIds: []string{"Rust"}},
+		devcontainer.LanguageServer{Command: "pylsp", LanguageIds: []string{"Python"}, FilePatterns: []string{"*.pyi", "SConstruct"}},
+		devcontainer.LanguageServer{LanguageIds: []string{"Ruby"}},
+	)
+
+	assert.Equal(t, []lsp.LanguageId{"Go", "JavaScript", "Python", "TypeScript"}, registry.Languages(model.Project{}))
+	assert.Equal(t, []lsp.LanguageId{"Go", "JavaScript", "Python", "Rust", "TypeScript"}, registry.Languages(project))
+
+	server, ok := registry.Get(project, lsp.Python)
+	assert.True(t, ok)
+	assert.Equal(t, lsp.NewCommand("pylsp", nil), server.Command)
+
+	server, ok = registry.Get(model.Project{}, lsp.Python)
+	assert.True(t, ok)
+	assert.Equal(t, lsp.NewCommand("pyright-langserver", []string{"--stdio"}), server.Command)
+
+	_, ok = registry.Get(project, "Ruby")
+	assert.False(t, ok, "server without command")
+
+	language, ok := registry.MatchFile(project, "build/SConstruct")
+	assert.True(t, ok)
+	assert.Equal(t, lsp.Python, language)
+
+	_, ok = registry.MatchFile(project, "main.rs")
+	assert.False(t, ok)
+
+	// the servers of the project are created again once its configuration changes
+	project = projectWithLanguageServers(devcontainer.LanguageServer{Command: "pylsp", LanguageIds: []string{"Python"}, FilePatterns: []string{"*.pyx"}})
+
+	_, ok = registry.MatchFile(project, "build/SConstruct")
+	assert.False(t, ok)
+
+	language, ok = registry.MatchFile(project, "lib.pyx")
+	assert.True(t, ok)
+	assert.Equal(t, lsp.Python, language)
+}
+
+func TestParseServers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []lsp.LanguageId
+		wantErr string
+	}{
+		{
+			name: "servers",
+			content: `[
+	// comments are allowed
+	{"command": "clangd", "languageIds": ["C", "C++"]},
+	{"command": "jdtls", "languageIds": ["Java"], "settings": {"java": {"format": {"enabled": true}}}},
+]`,
+			want: []lsp.LanguageId{"C", "C++", "Java"},
+		},
+		{
+			name:    "missing languages",
+			content: `[{"command": "clangd"}]`,
+			wantErr: "languageIds of clangd must be provided",
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"command": "clangd"}`,
+			wantErr: "failed to parse language servers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers, err := lsp.ParseServers([]byte(tt.content))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, lsp.NewRegistry(servers...).Languages(model.Project{}))
+		})
+	}
+}
+
+func TestService_DetectsLanguageByFilePatterns(t *testing.T) {
+	project := projectWithLanguageServers(devcontainer.LanguageServer{Command: "gopls", LanguageIds: []string{"Go"}, FilePatterns: []string{"*.tmpl"}})
+	ctx := model.NewContextWithProject(context.Background(), &project)
+
+	client := &mocks.MockClient{}
+	client.On("NotifyDidOpen", mock.MatchedBy(isContext), mock.Anything).Return(nil)
+
+	clientPool := &mocks.MockClientPool{}
+	clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(lsp.DefaultServers...), lsp.NewDiagnosticsStore(), clientPool, nil)
+
+	assert.NoError(t, service.NotifyDidOpen(ctx, *model.NewFile("templates/page.tmpl", "{{ .Title }}")))
+	client.AssertExpectations(t)
+}
@@ -0,0 +1,152 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	"github.com/sourcegraph/jsonrpc2"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// Rename implements Service.
+func (s *ServiceImpl) Rename(ctx context.Context, file model.File, position Position, newName string) ([]files.FileChange, error) {
+	project, client, err := s.getClientForFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	params := textDocumentPosition(project, file, position)
+
+	prepared, err := client.PrepareRename(ctx, protocol.PrepareRenameParams{TextDocumentPositionParams: params})
+	if err != nil {
+		// prepareRename is optional, the rename request itself tells if the symbol can't be renamed
+		var rpcErr *jsonrpc2.Error
+		if !errors.As(err, &rpcErr) || rpcErr.Code != jsonrpc2.CodeMethodNotFound {
+			return nil, renameError(project, file, "prepare rename", err)
+		}
+	} else if prepared == nil {
+		return nil, NewRenameNotPossibleError(file.Path, "no symbol that can be renamed at the position")
+	}
+
+	edit, err := client.Rename(ctx, protocol.RenameParams{TextDocumentPositionParams: params, NewName: newName})
+	if err != nil {
+		return nil, renameError(project, file, "rename", err)
+	}
+
+	if edit == nil {
+		return []files.FileChange{}, nil
+	}
+
+	changes, err := toFileChanges(project, *edit)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to convert workspace edit")
+		return nil, fmt.Errorf("Failed to convert workspace edit: %w", err)
+	}
+
+	return changes, nil
+}
+
+// renameError reports errors of the server, e.g. for invalid names, as a rename that is not possible.
+func renameError(project *model.Project, file model.File, request string, err error) error {
+	var rpcErr *jsonrpc2.Error
+	if errors.As(err, &rpcErr) {
+		return NewRenameNotPossibleError(file.Path, rpcErr.Message)
+	}
+
+	log.Error().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msgf("Failed to %s", request)
+	return fmt.Errorf("Failed to %s: %w", request, err)
+}
+
+// toFileChanges converts a workspace edit to changes of files in the project. Document changes take precedence over changes, like in LSP clients.
+func toFileChanges(project *model.Project, edit protocol.WorkspaceEdit) ([]files.FileChange, error) {
+	changes := []files.FileChange{}
+
+	if len(edit.DocumentChanges) == 0 {
+		uris := make([]protocol.DocumentUri, 0, len(edit.Changes))
+		for uri := range edit.Changes {
+			uris = append(uris, uri)
+		}
+		sort.Strings(uris)
+
+		for _, uri := range uris {
+			path, err := projectFilePath(project, uri)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, files.FileChange{Path: path, Edits: toTextEdits(edit.Changes[uri])})
+		}
+
+		return changes, nil
+	}
+
+	for _, documentChange := range edit.DocumentChanges {
+		var change files.FileChange
+		var err error
+
+		switch c := documentChange.(type) {
+		case protocol.TextDocumentEdit:
+			edits := make([]protocol.TextEdit, 0, len(c.Edits))
+			for _, e := range c.Edits {
+				if textEdit, ok := e.(protocol.TextEdit); ok {
+					edits = append(edits, textEdit)
+				}
+			}
+			change.Edits = toTextEdits(edits)
+			change.Path, err = projectFilePath(project, c.TextDocument.URI)
+		case protocol.CreateFile:
+			change.Create = true
+			change.Path, err = projectFilePath(project, c.URI)
+		case protocol.RenameFile:
+			if change.Path, err = projectFilePath(project, c.OldURI); err == nil {
+				change.NewPath, err = projectFilePath(project, c.NewURI)
+			}
+		case protocol.DeleteFile:
+			change.Delete = true
+			change.Path, err = projectFilePath(project, c.URI)
+		default:
+			err = fmt.Errorf("unsupported document change %T", documentChange)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// projectFilePath is like uriToProjectPath, but files outside of the project are an error because they must not be changed.
+func projectFilePath(project *model.Project, uri protocol.DocumentUri) (string, error) {
+	path, err := uriToProjectPath(project, uri)
+	if err != nil {
+		return "", err
+	}
+
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("file %s is outside of the project", path)
+	}
+
+	return path, nil
+}
+
+func toTextEdits(edits []protocol.TextEdit) []files.TextEdit {
+	result := make([]files.TextEdit, len(edits))
+	for i, edit := range edits {
+		r := toRange(edit.Range)
+		result[i] = files.TextEdit{
+			StartLine:      r.Start.Line,
+			StartCharacter: r.Start.Character,
+			EndLine:        r.End.Line,
+			EndCharacter:   r.End.Character,
+			NewText:        edit.NewText,
+		}
+	}
+	return result
+}
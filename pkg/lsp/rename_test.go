@@ -0,0 +1,115 @@
+package lsp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/lsp/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+func TestService_Rename(t *testing.T) {
+	edit := protocol.TextEdit{
+		Range:   protocol.Range{Start: protocol.Position{Line: 2, Character: 5}, End: protocol.Position{Line: 2, Character: 8}},
+		NewText: "bar",
+	}
+	textEdit := files.TextEdit{StartLine: 3, StartCharacter: 5, EndLine: 3, EndCharacter: 8, NewText: "bar"}
+
+	tests := []struct {
+		name            string
+		prepare         *protocol.RangeWithPlaceholder
+		prepareErr      error
+		edit            *protocol.WorkspaceEdit
+		renameErr       error
+		want            []files.FileChange
+		wantErr         string
+		wantNotPossible bool
+	}{
+		{
+			name:    "changes",
+			prepare: &protocol.RangeWithPlaceholder{},
+			edit: &protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				"file:///test/project/util/util.go": {edit},
+				"file:///test/project/main.go":      {edit},
+			}},
+			want: []files.FileChange{{Path: "main.go", Edits: []files.TextEdit{textEdit}}, {Path: "util/util.go", Edits: []files.TextEdit{textEdit}}},
+		},
+		{
+			name:       "document changes without prepare rename",
+			prepareErr: &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: "method not found"},
+			edit: &protocol.WorkspaceEdit{DocumentChanges: []any{
+				protocol.TextDocumentEdit{
+					TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: "file:///test/project/main.go"}},
+					Edits:        []any{edit},
+				},
+				protocol.RenameFile{Kind: "rename", OldURI: "file:///test/project/main.go", NewURI: "file:///test/project/bar.go"},
+			}},
+			want: []files.FileChange{{Path: "main.go", Edits: []files.TextEdit{textEdit}}, {Path: "main.go", NewPath: "bar.go"}},
+		},
+		{
+			name:            "nothing to rename",
+			wantErr:         "no symbol that can be renamed",
+			wantNotPossible: true,
+		},
+		{
+			name:            "invalid name",
+			prepare:         &protocol.RangeWithPlaceholder{},
+			renameErr:       &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: "invalid identifier"},
+			wantErr:         "invalid identifier",
+			wantNotPossible: true,
+		},
+		{
+			name:    "file outside of the project",
+			prepare: &protocol.RangeWithPlaceholder{},
+			edit: &protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				"file:///usr/lib/go/src/fmt/print.go": {edit},
+			}},
+			wantErr: "outside of the project",
+		},
+		{
+			name:       "connection closed",
+			prepareErr: errors.New("connection closed"),
+			wantErr:    "Failed to prepare rename: connection closed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+			params := protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: "file:///test/project/main.go"},
+				Position:     protocol.Position{Line: 2, Character: 6},
+			}
+
+			client := &mocks.MockClient{}
+			client.On("PrepareRename", mock.MatchedBy(isContext), protocol.PrepareRenameParams{TextDocumentPositionParams: params}).Return(tt.prepare, tt.prepareErr)
+			client.On("Rename", mock.MatchedBy(isContext), protocol.RenameParams{TextDocumentPositionParams: params, NewName: "bar"}).Return(tt.edit, tt.renameErr)
+
+			clientPool := &mocks.MockClientPool{}
+			clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+			service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), nil, clientPool, nil)
+
+			got, err := service.Rename(ctx, *model.NewFile("main.go", "package main"), lsp.Position{Line: 3, Character: 6}, "bar")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+
+				var notPossible *lsp.RenameNotPossibleError
+				assert.Equal(t, tt.wantNotPossible, errors.As(err, &notPossible))
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
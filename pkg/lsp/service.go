@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net/url"
 	"path/filepath"
+	"time"
 
+	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/model"
 	"github.com/rs/zerolog/log"
+	"github.com/sourcegraph/jsonrpc2"
 	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
@@ -19,30 +22,76 @@ type (
 	LspDiagnostics = map[ProjectId]map[protocol.DocumentUri][]protocol.Diagnostic
 )
 
-var LspServerExecutables = map[LanguageId]Command{
-	Go:         NewCommand("gopls", []string{}),
-	Python:     NewCommand("pyright-langserver", []string{"--stdio"}),
-	JavaScript: NewCommand("typescript-language-server", []string{"--stdio"}),
-	TypeScript: NewCommand("typescript-language-server", []string{"--stdio"}),
-}
-
 type Service interface {
 	StartServer(ctx context.Context, languageId LanguageId) error
 	StopServer(ctx context.Context, languageId LanguageId) error
+	// GetServerHealth returns the state of the language servers of the project of the context, servers that crashed are restarted automatically
+	GetServerHealth(ctx context.Context) ([]ServerHealth, error)
+	// GetSupportedLanguages returns the languages that have a language server in the project of the context
+	GetSupportedLanguages(ctx context.Context) []LanguageId
 	GetWorkspaceSymbols(ctx context.Context, query string, symbolFilter SymbolFilter) ([]SymbolInfo, error)
+	// NotifyDidOpen opens the file in its language server, or sends its content as a change if it is already open.
+	// Documents stay open until NotifyDidClose, so that the server keeps their state between requests. The least recently used documents
+	// are closed once a project has more than maxOpenDocuments open documents.
 	NotifyDidOpen(ctx context.Context, file model.File) error
+	// NotifyDidChange sends the content of the file as a change if the file is open, it does nothing otherwise
+	NotifyDidChange(ctx context.Context, file model.File) error
 	NotifyDidClose(ctx context.Context, file model.File) error
-	// TODO: check if any LSP server supports this
-	// PullDiagnostics(ctx context.Context, params DocumentDiagnosticParams) (DocumentDiagnosticReport, error)
+	NotifyDidChangeWatchedFiles(ctx context.Context, events []model.FileEvent) error
 	GetDiagnostics(ctx context.Context, file model.File) ([]protocol.Diagnostic, error)
+	// WaitForDiagnostics syncs the file like NotifyDidOpen and waits until the server publishes the diagnostics of its content, at most for timeout.
+	// Servers that support pull diagnostics are asked for them instead. If the server doesn't answer in time, the last known diagnostics of the file are returned.
+	WaitForDiagnostics(ctx context.Context, file model.File, timeout time.Duration) ([]protocol.Diagnostic, error)
+	// GetProjectDiagnostics returns the diagnostics the language servers published for any file of the project, it doesn't open any file.
+	// With refresh, servers that support workspace diagnostics are asked for the diagnostics of the whole project first.
+	GetProjectDiagnostics(ctx context.Context, filter DiagnosticsFilter, refresh bool) (*DiagnosticsReport, error)
+	// SubscribeDiagnostics returns the current diagnostics of the files of the project that match the filter, followed by the diagnostics the language servers publish.
+	// Files whose diagnostics are cleared get an event without diagnostics. The channel is closed when the context is done or the project is cleaned up.
+	SubscribeDiagnostics(ctx context.Context, filter DiagnosticsFilter) (<-chan FileDiagnostics, error)
+	// Positions are 1-based for lines and 0-based for characters, like in the rest of Hide
+	GetDefinition(ctx context.Context, file model.File, position Position) ([]Location, error)
+	GetTypeDefinition(ctx context.Context, file model.File, position Position) ([]Location, error)
+	GetImplementation(ctx context.Context, file model.File, position Position) ([]Location, error)
+	GetReferences(ctx context.Context, file model.File, position Position, includeDeclaration bool) ([]Location, error)
+	GetHover(ctx context.Context, file model.File, position Position) (*HoverInfo, error)
+	// GetCompletions returns the completions for the position in the order the server sorts them, at most limit of them unless limit is 0
+	GetCompletions(ctx context.Context, file model.File, position Position, limit int) (*CompletionList, error)
+	GetDocumentSymbols(ctx context.Context, file model.File) ([]DocumentSymbol, error)
+	// GetCallHierarchy returns the functions at the position with their callers or callees, up to depth levels deep
+	GetCallHierarchy(ctx context.Context, file model.File, position Position, direction CallHierarchyDirection, depth int) ([]HierarchyItem, error)
+	// GetTypeHierarchy returns the types at the position with their supertypes or subtypes, up to depth levels deep
+	GetTypeHierarchy(ctx context.Context, file model.File, position Position, direction TypeHierarchyDirection, depth int) ([]HierarchyItem, error)
+	// Rename returns the changes of files in the project that rename the symbol at the position, it doesn't change any file
+	Rename(ctx context.Context, file model.File, position Position, newName string) ([]files.FileChange, error)
+	// GetCodeActions returns the actions for the range, the diagnostics of the range are passed to the server to get their quick fixes
+	GetCodeActions(ctx context.Context, file model.File, rng Range) ([]CodeAction, error)
+	// GetCodeActionChanges returns the changes of the code action with the title. Commands of the action are executed, but their edits are only returned like the other changes.
+	// With dryRun, actions with commands are rejected instead.
+	GetCodeActionChanges(ctx context.Context, file model.File, rng Range, title string, dryRun bool) ([]files.FileChange, error)
+	// Format returns the changes that organize the imports of the file and format it, it doesn't change any file
+	Format(ctx context.Context, file model.File, opts FormatOptions) ([]files.FileChange, error)
+	// ServeClient passes the JSON-RPC messages of the client through to the running language server of the language, until the client or the server disconnects.
+	// The client shares the server with Hide, it sees the URIs of project files relative to the project, like file:///src/main.go.
+	// Lifecycle and document sync messages are answered by Hide, requests that return edits are serialized with the requests of Hide.
+	ServeClient(ctx context.Context, languageId LanguageId, stream jsonrpc2.ObjectStream) error
 	CleanupProject(ctx context.Context, projectId ProjectId) error
 }
 
 type ServiceImpl struct {
-	languageDetector     LanguageDetector
-	clientPool           ClientPool
-	diagnosticsStore     *DiagnosticsStore
-	lspServerExecutables map[LanguageId]Command
+	languageDetector LanguageDetector
+	clientPool       ClientPool
+	diagnosticsStore *DiagnosticsStore
+	subscriptions    *diagnosticsSubscriptions
+	resultIds        *resultIdStore
+	documents        *documentStore
+	registry         Registry
+	processFactory   ProcessFactory
+	servers          *serverStore
+
+	minRestartDelay time.Duration
+	maxRestartDelay time.Duration
+	maxCrashes      int
+	stableUptime    time.Duration
 }
 
 // StartServer implements Service.
@@ -53,94 +102,70 @@ func (s *ServiceImpl) StartServer(ctx context.Context, languageId LanguageId) er
 		return fmt.Errorf("Project not found in context")
 	}
 
-	projectId := project.Id
-
-	command, ok := s.lspServerExecutables[languageId]
+	config, ok := s.registry.Get(*project, languageId)
 	if !ok {
 		return NewLanguageNotSupportedError(languageId)
 	}
 
-	// Start the language server
-	process, err := NewProcess(command)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to create language server process")
-		return fmt.Errorf("Failed to create language server process: %w", err)
+	server := newSupervisedServer(*project, languageId, config)
+	if err := s.servers.add(server); err != nil {
+		return err
 	}
 
-	if err := process.Start(); err != nil {
-		log.Error().Err(err).Msg("Failed to start language server")
-		return fmt.Errorf("Failed to start language server: %w", err)
-	}
-
-	// Create a client for the language server
-	client, diagnostics := NewClient(process)
-
-	// Initialize the language server
-	root := PathToURI(project.Path)
-	initResult, err := client.Initialize(ctx, protocol.InitializeParams{
-		RootURI: &root,
-		Capabilities: protocol.ClientCapabilities{
-			TextDocument: &protocol.TextDocumentClientCapabilities{
-				Synchronization: &protocol.TextDocumentSyncClientCapabilities{
-					DynamicRegistration: boolPointer(true),
-				},
-			},
-		},
-		WorkspaceFolders: []protocol.WorkspaceFolder{
-			{
-				URI:  root,
-				Name: project.Id,
-			},
-		},
-	})
-	if err != nil {
-		log.Error().Str("languageId", languageId).Str("projectId", projectId).Err(err).Msg("Failed to initialize language server")
-		return fmt.Errorf("Failed to initialize language server: %w", err)
+	if err := s.launch(ctx, server); err != nil {
+		// failed servers are kept, so that their error and output can be inspected
+		server.fail(err, 0)
+		return err
 	}
 
-	log.Debug().Str("languageId", languageId).Str("projectId", projectId).Msg("Initialized language server")
+	return nil
+}
 
-	// Check capabilities
-	if opt, ok := initResult.Capabilities.TextDocumentSync.(protocol.TextDocumentSyncOptions); ok {
-		log.Debug().Str("languageId", languageId).Str("projectId", projectId).Msgf("LSP server supports open/close file: %t", *opt.OpenClose)
-		log.Debug().Str("languageId", languageId).Str("projectId", projectId).Msgf("LSP server supports change notifications: %v", *opt.Change)
+func (s *ServiceImpl) StopServer(ctx context.Context, languageId LanguageId) error {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		log.Error().Msg("Project not found in context")
+		return fmt.Errorf("Project not found in context")
 	}
 
-	// Notify that initialized
-	if err := client.NotifyInitialized(ctx); err != nil {
-		log.Error().Err(err).Str("languageId", languageId).Str("projectId", projectId).Msg("Failed to notify initialized")
-		return fmt.Errorf("Failed to notify initialized: %w", err)
+	server, ok := s.servers.delete(project.Id, languageId)
+	if !ok {
+		log.Warn().Str("languageId", languageId).Str("projectId", project.Id).Msg("LSP client not found")
+		return NewLanguageServerNotFoundError(project.Id, languageId)
 	}
 
-	s.clientPool.Set(projectId, languageId, client)
+	s.shutdown(ctx, server)
+	s.documents.deleteAllForLanguage(project.Id, languageId)
 
-	// TODO: kill this goroutine when the project is deleted
-	go s.listenForDiagnostics(projectId, diagnostics)
 	return nil
 }
 
-func (s *ServiceImpl) StopServer(ctx context.Context, languageId LanguageId) error {
+// GetServerHealth implements Service.
+func (s *ServiceImpl) GetServerHealth(ctx context.Context) ([]ServerHealth, error) {
 	project, ok := model.ProjectFromContext(ctx)
 	if !ok {
 		log.Error().Msg("Project not found in context")
-		return fmt.Errorf("Project not found in context")
+		return nil, fmt.Errorf("Project not found in context")
 	}
 
-	client, ok := s.getClient(ctx, languageId)
+	servers := s.servers.getAllForProject(project.Id)
+	result := make([]ServerHealth, 0, len(servers))
+	for _, server := range servers {
+		result = append(result, server.health())
+	}
 
+	return result, nil
+}
+
+// GetSupportedLanguages implements Service.
+func (s *ServiceImpl) GetSupportedLanguages(ctx context.Context) []LanguageId {
+	project, ok := model.ProjectFromContext(ctx)
 	if !ok {
-		log.Warn().Str("languageId", languageId).Str("projectId", project.Id).Msg("LSP client not found")
+		log.Error().Msg("Project not found in context")
 		return nil
 	}
 
-	if err := client.Shutdown(ctx); err != nil {
-		log.Error().Err(err).Str("languageId", languageId).Str("projectId", project.Id).Msg("Failed to stop language server")
-		return fmt.Errorf("Failed to stop language server: %w", err)
-	}
-
-	s.clientPool.Delete(project.Id, languageId)
-
-	return nil
+	return s.registry.Languages(*project)
 }
 
 func (s *ServiceImpl) GetWorkspaceSymbols(ctx context.Context, query string, symbolFilter SymbolFilter) ([]SymbolInfo, error) {
@@ -150,6 +175,11 @@ func (s *ServiceImpl) GetWorkspaceSymbols(ctx context.Context, query string, sym
 		return nil, fmt.Errorf("project not found in context")
 	}
 
+	matchPath, err := symbolFilter.paths.Matcher()
+	if err != nil {
+		return nil, err
+	}
+
 	clients := s.getClients(ctx)
 	if len(clients) == 0 {
 		log.Warn().Str("projectId", project.Id).Msg("LSP client not found")
@@ -200,15 +230,25 @@ func (s *ServiceImpl) GetWorkspaceSymbols(ctx context.Context, query string, sym
 			return nil, fmt.Errorf("failed to get relative path of file: %w", err)
 		}
 
+		if !matchPath(relativePath) {
+			continue
+		}
+
+		var containerName string
+		if symbol.ContainerName != nil {
+			containerName = *symbol.ContainerName
+		}
+
 		result = append(result, SymbolInfo{
-			Name: symbol.Name,
-			Kind: symbolKindToString(symbol.Kind),
+			Name:          symbol.Name,
+			Kind:          symbolKindToString(symbol.Kind),
+			ContainerName: containerName,
 			// NOTE: LSP uses 0-based line numbers, but Hide uses 1-based. Characters remain 0-based.
 			Location: Location{Path: relativePath, Range: Range{Start: Position{Line: int(symbol.Location.Range.Start.Line) + 1, Character: int(symbol.Location.Range.Start.Character)}, End: Position{Line: int(symbol.Location.Range.End.Line) + 1, Character: int(symbol.Location.Range.End.Character)}}},
 		})
 	}
 
-	return result, nil
+	return rankSymbols(query, result), nil
 }
 
 // NotifyDidClose implements Service.
@@ -220,53 +260,186 @@ func (s *ServiceImpl) NotifyDidClose(ctx context.Context, file model.File) error
 		return fmt.Errorf("Project not found in context")
 	}
 
-	languageId := s.languageDetector.DetectLanguage(&file)
+	languageId := s.detectLanguage(*project, file)
 	client, ok := s.getClient(ctx, languageId)
 
 	if !ok {
 		log.Warn().Str("languageId", languageId).Str("projectId", project.Id).Msg("LSP client not found")
-		return LanguageServerNotFoundError{ProjectId: project.Id, LanguageId: languageId}
+		return NewLanguageServerNotFoundError(project.Id, languageId)
 	}
 
-	fullPath := filepath.Join(project.Path, file.Path)
-
-	err := client.NotifyDidClose(ctx, protocol.DidCloseTextDocumentParams{
-		TextDocument: protocol.TextDocumentIdentifier{
-			URI: PathToURI(fullPath),
-		},
+	uri := PathToURI(filepath.Join(project.Path, file.Path))
+	_, err := s.documents.close(project.Id, uri, func(LanguageId) error {
+		return client.NotifyDidClose(ctx, protocol.DidCloseTextDocumentParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: uri,
+			},
+		})
 	})
 
 	return err
 }
 
+// closeDocument closes the document in the server of its language, e.g. a document that was evicted or whose file was removed.
+func (s *ServiceImpl) closeDocument(ctx context.Context, project *model.Project, uri protocol.DocumentUri) {
+	_, err := s.documents.close(project.Id, uri, func(languageId LanguageId) error {
+		client, ok := s.getClient(ctx, languageId)
+		if !ok {
+			return nil
+		}
+		return client.NotifyDidClose(ctx, protocol.DidCloseTextDocumentParams{TextDocument: protocol.TextDocumentIdentifier{URI: uri}})
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("projectId", project.Id).Str("uri", uri).Msg("Failed to notify didClose")
+	}
+}
+
 // NotifyDidOpen implements Service.
 func (s *ServiceImpl) NotifyDidOpen(ctx context.Context, file model.File) error {
+	_, err := s.syncDocument(ctx, file, true)
+	return err
+}
+
+// NotifyDidChange implements Service.
+func (s *ServiceImpl) NotifyDidChange(ctx context.Context, file model.File) error {
+	_, err := s.syncDocument(ctx, file, false)
+	return err
+}
+
+// WaitForDiagnostics implements Service.
+func (s *ServiceImpl) WaitForDiagnostics(ctx context.Context, file model.File, timeout time.Duration) ([]protocol.Diagnostic, error) {
+	published, err := s.syncDocument(ctx, file, true)
+	if err != nil {
+		return nil, err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// pulled diagnostics belong to the synced content, servers that don't support them publish the diagnostics eventually
+	if project, client, err := s.getClientForFile(ctx, file); err == nil && client.DiagnosticProvider() != nil {
+		err := s.pullDiagnostics(waitCtx, project, client, file)
+		if err == nil {
+			return s.GetDiagnostics(ctx, file)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		log.Warn().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to pull diagnostics, waiting for published diagnostics")
+	}
+
+	select {
+	case <-published:
+	case <-waitCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Debug().Str("path", file.Path).Msgf("No diagnostics published within %s", timeout)
+	}
+
+	return s.GetDiagnostics(ctx, file)
+}
+
+// syncDocument sends the content of the file to its language server. Documents that are not open yet are only opened if open is true.
+// The returned channel is closed when the server publishes the diagnostics of the content, it is nil if the document is not open.
+func (s *ServiceImpl) syncDocument(ctx context.Context, file model.File, open bool) (<-chan struct{}, error) {
 	project, ok := model.ProjectFromContext(ctx)
 
 	if !ok {
 		log.Error().Msg("Project not found in context")
-		return fmt.Errorf("Project not found in context")
+		return nil, fmt.Errorf("Project not found in context")
 	}
 
-	languageId := s.languageDetector.DetectLanguage(&file)
+	languageId := s.detectLanguage(*project, file)
 	client, ok := s.getClient(ctx, languageId)
 
 	if !ok {
 		log.Warn().Str("languageId", languageId).Str("projectId", project.Id).Msg("LSP client not found")
-		return LanguageServerNotFoundError{ProjectId: project.Id, LanguageId: languageId}
+		return nil, NewLanguageServerNotFoundError(project.Id, languageId)
 	}
 
-	fullPath := filepath.Join(project.Path, file.Path)
+	uri := PathToURI(filepath.Join(project.Path, file.Path))
+	if !open && !s.documents.isOpen(project.Id, uri) {
+		return nil, nil
+	}
 
-	err := client.NotifyDidOpen(ctx, protocol.DidOpenTextDocumentParams{
-		TextDocument: protocol.TextDocumentItem{
-			URI:     PathToURI(fullPath),
-			Version: 1,
-			Text:    file.GetContent(),
-		},
+	published, evicted, err := s.documents.sync(project.Id, uri, languageId, file.GetContent(), func(version protocol.Integer, open bool) error {
+		if open {
+			return client.NotifyDidOpen(ctx, protocol.DidOpenTextDocumentParams{
+				TextDocument: protocol.TextDocumentItem{
+					URI:     uri,
+					Version: version,
+					Text:    file.GetContent(),
+				},
+			})
+		}
+
+		// the whole content is sent, so that the server doesn't need to support incremental changes
+		return client.NotifyDidChange(ctx, protocol.DidChangeTextDocumentParams{
+			TextDocument: protocol.VersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
+				Version:                version,
+			},
+			ContentChanges: []any{protocol.TextDocumentContentChangeEventWhole{Text: file.GetContent()}},
+		})
 	})
 
-	return err
+	for _, doc := range evicted {
+		log.Debug().Str("projectId", project.Id).Str("uri", doc.uri).Msg("Closing least recently used document")
+		s.closeDocument(ctx, project, doc.uri)
+	}
+
+	return published, err
+}
+
+// NotifyDidChangeWatchedFiles implements Service.
+func (s *ServiceImpl) NotifyDidChangeWatchedFiles(ctx context.Context, events []model.FileEvent) error {
+	project, ok := model.ProjectFromContext(ctx)
+	if !ok {
+		log.Error().Msg("Project not found in context")
+		return fmt.Errorf("Project not found in context")
+	}
+
+	changes := make([]protocol.FileEvent, 0, len(events))
+	var removed []protocol.DocumentUri
+	for _, event := range events {
+		uri := PathToURI(filepath.Join(project.Path, event.Path))
+
+		switch event.Type {
+		case model.FileCreated:
+			changes = append(changes, protocol.FileEvent{URI: uri, Type: protocol.FileChangeTypeCreated})
+		case model.FileModified:
+			changes = append(changes, protocol.FileEvent{URI: uri, Type: protocol.FileChangeTypeChanged})
+		case model.FileDeleted:
+			changes = append(changes, protocol.FileEvent{URI: uri, Type: protocol.FileChangeTypeDeleted})
+			removed = append(removed, uri)
+		case model.FileRenamed:
+			// LSP has no rename change type, so rename is reported as delete of the old path and create of the new one
+			oldURI := PathToURI(filepath.Join(project.Path, event.OldPath))
+			changes = append(changes, protocol.FileEvent{URI: oldURI, Type: protocol.FileChangeTypeDeleted})
+			changes = append(changes, protocol.FileEvent{URI: uri, Type: protocol.FileChangeTypeCreated})
+			removed = append(removed, oldURI)
+		}
+	}
+
+	// documents of removed files can't be open anymore
+	for _, uri := range removed {
+		s.closeDocument(ctx, project, uri)
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	for _, client := range s.getClients(ctx) {
+		if err := client.NotifyDidChangeWatchedFiles(ctx, protocol.DidChangeWatchedFilesParams{Changes: changes}); err != nil {
+			log.Error().Err(err).Str("projectId", project.Id).Msg("Failed to notify didChangeWatchedFiles")
+			return fmt.Errorf("Failed to notify didChangeWatchedFiles: %w", err)
+		}
+	}
+
+	return nil
 }
 
 func (s *ServiceImpl) GetDiagnostics(ctx context.Context, file model.File) ([]protocol.Diagnostic, error) {
@@ -286,22 +459,28 @@ func (s *ServiceImpl) GetDiagnostics(ctx context.Context, file model.File) ([]pr
 }
 
 func (s *ServiceImpl) CleanupProject(ctx context.Context, projectId ProjectId) error {
-	clients, ok := s.clientPool.GetAllForProject(projectId)
-	if !ok {
-		return nil
-	}
-
-	for _, client := range clients {
-		if err := client.Shutdown(ctx); err != nil {
-			return err
-		}
+	for _, server := range s.servers.deleteAllForProject(projectId) {
+		s.shutdown(ctx, server)
 	}
 
 	s.clientPool.DeleteAllForProject(projectId)
 	s.diagnosticsStore.DeleteAllForProject(projectId)
+	s.subscriptions.closeAllForProject(projectId)
+	s.resultIds.deleteAllForProject(projectId)
+	s.documents.deleteAllForProject(projectId)
+	s.registry.DeleteAllForProject(projectId)
 	return nil
 }
 
+// detectLanguage returns the language of the file, file patterns of the language servers take precedence over the language detector.
+func (s *ServiceImpl) detectLanguage(project model.Project, file model.File) LanguageId {
+	if languageId, ok := s.registry.MatchFile(project, file.Path); ok {
+		return languageId
+	}
+
+	return s.languageDetector.DetectLanguage(&file)
+}
+
 func (s *ServiceImpl) getClient(ctx context.Context, languageId LanguageId) (Client, bool) {
 	project, ok := model.ProjectFromContext(ctx)
 	if !ok {
@@ -350,21 +529,103 @@ func (s *ServiceImpl) listenForDiagnostics(projectId ProjectId, channel <-chan p
 
 func (s *ServiceImpl) updateDiagnostics(projectId ProjectId, diagnostics protocol.PublishDiagnosticsParams) {
 	s.diagnosticsStore.Set(projectId, diagnostics.URI, diagnostics.Diagnostics)
+	s.subscriptions.publish(projectId, diagnostics.URI, diagnostics.Diagnostics)
+	s.documents.published(projectId, diagnostics.URI, diagnostics.Version)
 }
 
 func PathToURI(path string) protocol.DocumentUri {
 	return protocol.DocumentUri("file://" + path)
 }
 
-func NewService(languageDetector LanguageDetector, lspServerExecutables map[LanguageId]Command, diagnosticsStore *DiagnosticsStore, clientPool ClientPool) Service {
+func NewService(languageDetector LanguageDetector, registry Registry, diagnosticsStore *DiagnosticsStore, clientPool ClientPool, processFactory ProcessFactory) Service {
 	return &ServiceImpl{
-		languageDetector:     languageDetector,
-		clientPool:           clientPool,
-		diagnosticsStore:     diagnosticsStore,
-		lspServerExecutables: lspServerExecutables,
+		processFactory:   processFactory,
+		languageDetector: languageDetector,
+		clientPool:       clientPool,
+		diagnosticsStore: diagnosticsStore,
+		subscriptions:    newDiagnosticsSubscriptions(),
+		resultIds:        newResultIdStore(),
+		documents:        newDocumentStore(),
+		registry:         registry,
+		servers:          newServerStore(),
+		minRestartDelay:  defaultRestartDelay,
+		maxRestartDelay:  defaultMaxRestartDelay,
+		maxCrashes:       defaultMaxCrashes,
+		stableUptime:     defaultStableUptime,
 	}
 }
 
+func clientCapabilities() ClientCapabilities {
+	capabilities := ClientCapabilities{
+		TextDocument: &TextDocumentClientCapabilities{
+			TextDocumentClientCapabilities: protocol.TextDocumentClientCapabilities{
+				Synchronization: &protocol.TextDocumentSyncClientCapabilities{
+					DynamicRegistration: boolPointer(true),
+				},
+				Completion:    &protocol.CompletionClientCapabilities{},
+				CallHierarchy: &protocol.CallHierarchyClientCapabilities{},
+				Rename: &protocol.RenameClientCapabilities{
+					PrepareSupport: boolPointer(true),
+				},
+				CodeAction: &protocol.CodeActionClientCapabilities{
+					IsPreferredSupport: boolPointer(true),
+					DisabledSupport:    boolPointer(true),
+				},
+				Formatting:      &protocol.DocumentFormattingClientCapabilities{},
+				RangeFormatting: &protocol.DocumentRangeFormattingClientCapabilities{},
+			},
+			// servers that support pull diagnostics answer them on request instead of publishing them eventually
+			Diagnostic: &DiagnosticClientCapabilities{
+				RelatedDocumentSupport: boolPointer(true),
+			},
+		},
+	}
+
+	// documentation and details are resolved lazily, so that the server doesn't compute them for items that are cut off
+	completion := capabilities.TextDocument.Completion
+	completion.CompletionItem = newOf(completion.CompletionItem)
+	completion.CompletionItem.DocumentationFormat = []protocol.MarkupKind{protocol.MarkupKindMarkdown, protocol.MarkupKindPlainText}
+	completion.CompletionItem.ResolveSupport = newOf(completion.CompletionItem.ResolveSupport)
+	completion.CompletionItem.ResolveSupport.Properties = []string{"documentation", "detail"}
+
+	// without literal support servers only return commands
+	codeAction := capabilities.TextDocument.CodeAction
+	codeAction.CodeActionLiteralSupport = newOf(codeAction.CodeActionLiteralSupport)
+	codeAction.CodeActionLiteralSupport.CodeActionKind.ValueSet = []protocol.CodeActionKind{
+		protocol.CodeActionKindEmpty,
+		protocol.CodeActionKindQuickFix,
+		protocol.CodeActionKindRefactor,
+		protocol.CodeActionKindRefactorExtract,
+		protocol.CodeActionKindRefactorInline,
+		protocol.CodeActionKindRefactorRewrite,
+		protocol.CodeActionKindSource,
+		protocol.CodeActionKindSourceOrganizeImports,
+	}
+
+	// workspace capabilities are an anonymous struct, so it can only be allocated through its pointer type
+	capabilities.Workspace = newOf(capabilities.Workspace)
+	// commands of code actions send their edits with workspace/applyEdit
+	capabilities.Workspace.ApplyEdit = boolPointer(true)
+	// settings of the language servers are answered from their configuration
+	capabilities.Workspace.Configuration = boolPointer(true)
+	capabilities.Workspace.ExecuteCommand = &protocol.ExecuteCommandClientCapabilities{}
+	// renames can move files, servers only send those with document changes and resource operations
+	capabilities.Workspace.WorkspaceEdit = &protocol.WorkspaceEditClientCapabilities{
+		DocumentChanges: boolPointer(true),
+		ResourceOperations: []protocol.ResourceOperationKind{
+			protocol.ResourceOperationKindCreate,
+			protocol.ResourceOperationKindRename,
+			protocol.ResourceOperationKindDelete,
+		},
+	}
+
+	return capabilities
+}
+
+func newOf[T any](_ *T) *T {
+	return new(T)
+}
+
 func boolPointer(b bool) *bool {
 	return &b
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/model"
 	"github.com/hide-org/hide/pkg/lsp"
 	"github.com/hide-org/hide/pkg/lsp/mocks"
@@ -112,6 +114,72 @@ func TestService_GetWorkspaceSymbols(t *testing.T) {
 			symbolFilter: lsp.NewIncludeSymbolFilter(includeKind),
 			wantSymbols:  []lsp.SymbolInfo{{Name: "test-name", Kind: "Class", Location: lsp.Location{Path: "test-uri", Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 0}, End: lsp.Position{Line: 2, Character: 1}}}}},
 		},
+		{
+			name:  "rank and deduplicate symbols",
+			ctx:   model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"}),
+			query: "handler",
+			mockSetup: func(m *mocks.MockClientPool) {
+				container := "server"
+				fuzzy := protocol.SymbolInformation{Name: "HandleAndReply", Kind: protocol.SymbolKindFunction, Location: protocol.Location{URI: "file:///test/project/a.go"}}
+				nested := protocol.SymbolInformation{Name: "Handler", Kind: protocol.SymbolKindClass, ContainerName: &container, Location: protocol.Location{URI: "file:///test/project/pkg/server/b.go"}}
+				exact := protocol.SymbolInformation{Name: "handler", Kind: protocol.SymbolKindVariable, Location: protocol.Location{URI: "file:///test/project/c.go"}}
+				prefix := protocol.SymbolInformation{Name: "HandlerFunc", Kind: protocol.SymbolKindInterface, Location: protocol.Location{URI: "file:///test/project/d.go"}}
+				other := protocol.SymbolInformation{Name: "Serve", Kind: protocol.SymbolKindMethod, Location: protocol.Location{URI: "file:///test/project/e.go"}}
+
+				goClient := &mocks.MockClient{}
+				goClient.On("GetWorkspaceSymbols", mock.MatchedBy(isContext), protocol.WorkspaceSymbolParams{Query: "handler"}).Return([]protocol.SymbolInformation{other, fuzzy, nested, prefix}, nil)
+				otherClient := &mocks.MockClient{}
+				otherClient.On("GetWorkspaceSymbols", mock.MatchedBy(isContext), protocol.WorkspaceSymbolParams{Query: "handler"}).Return([]protocol.SymbolInformation{nested, exact}, nil)
+
+				m.On("GetAllForProject", "project-id").Return(map[lsp.LanguageId]lsp.Client{lsp.Go: goClient, lsp.LanguageId("test-lang"): otherClient}, true)
+			},
+			wantSymbols: []lsp.SymbolInfo{
+				{Name: "handler", Kind: "Variable", Location: lsp.Location{Path: "c.go", Range: lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1}}}},
+				{Name: "Handler", Kind: "Class", ContainerName: "server", Location: lsp.Location{Path: "pkg/server/b.go", Range: lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1}}}},
+				{Name: "HandlerFunc", Kind: "Interface", Location: lsp.Location{Path: "d.go", Range: lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1}}}},
+				{Name: "HandleAndReply", Kind: "Function", Location: lsp.Location{Path: "a.go", Range: lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1}}}},
+				{Name: "Serve", Kind: "Method", Location: lsp.Location{Path: "e.go", Range: lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1}}}},
+			},
+		},
+		{
+			name:  "order symbols at the same location",
+			ctx:   model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"}),
+			query: "x",
+			mockSetup: func(m *mocks.MockClientPool) {
+				at := func(name string, kind protocol.SymbolKind, character protocol.UInteger) protocol.SymbolInformation {
+					return protocol.SymbolInformation{Name: name, Kind: kind, Location: protocol.Location{URI: "file:///test/project/f.go", Range: protocol.Range{Start: protocol.Position{Line: 2, Character: character}}}}
+				}
+				b, aFunction, aVariable, c := at("b", protocol.SymbolKindFunction, 4), at("a", protocol.SymbolKindFunction, 4), at("a", protocol.SymbolKindVariable, 4), at("c", protocol.SymbolKindFunction, 1)
+
+				goClient := &mocks.MockClient{}
+				goClient.On("GetWorkspaceSymbols", mock.MatchedBy(isContext), protocol.WorkspaceSymbolParams{Query: "x"}).Return([]protocol.SymbolInformation{b, aVariable, c}, nil)
+				otherClient := &mocks.MockClient{}
+				otherClient.On("GetWorkspaceSymbols", mock.MatchedBy(isContext), protocol.WorkspaceSymbolParams{Query: "x"}).Return([]protocol.SymbolInformation{aFunction, b}, nil)
+
+				m.On("GetAllForProject", "project-id").Return(map[lsp.LanguageId]lsp.Client{lsp.Go: goClient, lsp.LanguageId("test-lang"): otherClient}, true)
+			},
+			wantSymbols: []lsp.SymbolInfo{
+				{Name: "c", Kind: "Function", Location: lsp.Location{Path: "f.go", Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 1}, End: lsp.Position{Line: 1}}}},
+				{Name: "a", Kind: "Function", Location: lsp.Location{Path: "f.go", Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 4}, End: lsp.Position{Line: 1}}}},
+				{Name: "a", Kind: "Variable", Location: lsp.Location{Path: "f.go", Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 4}, End: lsp.Position{Line: 1}}}},
+				{Name: "b", Kind: "Function", Location: lsp.Location{Path: "f.go", Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 4}, End: lsp.Position{Line: 1}}}},
+			},
+		},
+		{
+			name:  "filter symbols by path",
+			ctx:   model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"}),
+			query: "test-query",
+			mockSetup: func(m *mocks.MockClientPool) {
+				vendored := protocol.SymbolInformation{Name: "test-name", Kind: protocol.SymbolKindClass, Location: protocol.Location{URI: "file:///test/project/vendor/lib.go"}}
+
+				client := &mocks.MockClient{}
+				client.On("GetWorkspaceSymbols", mock.MatchedBy(isContext), protocol.WorkspaceSymbolParams{Query: "test-query"}).Return([]protocol.SymbolInformation{aSymbol, vendored}, nil)
+
+				m.On("GetAllForProject", "project-id").Return(map[lsp.LanguageId]lsp.Client{lsp.LanguageId("test-lang"): client}, true)
+			},
+			symbolFilter: lsp.NewSymbolFilter(nil, nil, files.PatternFilter{Exclude: []string{"vendor/**"}}),
+			wantSymbols:  []lsp.SymbolInfo{{Name: "test-name", Kind: "Class", Location: lsp.Location{Path: "test-uri", Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 0}, End: lsp.Position{Line: 2, Character: 1}}}}},
+		},
 		{
 			name:  "fail to remove file prefix",
 			ctx:   model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"}),
@@ -143,7 +211,7 @@ func TestService_GetWorkspaceSymbols(t *testing.T) {
 			mockClientPool := &mocks.MockClientPool{}
 			tt.mockSetup(mockClientPool)
 
-			service := lsp.NewService(nil, nil, nil, mockClientPool)
+			service := lsp.NewService(nil, lsp.NewRegistry(), nil, mockClientPool, nil)
 
 			symbols, err := service.GetWorkspaceSymbols(tt.ctx, tt.query, tt.symbolFilter)
 
@@ -159,6 +227,90 @@ func TestService_GetWorkspaceSymbols(t *testing.T) {
 	}
 }
 
+func TestService_NotifyDidChangeWatchedFiles(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+	events := []model.FileEvent{
+		{Type: model.FileCreated, Path: "a.go"},
+		{Type: model.FileModified, Path: "b.go"},
+		{Type: model.FileDeleted, Path: "c.go"},
+		{Type: model.FileRenamed, Path: "e.go", OldPath: "d.go"},
+	}
+	want := protocol.DidChangeWatchedFilesParams{Changes: []protocol.FileEvent{
+		{URI: "file:///test/project/a.go", Type: protocol.FileChangeTypeCreated},
+		{URI: "file:///test/project/b.go", Type: protocol.FileChangeTypeChanged},
+		{URI: "file:///test/project/c.go", Type: protocol.FileChangeTypeDeleted},
+		{URI: "file:///test/project/d.go", Type: protocol.FileChangeTypeDeleted},
+		{URI: "file:///test/project/e.go", Type: protocol.FileChangeTypeCreated},
+	}}
+
+	client := &mocks.MockClient{}
+	client.On("NotifyDidChangeWatchedFiles", mock.MatchedBy(isContext), want).Return(nil)
+
+	clientPool := &mocks.MockClientPool{}
+	clientPool.On("GetAllForProject", "project-id").Return(map[lsp.LanguageId]lsp.Client{lsp.LanguageId("test-lang"): client}, true)
+
+	service := lsp.NewService(nil, lsp.NewRegistry(), nil, clientPool, nil)
+
+	assert.NoError(t, service.NotifyDidChangeWatchedFiles(ctx, events))
+	client.AssertExpectations(t)
+}
+
+func TestService_DocumentSync(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+	uri := protocol.DocumentUri("file:///test/project/main.go")
+
+	client := &mocks.MockClient{}
+	client.On("NotifyDidOpen", mock.MatchedBy(isContext), protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: uri, Version: 1, Text: "package main"},
+	}).Return(nil).Once()
+	client.On("NotifyDidChange", mock.MatchedBy(isContext), protocol.DidChangeTextDocumentParams{
+		TextDocument:   protocol.VersionedTextDocumentIdentifier{TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri}, Version: 2},
+		ContentChanges: []any{protocol.TextDocumentContentChangeEventWhole{Text: "package foo"}},
+	}).Return(nil).Once()
+	client.On("NotifyDidClose", mock.MatchedBy(isContext), protocol.DidCloseTextDocumentParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+	}).Return(nil).Once()
+
+	clientPool := &mocks.MockClientPool{}
+	clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), lsp.NewDiagnosticsStore(), clientPool, nil)
+
+	// files that are not open are not changed, open files are only changed if their content changed
+	assert.NoError(t, service.NotifyDidChange(ctx, *model.NewFile("other.go", "package main")))
+	assert.NoError(t, service.NotifyDidOpen(ctx, *model.NewFile("main.go", "package main")))
+	assert.NoError(t, service.NotifyDidOpen(ctx, *model.NewFile("main.go", "package main")))
+	assert.NoError(t, service.NotifyDidChange(ctx, *model.NewFile("main.go", "package foo")))
+	assert.NoError(t, service.NotifyDidClose(ctx, *model.NewFile("main.go", "package foo")))
+	assert.NoError(t, service.NotifyDidClose(ctx, *model.NewFile("main.go", "package foo")))
+
+	client.AssertExpectations(t)
+}
+
+func TestService_WaitForDiagnostics_Timeout(t *testing.T) {
+	ctx := model.NewContextWithProject(context.Background(), &model.Project{Id: "project-id", Path: "/test/project"})
+	diagnostics := []protocol.Diagnostic{{Message: "undefined: foo"}}
+
+	diagnosticsStore := lsp.NewDiagnosticsStore()
+	diagnosticsStore.Set("project-id", "file:///test/project/main.go", diagnostics)
+
+	client := &mocks.MockClient{}
+	client.On("NotifyDidOpen", mock.MatchedBy(isContext), mock.Anything).Return(nil)
+	client.On("DiagnosticProvider").Return(nil)
+
+	clientPool := &mocks.MockClientPool{}
+	clientPool.On("Get", "project-id", lsp.Go).Return(client, true)
+
+	service := lsp.NewService(lsp.NewLanguageDetector(), lsp.NewRegistry(), diagnosticsStore, clientPool, nil)
+
+	got, err := service.WaitForDiagnostics(ctx, *model.NewFile("main.go", "package main"), 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, diagnostics, got)
+}
+
 func isContext(ctx interface{}) bool {
 	_, ok := ctx.(context.Context)
 	return ok
@@ -0,0 +1,476 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+const (
+	// restarts are delayed by defaultRestartDelay, doubled after every crash up to defaultMaxRestartDelay
+	defaultRestartDelay    = time.Second
+	defaultMaxRestartDelay = 30 * time.Second
+	// servers that crash more than defaultMaxCrashes times without staying up for defaultStableUptime are not restarted anymore
+	defaultMaxCrashes   = 5
+	defaultStableUptime = time.Minute
+
+	initializeTimeout = time.Minute
+	shutdownTimeout   = 5 * time.Second
+	stderrLines       = 100
+	stderrLineLength  = 1024
+)
+
+var errServerStopped = errors.New("Language server was stopped")
+
+// supervisedServer is a language server of a project that is restarted when its process exits unexpectedly.
+type supervisedServer struct {
+	project    model.Project
+	languageId LanguageId
+	config     ServerConfig
+	// stderr is shared by the processes of the server, so that the output before a crash is kept
+	stderr *lineBuffer
+
+	mu        sync.Mutex
+	status    ServerStatus
+	process   Process
+	startedAt time.Time
+	restarts  int
+	// crashes counts the crashes since the server last stayed up for the stable uptime
+	crashes   int
+	lastError string
+	stopped   bool
+	// done is closed when the server is stopped, to cancel pending restarts
+	done chan struct{}
+}
+
+func newSupervisedServer(project model.Project, languageId LanguageId, config ServerConfig) *supervisedServer {
+	return &supervisedServer{
+		project:    project,
+		languageId: languageId,
+		config:     config,
+		stderr:     newLineBuffer(stderrLines),
+		done:       make(chan struct{}),
+	}
+}
+
+// stop marks the server as stopped, so that its process exiting is not a crash.
+func (s *supervisedServer) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.stopped {
+		s.stopped = true
+		close(s.done)
+	}
+}
+
+func (s *supervisedServer) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stopped
+}
+
+// fail records that the server could not be started, it returns true if the server should not be restarted anymore.
+func (s *supervisedServer) fail(err error, maxCrashes int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.crashes++
+	s.lastError = err.Error()
+	if s.crashes > maxCrashes {
+		s.status = ServerStatusFailed
+		return true
+	}
+
+	s.status = ServerStatusRestarting
+	return false
+}
+
+func (s *supervisedServer) health() ServerHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	health := ServerHealth{
+		LanguageId: s.languageId,
+		Command:    s.config.Command.String(),
+		Status:     s.status,
+		Restarts:   s.restarts,
+		LastError:  s.lastError,
+		Stderr:     s.stderr.Lines(),
+	}
+
+	if s.status == ServerStatusRunning {
+		startedAt := s.startedAt
+		health.StartedAt = &startedAt
+		health.UptimeSeconds = int64(time.Since(startedAt).Seconds())
+	}
+
+	return health
+}
+
+// serverStore contains the supervised servers of the projects.
+type serverStore struct {
+	servers map[ProjectId]map[LanguageId]*supervisedServer
+	mu      sync.Mutex
+}
+
+func newServerStore() *serverStore {
+	return &serverStore{servers: make(map[ProjectId]map[LanguageId]*supervisedServer)}
+}
+
+// add adds the server unless the project already has a server for the language that didn't fail.
+func (s *serverStore) add(server *supervisedServer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projectId := server.project.Id
+	if existing, ok := s.servers[projectId][server.languageId]; ok && existing.health().Status != ServerStatusFailed {
+		return NewLanguageServerAlreadyExistsError(projectId, server.languageId)
+	}
+
+	if _, ok := s.servers[projectId]; !ok {
+		s.servers[projectId] = make(map[LanguageId]*supervisedServer)
+	}
+
+	s.servers[projectId][server.languageId] = server
+	return nil
+}
+
+func (s *serverStore) getAllForProject(projectId ProjectId) []*supervisedServer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	servers := make([]*supervisedServer, 0, len(s.servers[projectId]))
+	for _, server := range s.servers[projectId] {
+		servers = append(servers, server)
+	}
+
+	sort.Slice(servers, func(i, j int) bool { return servers[i].languageId < servers[j].languageId })
+	return servers
+}
+
+func (s *serverStore) delete(projectId ProjectId, languageId LanguageId) (*supervisedServer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	server, ok := s.servers[projectId][languageId]
+	if ok {
+		delete(s.servers[projectId], languageId)
+	}
+
+	return server, ok
+}
+
+func (s *serverStore) deleteAllForProject(projectId ProjectId) []*supervisedServer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	servers := make([]*supervisedServer, 0, len(s.servers[projectId]))
+	for _, server := range s.servers[projectId] {
+		servers = append(servers, server)
+	}
+
+	delete(s.servers, projectId)
+	return servers
+}
+
+// launch starts a process of the server, initializes it and opens the documents that were open in the previous process.
+func (s *ServiceImpl) launch(ctx context.Context, server *supervisedServer) error {
+	project, languageId, config := server.project, server.languageId, server.config
+
+	process, mapping, err := s.processFactory.NewProcess(project, config.Command, server.stderr)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create language server process")
+		return fmt.Errorf("Failed to create language server process: %w", err)
+	}
+
+	if err := process.Start(); err != nil {
+		log.Error().Err(err).Msg("Failed to start language server")
+		return fmt.Errorf("Failed to start language server: %w", err)
+	}
+
+	client, diagnostics := NewClient(process, mapping, config.Settings)
+
+	// launched tells the supervisor whether the process became the process of the server
+	launched := make(chan bool, 1)
+	go s.supervise(server, process, diagnostics, launched)
+
+	ctx, cancel := context.WithTimeout(ctx, initializeTimeout)
+	defer cancel()
+
+	if err := s.initialize(ctx, project, languageId, config, client); err != nil {
+		launched <- false
+		process.Stop()
+		return err
+	}
+
+	s.documents.reopen(project.Id, languageId, func(uri protocol.DocumentUri, version protocol.Integer, content string) error {
+		err := client.NotifyDidOpen(ctx, protocol.DidOpenTextDocumentParams{
+			TextDocument: protocol.TextDocumentItem{URI: uri, Version: version, Text: content},
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("languageId", languageId).Str("projectId", project.Id).Str("uri", uri).Msg("Failed to reopen document")
+		}
+		return err
+	})
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if server.stopped {
+		launched <- false
+		process.Stop()
+		return errServerStopped
+	}
+
+	server.process = process
+	server.status = ServerStatusRunning
+	server.startedAt = time.Now()
+	s.clientPool.Set(project.Id, languageId, client)
+	launched <- true
+
+	return nil
+}
+
+func (s *ServiceImpl) initialize(ctx context.Context, project model.Project, languageId LanguageId, config ServerConfig, client Client) error {
+	projectId := project.Id
+
+	// Initialize the language server
+	root := PathToURI(project.Path)
+	initResult, err := client.Initialize(ctx, InitializeParams{
+		InitializeParams: protocol.InitializeParams{
+			RootURI:               &root,
+			InitializationOptions: config.InitializationOptions,
+			WorkspaceFolders: []protocol.WorkspaceFolder{
+				{
+					URI:  root,
+					Name: project.Id,
+				},
+			},
+		},
+		Capabilities: clientCapabilities(),
+	})
+	if err != nil {
+		log.Error().Str("languageId", languageId).Str("projectId", projectId).Err(err).Msg("Failed to initialize language server")
+		return fmt.Errorf("Failed to initialize language server: %w", err)
+	}
+
+	log.Debug().Str("languageId", languageId).Str("projectId", projectId).Msg("Initialized language server")
+
+	// Check capabilities
+	if opt, ok := initResult.Capabilities.TextDocumentSync.(protocol.TextDocumentSyncOptions); ok {
+		log.Debug().Str("languageId", languageId).Str("projectId", projectId).Msgf("LSP server supports open/close file: %t", *opt.OpenClose)
+		log.Debug().Str("languageId", languageId).Str("projectId", projectId).Msgf("LSP server supports change notifications: %v", *opt.Change)
+	}
+
+	if provider := client.DiagnosticProvider(); provider != nil {
+		log.Debug().Str("languageId", languageId).Str("projectId", projectId).Msgf("LSP server supports pull diagnostics, workspace diagnostics: %t", provider.WorkspaceDiagnostics)
+	}
+
+	// Notify that initialized
+	if err := client.NotifyInitialized(ctx); err != nil {
+		log.Error().Err(err).Str("languageId", languageId).Str("projectId", projectId).Msg("Failed to notify initialized")
+		return fmt.Errorf("Failed to notify initialized: %w", err)
+	}
+
+	// servers that don't ask for their settings with workspace/configuration get them pushed
+	if config.Settings != nil {
+		if err := client.NotifyDidChangeConfiguration(ctx, protocol.DidChangeConfigurationParams{Settings: config.Settings}); err != nil {
+			log.Error().Err(err).Str("languageId", languageId).Str("projectId", projectId).Msg("Failed to notify didChangeConfiguration")
+			return fmt.Errorf("Failed to notify didChangeConfiguration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// supervise receives the diagnostics of the process until the connection to it is closed and restarts the server if the process exited unexpectedly.
+func (s *ServiceImpl) supervise(server *supervisedServer, process Process, diagnostics Diagnostics, launched <-chan bool) {
+	s.listenForDiagnostics(server.project.Id, diagnostics)
+	err := process.Wait()
+
+	if !<-launched {
+		return
+	}
+
+	server.mu.Lock()
+	if server.stopped || server.process != process {
+		server.mu.Unlock()
+		return
+	}
+
+	if time.Since(server.startedAt) >= s.stableUptime {
+		server.crashes = 0
+	}
+
+	if err == nil {
+		err = errors.New("Language server exited")
+	}
+
+	server.process = nil
+	server.mu.Unlock()
+
+	s.clientPool.Delete(server.project.Id, server.languageId)
+
+	log.Warn().Err(err).Str("languageId", server.languageId).Str("projectId", server.project.Id).Strs("stderr", server.stderr.Tail(10)).Msg("Language server exited unexpectedly")
+
+	if server.fail(err, s.maxCrashes) {
+		log.Error().Str("languageId", server.languageId).Str("projectId", server.project.Id).Msg("Language server crashed too often, not restarting it")
+		return
+	}
+
+	s.restart(server)
+}
+
+// restart launches the server again with an exponential backoff, until it is running, stopped or failed too often.
+func (s *ServiceImpl) restart(server *supervisedServer) {
+	for {
+		server.mu.Lock()
+		delay := s.restartDelay(server.crashes)
+		server.mu.Unlock()
+
+		select {
+		case <-time.After(delay):
+		case <-server.done:
+			return
+		}
+
+		ctx := model.NewContextWithProject(context.Background(), &server.project)
+		err := s.launch(ctx, server)
+		if err == nil {
+			server.mu.Lock()
+			server.restarts++
+			server.mu.Unlock()
+
+			log.Info().Str("languageId", server.languageId).Str("projectId", server.project.Id).Msg("Restarted language server")
+			return
+		}
+
+		if server.isStopped() {
+			return
+		}
+
+		if server.fail(err, s.maxCrashes) {
+			log.Error().Err(err).Str("languageId", server.languageId).Str("projectId", server.project.Id).Msg("Failed to restart language server, giving up")
+			return
+		}
+	}
+}
+
+// restartDelay returns the delay before the restart after the crashes, it doubles with every crash.
+func (s *ServiceImpl) restartDelay(crashes int) time.Duration {
+	delay := s.minRestartDelay
+	for i := 1; i < crashes && delay < s.maxRestartDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, s.maxRestartDelay)
+}
+
+// shutdown stops the server and its process. Servers that don't shut down gracefully are killed.
+func (s *ServiceImpl) shutdown(ctx context.Context, server *supervisedServer) {
+	server.stop()
+
+	projectId, languageId := server.project.Id, server.languageId
+	defer s.clientPool.Delete(projectId, languageId)
+
+	client, ok := s.clientPool.Get(projectId, languageId)
+	if !ok {
+		return
+	}
+
+	// a server that does not answer must not block the shutdown, it is killed instead
+	ctx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+
+	err := client.Shutdown(ctx)
+	if err == nil {
+		return
+	}
+
+	log.Warn().Err(err).Str("languageId", languageId).Str("projectId", projectId).Msg("Failed to shut down language server, killing it")
+
+	server.mu.Lock()
+	process := server.process
+	server.mu.Unlock()
+
+	if process != nil {
+		if err := process.Stop(); err != nil {
+			log.Error().Err(err).Str("languageId", languageId).Str("projectId", projectId).Msg("Failed to kill language server")
+		}
+	}
+}
+
+// lineBuffer keeps the last lines written to it.
+type lineBuffer struct {
+	lines   []string
+	partial []byte
+	size    int
+	mu      sync.Mutex
+}
+
+func newLineBuffer(size int) *lineBuffer {
+	return &lineBuffer{size: size}
+}
+
+func (b *lineBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data := append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+
+		b.add(data[:i])
+		data = data[i+1:]
+	}
+
+	if len(data) > stderrLineLength {
+		b.add(data)
+		data = nil
+	}
+
+	b.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+func (b *lineBuffer) add(line []byte) {
+	b.lines = append(b.lines, string(bytes.TrimRight(line, "\r")))
+	if len(b.lines) > b.size {
+		b.lines = b.lines[len(b.lines)-b.size:]
+	}
+}
+
+// Lines returns the lines in the order they were written, including a last line without a line break.
+func (b *lineBuffer) Lines() []string {
+	return b.Tail(b.size)
+}
+
+// Tail returns at most the last n lines.
+func (b *lineBuffer) Tail(n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := append([]string{}, b.lines...)
+	if len(b.partial) > 0 {
+		lines = append(lines, string(b.partial))
+	}
+
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return lines
+}
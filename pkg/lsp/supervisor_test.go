@@ -0,0 +1,251 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_RestartsCrashedServer(t *testing.T) {
+	project := model.Project{Id: "project-id", Path: "/test/project"}
+	ctx := model.NewContextWithProject(context.Background(), &project)
+
+	factory := &fakeProcessFactory{}
+	service := NewService(NewLanguageDetector(), NewRegistry(DefaultServers...), NewDiagnosticsStore(), NewClientPool(), factory).(*ServiceImpl)
+	service.minRestartDelay = time.Millisecond
+
+	if err := service.StartServer(ctx, Go); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.StartServer(ctx, Go); !errors.As(err, new(*LanguageServerAlreadyExistsError)) {
+		t.Fatalf("expected LanguageServerAlreadyExistsError, got %v", err)
+	}
+
+	if err := service.NotifyDidOpen(ctx, *model.NewFile("main.go", "package main")); err != nil {
+		t.Fatal(err)
+	}
+
+	factory.process(0).crash("panic: runtime error")
+
+	waitFor(t, func() bool {
+		health, _ := service.GetServerHealth(ctx)
+		return len(health) == 1 && health[0].Status == ServerStatusRunning && health[0].Restarts == 1
+	})
+
+	health, err := service.GetServerHealth(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Go, health[0].LanguageId)
+	assert.Equal(t, "gopls", health[0].Command)
+	assert.Equal(t, "exit status 2", health[0].LastError)
+	assert.Equal(t, []string{"panic: runtime error"}, health[0].Stderr)
+	assert.NotNil(t, health[0].StartedAt)
+
+	// the restarted server gets the documents that were open
+	waitFor(t, func() bool { return len(factory.process(1).opened()) == 1 })
+	assert.Equal(t, []string{"file:///test/project/main.go"}, factory.process(1).opened())
+
+	if err := service.StopServer(ctx, Go); err != nil {
+		t.Fatal(err)
+	}
+
+	health, _ = service.GetServerHealth(ctx)
+	assert.Empty(t, health)
+	assert.Equal(t, 2, factory.count())
+}
+
+func TestService_GivesUpOnServerThatKeepsCrashing(t *testing.T) {
+	project := model.Project{Id: "project-id", Path: "/test/project"}
+	ctx := model.NewContextWithProject(context.Background(), &project)
+
+	factory := &fakeProcessFactory{crashOnStart: true}
+	service := NewService(NewLanguageDetector(), NewRegistry(DefaultServers...), NewDiagnosticsStore(), NewClientPool(), factory).(*ServiceImpl)
+	service.minRestartDelay = time.Millisecond
+	service.maxCrashes = 2
+
+	if err := service.StartServer(ctx, Go); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool {
+		health, _ := service.GetServerHealth(ctx)
+		return len(health) == 1 && health[0].Status == ServerStatusFailed
+	})
+
+	// the first process and one per restart until the server failed
+	assert.Equal(t, 3, factory.count())
+
+	if err := service.CleanupProject(ctx, project.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	health, _ := service.GetServerHealth(ctx)
+	assert.Empty(t, health)
+}
+
+func TestService_RestartDelay(t *testing.T) {
+	service := &ServiceImpl{minRestartDelay: time.Second, maxRestartDelay: 5 * time.Second}
+
+	assert.Equal(t, time.Second, service.restartDelay(1))
+	assert.Equal(t, 2*time.Second, service.restartDelay(2))
+	assert.Equal(t, 4*time.Second, service.restartDelay(3))
+	assert.Equal(t, 5*time.Second, service.restartDelay(4))
+	assert.Equal(t, 5*time.Second, service.restartDelay(100))
+}
+
+func TestLineBuffer(t *testing.T) {
+	buffer := newLineBuffer(2)
+
+	fmt.Fprint(buffer, "first\nsec")
+	fmt.Fprint(buffer, "ond\r\nthird\nfou")
+
+	assert.Equal(t, []string{"third", "fou"}, buffer.Lines())
+	assert.Equal(t, []string{"fou"}, buffer.Tail(1))
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// fakeProcessFactory creates in-memory language servers that answer initialize and record the opened documents.
+type fakeProcessFactory struct {
+	// crashOnStart makes the servers crash right after they are initialized
+	crashOnStart bool
+
+	mu        sync.Mutex
+	processes []*fakeProcess
+}
+
+func (f *fakeProcessFactory) NewProcess(project model.Project, command Command, stderr io.Writer) (Process, PathMapping, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	process := &fakeProcess{stderr: stderr, done: make(chan struct{}), crashOnStart: f.crashOnStart}
+	f.processes = append(f.processes, process)
+	return process, PathMapping{}, nil
+}
+
+func (f *fakeProcessFactory) process(i int) *fakeProcess {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.processes[i]
+}
+
+func (f *fakeProcessFactory) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.processes)
+}
+
+type fakeProcess struct {
+	stderr       io.Writer
+	crashOnStart bool
+	client       net.Conn
+	server       *jsonrpc2.Conn
+	done         chan struct{}
+	once         sync.Once
+	err          error
+
+	mu        sync.Mutex
+	documents []string
+}
+
+func (p *fakeProcess) Start() error {
+	client, server := net.Pipe()
+	p.client = client
+
+	p.server = jsonrpc2.NewConn(context.Background(), jsonrpc2.NewBufferedStream(server, jsonrpc2.VSCodeObjectCodec{}), jsonrpc2.AsyncHandler(jsonrpc2.HandlerWithError(p.handle)))
+	go func() {
+		<-p.server.DisconnectNotify()
+		p.exit(nil)
+	}()
+
+	return nil
+}
+
+func (p *fakeProcess) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return map[string]any{"capabilities": map[string]any{}}, nil
+	case "initialized":
+		if p.crashOnStart {
+			go p.crash("failed to load workspace")
+		}
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(*req.Params, &params); err != nil {
+			return nil, err
+		}
+
+		p.mu.Lock()
+		p.documents = append(p.documents, params.TextDocument.URI)
+		p.mu.Unlock()
+	case "exit":
+		go p.server.Close()
+	}
+
+	return nil, nil
+}
+
+func (p *fakeProcess) opened() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string{}, p.documents...)
+}
+
+// crash writes the message to stderr and exits with an error.
+func (p *fakeProcess) crash(message string) {
+	fmt.Fprintln(p.stderr, message)
+	p.exit(errors.New("exit status 2"))
+	p.server.Close()
+}
+
+func (p *fakeProcess) exit(err error) {
+	p.once.Do(func() {
+		p.err = err
+		p.client.Close()
+		close(p.done)
+	})
+}
+
+func (p *fakeProcess) Stop() error {
+	p.exit(errors.New("signal: killed"))
+	return nil
+}
+
+func (p *fakeProcess) ReadWriteCloser() io.ReadWriteCloser {
+	return p.client
+}
+
+func (p *fakeProcess) Wait() error {
+	<-p.done
+	return p.err
+}
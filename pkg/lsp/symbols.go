@@ -2,13 +2,17 @@ package lsp
 
 import (
 	"slices"
+	"sort"
+	"strings"
 
+	"github.com/hide-org/hide/pkg/files"
 	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
 type SymbolFilter struct {
 	include []protocol.SymbolKind
 	exclude []protocol.SymbolKind
+	paths   files.PatternFilter
 }
 
 func NewIncludeSymbolFilter(include ...protocol.SymbolKind) SymbolFilter {
@@ -18,6 +22,25 @@ func NewIncludeSymbolFilter(include ...protocol.SymbolKind) SymbolFilter {
 func NewExcludeSymbolFilter(exclude ...protocol.SymbolKind) SymbolFilter {
 	return SymbolFilter{exclude: exclude}
 }
+
+// NewSymbolFilter selects symbols of the included kinds that are not of the excluded ones, in files matching the paths.
+// Empty kinds include all kinds.
+func NewSymbolFilter(include, exclude []protocol.SymbolKind, paths files.PatternFilter) SymbolFilter {
+	return SymbolFilter{include: include, exclude: exclude, paths: paths}
+}
+
+// WithPaths returns a copy of the filter that only selects symbols in files matching the paths.
+func (f SymbolFilter) WithPaths(paths files.PatternFilter) SymbolFilter {
+	f.paths = paths
+	return f
+}
+
+// Validate checks the path patterns.
+func (f SymbolFilter) Validate() error {
+	_, err := f.paths.Matcher()
+	return err
+}
+
 func (f *SymbolFilter) shouldExcludeSymbol(symbol protocol.SymbolInformation) bool {
 	return slices.Contains(f.exclude, symbol.Kind)
 }
@@ -28,3 +51,121 @@ func (f *SymbolFilter) shouldIncludeSymbol(symbol protocol.SymbolInformation) bo
 	}
 	return slices.Contains(f.include, symbol.Kind)
 }
+
+func (f *SymbolFilter) matchesKind(kind protocol.SymbolKind) bool {
+	symbol := protocol.SymbolInformation{Kind: kind}
+	return !f.shouldExcludeSymbol(symbol) && f.shouldIncludeSymbol(symbol)
+}
+
+// ParseSymbolKind parses the name of a symbol kind as it is returned in SymbolInfo.Kind, ignoring case.
+func ParseSymbolKind(name string) (protocol.SymbolKind, bool) {
+	for kind := protocol.SymbolKindFile; kind <= protocol.SymbolKindTypeParameter; kind++ {
+		if strings.EqualFold(symbolKindToString(kind), name) {
+			return kind, true
+		}
+	}
+
+	return 0, false
+}
+
+// symbolMatch is how well the name of a symbol matches a query, lower is better.
+type symbolMatch int
+
+const (
+	symbolMatchExact symbolMatch = iota
+	symbolMatchPrefix
+	symbolMatchFuzzy
+	// symbolMatchOther is for symbols that language servers matched otherwise, e.g. by their container name
+	symbolMatchOther
+)
+
+func matchSymbol(query, name string) symbolMatch {
+	query, name = strings.ToLower(query), strings.ToLower(name)
+
+	switch {
+	case name == query:
+		return symbolMatchExact
+	case strings.HasPrefix(name, query):
+		return symbolMatchPrefix
+	case isSubsequence(query, name):
+		return symbolMatchFuzzy
+	default:
+		return symbolMatchOther
+	}
+}
+
+// isSubsequence reports if all characters of query appear in s in the same order.
+func isSubsequence(query, s string) bool {
+	runes := []rune(query)
+
+	i := 0
+	for _, r := range s {
+		if i < len(runes) && runes[i] == r {
+			i++
+		}
+	}
+
+	return i == len(runes)
+}
+
+// rankSymbols removes duplicates, e.g. the same symbol found by two language servers, and orders the symbols by how well
+// their names match the query: exact matches first, then prefixes, then fuzzy matches. Symbols that match equally well are
+// ordered by the depth of their files, so that symbols closer to the project root come first, and then by their location, name and
+// kind, so that the order is the same for every search, whatever order the servers answer in.
+func rankSymbols(query string, symbols []SymbolInfo) []SymbolInfo {
+	type key struct {
+		name, kind, path string
+		start            Position
+	}
+
+	seen := make(map[key]bool, len(symbols))
+	result := make([]SymbolInfo, 0, len(symbols))
+	for _, symbol := range symbols {
+		k := key{symbol.Name, symbol.Kind, symbol.Location.Path, symbol.Location.Range.Start}
+		if seen[k] {
+			continue
+		}
+
+		seen[k] = true
+		result = append(result, symbol)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if matchA, matchB := matchSymbol(query, a.Name), matchSymbol(query, b.Name); matchA != matchB {
+			return matchA < matchB
+		}
+
+		if depthA, depthB := pathDepth(a.Location.Path), pathDepth(b.Location.Path); depthA != depthB {
+			return depthA < depthB
+		}
+
+		if len(a.ContainerName) != len(b.ContainerName) {
+			return len(a.ContainerName) < len(b.ContainerName)
+		}
+
+		if a.Location.Path != b.Location.Path {
+			return a.Location.Path < b.Location.Path
+		}
+
+		if a.Location.Range.Start.Line != b.Location.Range.Start.Line {
+			return a.Location.Range.Start.Line < b.Location.Range.Start.Line
+		}
+
+		if a.Location.Range.Start.Character != b.Location.Range.Start.Character {
+			return a.Location.Range.Start.Character < b.Location.Range.Start.Character
+		}
+
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+
+		return a.Kind < b.Kind
+	})
+
+	return result
+}
+
+func pathDepth(path string) int {
+	return strings.Count(strings.Trim(path, "/"), "/")
+}
@@ -0,0 +1,346 @@
+package lsp
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// tagPattern matches a line declaring a symbol of the kind, the first group of the pattern is its name.
+type tagPattern struct {
+	kind    protocol.SymbolKind
+	pattern *regexp.Regexp
+}
+
+func tag(kind protocol.SymbolKind, pattern string) tagPattern {
+	return tagPattern{kind: kind, pattern: regexp.MustCompile(pattern)}
+}
+
+var (
+	jsTags = []tagPattern{
+		tag(protocol.SymbolKindClass, `^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)`),
+		tag(protocol.SymbolKindInterface, `^\s*(?:export\s+)?(?:declare\s+)?interface\s+(\w+)`),
+		tag(protocol.SymbolKindEnum, `^\s*(?:export\s+)?(?:declare\s+)?(?:const\s+)?enum\s+(\w+)`),
+		tag(protocol.SymbolKindFunction, `^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(\w+)`),
+		tag(protocol.SymbolKindFunction, `^\s*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|\w+\s*=>)`),
+		tag(protocol.SymbolKindMethod, `^\s+(?:(?:public|private|protected|static|async|readonly|override|get|set)\s+)*\*?(\w+)\s*(?:<[^>]*>)?\([^)]*\)\s*(?::[^{]+)?\{\s*\}?\s*$`),
+	}
+
+	javaLikeModifiers = `(?:(?:public|private|protected|internal|static|final|abstract|sealed|open|data|partial|override|virtual|async|synchronized|inline|suspend|readonly)\s+)*`
+
+	// tags extract the symbols of the languages from their declarations, like ctags does
+	tags = map[LanguageId][]tagPattern{
+		Go: {
+			tag(protocol.SymbolKindMethod, `^func\s+\([^)]*\)\s*(\w+)`),
+			tag(protocol.SymbolKindFunction, `^func\s+(\w+)`),
+			tag(protocol.SymbolKindStruct, `^(?:type\s+|\s+)(\w+)(?:\[[^\]]*\])?\s+struct\b`),
+			tag(protocol.SymbolKindInterface, `^(?:type\s+|\s+)(\w+)(?:\[[^\]]*\])?\s+interface\b`),
+			tag(protocol.SymbolKindClass, `^type\s+(\w+)\s+\w`),
+			tag(protocol.SymbolKindConstant, `^const\s+(\w+)`),
+			tag(protocol.SymbolKindVariable, `^var\s+(\w+)`),
+		},
+		Python: {
+			tag(protocol.SymbolKindClass, `^\s*class\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*(?:async\s+)?def\s+(\w+)`),
+			tag(protocol.SymbolKindConstant, `^([A-Z][A-Z0-9_]*)\s*(?::[^=]+)?=`),
+		},
+		JavaScript: jsTags,
+		TypeScript: jsTags,
+		"TSX":      jsTags,
+		"JSX":      jsTags,
+		"Vue":      jsTags,
+		"Svelte":   jsTags,
+		"CoffeeScript": {
+			tag(protocol.SymbolKindClass, `^\s*class\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*(\w+)\s*[:=]\s*(?:\([^)]*\)\s*)?[-=]>`),
+		},
+		"Java": {
+			tag(protocol.SymbolKindClass, `^\s*`+javaLikeModifiers+`(?:class|record)\s+(\w+)`),
+			tag(protocol.SymbolKindInterface, `^\s*`+javaLikeModifiers+`@?interface\s+(\w+)`),
+			tag(protocol.SymbolKindEnum, `^\s*`+javaLikeModifiers+`enum\s+(\w+)`),
+			tag(protocol.SymbolKindMethod, `^\s+`+javaLikeModifiers+`(?:<[^>]*>\s+)?[\w.<>\[\],?]+\s+(\w+)\s*\([^;]*$`),
+		},
+		"C#": {
+			tag(protocol.SymbolKindNamespace, `^\s*namespace\s+([\w.]+)`),
+			tag(protocol.SymbolKindClass, `^\s*`+javaLikeModifiers+`(?:class|record)\s+(\w+)`),
+			tag(protocol.SymbolKindStruct, `^\s*`+javaLikeModifiers+`struct\s+(\w+)`),
+			tag(protocol.SymbolKindInterface, `^\s*`+javaLikeModifiers+`interface\s+(\w+)`),
+			tag(protocol.SymbolKindEnum, `^\s*`+javaLikeModifiers+`enum\s+(\w+)`),
+			tag(protocol.SymbolKindMethod, `^\s+`+javaLikeModifiers+`[\w.<>\[\],?]+\s+(\w+)\s*(?:<[^>]*>)?\([^;]*$`),
+		},
+		"Kotlin": {
+			tag(protocol.SymbolKindInterface, `^\s*`+javaLikeModifiers+`(?:fun\s+)?interface\s+(\w+)`),
+			tag(protocol.SymbolKindEnum, `^\s*`+javaLikeModifiers+`enum\s+class\s+(\w+)`),
+			tag(protocol.SymbolKindClass, `^\s*`+javaLikeModifiers+`(?:class|object)\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*`+javaLikeModifiers+`fun\s+(?:<[^>]*>\s*)?(?:[\w.]+\.)?(\w+)\s*\(`),
+		},
+		"Scala": {
+			tag(protocol.SymbolKindInterface, `^\s*(?:sealed\s+)?trait\s+(\w+)`),
+			tag(protocol.SymbolKindClass, `^\s*(?:(?:abstract|case|final|sealed|implicit)\s+)*(?:class|object)\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*(?:(?:override|private|protected|final|implicit)\s+)*def\s+(\w+)`),
+		},
+		"Rust": {
+			tag(protocol.SymbolKindModule, `^\s*(?:pub(?:\([^)]*\))?\s+)?mod\s+(\w+)`),
+			tag(protocol.SymbolKindStruct, `^\s*(?:pub(?:\([^)]*\))?\s+)?struct\s+(\w+)`),
+			tag(protocol.SymbolKindEnum, `^\s*(?:pub(?:\([^)]*\))?\s+)?enum\s+(\w+)`),
+			tag(protocol.SymbolKindInterface, `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:unsafe\s+)?trait\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:default\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"[^"]*"\s+)?fn\s+(\w+)`),
+			tag(protocol.SymbolKindConstant, `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const|static)\s+(?:mut\s+)?(\w+)`),
+			tag(protocol.SymbolKindClass, `^\s*(?:pub(?:\([^)]*\))?\s+)?type\s+(\w+)`),
+		},
+		"C": {
+			tag(protocol.SymbolKindStruct, `^\s*(?:typedef\s+)?struct\s+(\w+)[^;]*$`),
+			tag(protocol.SymbolKindEnum, `^\s*(?:typedef\s+)?enum\s+(\w+)[^;]*$`),
+			tag(protocol.SymbolKindConstant, `^#\s*define\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^(?:[\w*]+[ \t*]+)+\**(\w+)\s*\([^;]*$`),
+		},
+		"C++": {
+			tag(protocol.SymbolKindNamespace, `^\s*namespace\s+(\w+)`),
+			tag(protocol.SymbolKindClass, `^\s*(?:template\s*<[^>]*>\s*)?class\s+(\w+)[^;]*$`),
+			tag(protocol.SymbolKindStruct, `^\s*(?:template\s*<[^>]*>\s*)?(?:typedef\s+)?struct\s+(\w+)[^;]*$`),
+			tag(protocol.SymbolKindEnum, `^\s*enum\s+(?:class\s+)?(\w+)[^;]*$`),
+			tag(protocol.SymbolKindConstant, `^#\s*define\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^(?:[\w*&:<>,]+[ \t*&]+)+[*&]*(?:\w+::)*(~?\w+)\s*\([^;]*$`),
+		},
+		"Objective-C": {
+			tag(protocol.SymbolKindClass, `^@(?:interface|implementation)\s+(\w+)`),
+			tag(protocol.SymbolKindInterface, `^@protocol\s+(\w+)`),
+			tag(protocol.SymbolKindMethod, `^[-+]\s*\([^)]*\)\s*(\w+)`),
+		},
+		"Swift": {
+			tag(protocol.SymbolKindClass, `^\s*`+javaLikeModifiers+`(?:final\s+)?class\s+(\w+)`),
+			tag(protocol.SymbolKindStruct, `^\s*`+javaLikeModifiers+`struct\s+(\w+)`),
+			tag(protocol.SymbolKindEnum, `^\s*`+javaLikeModifiers+`enum\s+(\w+)`),
+			tag(protocol.SymbolKindInterface, `^\s*`+javaLikeModifiers+`protocol\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*`+javaLikeModifiers+`(?:@\w+\s+)*(?:mutating\s+)?func\s+(\w+)`),
+		},
+		"Dart": {
+			tag(protocol.SymbolKindClass, `^\s*(?:abstract\s+)?(?:class|mixin)\s+(\w+)`),
+			tag(protocol.SymbolKindEnum, `^\s*enum\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*(?:static\s+)?(?:Future<[^>]*>|void|[\w<>?]+)\s+(\w+)\s*\([^;]*$`),
+		},
+		"Ruby": {
+			tag(protocol.SymbolKindModule, `^\s*module\s+([\w:]+)`),
+			tag(protocol.SymbolKindClass, `^\s*class\s+([\w:]+)`),
+			tag(protocol.SymbolKindMethod, `^\s*def\s+(?:self\.)?(\w+[?!=]?)`),
+		},
+		"PHP": {
+			tag(protocol.SymbolKindNamespace, `^\s*namespace\s+([\w\\]+)`),
+			tag(protocol.SymbolKindClass, `^\s*(?:(?:abstract|final|readonly)\s+)*class\s+(\w+)`),
+			tag(protocol.SymbolKindInterface, `^\s*(?:interface|trait)\s+(\w+)`),
+			tag(protocol.SymbolKindEnum, `^\s*enum\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*(?:(?:public|private|protected|static|abstract|final)\s+)*function\s+&?(\w+)`),
+		},
+		"Perl": {
+			tag(protocol.SymbolKindPackage, `^\s*package\s+([\w:]+)`),
+			tag(protocol.SymbolKindFunction, `^\s*sub\s+(\w+)`),
+		},
+		"Lua": {
+			tag(protocol.SymbolKindFunction, `^\s*(?:local\s+)?function\s+([\w.:]+)`),
+			tag(protocol.SymbolKindFunction, `^\s*(?:local\s+)?([\w.]+)\s*=\s*function\b`),
+		},
+		"Elixir": {
+			tag(protocol.SymbolKindModule, `^\s*defmodule\s+([\w.]+)`),
+			tag(protocol.SymbolKindFunction, `^\s*def(?:p|macro|macrop)?\s+(\w+[?!]?)`),
+		},
+		"Erlang": {
+			tag(protocol.SymbolKindModule, `^-module\(\s*(\w+)`),
+			tag(protocol.SymbolKindFunction, `^([a-z]\w*)\s*\([^)]*\)\s*(?:when\b.*)?->`),
+		},
+		"Haskell": {
+			tag(protocol.SymbolKindModule, `^module\s+([\w.]+)`),
+			tag(protocol.SymbolKindClass, `^(?:data|newtype|type)\s+(\w+)`),
+			tag(protocol.SymbolKindInterface, `^class\s+(?:\([^)]*\)\s*=>\s*)?(\w+)`),
+			tag(protocol.SymbolKindFunction, `^([a-z_]\w*'*)\s*::`),
+		},
+		"OCaml": {
+			tag(protocol.SymbolKindModule, `^\s*module\s+(?:type\s+)?(\w+)`),
+			tag(protocol.SymbolKindClass, `^\s*type\s+(?:'\w+\s+)?(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*let\s+(?:rec\s+)?(\w+)`),
+		},
+		"Clojure": {
+			tag(protocol.SymbolKindNamespace, `^\(ns\s+([\w.\-]+)`),
+			tag(protocol.SymbolKindFunction, `^\(defn-?\s+([^\s()\[\]]+)`),
+			tag(protocol.SymbolKindVariable, `^\(def\s+([^\s()\[\]]+)`),
+		},
+		"R": {
+			tag(protocol.SymbolKindFunction, `^\s*([\w.]+)\s*(?:<-|=)\s*function\b`),
+		},
+		"Julia": {
+			tag(protocol.SymbolKindModule, `^\s*module\s+(\w+)`),
+			tag(protocol.SymbolKindStruct, `^\s*(?:mutable\s+)?struct\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*function\s+([\w.!]+)`),
+		},
+		"Shell": {
+			tag(protocol.SymbolKindFunction, `^\s*function\s+([\w\-:.]+)`),
+			tag(protocol.SymbolKindFunction, `^\s*([\w\-:.]+)\s*\(\)`),
+		},
+		"PowerShell": {
+			tag(protocol.SymbolKindFunction, `(?i)^\s*function\s+([\w\-]+)`),
+			tag(protocol.SymbolKindClass, `(?i)^\s*class\s+(\w+)`),
+		},
+		"Zig": {
+			tag(protocol.SymbolKindFunction, `^\s*(?:pub\s+)?(?:export\s+)?(?:inline\s+)?fn\s+(\w+)`),
+			tag(protocol.SymbolKindStruct, `^\s*(?:pub\s+)?const\s+(\w+)\s*=\s*(?:packed\s+|extern\s+)?(?:struct|union)\b`),
+			tag(protocol.SymbolKindEnum, `^\s*(?:pub\s+)?const\s+(\w+)\s*=\s*enum\b`),
+		},
+		"Nim": {
+			tag(protocol.SymbolKindFunction, `^\s*(?:proc|func|method|iterator|macro|template)\s+(\w+)`),
+			tag(protocol.SymbolKindClass, `^\s+(\w+)\*?\s*=\s*(?:ref\s+)?object\b`),
+		},
+		"Solidity": {
+			tag(protocol.SymbolKindClass, `^\s*(?:abstract\s+)?(?:contract|library)\s+(\w+)`),
+			tag(protocol.SymbolKindInterface, `^\s*interface\s+(\w+)`),
+			tag(protocol.SymbolKindStruct, `^\s*struct\s+(\w+)`),
+			tag(protocol.SymbolKindEvent, `^\s*event\s+(\w+)`),
+			tag(protocol.SymbolKindFunction, `^\s*(?:function|modifier)\s+(\w+)`),
+		},
+		"Protocol Buffer": {
+			tag(protocol.SymbolKindStruct, `^\s*message\s+(\w+)`),
+			tag(protocol.SymbolKindEnum, `^\s*enum\s+(\w+)`),
+			tag(protocol.SymbolKindInterface, `^\s*service\s+(\w+)`),
+			tag(protocol.SymbolKindMethod, `^\s*rpc\s+(\w+)`),
+		},
+		"SQL": {
+			tag(protocol.SymbolKindStruct, `(?i)^\s*create\s+(?:or\s+replace\s+)?(?:temporary\s+)?(?:table|view)\s+(?:if\s+not\s+exists\s+)?([\w."]+)`),
+			tag(protocol.SymbolKindFunction, `(?i)^\s*create\s+(?:or\s+replace\s+)?(?:function|procedure)\s+([\w."]+)`),
+		},
+	}
+
+	// notSymbolNames are keywords that loose patterns, e.g. of methods, match like names
+	notSymbolNames = []string{"if", "else", "for", "foreach", "while", "switch", "case", "catch", "return", "new", "delete", "throw", "do", "try", "sizeof", "typeof", "function", "using", "lock", "fixed", "when", "with"}
+)
+
+// extractTags returns the symbols declared in the content, nested by their indentation. It returns nil for languages without tags.
+func extractTags(languageId LanguageId, content string) []DocumentSymbol {
+	patterns, ok := tags[languageId]
+	if !ok {
+		return nil
+	}
+
+	type found struct {
+		symbol DocumentSymbol
+		indent int
+	}
+
+	lines := strings.Split(content, "\n")
+	var symbols []found
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		for _, p := range patterns {
+			match := p.pattern.FindStringSubmatchIndex(line)
+			if match == nil || match[2] < 0 {
+				continue
+			}
+
+			name := line[match[2]:match[3]]
+			if slices.Contains(notSymbolNames, name) {
+				continue
+			}
+
+			end := blockEnd(lines, i)
+			symbols = append(symbols, found{
+				symbol: DocumentSymbol{
+					Name:  name,
+					Kind:  symbolKindToString(p.kind),
+					Range: Range{Start: Position{Line: i + 1, Character: utf16Len(line[:match[2]])}, End: Position{Line: end + 1, Character: utf16Len(strings.TrimRight(lines[end], "\r"))}},
+				},
+				indent: indentation(line),
+			})
+			break
+		}
+	}
+
+	// symbols are nested in the closest preceding symbol whose range contains them
+	var roots []DocumentSymbol
+	var stack []*DocumentSymbol
+	var indents []int
+	for _, f := range symbols {
+		for len(stack) > 0 && (stack[len(stack)-1].Range.End.Line < f.symbol.Range.Start.Line || indents[len(indents)-1] >= f.indent) {
+			stack, indents = stack[:len(stack)-1], indents[:len(indents)-1]
+		}
+
+		symbol := f.symbol
+		if len(stack) == 0 {
+			roots = append(roots, symbol)
+			stack = append(stack, &roots[len(roots)-1])
+		} else {
+			parent := stack[len(stack)-1]
+			if symbol.Kind == symbolKindToString(protocol.SymbolKindFunction) && isTypeKind(parent.Kind) {
+				symbol.Kind = symbolKindToString(protocol.SymbolKindMethod)
+			}
+			parent.Children = append(parent.Children, symbol)
+			stack = append(stack, &parent.Children[len(parent.Children)-1])
+		}
+		indents = append(indents, f.indent)
+	}
+
+	return roots
+}
+
+func isTypeKind(kind string) bool {
+	switch kind {
+	case "Class", "Struct", "Interface", "Enum", "Object":
+		return true
+	default:
+		return false
+	}
+}
+
+// blockEnd returns the last line of the block starting at the line, which ends before the next line that is not indented more,
+// or at that line if it closes the block, like a closing brace or end.
+func blockEnd(lines []string, start int) int {
+	indent := indentation(lines[start])
+
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+
+		if indentation(lines[i]) > indent {
+			end = i
+			continue
+		}
+
+		if strings.HasPrefix(line, "}") || strings.HasPrefix(line, ")") || strings.HasPrefix(line, "]") || line == "end" || strings.HasPrefix(line, "end ") {
+			end = i
+		}
+		break
+	}
+
+	return end
+}
+
+func indentation(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+
+	return n
+}
+
+// utf16Len returns the length of s in UTF-16 code units, the unit of the characters of positions in the language server protocol.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+
+	return n
+}
@@ -2,6 +2,7 @@ package lsp
 
 import (
 	"path/filepath"
+	"sort"
 
 	"github.com/go-enry/go-enry/v2"
 	"github.com/hide-org/hide/pkg/model"
@@ -21,6 +22,8 @@ type LanguageDetector interface {
 	DetectLanguage(file *model.File) string
 	DetectLanguages(files []*model.File) map[string]int
 	DetectMainLanguage(files []*model.File) string
+	// DetectMainLanguages returns the languages that make up at least threshold of the bytes of the files, the most used first
+	DetectMainLanguages(files []*model.File, threshold float64) []string
 }
 
 // LanguageDetectorImpl implements LanguageDetector using https://github.com/go-enry/go-enry
@@ -59,6 +62,32 @@ func (ld LanguageDetectorImpl) DetectMainLanguage(files []*model.File) string {
 	return maxLanguage
 }
 
+func (ld LanguageDetectorImpl) DetectMainLanguages(files []*model.File, threshold float64) []string {
+	languages := ld.DetectLanguages(files)
+	log.Debug().Any("languages", languages).Msg("Detected languages")
+
+	total := 0
+	for _, count := range languages {
+		total += count
+	}
+
+	var result []string
+	for language, count := range languages {
+		if count > 0 && float64(count) >= threshold*float64(total) {
+			result = append(result, language)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if languages[result[i]] != languages[result[j]] {
+			return languages[result[i]] > languages[result[j]]
+		}
+		return result[i] < result[j]
+	})
+
+	return result
+}
+
 func skipFile(filename string, content []byte) bool {
 	return enry.IsBinary(content) ||
 		enry.IsVendor(filename) ||
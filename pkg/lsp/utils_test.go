@@ -0,0 +1,35 @@
+package lsp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hide-org/hide/pkg/lsp"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageDetector_DetectMainLanguages(t *testing.T) {
+	files := []*model.File{
+		model.NewFile("main.go", "package main\n"+strings.Repeat("// backend\n", 60)),
+		model.NewFile("web/app.ts", "export const app = 1;\n"+strings.Repeat("// frontend\n", 30)),
+		model.NewFile("scripts/build.py", "print('build')\n"),
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		want      []string
+	}{
+		{name: "all languages", threshold: 0, want: []string{lsp.Go, lsp.TypeScript, lsp.Python}},
+		{name: "significant languages", threshold: 0.1, want: []string{lsp.Go, lsp.TypeScript}},
+		{name: "main language", threshold: 0.5, want: []string{lsp.Go}},
+		{name: "no language", threshold: 1.1, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, lsp.NewLanguageDetector().DetectMainLanguages(files, tt.threshold))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// parsePrepareRename parses a result that is either a Range, a range with a placeholder, a default behavior or null.
+func parsePrepareRename(data json.RawMessage) (*protocol.RangeWithPlaceholder, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	var value struct {
+		protocol.Range
+		RangeWithPlaceholder *protocol.Range `json:"range"`
+		Placeholder          string          `json:"placeholder"`
+		DefaultBehavior      *bool           `json:"defaultBehavior"`
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse prepare rename result: %w", err)
+	}
+
+	switch {
+	case value.DefaultBehavior != nil:
+		if !*value.DefaultBehavior {
+			return nil, nil
+		}
+		return &protocol.RangeWithPlaceholder{}, nil
+	case value.RangeWithPlaceholder != nil:
+		return &protocol.RangeWithPlaceholder{Range: *value.RangeWithPlaceholder, Placeholder: value.Placeholder}, nil
+	default:
+		return &protocol.RangeWithPlaceholder{Range: value.Range}, nil
+	}
+}
+
+// parseWorkspaceEdit parses a workspace edit. The unmarshaler of glsp tries the types of document changes in order and
+// reads file operations as empty text document edits, so document changes are parsed by their kind instead.
+func parseWorkspaceEdit(data json.RawMessage) (*protocol.WorkspaceEdit, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	var value struct {
+		Changes         map[protocol.DocumentUri][]protocol.TextEdit `json:"changes"`
+		DocumentChanges []json.RawMessage                            `json:"documentChanges"`
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace edit: %w", err)
+	}
+
+	edit := &protocol.WorkspaceEdit{Changes: value.Changes}
+	for _, documentChange := range value.DocumentChanges {
+		change, err := parseDocumentChange(documentChange)
+		if err != nil {
+			return nil, err
+		}
+		edit.DocumentChanges = append(edit.DocumentChanges, change)
+	}
+
+	return edit, nil
+}
+
+func parseDocumentChange(data json.RawMessage) (any, error) {
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &kind); err != nil {
+		return nil, fmt.Errorf("failed to parse document change: %w", err)
+	}
+
+	switch kind.Kind {
+	case "create":
+		var change protocol.CreateFile
+		err := json.Unmarshal(data, &change)
+		return change, wrapDocumentChangeError(kind.Kind, err)
+	case "rename":
+		var change protocol.RenameFile
+		err := json.Unmarshal(data, &change)
+		return change, wrapDocumentChangeError(kind.Kind, err)
+	case "delete":
+		var change protocol.DeleteFile
+		err := json.Unmarshal(data, &change)
+		return change, wrapDocumentChangeError(kind.Kind, err)
+	case "":
+		var value struct {
+			TextDocument protocol.OptionalVersionedTextDocumentIdentifier `json:"textDocument"`
+			// annotated text edits are text edits with an annotation id, which is not needed here
+			Edits []protocol.TextEdit `json:"edits"`
+		}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, wrapDocumentChangeError("text document edit", err)
+		}
+
+		edits := make([]any, len(value.Edits))
+		for i, edit := range value.Edits {
+			edits[i] = edit
+		}
+		return protocol.TextDocumentEdit{TextDocument: value.TextDocument, Edits: edits}, nil
+	default:
+		return nil, fmt.Errorf("unknown document change kind %s", kind.Kind)
+	}
+}
+
+func wrapDocumentChangeError(kind string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("failed to parse %s document change: %w", kind, err)
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	"github.com/hide-org/hide/pkg/handlers"
@@ -29,6 +30,12 @@ func PathValidator(next http.Handler) http.Handler {
 			return
 		}
 
+		// symlinks are resolved by the project file system, here we only reject paths that are outside of the project syntactically
+		if cleaned := filepath.Clean(filePath); cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			http.Error(w, "Invalid file path: path is outside of the project", http.StatusBadRequest)
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,18 @@
+package model
+
+type FileEventType string
+
+const (
+	FileCreated  FileEventType = "create"
+	FileModified FileEventType = "modify"
+	FileDeleted  FileEventType = "delete"
+	FileRenamed  FileEventType = "rename"
+)
+
+// FileEvent describes a change of a file in the project workspace. Paths are relative to the project root.
+type FileEvent struct {
+	Type    FileEventType `json:"type"`
+	Path    string        `json:"path"`
+	OldPath string        `json:"oldPath,omitempty"`
+	IsDir   bool          `json:"isDir,omitempty"`
+}
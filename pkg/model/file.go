@@ -14,6 +14,8 @@ type Line struct {
 type File struct {
 	Path  string `json:"path"`
 	Lines []Line `json:"lines"`
+	// LinkTarget is the target of a symlink as it is stored in the link, the link is not followed
+	LinkTarget string `json:"linkTarget,omitempty"`
 	// NOTE: should diagnostics be part of a line?
 	Diagnostics []protocol.Diagnostic `json:"diagnostics,omitempty"`
 }
@@ -135,3 +137,7 @@ func NewLines(content string) []Line {
 func EmptyFile(path string) *File {
 	return &File{Path: path, Lines: []Line{}}
 }
+
+func SymlinkFile(path, target string) *File {
+	return &File{Path: path, Lines: []Line{}, LinkTarget: target}
+}
@@ -45,6 +45,14 @@ func (project *Project) GetTasks() []devcontainer.Task {
 	return project.Config.DevContainerConfig.Customizations.Hide.Tasks
 }
 
+func (project *Project) GetLanguageServers() []devcontainer.LanguageServer {
+	if project.Config.DevContainerConfig.Customizations.Hide == nil {
+		return nil
+	}
+
+	return project.Config.DevContainerConfig.Customizations.Hide.LanguageServers
+}
+
 // unexported key type for Project; prevents collisions with keys defined in other packages
 type key int
 
@@ -15,6 +15,9 @@ func (fs Files) String() string {
 
 	for _, f := range fs {
 		parts := strings.Split(f.Path, "/")
+		if f.LinkTarget != "" {
+			parts[len(parts)-1] += " -> " + f.LinkTarget
+		}
 		root.addPath(parts)
 	}
 
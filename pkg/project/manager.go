@@ -4,26 +4,35 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/hide-org/hide/pkg/dap"
 	"github.com/hide-org/hide/pkg/devcontainer"
 	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/lsp"
 	"github.com/hide-org/hide/pkg/model"
 	"github.com/hide-org/hide/pkg/result"
+	"github.com/hide-org/hide/pkg/watcher"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/spf13/afero"
 	protocol "github.com/tliron/glsp/protocol_3_16"
 
 	"github.com/rs/zerolog/log"
-
-	"github.com/spf13/afero"
 )
 
-const MaxDiagnosticsDelay = time.Second * 1
+// DefaultDiagnosticsTimeout is how long to wait for a language server to publish the diagnostics of a changed file
+const DefaultDiagnosticsTimeout = time.Second * 3
+
+// DefaultLanguageThreshold is the share of the code of a project a language needs for its language server to be started
+const DefaultLanguageThreshold = 0.1
 
 type Repository struct {
 	Url    string  `json:"url" validate:"required,url"`
@@ -33,7 +42,8 @@ type Repository struct {
 type CreateProjectRequest struct {
 	Repository   Repository           `json:"repository" validate:"required"`
 	DevContainer *devcontainer.Config `json:"devcontainer,omitempty"`
-	Languages    []lsp.LanguageId     `json:"languages,omitempty" validate:"dive,oneof=Go JavaScript Python TypeScript"`
+	// Languages must have a language server in Hide or in the devcontainer.json of the project, they are detected if empty
+	Languages []lsp.LanguageId `json:"languages,omitempty" validate:"dive,required"`
 }
 
 type TaskResult struct {
@@ -42,22 +52,61 @@ type TaskResult struct {
 	ExitCode int    `json:"exitCode"`
 }
 
+// WorkspaceEditResult describes the files changed by a language server, e.g. by a rename or a code action.
+type WorkspaceEditResult struct {
+	Files []files.FileDiff `json:"files"`
+	// Diagnostics of the changed files, only if the changes are applied
+	Diagnostics map[string][]protocol.Diagnostic `json:"diagnostics,omitempty"`
+}
+
 type Manager interface {
-	ApplyPatch(ctx context.Context, projectId, path, patch string) (*model.File, error)
+	ApplyCodeAction(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range, title string, dryRun bool) (*WorkspaceEditResult, error)
+	ApplyPatch(ctx context.Context, projectId, path, patch string, opts ...WriteFileOption) (*model.File, error)
 	Cleanup(ctx context.Context) error
-	CreateFile(ctx context.Context, projectId, path, content string) (*model.File, error)
+	Complete(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, limit int) (*lsp.CompletionList, error)
+	// ConnectLanguageServer passes the JSON-RPC messages of the stream through to the language server, until one of them disconnects
+	ConnectLanguageServer(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId, stream jsonrpc2.ObjectStream) error
+	CreateFile(ctx context.Context, projectId, path, content string, opts ...WriteFileOption) (*model.File, error)
 	CreateProject(ctx context.Context, request CreateProjectRequest) <-chan result.Result[model.Project]
 	CreateTask(ctx context.Context, projectId model.ProjectId, command string) (TaskResult, error)
 	DeleteFile(ctx context.Context, projectId, path string) error
 	DeleteProject(ctx context.Context, projectId model.ProjectId) error
+	DownloadArchive(ctx context.Context, projectId model.ProjectId, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error
+	FindDefinition(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error)
+	FindReferences(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, includeDeclaration bool) ([]lsp.Location, error)
+	FormatFile(ctx context.Context, projectId model.ProjectId, path string, opts lsp.FormatOptions, dryRun bool) (*WorkspaceEditResult, error)
+	GetCallHierarchy(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.CallHierarchyDirection, depth int) ([]lsp.HierarchyItem, error)
+	GetCodeActions(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range) ([]lsp.CodeAction, error)
+	GetDebugScopes(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, frameId int) ([]dap.Scope, error)
+	GetDebugSession(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) (*dap.Session, error)
+	GetDebugStackTrace(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, threadId int, levels int) ([]dap.StackFrame, error)
+	GetDebugThreads(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) ([]dap.Thread, error)
+	GetDebugVariables(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, variablesReference int) ([]dap.Variable, error)
+	GetDiagnostics(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter, refresh bool) (*lsp.DiagnosticsReport, error)
+	GetLanguageServers(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error)
+	GetOutline(ctx context.Context, projectId model.ProjectId, path string) ([]lsp.DocumentSymbol, error)
 	GetProject(ctx context.Context, projectId model.ProjectId) (model.Project, error)
 	GetProjects(ctx context.Context) ([]*model.Project, error)
+	GetTypeHierarchy(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.TypeHierarchyDirection, depth int) ([]lsp.HierarchyItem, error)
+	Hover(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position) (*lsp.HoverInfo, error)
+	// LaunchDebugSession starts the debug adapter of the language in the project container and launches the program, see dap.Service
+	LaunchDebugSession(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error)
+	ListDebugSessions(ctx context.Context, projectId model.ProjectId) ([]dap.Session, error)
 	ListFiles(ctx context.Context, projectId string, opts ...files.ListFileOption) (model.Files, error)
-	ReadFile(ctx context.Context, projectId, path string) (*model.File, error)
+	ReadFile(ctx context.Context, projectId, path string, opts ...ReadFileOption) (*model.File, error)
+	Rename(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, newName string, dryRun bool) (*WorkspaceEditResult, error)
 	ResolveTaskAlias(ctx context.Context, projectId model.ProjectId, alias string) (devcontainer.Task, error)
 	SearchSymbols(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error)
-	UpdateFile(ctx context.Context, projectId, path, content string) (*model.File, error)
-	UpdateLines(ctx context.Context, projectId, path string, lineDiff files.LineDiffChunk) (*model.File, error)
+	SetBreakpoints(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, path string, breakpoints []dap.SourceBreakpoint) ([]dap.Breakpoint, error)
+	StartLanguageServer(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error
+	StepDebugSession(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, action dap.StepAction, threadId int, timeout time.Duration) (*dap.Session, error)
+	StopLanguageServer(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error
+	SubscribeDiagnostics(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter) (<-chan lsp.FileDiagnostics, error)
+	SubscribeFileEvents(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error)
+	TerminateDebugSession(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) error
+	UpdateFile(ctx context.Context, projectId, path, content string, opts ...WriteFileOption) (*model.File, error)
+	UpdateLines(ctx context.Context, projectId, path string, lineDiff files.LineDiffChunk, opts ...WriteFileOption) (*model.File, error)
+	UploadArchive(ctx context.Context, projectId model.ProjectId, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error)
 }
 
 type ManagerImpl struct {
@@ -66,27 +115,45 @@ type ManagerImpl struct {
 	projectsRoot       string
 	fileManager        files.FileManager
 	lspService         lsp.Service
+	debugService       dap.Service
 	languageDetector   lsp.LanguageDetector
+	fileWatcher        watcher.Service
 	randomString       func(int) string
+	diagnosticsTimeout time.Duration
+	languageThreshold  float64
+}
+
+// ManagerOptions are the dependencies and settings of a Manager, see NewProjectManager.
+type ManagerOptions struct {
+	DevContainerRunner devcontainer.Runner
+	Store              Store
+	// ProjectsRoot is the directory the repositories of the projects are cloned into
+	ProjectsRoot     string
+	FileManager      files.FileManager
+	LspService       lsp.Service
+	DebugService     dap.Service
+	LanguageDetector lsp.LanguageDetector
+	FileWatcher      watcher.Service
+	RandomString     func(int) string
+	// DiagnosticsTimeout is how long to wait for the diagnostics of a changed file, e.g. DefaultDiagnosticsTimeout
+	DiagnosticsTimeout time.Duration
+	// LanguageThreshold is the share of the code a language needs for its language server to be started, e.g. DefaultLanguageThreshold
+	LanguageThreshold float64
 }
 
-func NewProjectManager(
-	devContainerRunner devcontainer.Runner,
-	projectStore Store,
-	projectsRoot string,
-	fileManager files.FileManager,
-	lspService lsp.Service,
-	languageDetector lsp.LanguageDetector,
-	randomString func(int) string,
-) Manager {
+func NewProjectManager(opts ManagerOptions) Manager {
 	return ManagerImpl{
-		devContainerRunner: devContainerRunner,
-		store:              projectStore,
-		projectsRoot:       projectsRoot,
-		fileManager:        fileManager,
-		lspService:         lspService,
-		languageDetector:   languageDetector,
-		randomString:       randomString,
+		devContainerRunner: opts.DevContainerRunner,
+		store:              opts.Store,
+		projectsRoot:       opts.ProjectsRoot,
+		fileManager:        opts.FileManager,
+		lspService:         opts.LspService,
+		debugService:       opts.DebugService,
+		languageDetector:   opts.LanguageDetector,
+		fileWatcher:        opts.FileWatcher,
+		randomString:       opts.RandomString,
+		diagnosticsTimeout: opts.DiagnosticsTimeout,
+		languageThreshold:  opts.LanguageThreshold,
 	}
 }
 
@@ -130,6 +197,13 @@ func (pm ManagerImpl) CreateProject(ctx context.Context, request CreateProjectRe
 			devContainerConfig = config
 		}
 
+		if err := pm.validateLanguages(model.Project{Id: projectId, Path: projectPath, Config: model.Config{DevContainerConfig: devContainerConfig}}, request.Languages); err != nil {
+			log.Error().Err(err).Str("projectId", projectId).Msg("Invalid project languages")
+			removeProjectDir(projectPath)
+			c <- result.Failure[model.Project](err)
+			return
+		}
+
 		containerId, err := pm.devContainerRunner.Run(ctx, projectPath, devContainerConfig)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to launch devcontainer")
@@ -165,6 +239,10 @@ func (pm ManagerImpl) CreateProject(ctx context.Context, request CreateProjectRe
 			return
 		}
 
+		if err := pm.watchFiles(project); err != nil {
+			log.Warn().Err(err).Str("projectId", projectId).Msg("Failed to watch project files. File events will not be available.")
+		}
+
 		log.Debug().Msgf("Created project %s for repo %s", projectId, request.Repository.Url)
 
 		c <- result.Success(project)
@@ -202,11 +280,20 @@ func (pm ManagerImpl) DeleteProject(ctx context.Context, projectId string) error
 		return fmt.Errorf("Failed to get project with id %s: %w", projectId, err)
 	}
 
+	// debuggees are terminated while the container still runs
+	if err := pm.debugService.CleanupProject(ctx, projectId); err != nil {
+		log.Warn().Err(err).Str("projectId", projectId).Msg("Failed to terminate debug session(s)")
+	}
+
 	if err := pm.devContainerRunner.Stop(ctx, project.ContainerId); err != nil {
 		log.Error().Err(err).Msgf("Failed to stop container %s", project.ContainerId)
 		return fmt.Errorf("Failed to stop container: %w", err)
 	}
 
+	if err := pm.fileWatcher.Unwatch(projectId); err != nil {
+		log.Warn().Err(err).Str("projectId", projectId).Msg("Failed to stop watching project files")
+	}
+
 	if err := pm.lspService.CleanupProject(ctx, projectId); err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to stop LSP server(s)")
 		return fmt.Errorf("Failed to stop LSP server(s): %w", err)
@@ -301,9 +388,14 @@ func (pm ManagerImpl) Cleanup(ctx context.Context) error {
 	return nil
 }
 
-func (pm ManagerImpl) CreateFile(ctx context.Context, projectId, path, content string) (*model.File, error) {
+func (pm ManagerImpl) CreateFile(ctx context.Context, projectId, path, content string, opts ...WriteFileOption) (*model.File, error) {
 	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Creating file")
 
+	options := WriteFileOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	project, err := pm.GetProject(ctx, projectId)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
@@ -312,13 +404,18 @@ func (pm ManagerImpl) CreateFile(ctx context.Context, projectId, path, content s
 
 	ctx = model.NewContextWithProject(ctx, &project)
 
-	file, err := pm.fileManager.CreateFile(ctx, afero.NewBasePathFs(afero.NewOsFs(), project.Path), path, content)
+	fs := files.NewProjectFs(project.Path)
+	file, err := pm.fileManager.CreateFile(ctx, fs, path, content)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to create file")
 		return file, err
 	}
 
-	if diagnostics, err := pm.getDiagnostics(ctx, *file, MaxDiagnosticsDelay); err != nil {
+	if options.Format {
+		file = pm.formatWrittenFile(ctx, fs, file)
+	}
+
+	if diagnostics, err := pm.getDiagnostics(ctx, *file); err != nil {
 		log.Warn().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to get diagnostics")
 	} else {
 		file.Diagnostics = diagnostics
@@ -327,9 +424,14 @@ func (pm ManagerImpl) CreateFile(ctx context.Context, projectId, path, content s
 	return file, nil
 }
 
-func (pm ManagerImpl) ReadFile(ctx context.Context, projectId, path string) (*model.File, error) {
+func (pm ManagerImpl) ReadFile(ctx context.Context, projectId, path string, opts ...ReadFileOption) (*model.File, error) {
 	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Reading file")
 
+	options := ReadFileOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	project, err := pm.GetProject(ctx, projectId)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
@@ -337,13 +439,17 @@ func (pm ManagerImpl) ReadFile(ctx context.Context, projectId, path string) (*mo
 	}
 
 	ctx = model.NewContextWithProject(ctx, &project)
-	file, err := pm.fileManager.ReadFile(ctx, afero.NewBasePathFs(afero.NewOsFs(), project.Path), path)
+	file, err := pm.fileManager.ReadFile(ctx, files.NewProjectFs(project.Path), path)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to read file")
 		return file, err
 	}
 
-	if diagnostics, err := pm.getDiagnostics(ctx, *file, MaxDiagnosticsDelay); err != nil {
+	if options.SkipDiagnostics {
+		return file, nil
+	}
+
+	if diagnostics, err := pm.getDiagnostics(ctx, *file); err != nil {
 		log.Warn().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to get diagnostics")
 	} else {
 		file.Diagnostics = diagnostics
@@ -352,9 +458,14 @@ func (pm ManagerImpl) ReadFile(ctx context.Context, projectId, path string) (*mo
 	return file, nil
 }
 
-func (pm ManagerImpl) UpdateFile(ctx context.Context, projectId, path, content string) (*model.File, error) {
+func (pm ManagerImpl) UpdateFile(ctx context.Context, projectId, path, content string, opts ...WriteFileOption) (*model.File, error) {
 	log.Debug().Msgf("Updating file %s in project %s", path, projectId)
 
+	options := WriteFileOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	project, err := pm.GetProject(ctx, projectId)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
@@ -363,13 +474,18 @@ func (pm ManagerImpl) UpdateFile(ctx context.Context, projectId, path, content s
 
 	ctx = model.NewContextWithProject(ctx, &project)
 
-	file, err := pm.fileManager.UpdateFile(ctx, afero.NewBasePathFs(afero.NewOsFs(), project.Path), path, content)
+	fs := files.NewProjectFs(project.Path)
+	file, err := pm.fileManager.UpdateFile(ctx, fs, path, content)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to update file")
 		return file, err
 	}
 
-	if diagnostics, err := pm.getDiagnostics(ctx, *file, MaxDiagnosticsDelay); err != nil {
+	if options.Format {
+		file = pm.formatWrittenFile(ctx, fs, file)
+	}
+
+	if diagnostics, err := pm.getDiagnostics(ctx, *file); err != nil {
 		log.Warn().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to get diagnostics")
 	} else {
 		file.Diagnostics = diagnostics
@@ -387,7 +503,7 @@ func (pm ManagerImpl) DeleteFile(ctx context.Context, projectId, path string) er
 		return fmt.Errorf("Failed to get project with id %s: %w", projectId, err)
 	}
 
-	return pm.fileManager.DeleteFile(model.NewContextWithProject(ctx, &project), afero.NewBasePathFs(afero.NewOsFs(), project.Path), path)
+	return pm.fileManager.DeleteFile(model.NewContextWithProject(ctx, &project), files.NewProjectFs(project.Path), path)
 }
 
 func (pm ManagerImpl) ListFiles(ctx context.Context, projectId string, opts ...files.ListFileOption) (model.Files, error) {
@@ -401,7 +517,7 @@ func (pm ManagerImpl) ListFiles(ctx context.Context, projectId string, opts ...f
 
 	ctx = model.NewContextWithProject(ctx, &project)
 
-	files, err := pm.fileManager.ListFiles(ctx, afero.NewBasePathFs(afero.NewOsFs(), project.Path), opts...)
+	files, err := pm.fileManager.ListFiles(ctx, files.NewProjectFs(project.Path), opts...)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Msgf("Failed to list files")
 		return nil, fmt.Errorf("Failed to list files in project %s: %w", projectId, err)
@@ -410,9 +526,14 @@ func (pm ManagerImpl) ListFiles(ctx context.Context, projectId string, opts ...f
 	return files, nil
 }
 
-func (pm ManagerImpl) ApplyPatch(ctx context.Context, projectId, path, patch string) (*model.File, error) {
+func (pm ManagerImpl) ApplyPatch(ctx context.Context, projectId, path, patch string, opts ...WriteFileOption) (*model.File, error) {
 	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Patching file")
 
+	options := WriteFileOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	project, err := pm.GetProject(ctx, projectId)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
@@ -420,13 +541,18 @@ func (pm ManagerImpl) ApplyPatch(ctx context.Context, projectId, path, patch str
 	}
 
 	ctx = model.NewContextWithProject(ctx, &project)
-	file, err := pm.fileManager.ApplyPatch(ctx, afero.NewBasePathFs(afero.NewOsFs(), project.Path), path, patch)
+	fs := files.NewProjectFs(project.Path)
+	file, err := pm.fileManager.ApplyPatch(ctx, fs, path, patch)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to patch file")
 		return nil, fmt.Errorf("Failed to patch file %s: %w", path, err)
 	}
 
-	if diagnostics, err := pm.getDiagnostics(ctx, *file, MaxDiagnosticsDelay); err != nil {
+	if options.Format {
+		file = pm.formatWrittenFile(ctx, fs, file)
+	}
+
+	if diagnostics, err := pm.getDiagnostics(ctx, *file); err != nil {
 		log.Warn().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to get diagnostics")
 	} else {
 		file.Diagnostics = diagnostics
@@ -435,9 +561,14 @@ func (pm ManagerImpl) ApplyPatch(ctx context.Context, projectId, path, patch str
 	return file, nil
 }
 
-func (pm ManagerImpl) UpdateLines(ctx context.Context, projectId, path string, lineDiff files.LineDiffChunk) (*model.File, error) {
+func (pm ManagerImpl) UpdateLines(ctx context.Context, projectId, path string, lineDiff files.LineDiffChunk, opts ...WriteFileOption) (*model.File, error) {
 	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Replacing lines in file")
 
+	options := WriteFileOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	project, err := pm.GetProject(ctx, projectId)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
@@ -445,13 +576,18 @@ func (pm ManagerImpl) UpdateLines(ctx context.Context, projectId, path string, l
 	}
 
 	ctx = model.NewContextWithProject(ctx, &project)
-	file, err := pm.fileManager.UpdateLines(ctx, afero.NewBasePathFs(afero.NewOsFs(), project.Path), path, lineDiff)
+	fs := files.NewProjectFs(project.Path)
+	file, err := pm.fileManager.UpdateLines(ctx, fs, path, lineDiff)
 	if err != nil {
 		log.Error().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to replace lines in file")
 		return nil, fmt.Errorf("Failed to replace lines in file %s: %w", path, err)
 	}
 
-	if diagnostics, err := pm.getDiagnostics(ctx, *file, MaxDiagnosticsDelay); err != nil {
+	if options.Format {
+		file = pm.formatWrittenFile(ctx, fs, file)
+	}
+
+	if diagnostics, err := pm.getDiagnostics(ctx, *file); err != nil {
 		log.Warn().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to get diagnostics")
 	} else {
 		file.Diagnostics = diagnostics
@@ -460,6 +596,44 @@ func (pm ManagerImpl) UpdateLines(ctx context.Context, projectId, path string, l
 	return file, nil
 }
 
+func (pm ManagerImpl) DownloadArchive(ctx context.Context, projectId model.ProjectId, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error {
+	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Downloading archive")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return fmt.Errorf("Failed to get project with id %s: %w", projectId, err)
+	}
+
+	ctx = model.NewContextWithProject(ctx, &project)
+	if err := pm.fileManager.WriteArchive(ctx, files.NewProjectFs(project.Path), w, path, format, opts...); err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to write archive")
+		return fmt.Errorf("Failed to write archive of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (pm ManagerImpl) UploadArchive(ctx context.Context, projectId model.ProjectId, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Uploading archive")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("Failed to get project with id %s: %w", projectId, err)
+	}
+
+	ctx = model.NewContextWithProject(ctx, &project)
+	result, err := pm.fileManager.ExtractArchive(ctx, files.NewProjectFs(project.Path), r, path, format, overwrite)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to extract archive")
+		return nil, fmt.Errorf("Failed to extract archive into %s: %w", path, err)
+	}
+
+	log.Debug().Str("projectId", projectId).Str("path", path).Msgf("Extracted %d files", len(result.Files))
+	return result, nil
+}
+
 func (pm ManagerImpl) SearchSymbols(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error) {
 	log.Debug().Str("projectId", projectId).Str("query", query).Msg("Searching symbols")
 
@@ -485,6 +659,559 @@ func (pm ManagerImpl) SearchSymbols(ctx context.Context, projectId model.Project
 	return symbols, nil
 }
 
+func (pm ManagerImpl) GetDiagnostics(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter, refresh bool) (*lsp.DiagnosticsReport, error) {
+	log.Debug().Str("projectId", projectId).Msg("Getting project diagnostics")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	report, err := pm.lspService.GetProjectDiagnostics(model.NewContextWithProject(ctx, &project), filter, refresh)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project diagnostics")
+		return nil, fmt.Errorf("Failed to get project diagnostics: %w", err)
+	}
+
+	return report, nil
+}
+
+func (pm ManagerImpl) SubscribeDiagnostics(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter) (<-chan lsp.FileDiagnostics, error) {
+	log.Debug().Str("projectId", projectId).Msg("Subscribing to diagnostics")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("Failed to get project with id %s: %w", projectId, err)
+	}
+
+	diagnostics, err := pm.lspService.SubscribeDiagnostics(model.NewContextWithProject(ctx, &project), filter)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to subscribe to diagnostics")
+		return nil, fmt.Errorf("Failed to subscribe to diagnostics: %w", err)
+	}
+
+	return diagnostics, nil
+}
+
+func (pm ManagerImpl) GetLanguageServers(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error) {
+	log.Debug().Str("projectId", projectId).Msg("Getting language servers")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	servers, err := pm.lspService.GetServerHealth(model.NewContextWithProject(ctx, &project))
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get language servers")
+		return nil, fmt.Errorf("Failed to get language servers: %w", err)
+	}
+
+	return servers, nil
+}
+
+func (pm ManagerImpl) StartLanguageServer(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+	log.Debug().Str("projectId", projectId).Str("languageId", languageId).Msg("Starting language server")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	// the server outlives the request
+	if err := pm.lspService.StartServer(model.NewContextWithProject(context.Background(), &project), languageId); err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("languageId", languageId).Msg("Failed to start language server")
+		return fmt.Errorf("Failed to start language server: %w", err)
+	}
+
+	return nil
+}
+
+func (pm ManagerImpl) StopLanguageServer(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+	log.Debug().Str("projectId", projectId).Str("languageId", languageId).Msg("Stopping language server")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	if err := pm.lspService.StopServer(model.NewContextWithProject(ctx, &project), languageId); err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("languageId", languageId).Msg("Failed to stop language server")
+		return fmt.Errorf("Failed to stop language server: %w", err)
+	}
+
+	return nil
+}
+
+func (pm ManagerImpl) ConnectLanguageServer(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId, stream jsonrpc2.ObjectStream) error {
+	log.Debug().Str("projectId", projectId).Str("languageId", languageId).Msg("Connecting client to language server")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	if err := pm.lspService.ServeClient(model.NewContextWithProject(ctx, &project), languageId, stream); err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("languageId", languageId).Msg("Failed to connect client to language server")
+		return fmt.Errorf("Failed to connect client to language server: %w", err)
+	}
+
+	return nil
+}
+
+func (pm ManagerImpl) LaunchDebugSession(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error) {
+	log.Debug().Str("projectId", projectId).Str("languageId", opts.Language).Str("program", opts.Program).Msg("Launching debug session")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	// the session outlives the request, the timeout only bounds the wait for the program to stop
+	session, err := pm.debugService.Launch(model.NewContextWithProject(context.Background(), &project), opts, timeout)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("languageId", opts.Language).Msg("Failed to launch debug session")
+		return nil, fmt.Errorf("Failed to launch debug session: %w", err)
+	}
+
+	return session, nil
+}
+
+func (pm ManagerImpl) GetDebugSession(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) (*dap.Session, error) {
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	session, err := pm.debugService.GetSession(model.NewContextWithProject(ctx, &project), sessionId)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get debug session: %w", err)
+	}
+
+	return session, nil
+}
+
+func (pm ManagerImpl) ListDebugSessions(ctx context.Context, projectId model.ProjectId) ([]dap.Session, error) {
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	return pm.debugService.ListSessions(model.NewContextWithProject(ctx, &project)), nil
+}
+
+func (pm ManagerImpl) SetBreakpoints(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, path string, breakpoints []dap.SourceBreakpoint) ([]dap.Breakpoint, error) {
+	log.Debug().Str("projectId", projectId).Str("sessionId", sessionId).Str("path", path).Msg("Setting breakpoints")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	result, err := pm.debugService.SetBreakpoints(model.NewContextWithProject(ctx, &project), sessionId, path, breakpoints)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("sessionId", sessionId).Msg("Failed to set breakpoints")
+		return nil, fmt.Errorf("Failed to set breakpoints: %w", err)
+	}
+
+	return result, nil
+}
+
+func (pm ManagerImpl) StepDebugSession(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, action dap.StepAction, threadId int, timeout time.Duration) (*dap.Session, error) {
+	log.Debug().Str("projectId", projectId).Str("sessionId", sessionId).Str("action", string(action)).Msg("Stepping debug session")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	session, err := pm.debugService.Step(model.NewContextWithProject(ctx, &project), sessionId, action, threadId, timeout)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("sessionId", sessionId).Msg("Failed to step debug session")
+		return nil, fmt.Errorf("Failed to %s: %w", action, err)
+	}
+
+	return session, nil
+}
+
+func (pm ManagerImpl) GetDebugThreads(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) ([]dap.Thread, error) {
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	threads, err := pm.debugService.GetThreads(model.NewContextWithProject(ctx, &project), sessionId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("sessionId", sessionId).Msg("Failed to get threads")
+		return nil, fmt.Errorf("Failed to get threads: %w", err)
+	}
+
+	return threads, nil
+}
+
+func (pm ManagerImpl) GetDebugStackTrace(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, threadId int, levels int) ([]dap.StackFrame, error) {
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	frames, err := pm.debugService.GetStackTrace(model.NewContextWithProject(ctx, &project), sessionId, threadId, levels)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("sessionId", sessionId).Msg("Failed to get stack trace")
+		return nil, fmt.Errorf("Failed to get stack trace: %w", err)
+	}
+
+	return frames, nil
+}
+
+func (pm ManagerImpl) GetDebugScopes(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, frameId int) ([]dap.Scope, error) {
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	scopes, err := pm.debugService.GetScopes(model.NewContextWithProject(ctx, &project), sessionId, frameId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("sessionId", sessionId).Msg("Failed to get scopes")
+		return nil, fmt.Errorf("Failed to get scopes: %w", err)
+	}
+
+	return scopes, nil
+}
+
+func (pm ManagerImpl) GetDebugVariables(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, variablesReference int) ([]dap.Variable, error) {
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	variables, err := pm.debugService.GetVariables(model.NewContextWithProject(ctx, &project), sessionId, variablesReference)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("sessionId", sessionId).Msg("Failed to get variables")
+		return nil, fmt.Errorf("Failed to get variables: %w", err)
+	}
+
+	return variables, nil
+}
+
+func (pm ManagerImpl) TerminateDebugSession(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) error {
+	log.Debug().Str("projectId", projectId).Str("sessionId", sessionId).Msg("Terminating debug session")
+
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return fmt.Errorf("failed to get project with id %s: %w", projectId, err)
+	}
+
+	if err := pm.debugService.Terminate(model.NewContextWithProject(ctx, &project), sessionId); err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("sessionId", sessionId).Msg("Failed to terminate debug session")
+		return fmt.Errorf("Failed to terminate debug session: %w", err)
+	}
+
+	return nil
+}
+
+func (pm ManagerImpl) FindDefinition(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Str("kind", string(kind)).Msg("Finding definition")
+
+	return pm.findLocations(ctx, projectId, path, func(ctx context.Context, file model.File) ([]lsp.Location, error) {
+		switch kind {
+		case lsp.DefinitionKindType:
+			return pm.lspService.GetTypeDefinition(ctx, file, position)
+		case lsp.DefinitionKindImplementation:
+			return pm.lspService.GetImplementation(ctx, file, position)
+		default:
+			return pm.lspService.GetDefinition(ctx, file, position)
+		}
+	})
+}
+
+func (pm ManagerImpl) FindReferences(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, includeDeclaration bool) ([]lsp.Location, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Finding references")
+
+	return pm.findLocations(ctx, projectId, path, func(ctx context.Context, file model.File) ([]lsp.Location, error) {
+		return pm.lspService.GetReferences(ctx, file, position, includeDeclaration)
+	})
+}
+
+func (pm ManagerImpl) GetOutline(ctx context.Context, projectId model.ProjectId, path string) ([]lsp.DocumentSymbol, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Getting outline")
+
+	var symbols []lsp.DocumentSymbol
+	err := pm.withOpenFile(ctx, projectId, path, func(ctx context.Context, fs afero.Fs, file model.File) error {
+		var err error
+		symbols, err = pm.lspService.GetDocumentSymbols(ctx, file)
+		return err
+	})
+
+	return symbols, err
+}
+
+func (pm ManagerImpl) Hover(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position) (*lsp.HoverInfo, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Getting hover")
+
+	var hover *lsp.HoverInfo
+	err := pm.withOpenFile(ctx, projectId, path, func(ctx context.Context, fs afero.Fs, file model.File) error {
+		var err error
+		hover, err = pm.lspService.GetHover(ctx, file, position)
+		return err
+	})
+
+	return hover, err
+}
+
+func (pm ManagerImpl) Complete(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, limit int) (*lsp.CompletionList, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Getting completions")
+
+	var completions *lsp.CompletionList
+	err := pm.withOpenFile(ctx, projectId, path, func(ctx context.Context, fs afero.Fs, file model.File) error {
+		var err error
+		completions, err = pm.lspService.GetCompletions(ctx, file, position, limit)
+		return err
+	})
+
+	return completions, err
+}
+
+func (pm ManagerImpl) GetCallHierarchy(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.CallHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Str("direction", string(direction)).Int("depth", depth).Msg("Getting call hierarchy")
+
+	var items []lsp.HierarchyItem
+	err := pm.withOpenFile(ctx, projectId, path, func(ctx context.Context, fs afero.Fs, file model.File) error {
+		var err error
+		items, err = pm.lspService.GetCallHierarchy(ctx, file, position, direction, depth)
+		return err
+	})
+
+	return items, err
+}
+
+func (pm ManagerImpl) GetTypeHierarchy(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.TypeHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Str("direction", string(direction)).Int("depth", depth).Msg("Getting type hierarchy")
+
+	var items []lsp.HierarchyItem
+	err := pm.withOpenFile(ctx, projectId, path, func(ctx context.Context, fs afero.Fs, file model.File) error {
+		var err error
+		items, err = pm.lspService.GetTypeHierarchy(ctx, file, position, direction, depth)
+		return err
+	})
+
+	return items, err
+}
+
+func (pm ManagerImpl) Rename(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, newName string, dryRun bool) (*WorkspaceEditResult, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Str("newName", newName).Bool("dryRun", dryRun).Msg("Renaming symbol")
+
+	return pm.applyWorkspaceEdit(ctx, projectId, path, dryRun, func(ctx context.Context, file model.File) ([]files.FileChange, error) {
+		return pm.lspService.Rename(ctx, file, position, newName)
+	})
+}
+
+func (pm ManagerImpl) GetCodeActions(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range) ([]lsp.CodeAction, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Msg("Getting code actions")
+
+	var actions []lsp.CodeAction
+	err := pm.withOpenFile(ctx, projectId, path, func(ctx context.Context, fs afero.Fs, file model.File) error {
+		var err error
+		actions, err = pm.lspService.GetCodeActions(ctx, file, rng)
+		return err
+	})
+
+	return actions, err
+}
+
+func (pm ManagerImpl) ApplyCodeAction(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range, title string, dryRun bool) (*WorkspaceEditResult, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Str("title", title).Bool("dryRun", dryRun).Msg("Applying code action")
+
+	return pm.applyWorkspaceEdit(ctx, projectId, path, dryRun, func(ctx context.Context, file model.File) ([]files.FileChange, error) {
+		return pm.lspService.GetCodeActionChanges(ctx, file, rng, title, dryRun)
+	})
+}
+
+func (pm ManagerImpl) FormatFile(ctx context.Context, projectId model.ProjectId, path string, opts lsp.FormatOptions, dryRun bool) (*WorkspaceEditResult, error) {
+	log.Debug().Str("projectId", projectId).Str("path", path).Bool("organizeImports", opts.OrganizeImports).Bool("dryRun", dryRun).Msg("Formatting file")
+
+	opts.DryRun = dryRun
+	return pm.applyWorkspaceEdit(ctx, projectId, path, dryRun, func(ctx context.Context, file model.File) ([]files.FileChange, error) {
+		return pm.lspService.Format(ctx, file, opts)
+	})
+}
+
+// formatWrittenFile formats a file that was just written. Formatting is best effort, the file is returned as it was written if it fails.
+func (pm ManagerImpl) formatWrittenFile(ctx context.Context, fs afero.Fs, file *model.File) *model.File {
+	project, _ := model.ProjectFromContext(ctx)
+
+	if err := pm.lspService.NotifyDidOpen(ctx, *file); err != nil {
+		log.Warn().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to open file for formatting")
+		return file
+	}
+
+	changes, err := pm.lspService.Format(ctx, *file, lsp.FormatOptions{})
+	if err != nil {
+		log.Warn().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to format file")
+		return file
+	}
+
+	if len(changes) == 0 {
+		return file
+	}
+
+	if _, err := pm.fileManager.ApplyWorkspaceEdit(ctx, fs, changes, false); err != nil {
+		log.Warn().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to apply formatting")
+		return file
+	}
+
+	formatted, err := pm.fileManager.ReadFile(ctx, fs, file.Path)
+	if err != nil {
+		log.Warn().Err(err).Str("projectId", project.Id).Str("path", file.Path).Msg("Failed to read formatted file")
+		return file
+	}
+
+	return formatted
+}
+
+// applyWorkspaceEdit applies the changes that the language server computes for the file, and gets the diagnostics of the changed files.
+func (pm ManagerImpl) applyWorkspaceEdit(ctx context.Context, projectId model.ProjectId, path string, dryRun bool, getChanges func(ctx context.Context, file model.File) ([]files.FileChange, error)) (*WorkspaceEditResult, error) {
+	result := &WorkspaceEditResult{}
+	var projectCtx context.Context
+	var fs afero.Fs
+
+	err := pm.withOpenFile(ctx, projectId, path, func(ctx context.Context, fileSystem afero.Fs, file model.File) error {
+		changes, err := getChanges(ctx, file)
+		if err != nil {
+			return err
+		}
+
+		if result.Files, err = pm.fileManager.ApplyWorkspaceEdit(ctx, fileSystem, changes, dryRun); err != nil {
+			log.Error().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to apply workspace edit")
+			return fmt.Errorf("Failed to apply workspace edit: %w", err)
+		}
+
+		projectCtx, fs = ctx, fileSystem
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().Str("projectId", projectId).Str("path", path).Msgf("Workspace edit changes %d files", len(result.Files))
+
+	if dryRun {
+		return result, nil
+	}
+
+	// diagnostics are collected after the file is closed, because the changed files are opened again
+	var changed []model.File
+	for _, diff := range result.Files {
+		if diff.Deleted {
+			continue
+		}
+
+		file, err := pm.fileManager.ReadFile(projectCtx, fs, diff.Path)
+		if err != nil {
+			log.Warn().Err(err).Str("projectId", projectId).Str("path", diff.Path).Msg("Failed to read changed file")
+			continue
+		}
+		changed = append(changed, *file)
+	}
+
+	result.Diagnostics = pm.getFilesDiagnostics(projectCtx, changed)
+	return result, nil
+}
+
+// findLocations runs the query with the file opened in its language server and adds snippets of the target lines to the result.
+func (pm ManagerImpl) findLocations(ctx context.Context, projectId model.ProjectId, path string, query func(ctx context.Context, file model.File) ([]lsp.Location, error)) ([]lsp.Location, error) {
+	var locations []lsp.Location
+
+	err := pm.withOpenFile(ctx, projectId, path, func(ctx context.Context, fs afero.Fs, file model.File) error {
+		var err error
+		if locations, err = query(ctx, file); err != nil {
+			return err
+		}
+
+		snippets := make(map[string]*model.File)
+		for i, location := range locations {
+			// locations outside of the project, e.g. in dependencies, can't be read
+			if filepath.IsAbs(location.Path) {
+				continue
+			}
+
+			target, ok := snippets[location.Path]
+			if !ok {
+				if target, err = pm.fileManager.ReadFile(ctx, fs, location.Path); err != nil {
+					log.Warn().Err(err).Str("projectId", projectId).Str("path", location.Path).Msg("Failed to read location snippet")
+				}
+				snippets[location.Path] = target
+			}
+
+			if target != nil && location.Range.Start.Line <= len(target.Lines) {
+				locations[i].Snippet = target.GetLineRange(location.Range.Start.Line, location.Range.End.Line+1)
+			}
+		}
+
+		return nil
+	})
+
+	return locations, err
+}
+
+// withOpenFile reads the file and runs fn after the file is synced with its language server.
+func (pm ManagerImpl) withOpenFile(ctx context.Context, projectId model.ProjectId, path string, fn func(ctx context.Context, fs afero.Fs, file model.File) error) error {
+	project, err := pm.GetProject(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return fmt.Errorf("Failed to get project with id %s: %w", projectId, err)
+	}
+
+	ctx = model.NewContextWithProject(ctx, &project)
+	fs := files.NewProjectFs(project.Path)
+
+	file, err := pm.fileManager.ReadFile(ctx, fs, path)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Str("path", path).Msg("Failed to read file")
+		return err
+	}
+
+	// the file stays open, so that the language server keeps its state for the next requests, the least recently used files are closed
+	if err := pm.lspService.NotifyDidOpen(ctx, *file); err != nil {
+		return fmt.Errorf("Failed to notify didOpen for file %s: %w", path, err)
+	}
+
+	return fn(ctx, fs, *file)
+}
+
+func (pm ManagerImpl) SubscribeFileEvents(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error) {
+	log.Debug().Str("projectId", projectId).Msg("Subscribing to file events")
+
+	if _, err := pm.GetProject(ctx, projectId); err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to get project")
+		return nil, fmt.Errorf("Failed to get project with id %s: %w", projectId, err)
+	}
+
+	events, err := pm.fileWatcher.Subscribe(ctx, projectId)
+	if err != nil {
+		log.Error().Err(err).Str("projectId", projectId).Msg("Failed to subscribe to file events")
+		return nil, fmt.Errorf("Failed to subscribe to file events: %w", err)
+	}
+
+	return events, nil
+}
+
 func (pm ManagerImpl) createProjectDir(path string) error {
 	if err := os.MkdirAll(path, 0o755); err != nil {
 		return fmt.Errorf("Failed to create project directory: %w", err)
@@ -509,51 +1236,148 @@ func (pm ManagerImpl) configFromProject(fileSystem fs.FS) (devcontainer.Config,
 	return *config, nil
 }
 
-func (pm ManagerImpl) getDiagnostics(ctx context.Context, file model.File, waitFor time.Duration) ([]protocol.Diagnostic, error) {
-	if err := pm.lspService.NotifyDidOpen(ctx, file); err != nil {
+// getDiagnostics syncs the file with its language server and waits for the diagnostics of its content.
+func (pm ManagerImpl) getDiagnostics(ctx context.Context, file model.File) ([]protocol.Diagnostic, error) {
+	diagnostics, err := pm.lspService.WaitForDiagnostics(ctx, file, pm.diagnosticsTimeout)
+	if err != nil {
 		var lspLanguageServerNotFoundError *lsp.LanguageServerNotFoundError
 		if errors.As(err, &lspLanguageServerNotFoundError) {
 			return nil, nil
 		}
 
-		return nil, fmt.Errorf("Failed to notify didOpen while reading file %s: %w", file.Path, err)
+		return nil, fmt.Errorf("Failed to get diagnostics for file %s: %w", file.Path, err)
 	}
 
-	// wait for diagnostics
-	time.Sleep(waitFor)
+	return diagnostics, nil
+}
 
-	diagnostics, err := pm.lspService.GetDiagnostics(ctx, file)
-	if err != nil {
-		var lspLanguageServerNotFoundError *lsp.LanguageServerNotFoundError
-		if errors.As(err, &lspLanguageServerNotFoundError) {
-			return nil, nil
-		}
+// getFilesDiagnostics is like getDiagnostics for multiple files, it waits for the diagnostics of all files at once.
+// Files without diagnostics or a language server are left out.
+func (pm ManagerImpl) getFilesDiagnostics(ctx context.Context, files []model.File) map[string][]protocol.Diagnostic {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = make(map[string][]protocol.Diagnostic)
+	)
 
-		return nil, fmt.Errorf("Failed to get diagnostics for file %s: %w", file.Path, err)
+	for _, file := range files {
+		wg.Add(1)
+		go func(file model.File) {
+			defer wg.Done()
+
+			diagnostics, err := pm.getDiagnostics(ctx, file)
+			if err != nil {
+				log.Warn().Err(err).Str("path", file.Path).Msg("Failed to get diagnostics")
+				return
+			}
+
+			if len(diagnostics) > 0 {
+				mu.Lock()
+				result[file.Path] = diagnostics
+				mu.Unlock()
+			}
+		}(file)
 	}
 
-	if err := pm.lspService.NotifyDidClose(ctx, file); err != nil {
-		var lspLanguageServerNotFoundError *lsp.LanguageServerNotFoundError
-		if errors.As(err, &lspLanguageServerNotFoundError) {
-			return nil, nil
-		}
+	wg.Wait()
 
-		return nil, fmt.Errorf("Failed to notify didClose while reading file %s: %w", file.Path, err)
+	if len(result) == 0 {
+		return nil
 	}
 
-	return diagnostics, nil
+	return result
 }
 
+// validateLanguages checks that the languages have a language server in the project.
+func (pm ManagerImpl) validateLanguages(project model.Project, languages []lsp.LanguageId) error {
+	if len(languages) == 0 {
+		return nil
+	}
+
+	supported := pm.lspService.GetSupportedLanguages(model.NewContextWithProject(context.Background(), &project))
+	for _, language := range languages {
+		if !slices.Contains(supported, language) {
+			return lsp.NewLanguageNotSupportedError(language)
+		}
+	}
+
+	return nil
+}
+
+// detectLanguages returns the languages of the project with a language server that make up at least the language threshold of its code.
 func (pm ManagerImpl) detectLanguages(project model.Project) ([]lsp.LanguageId, error) {
-	files, err := pm.fileManager.ListFiles(model.NewContextWithProject(context.Background(), &project), afero.NewBasePathFs(afero.NewOsFs(), project.Path), files.ListFilesWithContent())
+	files, err := pm.fileManager.ListFiles(model.NewContextWithProject(context.Background(), &project), files.NewProjectFs(project.Path), files.ListFilesWithContent())
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
 
-	// TODO: handle multiple main language
-	language := pm.languageDetector.DetectMainLanguage(files)
-	log.Debug().Msgf("Detected main language %s for project %s", language, project.Id)
-	return []lsp.LanguageId{language}, nil
+	detected := pm.languageDetector.DetectMainLanguages(files, pm.languageThreshold)
+	if len(detected) == 0 {
+		// the main language is used even if no language reaches the threshold
+		detected = []lsp.LanguageId{pm.languageDetector.DetectMainLanguage(files)}
+	}
+
+	supported := pm.lspService.GetSupportedLanguages(model.NewContextWithProject(context.Background(), &project))
+	languages := make([]lsp.LanguageId, 0, len(detected))
+	for _, language := range detected {
+		if !slices.Contains(supported, language) {
+			log.Debug().Str("projectId", project.Id).Msgf("No language server for language %s", language)
+			continue
+		}
+		languages = append(languages, language)
+	}
+
+	log.Debug().Str("projectId", project.Id).Msgf("Detected languages %v", languages)
+	return languages, nil
+}
+
+// watchFiles starts watching the project directory and forwards file events to the language servers.
+func (pm ManagerImpl) watchFiles(project model.Project) error {
+	if err := pm.fileWatcher.Watch(project.Id, project.Path); err != nil {
+		return fmt.Errorf("failed to watch project directory: %w", err)
+	}
+
+	// the subscription ends when the project is unwatched
+	events, err := pm.fileWatcher.Subscribe(context.Background(), project.Id)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to file events: %w", err)
+	}
+
+	go func() {
+		ctx := model.NewContextWithProject(context.Background(), &project)
+		fs := files.NewProjectFs(project.Path)
+		for batch := range events {
+			if err := pm.lspService.NotifyDidChangeWatchedFiles(ctx, batch); err != nil {
+				log.Warn().Err(err).Str("projectId", project.Id).Msg("Failed to forward file events to language servers")
+			}
+
+			pm.syncModifiedFiles(ctx, fs, batch)
+		}
+	}()
+
+	return nil
+}
+
+// syncModifiedFiles sends the content of modified files that are open in a language server, so that changes made outside of Hide are seen too.
+func (pm ManagerImpl) syncModifiedFiles(ctx context.Context, fs afero.Fs, events []model.FileEvent) {
+	for _, event := range events {
+		if event.Type != model.FileModified {
+			continue
+		}
+
+		file, err := pm.fileManager.ReadFile(ctx, fs, event.Path)
+		if err != nil {
+			log.Debug().Err(err).Str("path", event.Path).Msg("Failed to read modified file")
+			continue
+		}
+
+		if err := pm.lspService.NotifyDidChange(ctx, *file); err != nil {
+			var lspLanguageServerNotFoundError *lsp.LanguageServerNotFoundError
+			if !errors.As(err, &lspLanguageServerNotFoundError) {
+				log.Warn().Err(err).Str("path", event.Path).Msg("Failed to notify didChange")
+			}
+		}
+	}
 }
 
 func removeProjectDir(projectPath string) {
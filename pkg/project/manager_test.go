@@ -3,17 +3,24 @@ package project_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/hide-org/hide/pkg/devcontainer"
 	dc_mocks "github.com/hide-org/hide/pkg/devcontainer/mocks"
+	"github.com/hide-org/hide/pkg/files"
+	"github.com/hide-org/hide/pkg/gitignore"
 	"github.com/hide-org/hide/pkg/lsp"
 	lsp_mocks "github.com/hide-org/hide/pkg/lsp/mocks"
 	"github.com/hide-org/hide/pkg/model"
 	"github.com/hide-org/hide/pkg/project"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
 func TestProject_findTaskByAlias(t *testing.T) {
@@ -74,7 +81,7 @@ func TestManagerImpl_CreateProject(t *testing.T) {
 
 func TestManagerImpl_GetProject_Succeeds(t *testing.T) {
 	_project := model.Project{Id: "test-project", Path: "/tmp/test-project", Config: model.Config{}}
-	pm := project.NewProjectManager(nil, project.NewInMemoryStore(map[string]*model.Project{"test-project": &_project}), "/tmp", nil, nil, nil, nil)
+	pm := project.NewProjectManager(project.ManagerOptions{Store: project.NewInMemoryStore(map[string]*model.Project{"test-project": &_project}), ProjectsRoot: "/tmp"})
 	project, err := pm.GetProject(context.Background(), "test-project")
 
 	if err != nil {
@@ -87,7 +94,7 @@ func TestManagerImpl_GetProject_Succeeds(t *testing.T) {
 }
 
 func TestManagerImpl_GetProject_Fails(t *testing.T) {
-	pm := project.NewProjectManager(nil, project.NewInMemoryStore(map[string]*model.Project{}), "/tmp", nil, nil, nil, nil)
+	pm := project.NewProjectManager(project.ManagerOptions{Store: project.NewInMemoryStore(map[string]*model.Project{}), ProjectsRoot: "/tmp"})
 	_, err := pm.GetProject(context.Background(), "missing-project")
 
 	if err == nil {
@@ -112,7 +119,7 @@ func TestManagerImpl_ResolveTaskAlias_Succeeds(t *testing.T) {
 			},
 		},
 	}
-	pm := project.NewProjectManager(nil, project.NewInMemoryStore(map[string]*model.Project{"test-project": &_project}), "/tmp", nil, nil, nil, nil)
+	pm := project.NewProjectManager(project.ManagerOptions{Store: project.NewInMemoryStore(map[string]*model.Project{"test-project": &_project}), ProjectsRoot: "/tmp"})
 	resolvedTask, err := pm.ResolveTaskAlias(context.Background(), "test-project", "test-alias")
 
 	if err != nil {
@@ -125,7 +132,7 @@ func TestManagerImpl_ResolveTaskAlias_Succeeds(t *testing.T) {
 }
 
 func TestManagerImpl_ResolveTaskAlias_ProjectNotFound(t *testing.T) {
-	pm := project.NewProjectManager(nil, project.NewInMemoryStore(map[string]*model.Project{}), "/tmp", nil, nil, nil, nil)
+	pm := project.NewProjectManager(project.ManagerOptions{Store: project.NewInMemoryStore(map[string]*model.Project{}), ProjectsRoot: "/tmp"})
 	_, err := pm.ResolveTaskAlias(context.Background(), "missing-project", "test-alias")
 
 	if err == nil {
@@ -135,7 +142,7 @@ func TestManagerImpl_ResolveTaskAlias_ProjectNotFound(t *testing.T) {
 
 func TestManagerImpl_ResolveTaskAlias_TaskNotFound(t *testing.T) {
 	_project := model.Project{Id: "test-project", Path: "/tmp/test-project", Config: model.Config{}}
-	pm := project.NewProjectManager(nil, project.NewInMemoryStore(map[string]*model.Project{"test-project": &_project}), "/tmp", nil, nil, nil, nil)
+	pm := project.NewProjectManager(project.ManagerOptions{Store: project.NewInMemoryStore(map[string]*model.Project{"test-project": &_project}), ProjectsRoot: "/tmp"})
 	_, err := pm.ResolveTaskAlias(context.Background(), "test-project", "missing-alias")
 
 	if err == nil {
@@ -150,7 +157,7 @@ func TestManagerImpl_CreateTask(t *testing.T) {
 		ExecFunc: func(ctx context.Context, containerId string, command []string) (devcontainer.ExecResult, error) {
 			return devcontainer.ExecResult{StdOut: "test-stdout", StdErr: "test-stderr", ExitCode: 1}, nil
 		}}
-	pm := project.NewProjectManager(devContainerRunner, project.NewInMemoryStore(map[string]*model.Project{projectId: &_project}), "/tmp", nil, nil, nil, nil)
+	pm := project.NewProjectManager(project.ManagerOptions{DevContainerRunner: devContainerRunner, Store: project.NewInMemoryStore(map[string]*model.Project{projectId: &_project}), ProjectsRoot: "/tmp"})
 
 	taskResult, err := pm.CreateTask(context.Background(), projectId, "echo test")
 
@@ -166,7 +173,7 @@ func TestManagerImpl_CreateTask(t *testing.T) {
 }
 
 func TestManagerImpl_CreateTask_ProjectNotFound(t *testing.T) {
-	pm := project.NewProjectManager(nil, project.NewInMemoryStore(map[string]*model.Project{}), "/tmp", nil, nil, nil, nil)
+	pm := project.NewProjectManager(project.ManagerOptions{Store: project.NewInMemoryStore(map[string]*model.Project{}), ProjectsRoot: "/tmp"})
 	_, err := pm.CreateTask(context.Background(), "missing-project", "echo test")
 
 	if err == nil {
@@ -182,7 +189,7 @@ func TestManagerImpl_CreateTask_ExecError(t *testing.T) {
 			return devcontainer.ExecResult{}, errors.New("exec error")
 		},
 	}
-	pm := project.NewProjectManager(devContainerRunner, project.NewInMemoryStore(map[string]*model.Project{projectId: &_project}), "/tmp", nil, nil, nil, nil)
+	pm := project.NewProjectManager(project.ManagerOptions{DevContainerRunner: devContainerRunner, Store: project.NewInMemoryStore(map[string]*model.Project{projectId: &_project}), ProjectsRoot: "/tmp"})
 
 	_, err := pm.CreateTask(context.Background(), projectId, "echo test")
 
@@ -265,7 +272,7 @@ func TestManagerImpl_SearchSymbols(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			lspService := &lsp_mocks.MockLspService{}
 			tt.mockSetup(lspService)
-			pm := project.NewProjectManager(nil, project.NewInMemoryStore(tt.store), "/tmp", nil, lspService, nil, nil)
+			pm := project.NewProjectManager(project.ManagerOptions{Store: project.NewInMemoryStore(tt.store), ProjectsRoot: "/tmp", LspService: lspService})
 
 			symbols, err := pm.SearchSymbols(tt.context, tt.projectId, tt.query, tt.symbolFilter)
 
@@ -280,3 +287,251 @@ func TestManagerImpl_SearchSymbols(t *testing.T) {
 		})
 	}
 }
+
+func TestManagerImpl_ReadFile(t *testing.T) {
+	diagnostic := protocol.Diagnostic{Message: "undefined: foo"}
+
+	tests := []struct {
+		name            string
+		opts            []project.ReadFileOption
+		wantDiagnostics []protocol.Diagnostic
+	}{
+		{
+			name:            "with diagnostics",
+			wantDiagnostics: []protocol.Diagnostic{diagnostic},
+		},
+		{
+			name: "without diagnostics",
+			opts: []project.ReadFileOption{project.ReadFileWithoutDiagnostics()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {\n\tfoo()\n}"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			lspService := &lsp_mocks.MockLspService{}
+			if tt.wantDiagnostics != nil {
+				lspService.On("WaitForDiagnostics", mock.Anything, mock.MatchedBy(func(file model.File) bool { return file.Path == "main.go" }), time.Second).Return(tt.wantDiagnostics, nil)
+			}
+
+			store := project.NewInMemoryStore(map[string]*model.Project{"project-id": {Id: "project-id", Path: root}})
+			pm := project.NewProjectManager(project.ManagerOptions{Store: store, ProjectsRoot: "/tmp", FileManager: files.NewFileManager(gitignore.NewMatcherFactory(), nil), LspService: lspService, DiagnosticsTimeout: time.Second})
+
+			file, err := pm.ReadFile(context.Background(), "project-id", "main.go", tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.wantDiagnostics, file.Diagnostics)
+			lspService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestManagerImpl_FindReferences(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {\n\tfoo()\n}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "lib.go"), []byte("package main\n\nfunc foo() {}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lspService := &lsp_mocks.MockLspService{}
+	lspService.On("NotifyDidOpen", mock.Anything, mock.Anything).Return(nil)
+	lspService.On("GetReferences", mock.Anything, mock.MatchedBy(func(file model.File) bool { return file.Path == "lib.go" }), lsp.Position{Line: 3, Character: 5}, true).Return([]lsp.Location{
+		{Path: "main.go", Range: lsp.Range{Start: lsp.Position{Line: 4, Character: 1}, End: lsp.Position{Line: 4, Character: 4}}},
+		{Path: "/usr/local/go/src/builtin/builtin.go", Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 0}, End: lsp.Position{Line: 1, Character: 4}}},
+	}, nil)
+
+	store := project.NewInMemoryStore(map[string]*model.Project{"project-id": {Id: "project-id", Path: root}})
+	pm := project.NewProjectManager(project.ManagerOptions{Store: store, ProjectsRoot: "/tmp", FileManager: files.NewFileManager(gitignore.NewMatcherFactory(), nil), LspService: lspService})
+
+	locations, err := pm.FindReferences(context.Background(), "project-id", "lib.go", lsp.Position{Line: 3, Character: 5}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []lsp.Location{
+		{Path: "main.go", Range: lsp.Range{Start: lsp.Position{Line: 4, Character: 1}, End: lsp.Position{Line: 4, Character: 4}}, Snippet: []model.Line{{Number: 4, Content: "\tfoo()"}}},
+		{Path: "/usr/local/go/src/builtin/builtin.go", Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 0}, End: lsp.Position{Line: 1, Character: 4}}},
+	}, locations)
+	lspService.AssertExpectations(t)
+}
+
+func TestManagerImpl_Rename(t *testing.T) {
+	changes := []files.FileChange{
+		{Path: "lib.go", Edits: []files.TextEdit{{StartLine: 3, StartCharacter: 5, EndLine: 3, EndCharacter: 8, NewText: "bar"}}},
+		{Path: "main.go", Edits: []files.TextEdit{{StartLine: 4, StartCharacter: 1, EndLine: 4, EndCharacter: 4, NewText: "bar"}}},
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		t.Run(fmt.Sprintf("dryRun=%t", dryRun), func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {\n\tfoo()\n}\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(root, "lib.go"), []byte("package main\n\nfunc foo() {}\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			diagnostic := protocol.Diagnostic{Message: "bar declared and not used"}
+
+			lspService := &lsp_mocks.MockLspService{}
+			lspService.On("NotifyDidOpen", mock.Anything, mock.Anything).Return(nil)
+			lspService.On("Rename", mock.Anything, mock.MatchedBy(func(file model.File) bool { return file.Path == "lib.go" }), lsp.Position{Line: 3, Character: 5}, "bar").Return(changes, nil)
+			lspService.On("WaitForDiagnostics", mock.Anything, mock.MatchedBy(func(file model.File) bool { return file.Path == "lib.go" }), time.Second).Return([]protocol.Diagnostic{diagnostic}, nil)
+			lspService.On("WaitForDiagnostics", mock.Anything, mock.MatchedBy(func(file model.File) bool { return file.Path == "main.go" }), time.Second).Return([]protocol.Diagnostic{}, nil)
+
+			store := project.NewInMemoryStore(map[string]*model.Project{"project-id": {Id: "project-id", Path: root}})
+			pm := project.NewProjectManager(project.ManagerOptions{Store: store, ProjectsRoot: "/tmp", FileManager: files.NewFileManager(gitignore.NewMatcherFactory(), nil), LspService: lspService, DiagnosticsTimeout: time.Second})
+
+			result, err := pm.Rename(context.Background(), "project-id", "lib.go", lsp.Position{Line: 3, Character: 5}, "bar", dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, []files.FileDiff{
+				{Path: "lib.go", Diff: "--- lib.go\n+++ lib.go\n@@ -1,3 +1,3 @@\n package main\n \n-func foo() {}\n+func bar() {}\n"},
+				{Path: "main.go", Diff: "--- main.go\n+++ main.go\n@@ -1,5 +1,5 @@\n package main\n \n func main() {\n-\tfoo()\n+\tbar()\n }\n"},
+			}, result.Files)
+
+			content, err := os.ReadFile(filepath.Join(root, "main.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if dryRun {
+				assert.Nil(t, result.Diagnostics)
+				assert.Equal(t, "package main\n\nfunc main() {\n\tfoo()\n}\n", string(content))
+			} else {
+				assert.Equal(t, map[string][]protocol.Diagnostic{"lib.go": {diagnostic}}, result.Diagnostics)
+				assert.Equal(t, "package main\n\nfunc main() {\n\tbar()\n}\n", string(content))
+			}
+		})
+	}
+}
+
+func TestManagerImpl_UpdateFile_Format(t *testing.T) {
+	formatting := []files.FileChange{{Path: "main.go", Edits: []files.TextEdit{{StartLine: 4, EndLine: 4, NewText: "\t"}}}}
+
+	tests := []struct {
+		name        string
+		formatErr   error
+		wantContent string
+	}{
+		{
+			name:        "formatted",
+			wantContent: "package main\n\nfunc main() {\n\tfoo()\n}\n",
+		},
+		{
+			// the written file is kept if it can't be formatted
+			name:        "formatting fails",
+			formatErr:   lsp.NewFeatureNotSupportedError("formatting"),
+			wantContent: "package main\n\nfunc main() {\nfoo()\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			lspService := &lsp_mocks.MockLspService{}
+			lspService.On("NotifyDidOpen", mock.Anything, mock.Anything).Return(nil)
+			lspService.On("WaitForDiagnostics", mock.Anything, mock.Anything, time.Second).Return([]protocol.Diagnostic{}, nil)
+			if tt.formatErr != nil {
+				lspService.On("Format", mock.Anything, mock.Anything, lsp.FormatOptions{}).Return(nil, tt.formatErr)
+			} else {
+				lspService.On("Format", mock.Anything, mock.Anything, lsp.FormatOptions{}).Return(formatting, nil)
+			}
+
+			store := project.NewInMemoryStore(map[string]*model.Project{"project-id": {Id: "project-id", Path: root}})
+			pm := project.NewProjectManager(project.ManagerOptions{Store: store, ProjectsRoot: "/tmp", FileManager: files.NewFileManager(gitignore.NewMatcherFactory(), nil), LspService: lspService, DiagnosticsTimeout: time.Second})
+
+			file, err := pm.UpdateFile(context.Background(), "project-id", "main.go", "package main\n\nfunc main() {\nfoo()\n}\n", project.WriteFileWithFormatting())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(root, "main.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.wantContent, file.GetContent())
+			assert.Equal(t, tt.wantContent, string(content))
+			lspService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestManagerImpl_StartLanguageServer(t *testing.T) {
+	tests := []struct {
+		name      string
+		store     map[string]*model.Project
+		mockSetup func(*lsp_mocks.MockLspService)
+		wantErr   string
+	}{
+		{
+			name:  "success",
+			store: map[string]*model.Project{"project-id": {Id: "project-id"}},
+			mockSetup: func(m *lsp_mocks.MockLspService) {
+				m.On("StartServer", mock.Anything, lsp.TypeScript).Return(nil)
+			},
+		},
+		{
+			name:      "project not found",
+			store:     map[string]*model.Project{},
+			mockSetup: func(m *lsp_mocks.MockLspService) {},
+			wantErr:   "project project-id not found",
+		},
+		{
+			name:  "already running",
+			store: map[string]*model.Project{"project-id": {Id: "project-id"}},
+			mockSetup: func(m *lsp_mocks.MockLspService) {
+				m.On("StartServer", mock.Anything, lsp.TypeScript).Return(lsp.NewLanguageServerAlreadyExistsError("project-id", lsp.TypeScript))
+			},
+			wantErr: "Language server already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lspService := &lsp_mocks.MockLspService{}
+			tt.mockSetup(lspService)
+			pm := project.NewProjectManager(project.ManagerOptions{Store: project.NewInMemoryStore(tt.store), ProjectsRoot: "/tmp", LspService: lspService})
+
+			err := pm.StartLanguageServer(context.Background(), "project-id", lsp.TypeScript)
+
+			if tt.wantErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			lspService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestManagerImpl_StopLanguageServer(t *testing.T) {
+	lspService := &lsp_mocks.MockLspService{}
+	lspService.On("StopServer", mock.Anything, lsp.Go).Return(lsp.NewLanguageServerNotFoundError("project-id", lsp.Go))
+
+	pm := project.NewProjectManager(project.ManagerOptions{Store: project.NewInMemoryStore(map[string]*model.Project{"project-id": {Id: "project-id"}}), ProjectsRoot: "/tmp", LspService: lspService})
+
+	err := pm.StopLanguageServer(context.Background(), "project-id", lsp.Go)
+
+	var languageServerNotFoundError *lsp.LanguageServerNotFoundError
+	if !errors.As(err, &languageServerNotFoundError) {
+		t.Fatalf("expected LanguageServerNotFoundError, got %v", err)
+	}
+}
@@ -2,32 +2,66 @@ package mocks
 
 import (
 	"context"
+	"io"
+	"time"
 
+	"github.com/hide-org/hide/pkg/dap"
 	"github.com/hide-org/hide/pkg/devcontainer"
 	"github.com/hide-org/hide/pkg/files"
 	"github.com/hide-org/hide/pkg/lsp"
 	"github.com/hide-org/hide/pkg/model"
 	"github.com/hide-org/hide/pkg/project"
 	"github.com/hide-org/hide/pkg/result"
+	"github.com/sourcegraph/jsonrpc2"
 )
 
 // MockProjectManager is a mock of the project.Manager interface for testing
 type MockProjectManager struct {
-	ApplyPatchFunc       func(ctx context.Context, projectId, path, patch string) (*model.File, error)
-	CleanupFunc          func(ctx context.Context) error
-	CreateFileFunc       func(ctx context.Context, projectId, path, content string) (*model.File, error)
-	CreateProjectFunc    func(ctx context.Context, request project.CreateProjectRequest) <-chan result.Result[model.Project]
-	CreateTaskFunc       func(ctx context.Context, projectId string, command string) (project.TaskResult, error)
-	DeleteFileFunc       func(ctx context.Context, projectId, path string) error
-	DeleteProjectFunc    func(ctx context.Context, projectId string) error
-	GetProjectFunc       func(ctx context.Context, projectId string) (model.Project, error)
-	GetProjectsFunc      func(ctx context.Context) ([]*model.Project, error)
-	ListFilesFunc        func(ctx context.Context, projectId string, opts ...files.ListFileOption) (model.Files, error)
-	ReadFileFunc         func(ctx context.Context, projectId, path string) (*model.File, error)
-	ResolveTaskAliasFunc func(ctx context.Context, projectId string, alias string) (devcontainer.Task, error)
-	SearchSymbolsFunc    func(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error)
-	UpdateFileFunc       func(ctx context.Context, projectId, path, content string) (*model.File, error)
-	UpdateLinesFunc      func(ctx context.Context, projectId, path string, lineDiff files.LineDiffChunk) (*model.File, error)
+	ApplyPatchFunc            func(ctx context.Context, projectId, path, patch string, opts ...project.WriteFileOption) (*model.File, error)
+	CleanupFunc               func(ctx context.Context) error
+	CompleteFunc              func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, limit int) (*lsp.CompletionList, error)
+	CreateFileFunc            func(ctx context.Context, projectId, path, content string, opts ...project.WriteFileOption) (*model.File, error)
+	CreateProjectFunc         func(ctx context.Context, request project.CreateProjectRequest) <-chan result.Result[model.Project]
+	CreateTaskFunc            func(ctx context.Context, projectId string, command string) (project.TaskResult, error)
+	DeleteFileFunc            func(ctx context.Context, projectId, path string) error
+	DeleteProjectFunc         func(ctx context.Context, projectId string) error
+	DownloadArchiveFunc       func(ctx context.Context, projectId model.ProjectId, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error
+	FindDefinitionFunc        func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error)
+	FindReferencesFunc        func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, includeDeclaration bool) ([]lsp.Location, error)
+	FormatFileFunc            func(ctx context.Context, projectId model.ProjectId, path string, opts lsp.FormatOptions, dryRun bool) (*project.WorkspaceEditResult, error)
+	GetCallHierarchyFunc      func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.CallHierarchyDirection, depth int) ([]lsp.HierarchyItem, error)
+	GetDiagnosticsFunc        func(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter, refresh bool) (*lsp.DiagnosticsReport, error)
+	GetLanguageServersFunc    func(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error)
+	StartLanguageServerFunc   func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error
+	StopLanguageServerFunc    func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error
+	ConnectLanguageServerFunc func(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId, stream jsonrpc2.ObjectStream) error
+	GetOutlineFunc            func(ctx context.Context, projectId model.ProjectId, path string) ([]lsp.DocumentSymbol, error)
+	GetProjectFunc            func(ctx context.Context, projectId string) (model.Project, error)
+	GetProjectsFunc           func(ctx context.Context) ([]*model.Project, error)
+	GetTypeHierarchyFunc      func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.TypeHierarchyDirection, depth int) ([]lsp.HierarchyItem, error)
+	HoverFunc                 func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position) (*lsp.HoverInfo, error)
+	ListFilesFunc             func(ctx context.Context, projectId string, opts ...files.ListFileOption) (model.Files, error)
+	ReadFileFunc              func(ctx context.Context, projectId, path string, opts ...project.ReadFileOption) (*model.File, error)
+	GetCodeActionsFunc        func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range) ([]lsp.CodeAction, error)
+	ApplyCodeActionFunc       func(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range, title string, dryRun bool) (*project.WorkspaceEditResult, error)
+	RenameFunc                func(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, newName string, dryRun bool) (*project.WorkspaceEditResult, error)
+	ResolveTaskAliasFunc      func(ctx context.Context, projectId string, alias string) (devcontainer.Task, error)
+	SearchSymbolsFunc         func(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error)
+	SubscribeDiagnosticsFunc  func(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter) (<-chan lsp.FileDiagnostics, error)
+	SubscribeFileEventsFunc   func(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error)
+	UpdateFileFunc            func(ctx context.Context, projectId, path, content string, opts ...project.WriteFileOption) (*model.File, error)
+	UpdateLinesFunc           func(ctx context.Context, projectId, path string, lineDiff files.LineDiffChunk, opts ...project.WriteFileOption) (*model.File, error)
+	UploadArchiveFunc         func(ctx context.Context, projectId model.ProjectId, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error)
+	LaunchDebugSessionFunc    func(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error)
+	GetDebugSessionFunc       func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) (*dap.Session, error)
+	ListDebugSessionsFunc     func(ctx context.Context, projectId model.ProjectId) ([]dap.Session, error)
+	SetBreakpointsFunc        func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, path string, breakpoints []dap.SourceBreakpoint) ([]dap.Breakpoint, error)
+	StepDebugSessionFunc      func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, action dap.StepAction, threadId int, timeout time.Duration) (*dap.Session, error)
+	GetDebugThreadsFunc       func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) ([]dap.Thread, error)
+	GetDebugStackTraceFunc    func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, threadId int, levels int) ([]dap.StackFrame, error)
+	GetDebugScopesFunc        func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, frameId int) ([]dap.Scope, error)
+	GetDebugVariablesFunc     func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, variablesReference int) ([]dap.Variable, error)
+	TerminateDebugSessionFunc func(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) error
 }
 
 func (m *MockProjectManager) CreateProject(ctx context.Context, request project.CreateProjectRequest) <-chan result.Result[model.Project] {
@@ -58,16 +92,16 @@ func (m *MockProjectManager) Cleanup(ctx context.Context) error {
 	return m.CleanupFunc(ctx)
 }
 
-func (m *MockProjectManager) CreateFile(ctx context.Context, projectId, path, content string) (*model.File, error) {
-	return m.CreateFileFunc(ctx, projectId, path, content)
+func (m *MockProjectManager) CreateFile(ctx context.Context, projectId, path, content string, opts ...project.WriteFileOption) (*model.File, error) {
+	return m.CreateFileFunc(ctx, projectId, path, content, opts...)
 }
 
-func (m *MockProjectManager) ReadFile(ctx context.Context, projectId, path string) (*model.File, error) {
-	return m.ReadFileFunc(ctx, projectId, path)
+func (m *MockProjectManager) ReadFile(ctx context.Context, projectId, path string, opts ...project.ReadFileOption) (*model.File, error) {
+	return m.ReadFileFunc(ctx, projectId, path, opts...)
 }
 
-func (m *MockProjectManager) UpdateFile(ctx context.Context, projectId, path, content string) (*model.File, error) {
-	return m.UpdateFileFunc(ctx, projectId, path, content)
+func (m *MockProjectManager) UpdateFile(ctx context.Context, projectId, path, content string, opts ...project.WriteFileOption) (*model.File, error) {
+	return m.UpdateFileFunc(ctx, projectId, path, content, opts...)
 }
 
 func (m *MockProjectManager) DeleteFile(ctx context.Context, projectId, path string) error {
@@ -78,14 +112,134 @@ func (m *MockProjectManager) ListFiles(ctx context.Context, projectId string, op
 	return m.ListFilesFunc(ctx, projectId, opts...)
 }
 
-func (m *MockProjectManager) ApplyPatch(ctx context.Context, projectId, path, patch string) (*model.File, error) {
-	return m.ApplyPatchFunc(ctx, projectId, path, patch)
+func (m *MockProjectManager) ApplyPatch(ctx context.Context, projectId, path, patch string, opts ...project.WriteFileOption) (*model.File, error) {
+	return m.ApplyPatchFunc(ctx, projectId, path, patch, opts...)
 }
 
-func (m *MockProjectManager) UpdateLines(ctx context.Context, projectId, path string, lineDiff files.LineDiffChunk) (*model.File, error) {
-	return m.UpdateLinesFunc(ctx, projectId, path, lineDiff)
+func (m *MockProjectManager) UpdateLines(ctx context.Context, projectId, path string, lineDiff files.LineDiffChunk, opts ...project.WriteFileOption) (*model.File, error) {
+	return m.UpdateLinesFunc(ctx, projectId, path, lineDiff, opts...)
 }
 
 func (m *MockProjectManager) SearchSymbols(ctx context.Context, projectId model.ProjectId, query string, symbolFilter lsp.SymbolFilter) ([]lsp.SymbolInfo, error) {
 	return m.SearchSymbolsFunc(ctx, projectId, query, symbolFilter)
 }
+
+func (m *MockProjectManager) SubscribeFileEvents(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error) {
+	return m.SubscribeFileEventsFunc(ctx, projectId)
+}
+
+func (m *MockProjectManager) DownloadArchive(ctx context.Context, projectId model.ProjectId, w io.Writer, path string, format files.ArchiveFormat, opts ...files.ListFileOption) error {
+	return m.DownloadArchiveFunc(ctx, projectId, w, path, format, opts...)
+}
+
+func (m *MockProjectManager) UploadArchive(ctx context.Context, projectId model.ProjectId, r io.Reader, path string, format files.ArchiveFormat, overwrite files.OverwritePolicy) (*files.ExtractResult, error) {
+	return m.UploadArchiveFunc(ctx, projectId, r, path, format, overwrite)
+}
+
+func (m *MockProjectManager) FindDefinition(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, kind lsp.DefinitionKind) ([]lsp.Location, error) {
+	return m.FindDefinitionFunc(ctx, projectId, path, position, kind)
+}
+
+func (m *MockProjectManager) FindReferences(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, includeDeclaration bool) ([]lsp.Location, error) {
+	return m.FindReferencesFunc(ctx, projectId, path, position, includeDeclaration)
+}
+
+func (m *MockProjectManager) Complete(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, limit int) (*lsp.CompletionList, error) {
+	return m.CompleteFunc(ctx, projectId, path, position, limit)
+}
+
+func (m *MockProjectManager) GetCallHierarchy(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.CallHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+	return m.GetCallHierarchyFunc(ctx, projectId, path, position, direction, depth)
+}
+
+func (m *MockProjectManager) GetTypeHierarchy(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, direction lsp.TypeHierarchyDirection, depth int) ([]lsp.HierarchyItem, error) {
+	return m.GetTypeHierarchyFunc(ctx, projectId, path, position, direction, depth)
+}
+
+func (m *MockProjectManager) GetDiagnostics(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter, refresh bool) (*lsp.DiagnosticsReport, error) {
+	return m.GetDiagnosticsFunc(ctx, projectId, filter, refresh)
+}
+
+func (m *MockProjectManager) SubscribeDiagnostics(ctx context.Context, projectId model.ProjectId, filter lsp.DiagnosticsFilter) (<-chan lsp.FileDiagnostics, error) {
+	return m.SubscribeDiagnosticsFunc(ctx, projectId, filter)
+}
+
+func (m *MockProjectManager) GetLanguageServers(ctx context.Context, projectId model.ProjectId) ([]lsp.ServerHealth, error) {
+	return m.GetLanguageServersFunc(ctx, projectId)
+}
+
+func (m *MockProjectManager) StartLanguageServer(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+	return m.StartLanguageServerFunc(ctx, projectId, languageId)
+}
+
+func (m *MockProjectManager) StopLanguageServer(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId) error {
+	return m.StopLanguageServerFunc(ctx, projectId, languageId)
+}
+
+func (m *MockProjectManager) ConnectLanguageServer(ctx context.Context, projectId model.ProjectId, languageId lsp.LanguageId, stream jsonrpc2.ObjectStream) error {
+	return m.ConnectLanguageServerFunc(ctx, projectId, languageId, stream)
+}
+
+func (m *MockProjectManager) Hover(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position) (*lsp.HoverInfo, error) {
+	return m.HoverFunc(ctx, projectId, path, position)
+}
+
+func (m *MockProjectManager) Rename(ctx context.Context, projectId model.ProjectId, path string, position lsp.Position, newName string, dryRun bool) (*project.WorkspaceEditResult, error) {
+	return m.RenameFunc(ctx, projectId, path, position, newName, dryRun)
+}
+
+func (m *MockProjectManager) GetCodeActions(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range) ([]lsp.CodeAction, error) {
+	return m.GetCodeActionsFunc(ctx, projectId, path, rng)
+}
+
+func (m *MockProjectManager) ApplyCodeAction(ctx context.Context, projectId model.ProjectId, path string, rng lsp.Range, title string, dryRun bool) (*project.WorkspaceEditResult, error) {
+	return m.ApplyCodeActionFunc(ctx, projectId, path, rng, title, dryRun)
+}
+
+func (m *MockProjectManager) FormatFile(ctx context.Context, projectId model.ProjectId, path string, opts lsp.FormatOptions, dryRun bool) (*project.WorkspaceEditResult, error) {
+	return m.FormatFileFunc(ctx, projectId, path, opts, dryRun)
+}
+
+func (m *MockProjectManager) GetOutline(ctx context.Context, projectId model.ProjectId, path string) ([]lsp.DocumentSymbol, error) {
+	return m.GetOutlineFunc(ctx, projectId, path)
+}
+
+func (m *MockProjectManager) LaunchDebugSession(ctx context.Context, projectId model.ProjectId, opts dap.LaunchOptions, timeout time.Duration) (*dap.Session, error) {
+	return m.LaunchDebugSessionFunc(ctx, projectId, opts, timeout)
+}
+
+func (m *MockProjectManager) GetDebugSession(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) (*dap.Session, error) {
+	return m.GetDebugSessionFunc(ctx, projectId, sessionId)
+}
+
+func (m *MockProjectManager) ListDebugSessions(ctx context.Context, projectId model.ProjectId) ([]dap.Session, error) {
+	return m.ListDebugSessionsFunc(ctx, projectId)
+}
+
+func (m *MockProjectManager) SetBreakpoints(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, path string, breakpoints []dap.SourceBreakpoint) ([]dap.Breakpoint, error) {
+	return m.SetBreakpointsFunc(ctx, projectId, sessionId, path, breakpoints)
+}
+
+func (m *MockProjectManager) StepDebugSession(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, action dap.StepAction, threadId int, timeout time.Duration) (*dap.Session, error) {
+	return m.StepDebugSessionFunc(ctx, projectId, sessionId, action, threadId, timeout)
+}
+
+func (m *MockProjectManager) GetDebugThreads(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) ([]dap.Thread, error) {
+	return m.GetDebugThreadsFunc(ctx, projectId, sessionId)
+}
+
+func (m *MockProjectManager) GetDebugStackTrace(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, threadId int, levels int) ([]dap.StackFrame, error) {
+	return m.GetDebugStackTraceFunc(ctx, projectId, sessionId, threadId, levels)
+}
+
+func (m *MockProjectManager) GetDebugScopes(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, frameId int) ([]dap.Scope, error) {
+	return m.GetDebugScopesFunc(ctx, projectId, sessionId, frameId)
+}
+
+func (m *MockProjectManager) GetDebugVariables(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId, variablesReference int) ([]dap.Variable, error) {
+	return m.GetDebugVariablesFunc(ctx, projectId, sessionId, variablesReference)
+}
+
+func (m *MockProjectManager) TerminateDebugSession(ctx context.Context, projectId model.ProjectId, sessionId dap.SessionId) error {
+	return m.TerminateDebugSessionFunc(ctx, projectId, sessionId)
+}
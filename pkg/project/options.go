@@ -0,0 +1,27 @@
+package project
+
+type ReadFileOptions struct {
+	SkipDiagnostics bool
+}
+
+type ReadFileOption func(opts *ReadFileOptions)
+
+// ReadFileWithoutDiagnostics skips waiting for the diagnostics of the file, the file is not opened in its language server
+func ReadFileWithoutDiagnostics() ReadFileOption {
+	return func(opts *ReadFileOptions) {
+		opts.SkipDiagnostics = true
+	}
+}
+
+type WriteFileOptions struct {
+	Format bool
+}
+
+type WriteFileOption func(opts *WriteFileOptions)
+
+// WriteFileWithFormatting formats the written file with its language server before it is returned, the file is kept as it was written if it can't be formatted
+func WriteFileWithFormatting() WriteFileOption {
+	return func(opts *WriteFileOptions) {
+		opts.Format = true
+	}
+}
@@ -0,0 +1,97 @@
+package watcher
+
+import "github.com/hide-org/hide/pkg/model"
+
+// batch accumulates file events between two flushes and coalesces events for the same path,
+// e.g. a file that is created and then modified is reported as created.
+type batch struct {
+	events map[string]model.FileEvent
+	order  []string
+	// old path of the last rename that has not been paired with a create yet
+	lastRename string
+}
+
+func newBatch() *batch {
+	return &batch{events: make(map[string]model.FileEvent)}
+}
+
+func (b *batch) add(event model.FileEvent) {
+	if event.Type == model.FileCreated && b.lastRename != "" {
+		oldPath := b.lastRename
+		b.lastRename = ""
+
+		// rename is reported by the OS as a rename of the old path followed by a create of the new path
+		if prev, ok := b.events[oldPath]; ok && prev.Type == model.FileDeleted {
+			delete(b.events, oldPath)
+			event = model.FileEvent{Type: model.FileRenamed, Path: event.Path, OldPath: oldPath, IsDir: event.IsDir}
+		}
+	} else {
+		b.lastRename = ""
+	}
+
+	prev, ok := b.events[event.Path]
+	if !ok {
+		b.events[event.Path] = event
+		b.order = append(b.order, event.Path)
+		return
+	}
+
+	merged, keep := merge(prev, event)
+	if !keep {
+		delete(b.events, event.Path)
+		return
+	}
+
+	b.events[event.Path] = merged
+}
+
+// rename records a rename of the given path. Until it is paired with a create, it is reported as a delete.
+func (b *batch) rename(path string, isDir bool) {
+	b.add(model.FileEvent{Type: model.FileDeleted, Path: path, IsDir: isDir})
+	b.lastRename = path
+}
+
+func (b *batch) isEmpty() bool {
+	return len(b.events) == 0
+}
+
+// flush returns the accumulated events in the order they first occurred and resets the batch.
+func (b *batch) flush() []model.FileEvent {
+	events := make([]model.FileEvent, 0, len(b.events))
+	seen := make(map[string]bool, len(b.order))
+
+	for _, path := range b.order {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		if event, ok := b.events[path]; ok {
+			events = append(events, event)
+		}
+	}
+
+	b.events = make(map[string]model.FileEvent)
+	b.order = nil
+	b.lastRename = ""
+
+	return events
+}
+
+// merge combines two consecutive events for the same path. Returns false if the events cancel each other out.
+func merge(prev, next model.FileEvent) (model.FileEvent, bool) {
+	switch {
+	case prev.Type == model.FileCreated && next.Type == model.FileModified:
+		return prev, true
+	case prev.Type == model.FileCreated && next.Type == model.FileDeleted:
+		return model.FileEvent{}, false
+	case prev.Type == model.FileDeleted && next.Type == model.FileCreated:
+		return model.FileEvent{Type: model.FileModified, Path: next.Path, IsDir: next.IsDir}, true
+	case prev.Type == model.FileRenamed && next.Type == model.FileModified:
+		return prev, true
+	case prev.Type == model.FileRenamed && next.Type == model.FileDeleted:
+		return model.FileEvent{Type: model.FileDeleted, Path: prev.OldPath, IsDir: prev.IsDir}, true
+	default:
+		return next, true
+	}
+}
@@ -0,0 +1,114 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch_Flush(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply func(b *batch)
+		want  []model.FileEvent
+	}{
+		{
+			name: "create and modify",
+			apply: func(b *batch) {
+				b.add(model.FileEvent{Type: model.FileCreated, Path: "a.go"})
+				b.add(model.FileEvent{Type: model.FileModified, Path: "a.go"})
+			},
+			want: []model.FileEvent{{Type: model.FileCreated, Path: "a.go"}},
+		},
+		{
+			name: "create and delete",
+			apply: func(b *batch) {
+				b.add(model.FileEvent{Type: model.FileCreated, Path: "a.go"})
+				b.add(model.FileEvent{Type: model.FileDeleted, Path: "a.go"})
+			},
+			want: []model.FileEvent{},
+		},
+		{
+			name: "delete and create",
+			apply: func(b *batch) {
+				b.add(model.FileEvent{Type: model.FileDeleted, Path: "a.go"})
+				b.add(model.FileEvent{Type: model.FileCreated, Path: "a.go"})
+			},
+			want: []model.FileEvent{{Type: model.FileModified, Path: "a.go"}},
+		},
+		{
+			name: "rename",
+			apply: func(b *batch) {
+				b.rename("a.go", false)
+				b.add(model.FileEvent{Type: model.FileCreated, Path: "b.go"})
+				b.add(model.FileEvent{Type: model.FileModified, Path: "b.go"})
+			},
+			want: []model.FileEvent{{Type: model.FileRenamed, Path: "b.go", OldPath: "a.go"}},
+		},
+		{
+			name: "rename without create",
+			apply: func(b *batch) {
+				b.rename("a.go", false)
+				b.add(model.FileEvent{Type: model.FileModified, Path: "c.go"})
+				b.add(model.FileEvent{Type: model.FileCreated, Path: "b.go"})
+			},
+			want: []model.FileEvent{
+				{Type: model.FileDeleted, Path: "a.go"},
+				{Type: model.FileModified, Path: "c.go"},
+				{Type: model.FileCreated, Path: "b.go"},
+			},
+		},
+		{
+			name: "keeps order",
+			apply: func(b *batch) {
+				b.add(model.FileEvent{Type: model.FileModified, Path: "b.go"})
+				b.add(model.FileEvent{Type: model.FileModified, Path: "a.go"})
+				b.add(model.FileEvent{Type: model.FileModified, Path: "b.go"})
+			},
+			want: []model.FileEvent{
+				{Type: model.FileModified, Path: "b.go"},
+				{Type: model.FileModified, Path: "a.go"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBatch()
+			tt.apply(b)
+
+			assert.Equal(t, tt.want, b.flush())
+			assert.True(t, b.isEmpty())
+		})
+	}
+}
+
+func TestSubscriber_SlowReceiver(t *testing.T) {
+	sub := newSubscriber()
+	go sub.run()
+	defer sub.close()
+
+	// more batches than the channel holds, none of them are received until all are published
+	for i := 0; i < subscriberQueue*2; i++ {
+		sub.add([]model.FileEvent{{Type: model.FileModified, Path: "a.go"}})
+	}
+	sub.add([]model.FileEvent{{Type: model.FileCreated, Path: "b.go"}})
+
+	received := map[string]model.FileEvent{}
+	timeout := time.After(time.Second)
+	for len(received) < 2 {
+		select {
+		case events := <-sub.ch:
+			for _, event := range events {
+				received[event.Path] = event
+			}
+		case <-timeout:
+			t.Fatalf("events were lost, received %v", received)
+		}
+	}
+
+	assert.Equal(t, model.FileEvent{Type: model.FileModified, Path: "a.go"}, received["a.go"])
+	assert.Equal(t, model.FileEvent{Type: model.FileCreated, Path: "b.go"}, received["b.go"])
+}
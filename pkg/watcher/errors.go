@@ -0,0 +1,31 @@
+package watcher
+
+import (
+	"fmt"
+
+	"github.com/hide-org/hide/pkg/model"
+)
+
+type WatcherNotFoundError struct {
+	projectId model.ProjectId
+}
+
+func (e WatcherNotFoundError) Error() string {
+	return fmt.Sprintf("project %s is not watched", e.projectId)
+}
+
+func NewWatcherNotFoundError(projectId model.ProjectId) *WatcherNotFoundError {
+	return &WatcherNotFoundError{projectId: projectId}
+}
+
+type WatcherAlreadyExistsError struct {
+	projectId model.ProjectId
+}
+
+func (e WatcherAlreadyExistsError) Error() string {
+	return fmt.Sprintf("project %s is already watched", e.projectId)
+}
+
+func NewWatcherAlreadyExistsError(projectId model.ProjectId) *WatcherAlreadyExistsError {
+	return &WatcherAlreadyExistsError{projectId: projectId}
+}
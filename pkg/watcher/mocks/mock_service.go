@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/watcher"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ watcher.Service = (*MockWatcherService)(nil)
+
+type MockWatcherService struct {
+	mock.Mock
+}
+
+func (m *MockWatcherService) Watch(projectId model.ProjectId, root string) error {
+	args := m.Called(projectId, root)
+	return args.Error(0)
+}
+
+func (m *MockWatcherService) Unwatch(projectId model.ProjectId) error {
+	args := m.Called(projectId)
+	return args.Error(0)
+}
+
+func (m *MockWatcherService) Subscribe(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error) {
+	args := m.Called(ctx, projectId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(<-chan []model.FileEvent), args.Error(1)
+}
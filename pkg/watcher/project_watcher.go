@@ -0,0 +1,406 @@
+package watcher
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hide-org/hide/pkg/gitignore"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/afero"
+)
+
+const (
+	gitDir          = ".git"
+	gitignoreFile   = ".gitignore"
+	subscriberQueue = 64
+)
+
+// projectWatcher watches a single project directory recursively and publishes debounced events to subscribers.
+type projectWatcher struct {
+	projectId      model.ProjectId
+	root           string
+	debounce       time.Duration
+	matcherFactory gitignore.MatcherFactory
+	matcher        gitignore.Matcher
+	fsWatcher      *fsnotify.Watcher
+	dirs           map[string]struct{}
+	subscribers    map[chan []model.FileEvent]*subscriber
+	mu             sync.Mutex
+	done           chan struct{}
+	closeOnce      sync.Once
+}
+
+func newProjectWatcher(projectId model.ProjectId, root string, matcherFactory gitignore.MatcherFactory, debounce time.Duration) (*projectWatcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file system watcher: %w", err)
+	}
+
+	w := &projectWatcher{
+		projectId:      projectId,
+		root:           root,
+		debounce:       debounce,
+		matcherFactory: matcherFactory,
+		fsWatcher:      fsWatcher,
+		dirs:           make(map[string]struct{}),
+		subscribers:    make(map[chan []model.FileEvent]*subscriber),
+		done:           make(chan struct{}),
+	}
+
+	if err := w.reloadMatcher(); err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+
+	if _, err := w.addDir(root); err != nil {
+		fsWatcher.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", root, err)
+	}
+
+	return w, nil
+}
+
+func (w *projectWatcher) start() {
+	go w.run()
+}
+
+func (w *projectWatcher) close() error {
+	var err error
+
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.fsWatcher.Close()
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		for _, sub := range w.subscribers {
+			sub.close()
+		}
+		w.subscribers = make(map[chan []model.FileEvent]*subscriber)
+	})
+
+	return err
+}
+
+func (w *projectWatcher) subscribe() chan []model.FileEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sub := newSubscriber()
+	w.subscribers[sub.ch] = sub
+	go sub.run()
+	return sub.ch
+}
+
+func (w *projectWatcher) unsubscribe(ch chan []model.FileEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if sub, ok := w.subscribers[ch]; ok {
+		delete(w.subscribers, ch)
+		sub.close()
+	}
+}
+
+func (w *projectWatcher) run() {
+	b := newBatch()
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	var flush <-chan time.Time
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return
+			}
+
+			w.handle(b, event)
+
+			if b.isEmpty() {
+				continue
+			}
+
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(w.debounce)
+			flush = timer.C
+		case err, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+
+			log.Warn().Err(err).Str("projectId", w.projectId).Msg("File system watcher error")
+		case <-flush:
+			flush = nil
+			if events := b.flush(); len(events) > 0 {
+				w.publish(events)
+			}
+		}
+	}
+}
+
+func (w *projectWatcher) handle(b *batch, event fsnotify.Event) {
+	path, err := filepath.Rel(w.root, event.Name)
+	if err != nil {
+		log.Warn().Err(err).Str("projectId", w.projectId).Str("path", event.Name).Msg("Failed to get relative path of changed file")
+		return
+	}
+
+	switch {
+	case event.Has(fsnotify.Create):
+		info, err := os.Lstat(event.Name)
+		if err != nil {
+			// file is already gone
+			return
+		}
+
+		if w.ignored(path, info.IsDir()) {
+			return
+		}
+
+		renamed := b.lastRename != ""
+		b.add(model.FileEvent{Type: model.FileCreated, Path: path, IsDir: info.IsDir()})
+
+		if info.IsDir() {
+			created, err := w.addDir(event.Name)
+			if err != nil {
+				log.Warn().Err(err).Str("projectId", w.projectId).Str("path", path).Msg("Failed to watch directory")
+			}
+
+			// files created before the watch was added would be missed otherwise; renamed directories keep their content
+			if !renamed {
+				for _, p := range created {
+					b.add(p)
+				}
+			}
+		}
+	case event.Has(fsnotify.Write):
+		if w.ignored(path, false) {
+			return
+		}
+
+		b.add(model.FileEvent{Type: model.FileModified, Path: path})
+	case event.Has(fsnotify.Remove):
+		isDir := w.removeDir(event.Name)
+		if w.ignored(path, isDir) {
+			return
+		}
+
+		b.add(model.FileEvent{Type: model.FileDeleted, Path: path, IsDir: isDir})
+	case event.Has(fsnotify.Rename):
+		isDir := w.removeDir(event.Name)
+		if w.ignored(path, isDir) {
+			return
+		}
+
+		b.rename(path, isDir)
+	default:
+		// chmod events are not interesting
+		return
+	}
+
+	if filepath.Base(path) == gitignoreFile {
+		if err := w.reloadMatcher(); err != nil {
+			log.Warn().Err(err).Str("projectId", w.projectId).Msg("Failed to reload gitignore patterns")
+		}
+	}
+}
+
+// addDir watches the directory and all its subdirectories that are not ignored.
+// Returns create events for the files and directories found under the directory.
+func (w *projectWatcher) addDir(dir string) ([]model.FileEvent, error) {
+	var events []model.FileEvent
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			// an unreadable directory must not stop the rest of the project from being watched
+			log.Warn().Err(err).Str("projectId", w.projectId).Str("path", path).Msg("Failed to read directory, skipping it")
+			return fs.SkipDir
+		}
+
+		rel, err := filepath.Rel(w.root, path)
+		if err != nil {
+			return err
+		}
+
+		if rel != "." && w.ignored(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if path != dir {
+			events = append(events, model.FileEvent{Type: model.FileCreated, Path: rel, IsDir: d.IsDir()})
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if err := w.fsWatcher.Add(path); err != nil {
+			if path == dir {
+				return fmt.Errorf("failed to watch directory %s: %w", path, err)
+			}
+			log.Warn().Err(err).Str("projectId", w.projectId).Str("path", path).Msg("Failed to watch directory, skipping it")
+			return fs.SkipDir
+		}
+
+		w.mu.Lock()
+		w.dirs[path] = struct{}{}
+		w.mu.Unlock()
+
+		return nil
+	})
+
+	return events, err
+}
+
+// removeDir stops watching the directory and its subdirectories. Returns false if the path is not a watched directory.
+func (w *projectWatcher) removeDir(dir string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.dirs[dir]; !ok {
+		return false
+	}
+
+	prefix := dir + string(filepath.Separator)
+	for d := range w.dirs {
+		if d == dir || strings.HasPrefix(d, prefix) {
+			// the watch might be already removed by the OS
+			_ = w.fsWatcher.Remove(d)
+			delete(w.dirs, d)
+		}
+	}
+
+	return true
+}
+
+func (w *projectWatcher) ignored(path string, isDir bool) bool {
+	if path == gitDir || strings.HasPrefix(path, gitDir+string(filepath.Separator)) {
+		return true
+	}
+
+	w.mu.Lock()
+	matcher := w.matcher
+	w.mu.Unlock()
+
+	match, err := matcher.Match(path, isDir)
+	if err != nil {
+		log.Warn().Err(err).Str("projectId", w.projectId).Str("path", path).Msg("Failed to match path against gitignore")
+		return false
+	}
+
+	return match
+}
+
+func (w *projectWatcher) reloadMatcher() error {
+	matcher, err := w.matcherFactory.NewMatcher(afero.NewBasePathFs(afero.NewOsFs(), w.root))
+	if err != nil {
+		return fmt.Errorf("failed to create gitignore matcher: %w", err)
+	}
+
+	w.mu.Lock()
+	w.matcher = matcher
+	w.mu.Unlock()
+
+	return nil
+}
+
+func (w *projectWatcher) publish(events []model.FileEvent) {
+	log.Debug().Str("projectId", w.projectId).Int("events", len(events)).Msg("Publishing file events")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, sub := range w.subscribers {
+		sub.add(events)
+	}
+}
+
+// subscriber delivers the events of a watcher to one channel. Events that the subscriber didn't receive yet
+// are coalesced per path, so that a slow subscriber gets fewer events instead of losing some.
+type subscriber struct {
+	ch      chan []model.FileEvent
+	mu      sync.Mutex
+	pending *batch
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newSubscriber() *subscriber {
+	return &subscriber{
+		ch:      make(chan []model.FileEvent, subscriberQueue),
+		pending: newBatch(),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (s *subscriber) add(events []model.FileEvent) {
+	s.mu.Lock()
+	for _, event := range events {
+		s.pending.add(event)
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscriber) run() {
+	defer close(s.stopped)
+
+	for {
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+
+		s.mu.Lock()
+		events := s.pending.flush()
+		s.mu.Unlock()
+
+		if len(events) == 0 {
+			continue
+		}
+
+		select {
+		case s.ch <- events:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// close stops the delivery and closes the channel, pending events are discarded.
+func (s *subscriber) close() {
+	close(s.done)
+	<-s.stopped
+	close(s.ch)
+}
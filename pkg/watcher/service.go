@@ -0,0 +1,92 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hide-org/hide/pkg/gitignore"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const DefaultDebounce = 100 * time.Millisecond
+
+// Service watches project workspaces for changes made outside of Hide, e.g. by tasks running in the devcontainer.
+type Service interface {
+	Watch(projectId model.ProjectId, root string) error
+	Unwatch(projectId model.ProjectId) error
+	// Subscribe returns a channel of debounced file events. The channel is closed when the context is done or the project is unwatched.
+	Subscribe(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error)
+}
+
+type ServiceImpl struct {
+	matcherFactory gitignore.MatcherFactory
+	debounce       time.Duration
+	watchers       map[model.ProjectId]*projectWatcher
+	mu             sync.Mutex
+}
+
+func NewService(matcherFactory gitignore.MatcherFactory, debounce time.Duration) Service {
+	return &ServiceImpl{
+		matcherFactory: matcherFactory,
+		debounce:       debounce,
+		watchers:       make(map[model.ProjectId]*projectWatcher),
+	}
+}
+
+func (s *ServiceImpl) Watch(projectId model.ProjectId, root string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.watchers[projectId]; ok {
+		return NewWatcherAlreadyExistsError(projectId)
+	}
+
+	w, err := newProjectWatcher(projectId, root, s.matcherFactory, s.debounce)
+	if err != nil {
+		return err
+	}
+
+	w.start()
+	s.watchers[projectId] = w
+
+	log.Debug().Str("projectId", projectId).Str("root", root).Msg("Started watching project")
+	return nil
+}
+
+func (s *ServiceImpl) Unwatch(projectId model.ProjectId) error {
+	s.mu.Lock()
+	w, ok := s.watchers[projectId]
+	delete(s.watchers, projectId)
+	s.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	log.Debug().Str("projectId", projectId).Msg("Stopped watching project")
+	return w.close()
+}
+
+func (s *ServiceImpl) Subscribe(ctx context.Context, projectId model.ProjectId) (<-chan []model.FileEvent, error) {
+	s.mu.Lock()
+	w, ok := s.watchers[projectId]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, NewWatcherNotFoundError(projectId)
+	}
+
+	ch := w.subscribe()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.unsubscribe(ch)
+		case <-w.done:
+		}
+	}()
+
+	return ch, nil
+}
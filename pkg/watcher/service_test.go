@@ -0,0 +1,146 @@
+package watcher_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hide-org/hide/pkg/gitignore"
+	gitignore_mocks "github.com/hide-org/hide/pkg/gitignore/mocks"
+	"github.com/hide-org/hide/pkg/model"
+	"github.com/hide-org/hide/pkg/watcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_Subscribe(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("node_modules\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "old.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	service := watcher.NewService(gitignore.NewMatcherFactory(), 20*time.Millisecond)
+	if err := service.Watch("project-id", root); err != nil {
+		t.Fatal(err)
+	}
+	defer service.Unwatch("project-id")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := service.Subscribe(ctx, "project-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "node_modules"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "node_modules", "index.js"), []byte(""), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(root, "old.go"), filepath.Join(root, "new.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := collect(t, events, 2)
+
+	assert.ElementsMatch(t, []model.FileEvent{
+		{Type: model.FileCreated, Path: "main.go"},
+		{Type: model.FileRenamed, Path: "new.go", OldPath: "old.go"},
+	}, got)
+}
+
+func TestService_Watch_UnreadableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("directory permissions do not apply to root")
+	}
+
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "private"), 0o000); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(filepath.Join(root, "private"), 0o755)
+
+	// the gitignore files are not read, only the walk of the watcher is tested
+	matcherFactory := gitignore_mocks.NewMockMatcherFactory()
+	matcherFactory.On("NewMatcher", mock.Anything).Return(gitignore.NewPatternMatcher(nil), nil)
+
+	service := watcher.NewService(matcherFactory, 20*time.Millisecond)
+	if err := service.Watch("project-id", root); err != nil {
+		t.Fatal(err)
+	}
+	defer service.Unwatch("project-id")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := service.Subscribe(ctx, "project-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []model.FileEvent{{Type: model.FileCreated, Path: "main.go"}}, collect(t, events, 1))
+}
+
+func TestService_Subscribe_NotWatched(t *testing.T) {
+	service := watcher.NewService(gitignore.NewMatcherFactory(), 20*time.Millisecond)
+
+	_, err := service.Subscribe(context.Background(), "project-id")
+
+	var notFound *watcher.WatcherNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestService_Unwatch_ClosesSubscriptions(t *testing.T) {
+	service := watcher.NewService(gitignore.NewMatcherFactory(), 20*time.Millisecond)
+	if err := service.Watch("project-id", t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := service.Subscribe(context.Background(), "project-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Unwatch("project-id"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+}
+
+func collect(t *testing.T, events <-chan []model.FileEvent, n int) []model.FileEvent {
+	t.Helper()
+
+	var got []model.FileEvent
+	timeout := time.After(2 * time.Second)
+
+	for len(got) < n {
+		select {
+		case batch := <-events:
+			got = append(got, batch...)
+		case <-timeout:
+			t.Fatalf("timed out waiting for events, got %+v", got)
+		}
+	}
+
+	return got
+}